@@ -9,16 +9,24 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	fiberLogger "github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	adoptionAPI "github.com/solrac97gr/petparadise/internal/adoptions/infrastructure/api"
 	donationAPI "github.com/solrac97gr/petparadise/internal/donations/infrastructure/api"
+	petModels "github.com/solrac97gr/petparadise/internal/pets/domain/models"
 	petAPI "github.com/solrac97gr/petparadise/internal/pets/infrastructure/api"
+	"github.com/solrac97gr/petparadise/internal/pets/infrastructure/sponsorship"
+	shelterAPI "github.com/solrac97gr/petparadise/internal/shelters/infrastructure/api"
+	userModels "github.com/solrac97gr/petparadise/internal/users/domain/models"
 	userAPI "github.com/solrac97gr/petparadise/internal/users/infrastructure/api"
+	volunteerAPI "github.com/solrac97gr/petparadise/internal/volunteers/infrastructure/api"
+	"github.com/solrac97gr/petparadise/pkg/audit"
 	"github.com/solrac97gr/petparadise/pkg/auth"
 	"github.com/solrac97gr/petparadise/pkg/config"
 	"github.com/solrac97gr/petparadise/pkg/database"
 	"github.com/solrac97gr/petparadise/pkg/logger"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
 )
 
 func main() {
@@ -30,8 +38,29 @@ func main() {
 	defer appLogger.Sync()
 
 	// Initialize JWT secret
+	if cfg.Environment == "production" && cfg.JWTSecret == config.DefaultJWTSecret {
+		appLogger.Fatal("JWT_SECRET must be set in production")
+	}
 	auth.InitJWTSecret(cfg)
 
+	// Sign access tokens with an asymmetric key, published at /.well-known/jwks.json
+	if cfg.JWTSigningKeyFile != "" {
+		if err := auth.LoadSigningKeys(cfg.JWTSigningKeyFile, cfg.JWTVerificationKeyFiles); err != nil {
+			appLogger.Fatal("Failed to load JWT signing keys: " + err.Error())
+		}
+	}
+
+	// Roles that must use two-factor authentication on sensitive routes
+	var mfaRoles []userModels.Role
+	for _, name := range cfg.MFARequiredRoles {
+		role := userModels.Role(name)
+		if !role.IsValid() {
+			appLogger.Fatal("Invalid role in MFA_REQUIRED_ROLES: " + name)
+		}
+		mfaRoles = append(mfaRoles, role)
+	}
+	auth.SetMFARequiredRoles(mfaRoles...)
+
 	// Connect to the database
 	db, err := sqlx.Connect("postgres", cfg.DatabaseURL)
 	if err != nil {
@@ -51,20 +80,55 @@ func main() {
 
 	appLogger.Info("Connected to database")
 
+	// Keep issued refresh tokens, sessions and revocations in the database so they survive restarts
+	auth.SetRefreshTokenStore(auth.NewPostgresRefreshTokenStore(db))
+	auth.SetSessionStore(auth.NewPostgresSessionStore(db))
+	auth.SetRevocationStore(auth.NewPostgresRevocationStore(db))
+
+	// Load role permissions from the database so admins can edit them at runtime
+	auth.SetPermissionStore(auth.NewPostgresPermissionStore(db))
+
+	// Keep API keys for machine clients in the database
+	auth.SetAPIKeyStore(auth.NewPostgresAPIKeyStore(db))
+
+	// Keep the audit log of changes across modules in the database
+	audit.SetStore(audit.NewPostgresStore(db))
+
+	// Periodically purge expired refresh tokens, sessions and revocations
+	stopSweeper := auth.StartSweeper(auth.SweepInterval)
+	defer stopSweeper()
+
+	// Initialize mailer
+	mail, err := mailer.New(cfg)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize mailer: " + err.Error())
+	}
+
 	// Initialize Fiber app
-	app := fiber.New(fiber.Config{
+	appConfig := fiber.Config{
 		AppName: "Pet Paradise API",
-	})
+	}
+
+	// Behind a reverse proxy, client IP addresses (used to throttle logins) come from X-Forwarded-For
+	if len(cfg.TrustedProxies) > 0 {
+		appConfig.EnableTrustedProxyCheck = true
+		appConfig.TrustedProxies = cfg.TrustedProxies
+		appConfig.ProxyHeader = fiber.HeaderXForwardedFor
+		appConfig.EnableIPValidation = true
+	}
+
+	app := fiber.New(appConfig)
 
 	// Middleware
 	app.Use(recover.New())
+	app.Use(requestid.New()) // X-Request-ID, recorded in the audit log
 	app.Use(fiberLogger.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     strings.Join(cfg.CORSAllowedOrigins, ","),
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-API-Key",
 		AllowMethods:     "GET, POST, PUT, DELETE, PATCH",
 		AllowCredentials: true,
-		ExposeHeaders:    "Authorization",
+		ExposeHeaders:    "Authorization, X-Request-ID",
 	}))
 
 	// Root route
@@ -80,24 +144,57 @@ func main() {
 		})
 	})
 
+	// Keys other services verify access tokens with
+	app.Get("/.well-known/jwks.json", auth.JWKSHandler())
+
 	// API routes
 	api := app.Group("/api")
 
+	// Shelters routes
+	shelters := api.Group("/shelters")
+	shelterAPI.SetupShelterRoutes(shelters, db)
+
 	// Users routes
 	users := api.Group("/users")
-	userAPI.SetupUserRoutes(users, db)
+	accountDeletions := userAPI.SetupUserRoutes(users, db, mail, cfg)
+
+	// Erase the accounts whose grace period is over
+	stopEraser := accountDeletions.StartEraser(userModels.AccountErasureInterval)
+	defer stopEraser()
 
-	// Pets routes
+	// Donations routes
+	donations := api.Group("/donations")
+	donationService := donationAPI.SetupDonationRoutes(donations, db, mail)
+
+	// Pets routes, with their sponsorships from the donations module
 	pets := api.Group("/pets")
-	petAPI.SetupPetRoutes(pets, db)
+	sponsorships := sponsorship.NewDonationsGateway(donationService)
+	petAPI.SetupPetRoutes(pets, db, sponsorships, sponsorships)
 
 	// Adoptions routes
 	adoptions := api.Group("/adoptions")
 	adoptionAPI.SetupAdoptionRoutes(adoptions, db)
 
-	// Donations routes
-	donations := api.Group("/donations")
-	donationAPI.SetupDonationRoutes(donations, db)
+	// Vet appointments routes
+	appointments := api.Group("/appointments")
+	appointmentService := petAPI.SetupAppointmentRoutes(appointments, db)
+
+	// Move pets in and out of medical care around their procedures
+	stopCareScheduler := appointmentService.StartCareScheduler(petModels.AppointmentCareInterval)
+	defer stopCareScheduler()
+
+	// Pet transfers between shelters routes
+	transfers := api.Group("/transfers")
+	petAPI.SetupTransferRoutes(transfers, db)
+
+	// Volunteers routes
+	volunteers := api.Group("/volunteers")
+	volunteerAPI.SetupVolunteerRoutes(volunteers, db)
+
+	// Audit log routes
+	auditLog := api.Group("/audit", auth.Protected(), auth.RequirePermission(userModels.PermissionAuditRead), auth.RequirePlatformAdmin())
+	auditLog.Get("/", audit.ListHandler())
+	auditLog.Get("/verify", audit.VerifyHandler())
 
 	// Start server
 	serverPort := strconv.Itoa(cfg.ServerPort)
@@ -6,25 +6,37 @@ import (
 	"github.com/google/uuid"
 	"github.com/solrac97gr/petparadise/internal/adoptions/domain/models"
 	"github.com/solrac97gr/petparadise/internal/adoptions/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
 )
 
 type AdoptionService struct {
 	repository ports.AdoptionRepository
+	pets       ports.PetDirectory
 }
 
 // NewAdoptionService creates a new AdoptionService instance
-func NewAdoptionService(repository ports.AdoptionRepository) *AdoptionService {
+func NewAdoptionService(repository ports.AdoptionRepository, pets ports.PetDirectory) *AdoptionService {
 	return &AdoptionService{
 		repository: repository,
+		pets:       pets,
 	}
 }
 
-// CreateAdoption creates a new adoption request
+// CreateAdoption creates a new adoption request, handled by the shelter of the pet
 func (s *AdoptionService) CreateAdoption(petID, userID string, documents []string) (*models.Adoption, error) {
+	shelterID, err := s.pets.GetPetShelter(petID)
+	if err != nil {
+		return nil, err
+	}
+
+	if shelterID == "" {
+		return nil, models.ErrPetNotFound
+	}
+
 	id := uuid.New().String()
 	now := time.Now().Format(time.RFC3339)
 
-	adoption, err := models.NewAdoption(id, petID, userID, models.StatusPending, documents)
+	adoption, err := models.NewAdoption(id, shelterID, petID, userID, models.StatusPending, documents)
 	if err != nil {
 		return nil, err
 	}
@@ -40,28 +52,32 @@ func (s *AdoptionService) CreateAdoption(petID, userID string, documents []strin
 	return adoption, nil
 }
 
-// GetAdoptionByID returns an adoption by its ID
-func (s *AdoptionService) GetAdoptionByID(id string) (*models.Adoption, error) {
-	return s.repository.FindByID(id)
+// GetAdoptionByID returns an adoption of the shelters in scope by its ID
+func (s *AdoptionService) GetAdoptionByID(id string, scope tenant.Scope) (*models.Adoption, error) {
+	return s.repository.FindByID(id, scope)
 }
 
-// GetAdoptionsByUserID returns all adoptions for a user
-func (s *AdoptionService) GetAdoptionsByUserID(userID string) ([]*models.Adoption, error) {
-	return s.repository.FindByUserID(userID)
+// GetAdoptionsByUserID returns all adoptions of the shelters in scope for a user
+func (s *AdoptionService) GetAdoptionsByUserID(userID string, scope tenant.Scope) ([]*models.Adoption, error) {
+	return s.repository.FindByUserID(userID, scope)
 }
 
-// GetAllAdoptions returns all adoptions
-func (s *AdoptionService) GetAllAdoptions() ([]*models.Adoption, error) {
-	return s.repository.FindAll()
+// GetAllAdoptions returns all adoptions of the shelters in scope
+func (s *AdoptionService) GetAllAdoptions(scope tenant.Scope) ([]*models.Adoption, error) {
+	return s.repository.FindAll(scope)
 }
 
-// UpdateAdoption updates an adoption
-func (s *AdoptionService) UpdateAdoption(id string, status models.Status, documents []string) (*models.Adoption, error) {
-	adoption, err := s.repository.FindByID(id)
+// UpdateAdoption updates an adoption of the shelters in scope, returning nil if there is none
+func (s *AdoptionService) UpdateAdoption(id string, status models.Status, documents []string, scope tenant.Scope) (*models.Adoption, error) {
+	adoption, err := s.repository.FindByID(id, scope)
 	if err != nil {
 		return nil, err
 	}
 
+	if adoption == nil {
+		return nil, nil
+	}
+
 	if !status.IsValid() {
 		return nil, models.ErrInvalidStatus
 	}
@@ -82,7 +98,7 @@ func (s *AdoptionService) UpdateAdoption(id string, status models.Status, docume
 	return adoption, nil
 }
 
-// DeleteAdoption deletes an adoption
-func (s *AdoptionService) DeleteAdoption(id string) error {
-	return s.repository.Delete(id)
+// DeleteAdoption deletes an adoption of the shelters in scope
+func (s *AdoptionService) DeleteAdoption(id string, scope tenant.Scope) error {
+	return s.repository.Delete(id, scope)
 }
@@ -2,6 +2,7 @@ package models
 
 type Adoption struct {
 	ID        string   `json:"id" db:"id"`
+	ShelterID string   `json:"shelter_id" db:"shelter_id"`
 	PetID     string   `json:"pet_id" db:"pet_id"`
 	UserID    string   `json:"user_id" db:"user_id"`
 	Status    Status   `json:"status" db:"status"`
@@ -11,13 +12,14 @@ type Adoption struct {
 }
 
 // NewAdoption creates a new Adoption instance
-func NewAdoption(id, petID, userID string, status Status, documents []string) (*Adoption, error) {
+func NewAdoption(id, shelterID, petID, userID string, status Status, documents []string) (*Adoption, error) {
 	if !status.IsValid() {
 		return nil, ErrInvalidStatus
 	}
 
 	return &Adoption{
 		ID:        id,
+		ShelterID: shelterID,
 		PetID:     petID,
 		UserID:    userID,
 		Status:    status,
@@ -6,6 +6,7 @@ type Status string
 
 var (
 	ErrInvalidStatus = errors.New("invalid status")
+	ErrPetNotFound   = errors.New("pet not found")
 )
 
 const (
@@ -1,21 +1,31 @@
 package ports
 
-import "github.com/solrac97gr/petparadise/internal/adoptions/domain/models"
+import (
+	"github.com/solrac97gr/petparadise/internal/adoptions/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
 
+// AdoptionRepository looks adoptions up within the shelters in scope
 type AdoptionRepository interface {
 	Save(adoption *models.Adoption) error
-	FindByID(id string) (*models.Adoption, error)
-	FindByUserID(userID string) ([]*models.Adoption, error)
-	FindAll() ([]*models.Adoption, error)
+	FindByID(id string, scope tenant.Scope) (*models.Adoption, error)
+	FindByUserID(userID string, scope tenant.Scope) ([]*models.Adoption, error)
+	FindAll(scope tenant.Scope) ([]*models.Adoption, error)
 	Update(adoption *models.Adoption) error
-	Delete(id string) error
+	Delete(id string, scope tenant.Scope) error
+}
+
+// PetDirectory tells the adoptions module which shelter a pet belongs to
+type PetDirectory interface {
+	// GetPetShelter returns the shelter of a pet, empty if the pet doesn't exist
+	GetPetShelter(petID string) (string, error)
 }
 
 type AdoptionService interface {
 	CreateAdoption(petID, userID string, documents []string) (*models.Adoption, error)
-	GetAdoptionByID(id string) (*models.Adoption, error)
-	GetAdoptionsByUserID(userID string) ([]*models.Adoption, error)
-	GetAllAdoptions() ([]*models.Adoption, error)
-	UpdateAdoption(id string, status models.Status, documents []string) (*models.Adoption, error)
-	DeleteAdoption(id string) error
+	GetAdoptionByID(id string, scope tenant.Scope) (*models.Adoption, error)
+	GetAdoptionsByUserID(userID string, scope tenant.Scope) ([]*models.Adoption, error)
+	GetAllAdoptions(scope tenant.Scope) ([]*models.Adoption, error)
+	UpdateAdoption(id string, status models.Status, documents []string, scope tenant.Scope) (*models.Adoption, error)
+	DeleteAdoption(id string, scope tenant.Scope) error
 }
@@ -4,6 +4,10 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/solrac97gr/petparadise/internal/adoptions/domain/models"
 	"github.com/solrac97gr/petparadise/internal/adoptions/domain/ports"
+	userModels "github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
 )
 
 type adoptionHandler struct {
@@ -46,15 +50,23 @@ func (h *adoptionHandler) CreateAdoption(c *fiber.Ctx) error {
 
 	adoption, err := h.service.CreateAdoption(req.PetID, req.UserID, req.Documents)
 	if err != nil {
+		if err == models.ErrPetNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	audit.Record(c, "adoption.create", "adoption", adoption.ID, nil, adoption)
+
 	return c.Status(fiber.StatusCreated).JSON(adoption)
 }
 
-// GetAdoptionByID handles getting a single adoption by ID
+// GetAdoptionByID handles getting a single adoption by ID. Users see their own adoptions, and staff
+// with the adoptions:read permission those of their own shelter.
 func (h *adoptionHandler) GetAdoptionByID(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
@@ -63,14 +75,15 @@ func (h *adoptionHandler) GetAdoptionByID(c *fiber.Ctx) error {
 		})
 	}
 
-	adoption, err := h.service.GetAdoptionByID(id)
+	adoption, err := h.service.GetAdoptionByID(id, tenant.AllShelters())
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	if adoption == nil {
+	// Adoptions the requester can't reach are hidden
+	if adoption == nil || !auth.CanReachRecord(c, adoption.UserID, adoption.ShelterID, userModels.PermissionAdoptionsRead) {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Adoption not found",
 		})
@@ -79,7 +92,8 @@ func (h *adoptionHandler) GetAdoptionByID(c *fiber.Ctx) error {
 	return c.JSON(adoption)
 }
 
-// GetAdoptionsByUserID handles getting all adoptions for a specific user
+// GetAdoptionsByUserID handles getting all adoptions for a specific user: all of the requester's
+// own, or for staff with the adoptions:read permission those at their own shelter
 func (h *adoptionHandler) GetAdoptionsByUserID(c *fiber.Ctx) error {
 	userID := c.Params("userId")
 	if userID == "" {
@@ -88,7 +102,15 @@ func (h *adoptionHandler) GetAdoptionsByUserID(c *fiber.Ctx) error {
 		})
 	}
 
-	adoptions, err := h.service.GetAdoptionsByUserID(userID)
+	if requestingUserID, _ := c.Locals("userID").(string); requestingUserID != userID {
+		if allowed, err := auth.Allowed(c, userModels.PermissionAdoptionsRead); err != nil || !allowed {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Insufficient permissions to view these adoptions",
+			})
+		}
+	}
+
+	adoptions, err := h.service.GetAdoptionsByUserID(userID, auth.OwnerShelterScope(c, userID))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -98,9 +120,9 @@ func (h *adoptionHandler) GetAdoptionsByUserID(c *fiber.Ctx) error {
 	return c.JSON(adoptions)
 }
 
-// GetAllAdoptions handles getting all adoptions
+// GetAllAdoptions handles getting all adoptions of the shelters in scope
 func (h *adoptionHandler) GetAllAdoptions(c *fiber.Ctx) error {
-	adoptions, err := h.service.GetAllAdoptions()
+	adoptions, err := h.service.GetAllAdoptions(auth.ShelterScope(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -144,7 +166,22 @@ func (h *adoptionHandler) UpdateAdoption(c *fiber.Ctx) error {
 		})
 	}
 
-	adoption, err := h.service.UpdateAdoption(id, status, req.Documents)
+	// Staff only handle the adoptions of their own shelter. Kept for the audit log.
+	scope := auth.ShelterScope(c)
+	before, err := h.service.GetAdoptionByID(id, scope)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if before == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Adoption not found",
+		})
+	}
+
+	adoption, err := h.service.UpdateAdoption(id, status, req.Documents, scope)
 	if err != nil {
 		if err == models.ErrInvalidStatus {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -162,6 +199,8 @@ func (h *adoptionHandler) UpdateAdoption(c *fiber.Ctx) error {
 		})
 	}
 
+	audit.Record(c, "adoption.update", "adoption", adoption.ID, before, adoption)
+
 	return c.JSON(adoption)
 }
 
@@ -174,12 +213,25 @@ func (h *adoptionHandler) DeleteAdoption(c *fiber.Ctx) error {
 		})
 	}
 
-	err := h.service.DeleteAdoption(id)
+	// Staff only delete the adoptions of their own shelter. Kept for the audit log.
+	scope := auth.ShelterScope(c)
+	before, err := h.service.GetAdoptionByID(id, scope)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = h.service.DeleteAdoption(id, scope)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	if before != nil {
+		audit.Record(c, "adoption.delete", "adoption", id, before, nil)
+	}
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
@@ -4,18 +4,27 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/jmoiron/sqlx"
 	"github.com/solrac97gr/petparadise/internal/adoptions/aplication"
+	"github.com/solrac97gr/petparadise/internal/adoptions/infrastructure/pets"
 	"github.com/solrac97gr/petparadise/internal/adoptions/infrastructure/repository"
+	petRepository "github.com/solrac97gr/petparadise/internal/pets/infrastructure/repository"
 	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/audit"
 	"github.com/solrac97gr/petparadise/pkg/auth"
 )
 
 // SetupAdoptionRoutes sets up all adoption routes
 func SetupAdoptionRoutes(router fiber.Router, db *sqlx.DB) {
+	// Documents are only recorded as changed in the audit log
+	audit.SetPersonalFields("adoption", "documents")
+
 	// Initialize repository
 	adoptionRepo := repository.NewPostgresRepository(db)
 
+	// Initialize pet directory backed by the pets module
+	petDirectory := pets.NewPetsDirectory(petRepository.NewPostgresRepository(db))
+
 	// Initialize service
-	adoptionService := aplication.NewAdoptionService(adoptionRepo)
+	adoptionService := aplication.NewAdoptionService(adoptionRepo, petDirectory)
 
 	// Initialize handler
 	adoptionHandler := NewAdoptionHandler(adoptionService)
@@ -28,9 +37,8 @@ func SetupAdoptionRoutes(router fiber.Router, db *sqlx.DB) {
 	protected.Get("/:id", adoptionHandler.GetAdoptionByID)
 	protected.Get("/user/:userId", adoptionHandler.GetAdoptionsByUserID)
 
-	// Staff routes - require admin, volunteer or vet role
-	staffRoutes := protected.Use(auth.RoleRequired(models.RoleAdmin, models.RoleVet, models.RoleVolunteer))
-	staffRoutes.Get("/", adoptionHandler.GetAllAdoptions)
-	staffRoutes.Put("/:id", adoptionHandler.UpdateAdoption)
-	staffRoutes.Delete("/:id", adoptionHandler.DeleteAdoption)
+	// Staff routes - require the permission for the action, on the adoptions of their own shelter
+	protected.Get("/", auth.RequirePermission(models.PermissionAdoptionsRead), adoptionHandler.GetAllAdoptions)
+	protected.Put("/:id", auth.RequirePermission(models.PermissionAdoptionsApprove), adoptionHandler.UpdateAdoption)
+	protected.Delete("/:id", auth.RequirePermission(models.PermissionAdoptionsDelete), auth.MFARequired(), adoptionHandler.DeleteAdoption)
 }
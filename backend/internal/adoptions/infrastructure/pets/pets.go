@@ -0,0 +1,28 @@
+package pets
+
+import (
+	petPorts "github.com/solrac97gr/petparadise/internal/pets/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// PetsDirectory implements the PetDirectory interface on top of the pets module
+type PetsDirectory struct {
+	pets petPorts.PetRepository
+}
+
+// NewPetsDirectory creates a new PetsDirectory
+func NewPetsDirectory(pets petPorts.PetRepository) *PetsDirectory {
+	return &PetsDirectory{
+		pets: pets,
+	}
+}
+
+// GetPetShelter returns the shelter of a pet, empty if the pet doesn't exist
+func (d *PetsDirectory) GetPetShelter(petID string) (string, error) {
+	pet, err := d.pets.FindByID(petID, tenant.AllShelters())
+	if err != nil || pet == nil {
+		return "", err
+	}
+
+	return pet.ShelterID, nil
+}
@@ -0,0 +1,6 @@
+-- An adoption belongs to the shelter of its pet; existing adoptions belong to the default shelter
+ALTER TABLE adoptions ADD COLUMN IF NOT EXISTS shelter_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+    REFERENCES shelters(id) ON DELETE RESTRICT;
+ALTER TABLE adoptions ALTER COLUMN shelter_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_adoptions_shelter_id ON adoptions(shelter_id);
@@ -7,8 +7,12 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/solrac97gr/petparadise/internal/adoptions/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
 )
 
+// adoptionColumns are the columns scanned by scanAdoption
+const adoptionColumns = `id, shelter_id, pet_id, user_id, status, created, updated, documents`
+
 // PostgresRepository implements the AdoptionRepository interface
 type PostgresRepository struct {
 	db *sqlx.DB
@@ -28,12 +32,13 @@ func (r *PostgresRepository) Save(adoption *models.Adoption) error {
 		return err
 	}
 
-	query := `INSERT INTO adoptions (id, pet_id, user_id, status, created, updated, documents) 
-              VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	query := `INSERT INTO adoptions (id, shelter_id, pet_id, user_id, status, created, updated, documents)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 
 	_, err = r.db.Exec(
 		query,
 		adoption.ID,
+		adoption.ShelterID,
 		adoption.PetID,
 		adoption.UserID,
 		adoption.Status.String(),
@@ -45,101 +50,70 @@ func (r *PostgresRepository) Save(adoption *models.Adoption) error {
 	return err
 }
 
-// FindByID finds an adoption by its ID
-func (r *PostgresRepository) FindByID(id string) (*models.Adoption, error) {
-	var adoption models.Adoption
-	var documentsJSON string
-	var statusStr string
+// FindByID finds an adoption of the shelters in scope by its ID
+func (r *PostgresRepository) FindByID(id string, scope tenant.Scope) (*models.Adoption, error) {
+	all, shelterID := scope.Filter()
 
-	query := `SELECT id, pet_id, user_id, status, created, updated, documents 
-              FROM adoptions WHERE id = $1`
+	query := `SELECT ` + adoptionColumns + `
+              FROM adoptions WHERE id = $1 AND ` + tenant.Condition("shelter_id", 2)
 
-	err := r.db.QueryRow(query, id).Scan(
-		&adoption.ID,
-		&adoption.PetID,
-		&adoption.UserID,
-		&statusStr,
-		&adoption.Created,
-		&adoption.Updated,
-		&documentsJSON,
-	)
+	return scanAdoption(r.db.QueryRow(query, id, all, shelterID))
+}
 
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, err
-	}
+// FindByUserID finds all adoptions of the shelters in scope for a user
+func (r *PostgresRepository) FindByUserID(userID string, scope tenant.Scope) ([]*models.Adoption, error) {
+	all, shelterID := scope.Filter()
 
-	adoption.Status = models.Status(statusStr)
+	query := `SELECT ` + adoptionColumns + `
+              FROM adoptions WHERE user_id = $1 AND ` + tenant.Condition("shelter_id", 2)
 
-	var documents []string
-	err = json.Unmarshal([]byte(documentsJSON), &documents)
-	if err != nil {
-		return nil, err
-	}
+	return r.findMany(query, userID, all, shelterID)
+}
 
-	adoption.Documents = documents
+// FindAll finds all adoptions of the shelters in scope
+func (r *PostgresRepository) FindAll(scope tenant.Scope) ([]*models.Adoption, error) {
+	all, shelterID := scope.Filter()
 
-	return &adoption, nil
-}
+	query := `SELECT ` + adoptionColumns + ` FROM adoptions WHERE ` + tenant.Condition("shelter_id", 1)
 
-// FindByUserID finds all adoptions for a user
-func (r *PostgresRepository) FindByUserID(userID string) ([]*models.Adoption, error) {
-	query := `SELECT id, pet_id, user_id, status, created, updated, documents 
-              FROM adoptions WHERE user_id = $1`
+	return r.findMany(query, all, shelterID)
+}
 
-	rows, err := r.db.Query(query, userID)
+// Update updates an adoption. Its shelter can't be changed.
+func (r *PostgresRepository) Update(adoption *models.Adoption) error {
+	documentsJSON, err := json.Marshal(adoption.Documents)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
-
-	var adoptions []*models.Adoption
 
-	for rows.Next() {
-		var adoption models.Adoption
-		var documentsJSON string
-		var statusStr string
-
-		err := rows.Scan(
-			&adoption.ID,
-			&adoption.PetID,
-			&adoption.UserID,
-			&statusStr,
-			&adoption.Created,
-			&adoption.Updated,
-			&documentsJSON,
-		)
-
-		if err != nil {
-			return nil, err
-		}
-
-		adoption.Status = models.Status(statusStr)
+	query := `UPDATE adoptions SET pet_id = $1, user_id = $2, status = $3, updated = $4, documents = $5
+              WHERE id = $6`
 
-		var documents []string
-		err = json.Unmarshal([]byte(documentsJSON), &documents)
-		if err != nil {
-			return nil, err
-		}
+	_, err = r.db.Exec(
+		query,
+		adoption.PetID,
+		adoption.UserID,
+		adoption.Status.String(),
+		adoption.Updated,
+		documentsJSON,
+		adoption.ID,
+	)
 
-		adoption.Documents = documents
-		adoptions = append(adoptions, &adoption)
-	}
+	return err
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
+// Delete deletes an adoption of the shelters in scope
+func (r *PostgresRepository) Delete(id string, scope tenant.Scope) error {
+	all, shelterID := scope.Filter()
 
-	return adoptions, nil
+	query := `DELETE FROM adoptions WHERE id = $1 AND ` + tenant.Condition("shelter_id", 2)
+	_, err := r.db.Exec(query, id, all, shelterID)
+	return err
 }
 
-// FindAll finds all adoptions
-func (r *PostgresRepository) FindAll() ([]*models.Adoption, error) {
-	query := `SELECT id, pet_id, user_id, status, created, updated, documents FROM adoptions`
-
-	rows, err := r.db.Query(query)
+// findMany runs a query returning rows of adoptionColumns
+func (r *PostgresRepository) findMany(query string, args ...any) ([]*models.Adoption, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -148,34 +122,11 @@ func (r *PostgresRepository) FindAll() ([]*models.Adoption, error) {
 	var adoptions []*models.Adoption
 
 	for rows.Next() {
-		var adoption models.Adoption
-		var documentsJSON string
-		var statusStr string
-
-		err := rows.Scan(
-			&adoption.ID,
-			&adoption.PetID,
-			&adoption.UserID,
-			&statusStr,
-			&adoption.Created,
-			&adoption.Updated,
-			&documentsJSON,
-		)
-
+		adoption, err := scanAdoption(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		adoption.Status = models.Status(statusStr)
-
-		var documents []string
-		err = json.Unmarshal([]byte(documentsJSON), &documents)
-		if err != nil {
-			return nil, err
-		}
-
-		adoption.Documents = documents
-		adoptions = append(adoptions, &adoption)
+		adoptions = append(adoptions, adoption)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -185,32 +136,39 @@ func (r *PostgresRepository) FindAll() ([]*models.Adoption, error) {
 	return adoptions, nil
 }
 
-// Update updates an adoption
-func (r *PostgresRepository) Update(adoption *models.Adoption) error {
-	documentsJSON, err := json.Marshal(adoption.Documents)
+// scanAdoption scans a row of adoptionColumns, returning nil if there is no row
+func scanAdoption(row interface{ Scan(dest ...any) error }) (*models.Adoption, error) {
+	var adoption models.Adoption
+	var documentsJSON string
+	var statusStr string
+
+	err := row.Scan(
+		&adoption.ID,
+		&adoption.ShelterID,
+		&adoption.PetID,
+		&adoption.UserID,
+		&statusStr,
+		&adoption.Created,
+		&adoption.Updated,
+		&documentsJSON,
+	)
+
 	if err != nil {
-		return err
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
 	}
 
-	query := `UPDATE adoptions SET pet_id = $1, user_id = $2, status = $3, updated = $4, documents = $5
-              WHERE id = $6`
+	adoption.Status = models.Status(statusStr)
 
-	_, err = r.db.Exec(
-		query,
-		adoption.PetID,
-		adoption.UserID,
-		adoption.Status.String(),
-		adoption.Updated,
-		documentsJSON,
-		adoption.ID,
-	)
+	var documents []string
+	err = json.Unmarshal([]byte(documentsJSON), &documents)
+	if err != nil {
+		return nil, err
+	}
 
-	return err
-}
+	adoption.Documents = documents
 
-// Delete deletes an adoption
-func (r *PostgresRepository) Delete(id string) error {
-	query := `DELETE FROM adoptions WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
+	return &adoption, nil
 }
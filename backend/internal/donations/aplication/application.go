@@ -1,30 +1,40 @@
 package aplication
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
 	"github.com/solrac97gr/petparadise/internal/donations/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
 )
 
 type DonationService struct {
 	repository ports.DonationRepository
+	pets       ports.PetDirectory
+	mailer     mailer.Mailer
 }
 
 // NewDonationService creates a new DonationService instance
-func NewDonationService(repository ports.DonationRepository) *DonationService {
+func NewDonationService(repository ports.DonationRepository, pets ports.PetDirectory, mailer mailer.Mailer) *DonationService {
 	return &DonationService{
 		repository: repository,
+		pets:       pets,
+		mailer:     mailer,
 	}
 }
 
-// CreateDonation creates a new donation
-func (s *DonationService) CreateDonation(userID string, amount float64, comment string, anonymous bool) (*models.Donation, error) {
+// CreateDonation creates a new donation to a shelter
+func (s *DonationService) CreateDonation(userID, shelterID string, amount float64, comment, campaign string, anonymous bool) (*models.Donation, error) {
 	id := uuid.New().String()
 	now := time.Now().Format(time.RFC3339)
 
-	donation, err := models.NewDonation(id, userID, amount, models.StatusPending, comment, anonymous)
+	donation, err := models.NewDonation(id, shelterID, userID, amount, models.StatusPending, comment, campaign, anonymous)
 	if err != nil {
 		return nil, err
 	}
@@ -40,48 +50,174 @@ func (s *DonationService) CreateDonation(userID string, amount float64, comment
 	return donation, nil
 }
 
-// GetDonationByID returns a donation by its ID
-func (s *DonationService) GetDonationByID(id string) (*models.Donation, error) {
-	return s.repository.FindByID(id)
+// CreateSponsorship creates a new donation earmarked for a pet, made to the pet's shelter
+func (s *DonationService) CreateSponsorship(userID, petID string, amount float64, sponsorship models.SponsorshipType, comment, campaign string, anonymous bool) (*models.Donation, error) {
+	shelterID, err := s.pets.GetPetShelter(petID)
+	if err != nil {
+		return nil, err
+	}
+
+	if shelterID == "" {
+		return nil, models.ErrPetNotFound
+	}
+
+	id := uuid.New().String()
+	now := time.Now().Format(time.RFC3339)
+
+	donation, err := models.NewSponsorship(id, shelterID, userID, petID, amount, sponsorship, models.StatusPending, comment, campaign, anonymous)
+	if err != nil {
+		return nil, err
+	}
+
+	donation.Created = now
+	donation.Updated = now
+
+	err = s.repository.Save(donation)
+	if err != nil {
+		return nil, err
+	}
+
+	return donation, nil
 }
 
-// GetDonationsByUserID returns all donations for a user
-func (s *DonationService) GetDonationsByUserID(userID string) ([]*models.Donation, error) {
-	return s.repository.FindByUserID(userID)
+// GetDonationByID returns a donation to the shelters in scope by its ID
+func (s *DonationService) GetDonationByID(id string, scope tenant.Scope) (*models.Donation, error) {
+	return s.repository.FindByID(id, scope)
 }
 
-// GetAllDonations returns all donations
-func (s *DonationService) GetAllDonations() ([]*models.Donation, error) {
-	return s.repository.FindAll()
+// GetDonationsByUserID returns all donations of a user to the shelters in scope
+func (s *DonationService) GetDonationsByUserID(userID string, scope tenant.Scope) ([]*models.Donation, error) {
+	return s.repository.FindByUserID(userID, scope)
 }
 
-// UpdateDonation updates a donation's status
-func (s *DonationService) UpdateDonation(id string, status models.Status) (*models.Donation, error) {
+// GetAllDonations returns all donations to the shelters in scope
+func (s *DonationService) GetAllDonations(scope tenant.Scope) ([]*models.Donation, error) {
+	return s.repository.FindAll(scope)
+}
+
+// GetPetSponsorship returns the totals of the completed donations earmarked for a pet, net of
+// refunds, along with the names of its non-anonymous sponsors
+func (s *DonationService) GetPetSponsorship(petID string) (*models.SponsorshipSummary, error) {
+	donations, err := s.repository.FindByPetID(petID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.SponsorshipSummary{
+		PetID:    petID,
+		Sponsors: []string{},
+	}
+
+	for _, donation := range donations {
+		// Fully refunded donations have nothing left to count
+		if !donation.Status.IsRefundable() {
+			continue
+		}
+
+		summary.TotalAmount += donation.NetAmount
+		if donation.Sponsorship.IsEquals(models.SponsorshipMonthly) {
+			summary.MonthlyAmount += donation.NetAmount
+		} else {
+			summary.OneOffAmount += donation.NetAmount
+		}
+	}
+
+	sponsors, err := s.repository.FindSponsorsByPetID(petID, models.StatusCompleted, models.StatusPartiallyRefunded)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.SponsorCount = len(sponsors)
+	for _, sponsor := range sponsors {
+		if !sponsor.Anonymous {
+			summary.Sponsors = append(summary.Sponsors, sponsor.PublicName)
+		}
+	}
+
+	return summary, nil
+}
+
+// NotifyPetAdopted lets every sponsor of a pet whose donation was received know that it has been
+// adopted. A sponsor who can't be emailed is logged and doesn't keep the others from hearing the news.
+func (s *DonationService) NotifyPetAdopted(petID, petName string) error {
+	sponsors, err := s.repository.FindSponsorsByPetID(petID, models.StatusCompleted, models.StatusPartiallyRefunded)
+	if err != nil {
+		return err
+	}
+
+	var notified int
+	var errs []error
+	for _, sponsor := range sponsors {
+		if sponsor.Erased {
+			continue
+		}
+
+		err := s.mailer.Send(&mailer.Message{
+			To:      []string{sponsor.Email},
+			Subject: fmt.Sprintf("%s has been adopted!", petName),
+			Body: fmt.Sprintf("Hi %s,\n\nGreat news: %s, the pet you sponsored, has found a new home. "+
+				"Thank you for supporting their care while they waited.\n\nPet Paradise", sponsor.Name, petName),
+		})
+		if err != nil {
+			log.Printf("Failed to send pet adopted email to sponsor %s: %v", sponsor.UserID, err)
+			errs = append(errs, err)
+			continue
+		}
+		notified++
+	}
+
+	audit.RecordSystem("pet.notify_sponsors", "pet", petID, nil, map[string]any{"notified": notified, "failed": len(errs)})
+
+	return errors.Join(errs...)
+}
+
+// UpdateDonation updates the status of a donation to the shelters in scope, returning nil if there
+// is none. Completing a donation records its charge in the ledger; once money has been received
+// it can only be returned through the refund workflow.
+func (s *DonationService) UpdateDonation(id string, status models.Status, actorID string, scope tenant.Scope) (*models.Donation, error) {
 	if !status.IsValid() {
 		return nil, models.ErrInvalidStatus
 	}
 
-	donation, err := s.repository.FindByID(id)
+	if status.IsEquals(models.StatusRefunded) || status.IsEquals(models.StatusPartiallyRefunded) {
+		return nil, models.ErrUseRefundWorkflow
+	}
+
+	donation, err := s.repository.FindByID(id, scope)
 	if err != nil {
 		return nil, err
 	}
 
 	if donation == nil {
-		return nil, models.ErrInvalidStatus // In a real app, you'd have a more specific error like ErrDonationNotFound
+		return nil, nil
 	}
 
+	if donation.Status.HasReceivedMoney() && !donation.Status.IsEquals(status) {
+		return nil, models.ErrUseRefundWorkflow
+	}
+
+	previousStatus := donation.Status
 	donation.Status = status
 	donation.Updated = time.Now().Format(time.RFC3339)
 
-	err = s.repository.Update(donation)
-	if err != nil {
+	var charge *models.LedgerEntry
+	if status.IsEquals(models.StatusCompleted) && !previousStatus.IsEquals(models.StatusCompleted) {
+		charge = models.NewChargeEntry(uuid.New().String(), donation, actorID)
+		charge.Created = donation.Updated
+	}
+
+	if err := s.repository.UpdateStatus(donation, charge); err != nil {
 		return nil, err
 	}
 
+	if charge != nil {
+		donation.NetAmount = donation.Amount
+	}
+
 	return donation, nil
 }
 
-// DeleteDonation deletes a donation
-func (s *DonationService) DeleteDonation(id string) error {
-	return s.repository.Delete(id)
+// DeleteDonation deletes a donation to the shelters in scope
+func (s *DonationService) DeleteDonation(id string, scope tenant.Scope) error {
+	return s.repository.Delete(id, scope)
 }
@@ -0,0 +1,267 @@
+package aplication
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// fakeDonationRepository keeps donations, their charges and the sponsors of each pet in memory
+type fakeDonationRepository struct {
+	donations map[string]*models.Donation
+	charges   []*models.LedgerEntry
+	sponsors  []*models.Sponsor
+	statuses  []models.Status // Asked for by the last FindSponsorsByPetID
+}
+
+func newFakeDonationRepository(donations ...*models.Donation) *fakeDonationRepository {
+	repository := &fakeDonationRepository{donations: map[string]*models.Donation{}}
+	for _, donation := range donations {
+		repository.donations[donation.ID] = donation
+	}
+	return repository
+}
+
+func (r *fakeDonationRepository) Save(donation *models.Donation) error {
+	r.donations[donation.ID] = donation
+	return nil
+}
+
+func (r *fakeDonationRepository) FindByID(id string, scope tenant.Scope) (*models.Donation, error) {
+	donation, ok := r.donations[id]
+	if !ok || !scope.Allows(donation.ShelterID) {
+		return nil, nil
+	}
+	copied := *donation
+	return &copied, nil
+}
+
+func (r *fakeDonationRepository) FindByUserID(userID string, scope tenant.Scope) ([]*models.Donation, error) {
+	return nil, nil
+}
+
+func (r *fakeDonationRepository) FindByPetID(petID string) ([]*models.Donation, error) {
+	var donations []*models.Donation
+	for _, donation := range r.donations {
+		if donation.PetID == petID {
+			donations = append(donations, donation)
+		}
+	}
+	return donations, nil
+}
+
+func (r *fakeDonationRepository) FindAll(scope tenant.Scope) ([]*models.Donation, error) {
+	return nil, nil
+}
+
+func (r *fakeDonationRepository) FindSponsorsByPetID(petID string, statuses ...models.Status) ([]*models.Sponsor, error) {
+	r.statuses = statuses
+	return r.sponsors, nil
+}
+
+func (r *fakeDonationRepository) Update(donation *models.Donation) error {
+	r.donations[donation.ID] = donation
+	return nil
+}
+
+func (r *fakeDonationRepository) UpdateStatus(donation *models.Donation, charge *models.LedgerEntry) error {
+	r.donations[donation.ID] = donation
+	if charge != nil {
+		r.charges = append(r.charges, charge)
+	}
+	return nil
+}
+
+func (r *fakeDonationRepository) Delete(id string, scope tenant.Scope) error {
+	delete(r.donations, id)
+	return nil
+}
+
+// fakeMailer records the messages sent, failing for the addresses in failFor
+type fakeMailer struct {
+	sent    []*mailer.Message
+	failFor map[string]bool
+}
+
+func (m *fakeMailer) Send(msg *mailer.Message) error {
+	if m.failFor[msg.To[0]] {
+		return errors.New("mailbox unavailable")
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestGetPetSponsorshipTotals(t *testing.T) {
+	repository := newFakeDonationRepository(
+		&models.Donation{ID: "1", PetID: "pet-1", Amount: 20, NetAmount: 20, Status: models.StatusCompleted, Sponsorship: models.SponsorshipOneOff},
+		&models.Donation{ID: "2", PetID: "pet-1", Amount: 15, NetAmount: 15, Status: models.StatusCompleted, Sponsorship: models.SponsorshipMonthly},
+		&models.Donation{ID: "3", PetID: "pet-1", Amount: 50, Status: models.StatusPending, Sponsorship: models.SponsorshipOneOff},
+		&models.Donation{ID: "4", PetID: "pet-1", Amount: 30, Status: models.StatusFailed, Sponsorship: models.SponsorshipMonthly},
+		&models.Donation{ID: "5", PetID: "pet-2", Amount: 99, NetAmount: 99, Status: models.StatusCompleted, Sponsorship: models.SponsorshipOneOff},
+		&models.Donation{ID: "6", PetID: "pet-1", Amount: 40, NetAmount: 10, Status: models.StatusPartiallyRefunded, Sponsorship: models.SponsorshipOneOff},
+		&models.Donation{ID: "7", PetID: "pet-1", Amount: 25, NetAmount: 0, Status: models.StatusRefunded, Sponsorship: models.SponsorshipMonthly},
+	)
+	repository.sponsors = []*models.Sponsor{
+		{UserID: "user-1", Name: "Jane Doe", PublicName: "Jane"},
+		{UserID: "user-2", Name: "John Doe", Anonymous: true, PublicName: models.AnonymousDonorName},
+	}
+
+	service := NewDonationService(repository, nil, &fakeMailer{})
+
+	summary, err := service.GetPetSponsorship("pet-1")
+	if err != nil {
+		t.Fatalf("GetPetSponsorship() error = %v", err)
+	}
+
+	if summary.TotalAmount != 45 || summary.OneOffAmount != 30 || summary.MonthlyAmount != 15 {
+		t.Errorf("GetPetSponsorship() totals = %v, %v one-off, %v monthly, want 45, 30 one-off, 15 monthly",
+			summary.TotalAmount, summary.OneOffAmount, summary.MonthlyAmount)
+	}
+
+	if summary.SponsorCount != 2 {
+		t.Errorf("GetPetSponsorship() sponsor count = %d, want 2", summary.SponsorCount)
+	}
+
+	if len(summary.Sponsors) != 1 || summary.Sponsors[0] != "Jane" {
+		t.Errorf("GetPetSponsorship() sponsors = %v, want only [Jane]", summary.Sponsors)
+	}
+}
+
+func TestNotifyPetAdoptedEmailsEverySponsor(t *testing.T) {
+	repository := newFakeDonationRepository()
+	repository.sponsors = []*models.Sponsor{
+		{UserID: "user-1", Name: "Jane Doe", Email: "jane@example.com"},
+		{UserID: "user-2", Name: "John Doe", Email: "john@example.com", Anonymous: true},
+	}
+	mail := &fakeMailer{}
+
+	service := NewDonationService(repository, nil, mail)
+
+	if err := service.NotifyPetAdopted("pet-1", "Rex"); err != nil {
+		t.Fatalf("NotifyPetAdopted() error = %v", err)
+	}
+
+	if len(mail.sent) != 2 {
+		t.Fatalf("NotifyPetAdopted() sent %d emails, want 2", len(mail.sent))
+	}
+
+	if mail.sent[1].To[0] != "john@example.com" || mail.sent[1].Subject != "Rex has been adopted!" {
+		t.Errorf("NotifyPetAdopted() second email = %+v, want one to john@example.com about Rex", mail.sent[1])
+	}
+
+	// Only the sponsors whose money was received, as in the reports
+	want := []models.Status{models.StatusCompleted, models.StatusPartiallyRefunded}
+	if !slices.Equal(repository.statuses, want) {
+		t.Errorf("NotifyPetAdopted() looked for sponsors with statuses %v, want %v", repository.statuses, want)
+	}
+}
+
+func TestNotifyPetAdoptedSkipsErasedSponsors(t *testing.T) {
+	repository := newFakeDonationRepository()
+	repository.sponsors = []*models.Sponsor{
+		{UserID: "user-1", Name: "Deleted user", Email: "deleted-user-1@deleted.invalid", Erased: true},
+		{UserID: "user-2", Name: "John Doe", Email: "john@example.com"},
+	}
+	mail := &fakeMailer{}
+
+	service := NewDonationService(repository, nil, mail)
+
+	if err := service.NotifyPetAdopted("pet-1", "Rex"); err != nil {
+		t.Fatalf("NotifyPetAdopted() error = %v", err)
+	}
+
+	if len(mail.sent) != 1 || mail.sent[0].To[0] != "john@example.com" {
+		t.Errorf("NotifyPetAdopted() sent %d emails, want only the one to john@example.com", len(mail.sent))
+	}
+}
+
+func TestNotifyPetAdoptedKeepsGoingAfterAFailure(t *testing.T) {
+	repository := newFakeDonationRepository()
+	repository.sponsors = []*models.Sponsor{
+		{UserID: "user-1", Name: "Jane Doe", Email: "jane@example.com"},
+		{UserID: "user-2", Name: "John Doe", Email: "john@example.com"},
+	}
+	mail := &fakeMailer{failFor: map[string]bool{"jane@example.com": true}}
+
+	service := NewDonationService(repository, nil, mail)
+
+	if err := service.NotifyPetAdopted("pet-1", "Rex"); err == nil {
+		t.Error("NotifyPetAdopted() error = nil, want the failed email reported")
+	}
+
+	if len(mail.sent) != 1 || mail.sent[0].To[0] != "john@example.com" {
+		t.Errorf("NotifyPetAdopted() sent %d emails, want the one to john@example.com", len(mail.sent))
+	}
+}
+
+func TestUpdateDonationStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       models.Status
+		to         models.Status
+		wantErr    error
+		wantCharge bool
+	}{
+		{name: "Completing a pending donation charges it", from: models.StatusPending, to: models.StatusCompleted, wantCharge: true},
+		{name: "Failing a pending donation", from: models.StatusPending, to: models.StatusFailed},
+		{name: "Completing a completed donation again", from: models.StatusCompleted, to: models.StatusCompleted},
+		{name: "Refunding directly", from: models.StatusCompleted, to: models.StatusRefunded, wantErr: models.ErrUseRefundWorkflow},
+		{name: "Partially refunding directly", from: models.StatusCompleted, to: models.StatusPartiallyRefunded, wantErr: models.ErrUseRefundWorkflow},
+		{name: "Failing a completed donation", from: models.StatusCompleted, to: models.StatusFailed, wantErr: models.ErrUseRefundWorkflow},
+		{name: "Completing a refunded donation", from: models.StatusRefunded, to: models.StatusCompleted, wantErr: models.ErrUseRefundWorkflow},
+		{name: "Completing a partially refunded donation", from: models.StatusPartiallyRefunded, to: models.StatusCompleted, wantErr: models.ErrUseRefundWorkflow},
+		{name: "Unknown status", from: models.StatusPending, to: "lost", wantErr: models.ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := newFakeDonationRepository(&models.Donation{ID: "1", ShelterID: "shelter-1", Amount: 30, Status: tt.from})
+			service := NewDonationService(repository, nil, &fakeMailer{})
+
+			donation, err := service.UpdateDonation("1", tt.to, "admin-1", tenant.Shelter("shelter-1"))
+			if err != tt.wantErr {
+				t.Fatalf("UpdateDonation() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if gotCharge := len(repository.charges) == 1; gotCharge != tt.wantCharge {
+				t.Fatalf("UpdateDonation() recorded %d charges, want a charge: %v", len(repository.charges), tt.wantCharge)
+			}
+
+			if err != nil {
+				if repository.donations["1"].Status != tt.from {
+					t.Errorf("UpdateDonation() changed the status to %q, want it left at %q", repository.donations["1"].Status, tt.from)
+				}
+				return
+			}
+
+			if donation.Status != tt.to {
+				t.Errorf("UpdateDonation() status = %q, want %q", donation.Status, tt.to)
+			}
+
+			if tt.wantCharge {
+				charge := repository.charges[0]
+				if charge.Type != models.EntryTypeCharge || charge.Amount != 30 || charge.ActorID != "admin-1" || donation.NetAmount != 30 {
+					t.Errorf("UpdateDonation() charge = %+v, net amount %v, want a charge of 30 by admin-1", charge, donation.NetAmount)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateDonationOfAnotherShelter(t *testing.T) {
+	repository := newFakeDonationRepository(&models.Donation{ID: "1", ShelterID: "shelter-1", Amount: 30, Status: models.StatusPending})
+	service := NewDonationService(repository, nil, &fakeMailer{})
+
+	donation, err := service.UpdateDonation("1", models.StatusCompleted, "admin-2", tenant.Shelter("shelter-2"))
+	if err != nil || donation != nil {
+		t.Fatalf("UpdateDonation() = %v, %v, want nil, nil", donation, err)
+	}
+
+	if repository.donations["1"].Status != models.StatusPending || len(repository.charges) != 0 {
+		t.Errorf("UpdateDonation() changed a donation of another shelter")
+	}
+}
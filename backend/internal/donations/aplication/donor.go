@@ -0,0 +1,82 @@
+package aplication
+
+import (
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// DonorService implements the DonorService interface
+type DonorService struct {
+	repository ports.DonorRepository
+}
+
+// NewDonorService creates a new DonorService instance
+func NewDonorService(repository ports.DonorRepository) *DonorService {
+	return &DonorService{
+		repository: repository,
+	}
+}
+
+// GetDonorProfile returns a donor's public profile, or the defaults if they never set one
+func (s *DonorService) GetDonorProfile(userID string) (*models.DonorProfile, error) {
+	profile, err := s.repository.FindProfileByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile == nil {
+		return &models.DonorProfile{UserID: userID}, nil
+	}
+
+	return profile, nil
+}
+
+// UpdateDonorProfile sets how a donor appears on public donation pages
+func (s *DonorService) UpdateDonorProfile(userID, displayName string, hideAmounts bool) (*models.DonorProfile, error) {
+	profile, err := models.NewDonorProfile(userID, displayName, hideAmounts)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.Updated = time.Now().Format(time.RFC3339)
+
+	err = s.repository.SaveProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// GetPublicDonations returns the public donor wall of the shelters in scope, most recent donations first
+func (s *DonorService) GetPublicDonations(limit, offset int, scope tenant.Scope) ([]*models.PublicDonation, error) {
+	return s.repository.FindPublicDonations(limit, offset, scope)
+}
+
+// GetLeaderboard returns the top donors to the shelters in scope of the current calendar period
+func (s *DonorService) GetLeaderboard(period models.Period, limit int, scope tenant.Scope) (*models.Leaderboard, error) {
+	if !period.IsValid() {
+		return nil, models.ErrInvalidPeriod
+	}
+
+	since := period.Start(time.Now())
+
+	entries, err := s.repository.FindTopDonors(since, limit, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	leaderboard := &models.Leaderboard{
+		Period:  period,
+		Entries: entries,
+	}
+
+	if !since.IsZero() {
+		leaderboard.Since = since.Format(time.RFC3339)
+	}
+
+	return leaderboard, nil
+}
@@ -0,0 +1,207 @@
+package aplication
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// InKindService implements the InKindService interface
+type InKindService struct {
+	repository ports.InKindRepository
+	supplies   ports.SupplyRepository
+	mailer     mailer.Mailer
+}
+
+// NewInKindService creates a new InKindService instance
+func NewInKindService(repository ports.InKindRepository, supplies ports.SupplyRepository, mailer mailer.Mailer) *InKindService {
+	return &InKindService{
+		repository: repository,
+		supplies:   supplies,
+		mailer:     mailer,
+	}
+}
+
+// RecordInKindDonation records goods dropped off by a donor at a shelter, adds them to the shelter's
+// supply inventory and thanks the donor. A failed acknowledgement does not undo the recording; it
+// can be sent again.
+func (s *InKindService) RecordInKindDonation(userID, shelterID, dropOffDate, comment, receivedBy string, items []*models.InKindItem) (*models.InKindDonation, error) {
+	donor, err := s.repository.FindDonorContact(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if donor == nil {
+		return nil, errors.New("donor not found")
+	}
+
+	for _, item := range items {
+		item.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	donation, err := models.NewInKindDonation(uuid.New().String(), shelterID, userID, dropOffDate, comment, receivedBy, items, now)
+	if err != nil {
+		return nil, err
+	}
+
+	donation.Created = now.Format(time.RFC3339)
+	donation.Updated = donation.Created
+
+	err = s.repository.Save(donation)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.acknowledge(donation, donor); err != nil {
+		log.Printf("Failed to acknowledge in-kind donation %s: %v", donation.ID, err)
+	}
+
+	return donation, nil
+}
+
+// GetInKindDonationByID returns an in-kind donation to the shelters in scope by its ID
+func (s *InKindService) GetInKindDonationByID(id string, scope tenant.Scope) (*models.InKindDonation, error) {
+	return s.repository.FindByID(id, scope)
+}
+
+// GetInKindDonationsByUserID returns all in-kind donations of a donor
+func (s *InKindService) GetInKindDonationsByUserID(userID string) ([]*models.InKindDonation, error) {
+	return s.repository.FindByUserID(userID)
+}
+
+// GetAllInKindDonations returns all in-kind donations to the shelters in scope
+func (s *InKindService) GetAllInKindDonations(scope tenant.Scope) ([]*models.InKindDonation, error) {
+	return s.repository.FindAll(scope)
+}
+
+// GetAcknowledgement returns the acknowledgement of an in-kind donation to the shelters in scope
+// without sending it
+func (s *InKindService) GetAcknowledgement(id string, scope tenant.Scope) (*models.Acknowledgement, error) {
+	donation, donor, err := s.findWithDonor(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildAcknowledgement(donation, donor), nil
+}
+
+// SendAcknowledgement emails the acknowledgement of an in-kind donation to the shelters in scope
+// to its donor again
+func (s *InKindService) SendAcknowledgement(id string, scope tenant.Scope) (*models.Acknowledgement, error) {
+	donation, donor, err := s.findWithDonor(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.acknowledge(donation, donor); err != nil {
+		return nil, err
+	}
+
+	return buildAcknowledgement(donation, donor), nil
+}
+
+// GetSupplies returns the supply inventory of the shelters in scope
+func (s *InKindService) GetSupplies(scope tenant.Scope) ([]*models.SupplyItem, error) {
+	return s.supplies.FindAll(scope)
+}
+
+// UseSupply takes a quantity of a supply out of the inventory of the shelters in scope
+func (s *InKindService) UseSupply(id string, quantity float64, scope tenant.Scope) (*models.SupplyItem, error) {
+	if quantity <= 0 {
+		return nil, models.ErrInvalidQuantity
+	}
+
+	supply, err := s.supplies.Use(id, quantity, time.Now().Format(time.RFC3339), scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if supply == nil {
+		return nil, errors.New("supply not found")
+	}
+
+	return supply, nil
+}
+
+// findWithDonor finds an in-kind donation to the shelters in scope and the contact details of its donor
+func (s *InKindService) findWithDonor(id string, scope tenant.Scope) (*models.InKindDonation, *models.DonorContact, error) {
+	donation, err := s.repository.FindByID(id, scope)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if donation == nil {
+		return nil, nil, errors.New("in-kind donation not found")
+	}
+
+	donor, err := s.repository.FindDonorContact(donation.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if donor == nil {
+		return nil, nil, errors.New("donor not found")
+	}
+
+	return donation, donor, nil
+}
+
+// acknowledge emails the acknowledgement to the donor and records when it was sent
+func (s *InKindService) acknowledge(donation *models.InKindDonation, donor *models.DonorContact) error {
+	acknowledgement := buildAcknowledgement(donation, donor)
+
+	err := s.mailer.Send(&mailer.Message{
+		To:      []string{donor.Email},
+		Subject: acknowledgement.Subject,
+		Body:    acknowledgement.Message,
+	})
+	if err != nil {
+		return err
+	}
+
+	acknowledgedAt := time.Now().Format(time.RFC3339)
+	if err := s.repository.MarkAcknowledged(donation.ID, acknowledgedAt); err != nil {
+		return err
+	}
+
+	donation.AcknowledgedAt = acknowledgedAt
+	donation.Updated = acknowledgedAt
+
+	return nil
+}
+
+// buildAcknowledgement writes the thank-you note for an in-kind donation, listing the items given
+func buildAcknowledgement(donation *models.InKindDonation, donor *models.DonorContact) *models.Acknowledgement {
+	var items strings.Builder
+	for _, item := range donation.Items {
+		fmt.Fprintf(&items, "- %s: %s %s (%s, %s)\n", item.Name, formatQuantity(item.Quantity), item.Unit, item.Category, item.Condition)
+	}
+
+	message := fmt.Sprintf("Hi %s,\n\nThank you for the supplies you brought to the shelter on %s. "+
+		"We received:\n\n%s\nTheir estimated value is %.2f. No goods or services were provided in exchange for this donation.\n\nPet Paradise",
+		donor.Name, donation.DropOffDate, items.String(), donation.EstimatedValue)
+
+	return &models.Acknowledgement{
+		DonationID:     donation.ID,
+		DonorName:      donor.Name,
+		DropOffDate:    donation.DropOffDate,
+		Items:          donation.Items,
+		EstimatedValue: donation.EstimatedValue,
+		Subject:        "Thank you for your in-kind donation",
+		Message:        message,
+	}
+}
+
+// formatQuantity formats a quantity without trailing zeros
+func formatQuantity(quantity float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", quantity), "0"), ".")
+}
@@ -0,0 +1,184 @@
+package aplication
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// fakeInKindRepository keeps in-kind donations and donor contacts in memory
+type fakeInKindRepository struct {
+	donations map[string]*models.InKindDonation
+	donors    map[string]*models.DonorContact
+}
+
+func newFakeInKindRepository() *fakeInKindRepository {
+	return &fakeInKindRepository{
+		donations: map[string]*models.InKindDonation{},
+		donors: map[string]*models.DonorContact{
+			"user-1": {UserID: "user-1", Name: "Jane Doe", Email: "jane@example.com"},
+		},
+	}
+}
+
+func (r *fakeInKindRepository) Save(donation *models.InKindDonation) error {
+	r.donations[donation.ID] = donation
+	return nil
+}
+
+func (r *fakeInKindRepository) FindByID(id string, scope tenant.Scope) (*models.InKindDonation, error) {
+	donation, ok := r.donations[id]
+	if !ok || !scope.Allows(donation.ShelterID) {
+		return nil, nil
+	}
+	return donation, nil
+}
+
+func (r *fakeInKindRepository) FindByUserID(userID string) ([]*models.InKindDonation, error) {
+	return nil, nil
+}
+
+func (r *fakeInKindRepository) FindAll(scope tenant.Scope) ([]*models.InKindDonation, error) {
+	return nil, nil
+}
+
+func (r *fakeInKindRepository) MarkAcknowledged(id, acknowledgedAt string) error {
+	r.donations[id].AcknowledgedAt = acknowledgedAt
+	return nil
+}
+
+func (r *fakeInKindRepository) FindDonorContact(userID string) (*models.DonorContact, error) {
+	return r.donors[userID], nil
+}
+
+// fakeSupplyRepository keeps the stock of each supply in memory
+type fakeSupplyRepository struct {
+	supplies map[string]*models.SupplyItem
+}
+
+func (r *fakeSupplyRepository) FindAll(scope tenant.Scope) ([]*models.SupplyItem, error) {
+	return nil, nil
+}
+
+func (r *fakeSupplyRepository) Use(id string, quantity float64, updated string, scope tenant.Scope) (*models.SupplyItem, error) {
+	supply, ok := r.supplies[id]
+	if !ok || !scope.Allows(supply.ShelterID) {
+		return nil, nil
+	}
+
+	if supply.Quantity < quantity {
+		return nil, models.ErrInsufficientStock
+	}
+
+	supply.Quantity -= quantity
+	supply.Updated = updated
+	return supply, nil
+}
+
+// failingMailer fails to send every message
+type failingMailer struct{}
+
+func (m failingMailer) Send(msg *mailer.Message) error {
+	return errors.New("mail server unavailable")
+}
+
+func TestRecordInKindDonationAcknowledgesTheDonor(t *testing.T) {
+	repository := newFakeInKindRepository()
+	mail := &fakeMailer{}
+	service := NewInKindService(repository, &fakeSupplyRepository{}, mail)
+
+	items := []*models.InKindItem{
+		{Category: models.CategoryFood, Name: "Dry dog food", Quantity: 12.5, Unit: models.UnitKg, EstimatedValue: 40, Condition: models.ConditionNew},
+	}
+
+	donation, err := service.RecordInKindDonation("user-1", "shelter-1", time.Now().Format(models.ReportDateLayout), "", "staff-1", items)
+	if err != nil {
+		t.Fatalf("RecordInKindDonation() error = %v", err)
+	}
+
+	if donation.Items[0].ID == "" || donation.AcknowledgedAt == "" {
+		t.Errorf("RecordInKindDonation() = %+v, want items with IDs and an acknowledgement", donation)
+	}
+
+	if len(mail.sent) != 1 || mail.sent[0].To[0] != "jane@example.com" {
+		t.Fatalf("RecordInKindDonation() sent %v, want one email to jane@example.com", mail.sent)
+	}
+
+	if !strings.Contains(mail.sent[0].Body, "- Dry dog food: 12.5 kg (food, new)") {
+		t.Errorf("acknowledgement = %q, want it to list the dog food", mail.sent[0].Body)
+	}
+}
+
+func TestRecordInKindDonationWhenTheAcknowledgementFails(t *testing.T) {
+	repository := newFakeInKindRepository()
+	service := NewInKindService(repository, &fakeSupplyRepository{}, failingMailer{})
+
+	items := []*models.InKindItem{
+		{Category: models.CategoryBedding, Name: "Blanket", Quantity: 3, Unit: models.UnitPiece, Condition: models.ConditionGood},
+	}
+
+	donation, err := service.RecordInKindDonation("user-1", "shelter-1", time.Now().Format(models.ReportDateLayout), "", "staff-1", items)
+	if err != nil {
+		t.Fatalf("RecordInKindDonation() error = %v, want the donation recorded anyway", err)
+	}
+
+	if repository.donations[donation.ID] == nil || donation.AcknowledgedAt != "" {
+		t.Errorf("RecordInKindDonation() = %+v, want it saved and not acknowledged", donation)
+	}
+
+	if _, err := service.RecordInKindDonation("user-2", "shelter-1", time.Now().Format(models.ReportDateLayout), "", "staff-1", items); err == nil {
+		t.Errorf("RecordInKindDonation() of an unknown donor error = nil, want an error")
+	}
+}
+
+func TestUseSupplyStock(t *testing.T) {
+	supplies := &fakeSupplyRepository{supplies: map[string]*models.SupplyItem{
+		"supply-1": {ID: "supply-1", ShelterID: "shelter-1", Name: "Dry dog food", Unit: models.UnitKg, Quantity: 10},
+	}}
+	service := NewInKindService(newFakeInKindRepository(), supplies, &fakeMailer{})
+	scope := tenant.Shelter("shelter-1")
+
+	tests := []struct {
+		name         string
+		id           string
+		quantity     float64
+		scope        tenant.Scope
+		wantErr      error
+		wantQuantity float64
+	}{
+		{name: "Part of the stock", id: "supply-1", quantity: 4, scope: scope, wantQuantity: 6},
+		{name: "More than the stock", id: "supply-1", quantity: 6.5, scope: scope, wantErr: models.ErrInsufficientStock, wantQuantity: 6},
+		{name: "The rest of the stock", id: "supply-1", quantity: 6, scope: scope, wantQuantity: 0},
+		{name: "No quantity", id: "supply-1", quantity: 0, scope: scope, wantErr: models.ErrInvalidQuantity, wantQuantity: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.UseSupply(tt.id, tt.quantity, tt.scope)
+			if err != tt.wantErr {
+				t.Fatalf("UseSupply() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := supplies.supplies["supply-1"].Quantity; got != tt.wantQuantity {
+				t.Errorf("stock = %v, want %v", got, tt.wantQuantity)
+			}
+		})
+	}
+
+	if _, err := service.UseSupply("supply-1", 1, tenant.Shelter("shelter-2")); err == nil {
+		t.Errorf("UseSupply() of another shelter's supply error = nil, want an error")
+	}
+}
+
+func TestFormatQuantity(t *testing.T) {
+	for quantity, want := range map[float64]string{12: "12", 12.5: "12.5", 0.25: "0.25", 3.10: "3.1"} {
+		if got := formatQuantity(quantity); got != want {
+			t.Errorf("formatQuantity(%v) = %q, want %q", quantity, got, want)
+		}
+	}
+}
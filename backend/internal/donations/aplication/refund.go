@@ -0,0 +1,177 @@
+package aplication
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// RefundService implements the RefundService interface
+type RefundService struct {
+	refunds   ports.RefundRepository
+	ledger    ports.LedgerRepository
+	donations ports.DonationRepository
+}
+
+// NewRefundService creates a new RefundService instance
+func NewRefundService(refunds ports.RefundRepository, ledger ports.LedgerRepository, donations ports.DonationRepository) *RefundService {
+	return &RefundService{
+		refunds:   refunds,
+		ledger:    ledger,
+		donations: donations,
+	}
+}
+
+// RequestRefund creates a refund request for part or all of a donation.
+// An amount of 0 requests a refund of the donation's whole remaining balance.
+func (s *RefundService) RequestRefund(donationID string, amount float64, reason, requestedBy string) (*models.Refund, error) {
+	donation, err := s.donations.FindByID(donationID, tenant.AllShelters())
+	if err != nil {
+		return nil, err
+	}
+
+	if donation == nil {
+		return nil, errors.New("donation not found")
+	}
+
+	if !donation.Status.IsRefundable() {
+		return nil, models.ErrDonationNotRefundable
+	}
+
+	if amount == 0 {
+		amount = donation.NetAmount
+	}
+
+	if toCents(amount) > toCents(donation.NetAmount) {
+		return nil, models.ErrRefundExceedsBalance
+	}
+
+	refund, err := models.NewRefund(uuid.New().String(), donation.ID, amount, reason, requestedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	refund.Created = now
+	refund.Updated = now
+
+	err = s.refunds.Save(refund)
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// ApproveRefund approves a requested refund of a donation to the shelters in scope and records
+// the money returned in the ledger
+func (s *RefundService) ApproveRefund(id, adminID string, scope tenant.Scope) (*models.Refund, error) {
+	refund, err := s.findPendingRefund(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	refund.Status = models.RefundStatusApproved
+	refund.ReviewedBy = adminID
+	refund.Updated = time.Now().Format(time.RFC3339)
+
+	entry := models.NewRefundEntry(uuid.New().String(), refund, adminID)
+	entry.Created = refund.Updated
+
+	err = s.refunds.Approve(refund, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// RejectRefund rejects a requested refund of a donation to the shelters in scope without moving
+// any money
+func (s *RefundService) RejectRefund(id, adminID string, scope tenant.Scope) (*models.Refund, error) {
+	refund, err := s.findPendingRefund(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	refund.Status = models.RefundStatusRejected
+	refund.ReviewedBy = adminID
+	refund.Updated = time.Now().Format(time.RFC3339)
+
+	err = s.refunds.Update(refund)
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// GetRefundByID returns a refund of a donation to the shelters in scope by its ID
+func (s *RefundService) GetRefundByID(id string, scope tenant.Scope) (*models.Refund, error) {
+	return s.refunds.FindByID(id, scope)
+}
+
+// GetRefundsByDonationID returns all refunds of a donation
+func (s *RefundService) GetRefundsByDonationID(donationID string) ([]*models.Refund, error) {
+	return s.refunds.FindByDonationID(donationID)
+}
+
+// GetRefundsByStatus returns all refunds of the donations to the shelters in scope with a specific status
+func (s *RefundService) GetRefundsByStatus(status models.RefundStatus, scope tenant.Scope) ([]*models.Refund, error) {
+	if !status.IsValid() {
+		return nil, models.ErrInvalidRefundStatus
+	}
+
+	return s.refunds.FindByStatus(status, scope)
+}
+
+// GetLedger returns the money movements of a donation and its resulting net amount
+func (s *RefundService) GetLedger(donationID string) (*models.Ledger, error) {
+	entries, err := s.ledger.FindByDonationID(donationID)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := &models.Ledger{
+		DonationID: donationID,
+		Entries:    []*models.LedgerEntry{},
+	}
+
+	var netCents int64
+	for _, entry := range entries {
+		ledger.Entries = append(ledger.Entries, entry)
+		netCents += toCents(entry.Amount)
+	}
+	ledger.NetAmount = float64(netCents) / 100
+
+	return ledger, nil
+}
+
+// findPendingRefund returns a refund of a donation to the shelters in scope that is still waiting
+// to be reviewed
+func (s *RefundService) findPendingRefund(id string, scope tenant.Scope) (*models.Refund, error) {
+	refund, err := s.refunds.FindByID(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if refund == nil {
+		return nil, errors.New("refund not found")
+	}
+
+	if !refund.Status.IsEquals(models.RefundStatusRequested) {
+		return nil, models.ErrRefundNotPending
+	}
+
+	return refund, nil
+}
+
+// toCents converts a monetary amount into whole cents to avoid floating point comparisons
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
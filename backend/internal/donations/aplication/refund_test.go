@@ -0,0 +1,124 @@
+package aplication
+
+import (
+	"testing"
+
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// fakeRefundRepository keeps refunds in memory
+type fakeRefundRepository struct {
+	refunds map[string]*models.Refund
+}
+
+func newFakeRefundRepository() *fakeRefundRepository {
+	return &fakeRefundRepository{refunds: map[string]*models.Refund{}}
+}
+
+func (r *fakeRefundRepository) Save(refund *models.Refund) error {
+	r.refunds[refund.ID] = refund
+	return nil
+}
+
+func (r *fakeRefundRepository) FindByID(id string, scope tenant.Scope) (*models.Refund, error) {
+	return r.refunds[id], nil
+}
+
+func (r *fakeRefundRepository) FindByDonationID(donationID string) ([]*models.Refund, error) {
+	return nil, nil
+}
+
+func (r *fakeRefundRepository) FindByStatus(status models.RefundStatus, scope tenant.Scope) ([]*models.Refund, error) {
+	return nil, nil
+}
+
+func (r *fakeRefundRepository) Update(refund *models.Refund) error {
+	r.refunds[refund.ID] = refund
+	return nil
+}
+
+func (r *fakeRefundRepository) Approve(refund *models.Refund, entry *models.LedgerEntry) error {
+	r.refunds[refund.ID] = refund
+	return nil
+}
+
+// fakeLedgerRepository returns fixed ledger entries
+type fakeLedgerRepository struct {
+	entries []*models.LedgerEntry
+}
+
+func (r *fakeLedgerRepository) FindByDonationID(donationID string) ([]*models.LedgerEntry, error) {
+	return r.entries, nil
+}
+
+func TestRequestRefundBalance(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     models.Status
+		netAmount  float64
+		amount     float64
+		wantErr    error
+		wantAmount float64
+	}{
+		{name: "Part of a completed donation", status: models.StatusCompleted, netAmount: 50, amount: 20, wantAmount: 20},
+		{name: "The whole balance by default", status: models.StatusPartiallyRefunded, netAmount: 30, amount: 0, wantAmount: 30},
+		{name: "Exactly the balance", status: models.StatusPartiallyRefunded, netAmount: 10.1, amount: 10.1, wantAmount: 10.1},
+		{name: "More than the balance", status: models.StatusPartiallyRefunded, netAmount: 30, amount: 30.01, wantErr: models.ErrRefundExceedsBalance},
+		{name: "Pending donation", status: models.StatusPending, amount: 10, wantErr: models.ErrDonationNotRefundable},
+		{name: "Refunded donation", status: models.StatusRefunded, amount: 10, wantErr: models.ErrDonationNotRefundable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			donations := newFakeDonationRepository(&models.Donation{ID: "1", ShelterID: "shelter-1", Amount: 50, NetAmount: tt.netAmount, Status: tt.status})
+			service := NewRefundService(newFakeRefundRepository(), &fakeLedgerRepository{}, donations)
+
+			refund, err := service.RequestRefund("1", tt.amount, "Charged twice", "user-1")
+			if err != tt.wantErr {
+				t.Fatalf("RequestRefund() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && refund.Amount != tt.wantAmount {
+				t.Errorf("RequestRefund() amount = %v, want %v", refund.Amount, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestReviewRefundOnlyOnce(t *testing.T) {
+	refunds := newFakeRefundRepository()
+	refunds.refunds["refund-1"] = &models.Refund{ID: "refund-1", DonationID: "1", Amount: 10, Status: models.RefundStatusRequested}
+	service := NewRefundService(refunds, &fakeLedgerRepository{}, newFakeDonationRepository())
+
+	refund, err := service.ApproveRefund("refund-1", "admin-1", tenant.AllShelters())
+	if err != nil {
+		t.Fatalf("ApproveRefund() error = %v", err)
+	}
+
+	if refund.Status != models.RefundStatusApproved || refund.ReviewedBy != "admin-1" {
+		t.Errorf("ApproveRefund() = %+v, want approved by admin-1", refund)
+	}
+
+	if _, err := service.RejectRefund("refund-1", "admin-1", tenant.AllShelters()); err != models.ErrRefundNotPending {
+		t.Errorf("RejectRefund() error = %v, want %v", err, models.ErrRefundNotPending)
+	}
+}
+
+func TestGetLedgerNetAmount(t *testing.T) {
+	ledger := &fakeLedgerRepository{entries: []*models.LedgerEntry{
+		{Type: models.EntryTypeCharge, Amount: 100.1},
+		{Type: models.EntryTypeRefund, Amount: -40.05},
+		{Type: models.EntryTypeRefund, Amount: -0.05},
+	}}
+	service := NewRefundService(newFakeRefundRepository(), ledger, newFakeDonationRepository())
+
+	got, err := service.GetLedger("1")
+	if err != nil {
+		t.Fatalf("GetLedger() error = %v", err)
+	}
+
+	if got.NetAmount != 60 || len(got.Entries) != 3 {
+		t.Errorf("GetLedger() = %v net over %d entries, want 60 over 3", got.NetAmount, len(got.Entries))
+	}
+}
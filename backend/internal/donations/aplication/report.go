@@ -0,0 +1,68 @@
+package aplication
+
+import (
+	"math"
+
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// ReportService implements the ReportService interface
+type ReportService struct {
+	repository ports.ReportRepository
+}
+
+// NewReportService creates a new ReportService instance
+func NewReportService(repository ports.ReportRepository) *ReportService {
+	return &ReportService{
+		repository: repository,
+	}
+}
+
+// GetTotals returns the completed donation totals to the shelters in scope of the range by day,
+// week or month
+func (s *ReportService) GetTotals(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.PeriodTotal, error) {
+	if !interval.IsValid() {
+		return nil, models.ErrInvalidInterval
+	}
+
+	return s.repository.TotalsByInterval(reportRange, interval, scope)
+}
+
+// GetCampaignTotals returns the completed donation totals to the shelters in scope of the range
+// by campaign
+func (s *ReportService) GetCampaignTotals(reportRange *models.ReportRange, scope tenant.Scope) ([]*models.CampaignTotal, error) {
+	return s.repository.TotalsByCampaign(reportRange, scope)
+}
+
+// GetDonorActivity returns the new and returning donors to the shelters in scope of the range by
+// day, week or month
+func (s *ReportService) GetDonorActivity(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.DonorActivity, error) {
+	if !interval.IsValid() {
+		return nil, models.ErrInvalidInterval
+	}
+
+	return s.repository.DonorActivityByInterval(reportRange, interval, scope)
+}
+
+// GetSummary returns the headline figures of the donations to the shelters in scope of the range,
+// including donor retention against the range of the same length immediately before it
+func (s *ReportService) GetSummary(reportRange *models.ReportRange, scope tenant.Scope) (*models.ReportSummary, error) {
+	summary, err := s.repository.Summary(reportRange, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// The range is half-open, so its last included day is the one before To
+	summary.From = reportRange.From.Format(models.ReportDateLayout)
+	summary.To = reportRange.To.AddDate(0, 0, -1).Format(models.ReportDateLayout)
+	summary.ReturningDonors = summary.Donors - summary.NewDonors
+
+	if summary.PreviousDonors > 0 {
+		rate := float64(summary.RetainedDonors) / float64(summary.PreviousDonors)
+		summary.RetentionRate = math.Round(rate*10000) / 10000
+	}
+
+	return summary, nil
+}
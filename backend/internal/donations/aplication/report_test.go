@@ -0,0 +1,85 @@
+package aplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// fakeReportRepository returns a fixed summary
+type fakeReportRepository struct {
+	summary models.ReportSummary
+}
+
+func (r *fakeReportRepository) TotalsByInterval(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.PeriodTotal, error) {
+	return nil, nil
+}
+
+func (r *fakeReportRepository) TotalsByCampaign(reportRange *models.ReportRange, scope tenant.Scope) ([]*models.CampaignTotal, error) {
+	return nil, nil
+}
+
+func (r *fakeReportRepository) DonorActivityByInterval(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.DonorActivity, error) {
+	return nil, nil
+}
+
+func (r *fakeReportRepository) Summary(reportRange *models.ReportRange, scope tenant.Scope) (*models.ReportSummary, error) {
+	summary := r.summary
+	return &summary, nil
+}
+
+func TestGetSummary(t *testing.T) {
+	repository := &fakeReportRepository{summary: models.ReportSummary{Donors: 5, NewDonors: 2, PreviousDonors: 3, RetainedDonors: 2}}
+	service := NewReportService(repository)
+
+	reportRange, err := models.NewReportRange(
+		time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC),
+	)
+	if err != nil {
+		t.Fatalf("NewReportRange() error = %v", err)
+	}
+
+	summary, err := service.GetSummary(reportRange, tenant.AllShelters())
+	if err != nil {
+		t.Fatalf("GetSummary() error = %v", err)
+	}
+
+	if summary.From != "2024-03-01" || summary.To != "2024-03-31" {
+		t.Errorf("GetSummary() range = %s to %s, want 2024-03-01 to 2024-03-31", summary.From, summary.To)
+	}
+
+	if summary.ReturningDonors != 3 {
+		t.Errorf("GetSummary() returning donors = %d, want 3", summary.ReturningDonors)
+	}
+
+	if summary.RetentionRate != 0.6667 {
+		t.Errorf("GetSummary() retention rate = %v, want 0.6667", summary.RetentionRate)
+	}
+
+	repository.summary = models.ReportSummary{Donors: 1, NewDonors: 1}
+
+	summary, err = service.GetSummary(reportRange, tenant.AllShelters())
+	if err != nil {
+		t.Fatalf("GetSummary() error = %v", err)
+	}
+
+	if summary.RetentionRate != 0 {
+		t.Errorf("GetSummary() retention rate without previous donors = %v, want 0", summary.RetentionRate)
+	}
+}
+
+func TestReportsRejectUnknownIntervals(t *testing.T) {
+	service := NewReportService(&fakeReportRepository{})
+	reportRange := &models.ReportRange{From: time.Now().AddDate(0, -1, 0), To: time.Now()}
+
+	if _, err := service.GetTotals(reportRange, "hour", tenant.AllShelters()); err != models.ErrInvalidInterval {
+		t.Errorf("GetTotals() error = %v, want %v", err, models.ErrInvalidInterval)
+	}
+
+	if _, err := service.GetDonorActivity(reportRange, "year", tenant.AllShelters()); err != models.ErrInvalidInterval {
+		t.Errorf("GetDonorActivity() error = %v, want %v", err, models.ErrInvalidInterval)
+	}
+}
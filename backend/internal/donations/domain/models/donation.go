@@ -1,32 +1,74 @@
 package models
 
+import (
+	"strings"
+	"unicode/utf8"
+)
+
 type Donation struct {
-	ID        string  `json:"id" db:"id"`
-	UserID    string  `json:"user_id" db:"user_id"`
-	Amount    float64 `json:"amount" db:"amount"`
-	Status    Status  `json:"status" db:"status"`
-	Created   string  `json:"created" db:"created"`
-	Updated   string  `json:"updated" db:"updated"`
-	Comment   string  `json:"comment" db:"comment"`
-	Anonymous bool    `json:"anonymous" db:"anonymous"`
+	ID          string          `json:"id" db:"id"`
+	ShelterID   string          `json:"shelter_id" db:"shelter_id"`
+	UserID      string          `json:"user_id" db:"user_id"`
+	Amount      float64         `json:"amount" db:"amount"`
+	Status      Status          `json:"status" db:"status"`
+	Created     string          `json:"created" db:"created"`
+	Updated     string          `json:"updated" db:"updated"`
+	Comment     string          `json:"comment" db:"comment"`
+	Anonymous   bool            `json:"anonymous" db:"anonymous"`
+	PetID       string          `json:"pet_id,omitempty" db:"pet_id"`
+	Sponsorship SponsorshipType `json:"sponsorship,omitempty" db:"sponsorship"`
+	Campaign    string          `json:"campaign,omitempty" db:"campaign"`
+	NetAmount   float64         `json:"net_amount" db:"net_amount"` // Derived from the donation ledger
 }
 
-// NewDonation creates a new Donation instance
-func NewDonation(id, userID string, amount float64, status Status, comment string, anonymous bool) (*Donation, error) {
+// NewDonation creates a new Donation instance for a shelter
+func NewDonation(id, shelterID, userID string, amount float64, status Status, comment, campaign string, anonymous bool) (*Donation, error) {
 	if !status.IsValid() {
 		return nil, ErrInvalidStatus
 	}
 
+	if shelterID == "" {
+		return nil, ErrShelterRequired
+	}
+
 	if amount <= 0 {
 		return nil, ErrInvalidAmount
 	}
 
+	campaign = strings.TrimSpace(campaign)
+	if utf8.RuneCountInString(campaign) > maxCampaignLength {
+		return nil, ErrInvalidCampaign
+	}
+
 	return &Donation{
 		ID:        id,
+		ShelterID: shelterID,
 		UserID:    userID,
 		Amount:    amount,
 		Status:    status,
 		Comment:   comment,
 		Anonymous: anonymous,
+		Campaign:  campaign,
 	}, nil
 }
+
+// NewSponsorship creates a new Donation earmarked for a pet of a shelter
+func NewSponsorship(id, shelterID, userID, petID string, amount float64, sponsorship SponsorshipType, status Status, comment, campaign string, anonymous bool) (*Donation, error) {
+	if !sponsorship.IsValid() {
+		return nil, ErrInvalidSponsorship
+	}
+
+	if petID == "" {
+		return nil, ErrPetNotFound
+	}
+
+	donation, err := NewDonation(id, shelterID, userID, amount, status, comment, campaign, anonymous)
+	if err != nil {
+		return nil, err
+	}
+
+	donation.PetID = petID
+	donation.Sponsorship = sponsorship
+
+	return donation, nil
+}
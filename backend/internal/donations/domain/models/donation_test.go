@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func TestNewSponsorship(t *testing.T) {
+	tests := []struct {
+		name        string
+		petID       string
+		amount      float64
+		sponsorship SponsorshipType
+		wantErr     error
+	}{
+		{name: "One-off", petID: "pet-1", amount: 25, sponsorship: SponsorshipOneOff},
+		{name: "Monthly", petID: "pet-1", amount: 10, sponsorship: SponsorshipMonthly},
+		{name: "Unknown sponsorship", petID: "pet-1", amount: 10, sponsorship: "weekly", wantErr: ErrInvalidSponsorship},
+		{name: "No pet", petID: "", amount: 10, sponsorship: SponsorshipOneOff, wantErr: ErrPetNotFound},
+		{name: "No amount", petID: "pet-1", amount: 0, sponsorship: SponsorshipOneOff, wantErr: ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			donation, err := NewSponsorship("donation-1", "shelter-1", "user-1", tt.petID, tt.amount, tt.sponsorship, StatusPending, "", "", false)
+			if err != tt.wantErr {
+				t.Fatalf("NewSponsorship() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if donation.PetID != tt.petID || donation.Sponsorship != tt.sponsorship || donation.ShelterID != "shelter-1" {
+				t.Errorf("NewSponsorship() = %+v, want pet %q, sponsorship %q and shelter shelter-1", donation, tt.petID, tt.sponsorship)
+			}
+		})
+	}
+}
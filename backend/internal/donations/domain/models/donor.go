@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type Period string
+
+var (
+	ErrInvalidPeriod      = errors.New("invalid period")
+	ErrInvalidDisplayName = errors.New("display name must be at most 100 characters")
+)
+
+const (
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+	PeriodYear  Period = "year"
+	PeriodAll   Period = "all"
+)
+
+var (
+	validPeriods = map[Period]struct{}{
+		PeriodWeek:  {},
+		PeriodMonth: {},
+		PeriodYear:  {},
+		PeriodAll:   {},
+	}
+)
+
+// AnonymousDonorName is shown in place of the name of donors who gave anonymously
+const AnonymousDonorName = "Anonymous"
+
+// String converts the Period to a string
+func (p Period) String() string {
+	return string(p)
+}
+
+// IsValid checks if the period is valid
+func (p Period) IsValid() bool {
+	_, ok := validPeriods[p]
+	return ok
+}
+
+// Start returns the beginning of the calendar period containing now.
+// Weeks start on Monday; the all-time period starts at the zero time.
+func (p Period) Start(now time.Time) time.Time {
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	switch p {
+	case PeriodWeek:
+		daysSinceMonday := (int(today.Weekday()) + 6) % 7
+		return today.AddDate(0, 0, -daysSinceMonday)
+	case PeriodMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, now.Location())
+	case PeriodYear:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, now.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// DonorProfile holds how a donor wants to appear on public donation pages
+type DonorProfile struct {
+	UserID      string `json:"user_id" db:"user_id"`
+	DisplayName string `json:"display_name" db:"display_name"` // Shown instead of User.Name when set
+	HideAmounts bool   `json:"hide_amounts" db:"hide_amounts"`
+	Updated     string `json:"updated" db:"updated"`
+}
+
+// NewDonorProfile creates a new DonorProfile instance
+func NewDonorProfile(userID, displayName string, hideAmounts bool) (*DonorProfile, error) {
+	if len([]rune(displayName)) > 100 {
+		return nil, ErrInvalidDisplayName
+	}
+
+	return &DonorProfile{
+		UserID:      userID,
+		DisplayName: displayName,
+		HideAmounts: hideAmounts,
+	}, nil
+}
+
+// PublicDonation is a donation as shown on the public donor wall
+type PublicDonation struct {
+	DonorName string   `json:"donor_name"`
+	Amount    *float64 `json:"amount"` // Nil when the donor hides their amounts
+	Comment   string   `json:"comment,omitempty"`
+	PetID     string   `json:"pet_id,omitempty"`
+	Created   string   `json:"created"`
+}
+
+// LeaderboardEntry is a donor's position on the top donors leaderboard
+type LeaderboardEntry struct {
+	Rank          int      `json:"rank"`
+	DonorName     string   `json:"donor_name"`
+	Amount        *float64 `json:"amount"` // Nil when the donor hides their amounts
+	DonationCount int      `json:"donation_count"`
+}
+
+// Leaderboard ranks the top donors of a period
+type Leaderboard struct {
+	Period  Period              `json:"period"`
+	Since   string              `json:"since,omitempty"`
+	Entries []*LeaderboardEntry `json:"entries"`
+}
@@ -0,0 +1,239 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type ItemCategory string
+
+type ItemCondition string
+
+type ItemUnit string
+
+var (
+	ErrInvalidItemCategory   = errors.New("invalid item category")
+	ErrInvalidItemCondition  = errors.New("invalid item condition")
+	ErrInvalidItemUnit       = errors.New("invalid item unit")
+	ErrInvalidItemName       = errors.New("item name is required and must be at most 100 characters")
+	ErrInvalidQuantity       = errors.New("quantity must be greater than 0")
+	ErrInvalidEstimatedValue = errors.New("estimated value cannot be negative")
+	ErrInvalidDropOffDate    = errors.New("drop-off date must be a YYYY-MM-DD date that is not in the future")
+	ErrNoInKindItems         = errors.New("an in-kind donation must contain at least one item")
+	ErrInsufficientStock     = errors.New("not enough of the supply in stock")
+)
+
+const (
+	CategoryFood     ItemCategory = "food"
+	CategoryBedding  ItemCategory = "bedding"
+	CategoryMedicine ItemCategory = "medicine"
+	CategoryToys     ItemCategory = "toys"
+	CategoryCleaning ItemCategory = "cleaning"
+	CategoryOther    ItemCategory = "other"
+)
+
+const (
+	ConditionNew  ItemCondition = "new"
+	ConditionGood ItemCondition = "good"
+	ConditionFair ItemCondition = "fair"
+)
+
+const (
+	UnitPiece ItemUnit = "piece"
+	UnitKg    ItemUnit = "kg"
+	UnitLiter ItemUnit = "liter"
+	UnitBag   ItemUnit = "bag"
+	UnitBox   ItemUnit = "box"
+	UnitCan   ItemUnit = "can"
+)
+
+// maxItemNameLength is the maximum number of characters of an item name
+const maxItemNameLength = 100
+
+var (
+	validItemCategories = map[ItemCategory]struct{}{
+		CategoryFood:     {},
+		CategoryBedding:  {},
+		CategoryMedicine: {},
+		CategoryToys:     {},
+		CategoryCleaning: {},
+		CategoryOther:    {},
+	}
+
+	validItemConditions = map[ItemCondition]struct{}{
+		ConditionNew:  {},
+		ConditionGood: {},
+		ConditionFair: {},
+	}
+
+	validItemUnits = map[ItemUnit]struct{}{
+		UnitPiece: {},
+		UnitKg:    {},
+		UnitLiter: {},
+		UnitBag:   {},
+		UnitBox:   {},
+		UnitCan:   {},
+	}
+)
+
+// String converts the ItemCategory to a string
+func (c ItemCategory) String() string {
+	return string(c)
+}
+
+// IsValid checks if the item category is valid
+func (c ItemCategory) IsValid() bool {
+	_, ok := validItemCategories[c]
+	return ok
+}
+
+// String converts the ItemCondition to a string
+func (c ItemCondition) String() string {
+	return string(c)
+}
+
+// IsValid checks if the item condition is valid
+func (c ItemCondition) IsValid() bool {
+	_, ok := validItemConditions[c]
+	return ok
+}
+
+// String converts the ItemUnit to a string
+func (u ItemUnit) String() string {
+	return string(u)
+}
+
+// IsValid checks if the item unit is valid
+func (u ItemUnit) IsValid() bool {
+	_, ok := validItemUnits[u]
+	return ok
+}
+
+// InKindItem is one kind of goods handed in as part of an in-kind donation
+type InKindItem struct {
+	ID             string        `json:"id" db:"id"`
+	DonationID     string        `json:"donation_id" db:"donation_id"`
+	Category       ItemCategory  `json:"category" db:"category"`
+	Name           string        `json:"name" db:"name"`
+	Quantity       float64       `json:"quantity" db:"quantity"`
+	Unit           ItemUnit      `json:"unit" db:"unit"`
+	EstimatedValue float64       `json:"estimated_value" db:"estimated_value"` // For the whole quantity
+	Condition      ItemCondition `json:"condition" db:"condition"`
+}
+
+// NewInKindItem creates a new InKindItem instance
+func NewInKindItem(id string, category ItemCategory, name string, quantity float64, unit ItemUnit, estimatedValue float64, condition ItemCondition) (*InKindItem, error) {
+	if !category.IsValid() {
+		return nil, ErrInvalidItemCategory
+	}
+
+	if !condition.IsValid() {
+		return nil, ErrInvalidItemCondition
+	}
+
+	if !unit.IsValid() {
+		return nil, ErrInvalidItemUnit
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxItemNameLength {
+		return nil, ErrInvalidItemName
+	}
+
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	if estimatedValue < 0 {
+		return nil, ErrInvalidEstimatedValue
+	}
+
+	return &InKindItem{
+		ID:             id,
+		Category:       category,
+		Name:           name,
+		Quantity:       quantity,
+		Unit:           unit,
+		EstimatedValue: estimatedValue,
+		Condition:      condition,
+	}, nil
+}
+
+// InKindDonation records goods such as food, bedding or medicine dropped off by a donor
+type InKindDonation struct {
+	ID             string        `json:"id" db:"id"`
+	ShelterID      string        `json:"shelter_id" db:"shelter_id"`
+	UserID         string        `json:"user_id" db:"user_id"`
+	DropOffDate    string        `json:"drop_off_date" db:"drop_off_date"`
+	Comment        string        `json:"comment" db:"comment"`
+	ReceivedBy     string        `json:"received_by" db:"received_by"`
+	Items          []*InKindItem `json:"items"`
+	EstimatedValue float64       `json:"estimated_value" db:"estimated_value"` // Sum of the items' estimated values
+	AcknowledgedAt string        `json:"acknowledged_at,omitempty" db:"acknowledged_at"`
+	Created        string        `json:"created" db:"created"`
+	Updated        string        `json:"updated" db:"updated"`
+}
+
+// NewInKindDonation creates a new InKindDonation instance for the shelter receiving the goods
+func NewInKindDonation(id, shelterID, userID, dropOffDate, comment, receivedBy string, items []*InKindItem, now time.Time) (*InKindDonation, error) {
+	if shelterID == "" {
+		return nil, ErrShelterRequired
+	}
+
+	if len(items) == 0 {
+		return nil, ErrNoInKindItems
+	}
+
+	date, err := time.Parse(ReportDateLayout, dropOffDate)
+	if err != nil || date.After(now) {
+		return nil, ErrInvalidDropOffDate
+	}
+
+	donation := &InKindDonation{
+		ID:          id,
+		ShelterID:   shelterID,
+		UserID:      userID,
+		DropOffDate: dropOffDate,
+		Comment:     comment,
+		ReceivedBy:  receivedBy,
+		Items:       items,
+	}
+
+	for _, item := range items {
+		item.DonationID = id
+		donation.EstimatedValue += item.EstimatedValue
+	}
+
+	return donation, nil
+}
+
+// SupplyItem is a shelter's stock of one kind of goods, fed by in-kind donations
+type SupplyItem struct {
+	ID        string       `json:"id" db:"id"`
+	ShelterID string       `json:"shelter_id" db:"shelter_id"`
+	Category  ItemCategory `json:"category" db:"category"`
+	Name      string       `json:"name" db:"name"`
+	Unit      ItemUnit     `json:"unit" db:"unit"`
+	Quantity  float64      `json:"quantity" db:"quantity"`
+	Updated   string       `json:"updated" db:"updated"`
+}
+
+// DonorContact holds what is needed to write to a donor
+type DonorContact struct {
+	UserID string
+	Name   string
+	Email  string
+}
+
+// Acknowledgement thanks a donor for an in-kind donation, listing the items given
+type Acknowledgement struct {
+	DonationID     string        `json:"donation_id"`
+	DonorName      string        `json:"donor_name"`
+	DropOffDate    string        `json:"drop_off_date"`
+	Items          []*InKindItem `json:"items"`
+	EstimatedValue float64       `json:"estimated_value"`
+	Subject        string        `json:"subject"`
+	Message        string        `json:"message"`
+}
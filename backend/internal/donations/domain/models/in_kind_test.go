@@ -0,0 +1,91 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewInKindItem(t *testing.T) {
+	tests := []struct {
+		name           string
+		category       ItemCategory
+		itemName       string
+		quantity       float64
+		unit           ItemUnit
+		estimatedValue float64
+		condition      ItemCondition
+		wantErr        error
+	}{
+		{name: "Valid", category: CategoryFood, itemName: " Dry dog food ", quantity: 12.5, unit: UnitKg, estimatedValue: 40, condition: ConditionNew},
+		{name: "Free item", category: CategoryToys, itemName: "Ball", quantity: 1, unit: UnitPiece, condition: ConditionFair},
+		{name: "Unknown category", category: "furniture", itemName: "Chair", quantity: 1, unit: UnitPiece, condition: ConditionGood, wantErr: ErrInvalidItemCategory},
+		{name: "Unknown condition", category: CategoryBedding, itemName: "Blanket", quantity: 1, unit: UnitPiece, condition: "worn", wantErr: ErrInvalidItemCondition},
+		{name: "Unknown unit", category: CategoryFood, itemName: "Treats", quantity: 1, unit: "pallet", condition: ConditionNew, wantErr: ErrInvalidItemUnit},
+		{name: "Blank name", category: CategoryFood, itemName: "   ", quantity: 1, unit: UnitBag, condition: ConditionNew, wantErr: ErrInvalidItemName},
+		{name: "Long name", category: CategoryFood, itemName: strings.Repeat("a", 101), quantity: 1, unit: UnitBag, condition: ConditionNew, wantErr: ErrInvalidItemName},
+		{name: "No quantity", category: CategoryCleaning, itemName: "Bleach", quantity: 0, unit: UnitLiter, condition: ConditionNew, wantErr: ErrInvalidQuantity},
+		{name: "Negative value", category: CategoryMedicine, itemName: "Dewormer", quantity: 2, unit: UnitBox, estimatedValue: -1, condition: ConditionNew, wantErr: ErrInvalidEstimatedValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := NewInKindItem("item-1", tt.category, tt.itemName, tt.quantity, tt.unit, tt.estimatedValue, tt.condition)
+			if err != tt.wantErr {
+				t.Fatalf("NewInKindItem() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && item.Name != strings.TrimSpace(tt.itemName) {
+				t.Errorf("NewInKindItem() name = %q, want it trimmed", item.Name)
+			}
+		})
+	}
+}
+
+func TestNewInKindDonation(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 15, 0, 0, 0, time.UTC)
+	items := func() []*InKindItem {
+		return []*InKindItem{
+			{ID: "item-1", Name: "Dry dog food", Quantity: 10, EstimatedValue: 30},
+			{ID: "item-2", Name: "Blanket", Quantity: 2, EstimatedValue: 12.5},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		shelterID   string
+		dropOffDate string
+		items       []*InKindItem
+		wantErr     error
+	}{
+		{name: "Dropped off today", shelterID: "shelter-1", dropOffDate: "2024-03-10", items: items()},
+		{name: "Dropped off earlier", shelterID: "shelter-1", dropOffDate: "2024-02-28", items: items()},
+		{name: "No shelter", dropOffDate: "2024-03-10", items: items(), wantErr: ErrShelterRequired},
+		{name: "No items", shelterID: "shelter-1", dropOffDate: "2024-03-10", wantErr: ErrNoInKindItems},
+		{name: "In the future", shelterID: "shelter-1", dropOffDate: "2024-03-11", items: items(), wantErr: ErrInvalidDropOffDate},
+		{name: "Not a date", shelterID: "shelter-1", dropOffDate: "10/03/2024", items: items(), wantErr: ErrInvalidDropOffDate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			donation, err := NewInKindDonation("donation-1", tt.shelterID, "user-1", tt.dropOffDate, "", "staff-1", tt.items, now)
+			if err != tt.wantErr {
+				t.Fatalf("NewInKindDonation() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if donation.EstimatedValue != 42.5 {
+				t.Errorf("NewInKindDonation() estimated value = %v, want 42.5", donation.EstimatedValue)
+			}
+
+			for _, item := range donation.Items {
+				if item.DonationID != "donation-1" {
+					t.Errorf("NewInKindDonation() item %s donation = %q, want donation-1", item.ID, item.DonationID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+type EntryType string
+
+const (
+	EntryTypeCharge EntryType = "charge"
+	EntryTypeRefund EntryType = "refund"
+)
+
+// String converts the EntryType to a string
+func (t EntryType) String() string {
+	return string(t)
+}
+
+// IsEquals checks if the entry type is equal to another entry type
+func (t EntryType) IsEquals(in EntryType) bool {
+	return t.String() == in.String()
+}
+
+// LedgerEntry is an append-only record of money moving in or out of a donation.
+// Charges carry a positive amount and refunds a negative one, so the net amount
+// of a donation is the sum of its entries.
+type LedgerEntry struct {
+	ID         string    `json:"id" db:"id"`
+	DonationID string    `json:"donation_id" db:"donation_id"`
+	Type       EntryType `json:"type" db:"entry_type"`
+	Amount     float64   `json:"amount" db:"amount"`
+	RefundID   string    `json:"refund_id,omitempty" db:"refund_id"`
+	ActorID    string    `json:"actor_id,omitempty" db:"actor_id"`
+	Created    string    `json:"created" db:"created"`
+}
+
+// NewChargeEntry creates the ledger entry recording the money received for a donation
+func NewChargeEntry(id string, donation *Donation, actorID string) *LedgerEntry {
+	return &LedgerEntry{
+		ID:         id,
+		DonationID: donation.ID,
+		Type:       EntryTypeCharge,
+		Amount:     donation.Amount,
+		ActorID:    actorID,
+	}
+}
+
+// NewRefundEntry creates the ledger entry recording the money returned by an approved refund
+func NewRefundEntry(id string, refund *Refund, approvedBy string) *LedgerEntry {
+	return &LedgerEntry{
+		ID:         id,
+		DonationID: refund.DonationID,
+		Type:       EntryTypeRefund,
+		Amount:     -refund.Amount,
+		RefundID:   refund.ID,
+		ActorID:    approvedBy,
+	}
+}
+
+// Ledger is the list of money movements of a donation along with its resulting net amount
+type Ledger struct {
+	DonationID string         `json:"donation_id"`
+	Entries    []*LedgerEntry `json:"entries"`
+	NetAmount  float64        `json:"net_amount"`
+}
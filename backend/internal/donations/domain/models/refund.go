@@ -0,0 +1,78 @@
+package models
+
+import "errors"
+
+type RefundStatus string
+
+var (
+	ErrInvalidRefundStatus   = errors.New("invalid refund status")
+	ErrInvalidRefundAmount   = errors.New("invalid refund amount")
+	ErrRefundReasonRequired  = errors.New("refund reason is required")
+	ErrRefundExceedsBalance  = errors.New("refund exceeds the donation's remaining balance")
+	ErrDonationNotRefundable = errors.New("only completed donations can be refunded")
+	ErrRefundNotPending      = errors.New("refund has already been processed")
+	ErrUseRefundWorkflow     = errors.New("donations can only be refunded through a refund request")
+	ErrDonationHasLedger     = errors.New("donations with recorded payments cannot be deleted")
+)
+
+const (
+	RefundStatusRequested RefundStatus = "requested"
+	RefundStatusApproved  RefundStatus = "approved"
+	RefundStatusRejected  RefundStatus = "rejected"
+)
+
+var (
+	validRefundStatuses = map[RefundStatus]struct{}{
+		RefundStatusRequested: {},
+		RefundStatusApproved:  {},
+		RefundStatusRejected:  {},
+	}
+)
+
+// String converts the RefundStatus to a string
+func (s RefundStatus) String() string {
+	return string(s)
+}
+
+// IsEquals checks if the refund status is equal to another refund status
+func (s RefundStatus) IsEquals(in RefundStatus) bool {
+	return s.String() == in.String()
+}
+
+// IsValid checks if the refund status is valid
+func (s RefundStatus) IsValid() bool {
+	_, ok := validRefundStatuses[s]
+	return ok
+}
+
+type Refund struct {
+	ID          string       `json:"id" db:"id"`
+	DonationID  string       `json:"donation_id" db:"donation_id"`
+	Amount      float64      `json:"amount" db:"amount"`
+	Reason      string       `json:"reason" db:"reason"`
+	Status      RefundStatus `json:"status" db:"status"`
+	RequestedBy string       `json:"requested_by" db:"requested_by"`
+	ReviewedBy  string       `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	Created     string       `json:"created" db:"created"`
+	Updated     string       `json:"updated" db:"updated"`
+}
+
+// NewRefund creates a new Refund request
+func NewRefund(id, donationID string, amount float64, reason, requestedBy string) (*Refund, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidRefundAmount
+	}
+
+	if reason == "" {
+		return nil, ErrRefundReasonRequired
+	}
+
+	return &Refund{
+		ID:          id,
+		DonationID:  donationID,
+		Amount:      amount,
+		Reason:      reason,
+		Status:      RefundStatusRequested,
+		RequestedBy: requestedBy,
+	}, nil
+}
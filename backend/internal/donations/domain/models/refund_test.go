@@ -0,0 +1,72 @@
+package models
+
+import "testing"
+
+func TestNewRefund(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  float64
+		reason  string
+		wantErr error
+	}{
+		{name: "Valid", amount: 10, reason: "Charged twice"},
+		{name: "No amount", amount: 0, reason: "Charged twice", wantErr: ErrInvalidRefundAmount},
+		{name: "Negative amount", amount: -5, reason: "Charged twice", wantErr: ErrInvalidRefundAmount},
+		{name: "No reason", amount: 10, reason: "", wantErr: ErrRefundReasonRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refund, err := NewRefund("refund-1", "donation-1", tt.amount, tt.reason, "user-1")
+			if err != tt.wantErr {
+				t.Fatalf("NewRefund() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && refund.Status != RefundStatusRequested {
+				t.Errorf("NewRefund() status = %q, want %q", refund.Status, RefundStatusRequested)
+			}
+		})
+	}
+}
+
+func TestStatusRefunds(t *testing.T) {
+	tests := []struct {
+		status           Status
+		refundable       bool
+		hasReceivedMoney bool
+	}{
+		{status: StatusPending},
+		{status: StatusFailed},
+		{status: StatusCompleted, refundable: true, hasReceivedMoney: true},
+		{status: StatusPartiallyRefunded, refundable: true, hasReceivedMoney: true},
+		{status: StatusRefunded, hasReceivedMoney: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status.String(), func(t *testing.T) {
+			if got := tt.status.IsRefundable(); got != tt.refundable {
+				t.Errorf("IsRefundable() = %v, want %v", got, tt.refundable)
+			}
+
+			if got := tt.status.HasReceivedMoney(); got != tt.hasReceivedMoney {
+				t.Errorf("HasReceivedMoney() = %v, want %v", got, tt.hasReceivedMoney)
+			}
+		})
+	}
+}
+
+func TestLedgerEntries(t *testing.T) {
+	donation := &Donation{ID: "donation-1", Amount: 50}
+
+	charge := NewChargeEntry("entry-1", donation, "admin-1")
+	if charge.Type != EntryTypeCharge || charge.Amount != 50 || charge.DonationID != "donation-1" {
+		t.Errorf("NewChargeEntry() = %+v, want a charge of 50 to donation-1", charge)
+	}
+
+	refund := &Refund{ID: "refund-1", DonationID: "donation-1", Amount: 20}
+
+	entry := NewRefundEntry("entry-2", refund, "admin-1")
+	if entry.Type != EntryTypeRefund || entry.Amount != -20 || entry.RefundID != "refund-1" {
+		t.Errorf("NewRefundEntry() = %+v, want a refund of -20 for refund-1", entry)
+	}
+}
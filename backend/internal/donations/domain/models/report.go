@@ -0,0 +1,153 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type Interval string
+
+var (
+	ErrInvalidInterval    = errors.New("invalid interval")
+	ErrInvalidReportRange = errors.New("report start date must be before its end date")
+	ErrInvalidCampaign    = errors.New("campaign must be at most 100 characters")
+)
+
+const (
+	IntervalDay   Interval = "day"
+	IntervalWeek  Interval = "week"
+	IntervalMonth Interval = "month"
+)
+
+var (
+	validIntervals = map[Interval]struct{}{
+		IntervalDay:   {},
+		IntervalWeek:  {},
+		IntervalMonth: {},
+	}
+)
+
+const (
+	// DefaultCampaign groups the donations that were not made for a specific campaign
+	DefaultCampaign   = "general"
+	maxCampaignLength = 100
+	// ReportDateLayout is the layout of the dates used by reports
+	ReportDateLayout = "2006-01-02"
+)
+
+// String converts the Interval to a string
+func (i Interval) String() string {
+	return string(i)
+}
+
+// IsValid checks if the interval is valid
+func (i Interval) IsValid() bool {
+	_, ok := validIntervals[i]
+	return ok
+}
+
+// ReportRange is the half-open time range [From, To) covered by a report
+type ReportRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// NewReportRange creates a new ReportRange instance
+func NewReportRange(from, to time.Time) (*ReportRange, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidReportRange
+	}
+
+	return &ReportRange{
+		From: from,
+		To:   to,
+	}, nil
+}
+
+// Previous returns the range of the same length immediately before this one
+func (r *ReportRange) Previous() *ReportRange {
+	return &ReportRange{
+		From: r.From.Add(-r.To.Sub(r.From)),
+		To:   r.From,
+	}
+}
+
+// PeriodTotal aggregates the completed donations of a day, week or month
+type PeriodTotal struct {
+	PeriodStart string  `json:"period_start"`
+	Total       float64 `json:"total"`
+	Count       int     `json:"count"`
+	Donors      int     `json:"donors"`
+	AverageGift float64 `json:"average_gift"`
+}
+
+// CSVRecord converts the period total into a CSV record
+func (t *PeriodTotal) CSVRecord() []string {
+	return []string{t.PeriodStart, formatAmount(t.Total), strconv.Itoa(t.Count), strconv.Itoa(t.Donors), formatAmount(t.AverageGift)}
+}
+
+// CampaignTotal aggregates the completed donations of a campaign
+type CampaignTotal struct {
+	Campaign    string  `json:"campaign"`
+	Total       float64 `json:"total"`
+	Count       int     `json:"count"`
+	Donors      int     `json:"donors"`
+	AverageGift float64 `json:"average_gift"`
+}
+
+// CSVRecord converts the campaign total into a CSV record
+func (t *CampaignTotal) CSVRecord() []string {
+	return []string{t.Campaign, formatAmount(t.Total), strconv.Itoa(t.Count), strconv.Itoa(t.Donors), formatAmount(t.AverageGift)}
+}
+
+// DonorActivity counts the first-time and returning donors of a day, week or month
+type DonorActivity struct {
+	PeriodStart     string `json:"period_start"`
+	NewDonors       int    `json:"new_donors"`
+	ReturningDonors int    `json:"returning_donors"`
+}
+
+// CSVRecord converts the donor activity into a CSV record
+func (a *DonorActivity) CSVRecord() []string {
+	return []string{a.PeriodStart, strconv.Itoa(a.NewDonors), strconv.Itoa(a.ReturningDonors)}
+}
+
+// ReportSummary holds the headline figures of a report range.
+// Retention is the share of the previous range's donors who gave again in this range.
+type ReportSummary struct {
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	Total           float64 `json:"total"`
+	Count           int     `json:"count"`
+	Donors          int     `json:"donors"`
+	AverageGift     float64 `json:"average_gift"`
+	NewDonors       int     `json:"new_donors"`
+	ReturningDonors int     `json:"returning_donors"`
+	PreviousDonors  int     `json:"previous_donors"`
+	RetainedDonors  int     `json:"retained_donors"`
+	RetentionRate   float64 `json:"retention_rate"`
+}
+
+// CSVRecord converts the summary into a CSV record
+func (s *ReportSummary) CSVRecord() []string {
+	return []string{
+		s.From,
+		s.To,
+		formatAmount(s.Total),
+		strconv.Itoa(s.Count),
+		strconv.Itoa(s.Donors),
+		formatAmount(s.AverageGift),
+		strconv.Itoa(s.NewDonors),
+		strconv.Itoa(s.ReturningDonors),
+		strconv.Itoa(s.PreviousDonors),
+		strconv.Itoa(s.RetainedDonors),
+		fmt.Sprintf("%.4f", s.RetentionRate),
+	}
+}
+
+// formatAmount formats a monetary amount with two decimals
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewReportRange(t *testing.T) {
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := NewReportRange(from, from); err != ErrInvalidReportRange {
+		t.Errorf("NewReportRange() of an empty range error = %v, want %v", err, ErrInvalidReportRange)
+	}
+
+	if _, err := NewReportRange(from, from.AddDate(0, 0, -1)); err != ErrInvalidReportRange {
+		t.Errorf("NewReportRange() of a reversed range error = %v, want %v", err, ErrInvalidReportRange)
+	}
+
+	reportRange, err := NewReportRange(from, from.AddDate(0, 0, 10))
+	if err != nil {
+		t.Fatalf("NewReportRange() error = %v", err)
+	}
+
+	previous := reportRange.Previous()
+	if !previous.From.Equal(from.AddDate(0, 0, -10)) || !previous.To.Equal(from) {
+		t.Errorf("Previous() = [%v, %v), want the 10 days before %v", previous.From, previous.To, from)
+	}
+}
+
+func TestReportCSVRecords(t *testing.T) {
+	total := &PeriodTotal{PeriodStart: "2024-03-01", Total: 120.5, Count: 3, Donors: 2, AverageGift: 40.1666}
+	if got, want := total.CSVRecord(), []string{"2024-03-01", "120.50", "3", "2", "40.17"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PeriodTotal.CSVRecord() = %v, want %v", got, want)
+	}
+
+	summary := &ReportSummary{From: "2024-03-01", To: "2024-03-31", Total: 10, Count: 1, Donors: 1, AverageGift: 10, NewDonors: 1, RetentionRate: 0.3333}
+	got := summary.CSVRecord()
+	if len(got) != 11 || got[2] != "10.00" || got[10] != "0.3333" {
+		t.Errorf("ReportSummary.CSVRecord() = %v, want 11 fields with a total of 10.00 and a retention of 0.3333", got)
+	}
+}
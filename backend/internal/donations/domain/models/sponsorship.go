@@ -0,0 +1,58 @@
+package models
+
+import "errors"
+
+type SponsorshipType string
+
+var (
+	ErrInvalidSponsorship = errors.New("invalid sponsorship type")
+	ErrPetNotFound        = errors.New("pet not found")
+)
+
+const (
+	SponsorshipOneOff  SponsorshipType = "one_off"
+	SponsorshipMonthly SponsorshipType = "monthly"
+)
+
+var (
+	validSponsorshipTypes = map[SponsorshipType]struct{}{
+		SponsorshipOneOff:  {},
+		SponsorshipMonthly: {},
+	}
+)
+
+// String converts the SponsorshipType to a string
+func (t SponsorshipType) String() string {
+	return string(t)
+}
+
+// IsEquals checks if the sponsorship type is equal to another sponsorship type
+func (t SponsorshipType) IsEquals(in SponsorshipType) bool {
+	return t.String() == in.String()
+}
+
+// IsValid checks if the sponsorship type is valid
+func (t SponsorshipType) IsValid() bool {
+	_, ok := validSponsorshipTypes[t]
+	return ok
+}
+
+// SponsorshipSummary aggregates the completed donations earmarked for a pet by their net amount
+type SponsorshipSummary struct {
+	PetID         string   `json:"pet_id"`
+	TotalAmount   float64  `json:"total_amount"`
+	OneOffAmount  float64  `json:"one_off_amount"`
+	MonthlyAmount float64  `json:"monthly_amount"`
+	SponsorCount  int      `json:"sponsor_count"`
+	Sponsors      []string `json:"sponsors"`
+}
+
+// Sponsor represents a user that has sponsored a pet
+type Sponsor struct {
+	UserID     string `json:"user_id" db:"user_id"`
+	Name       string `json:"name" db:"name"`
+	Email      string `json:"email" db:"email"`
+	Anonymous  bool   `json:"anonymous" db:"anonymous"`
+	PublicName string `json:"public_name" db:"public_name"` // The donor's display name, or AnonymousDonorName
+	Erased     bool   `json:"-" db:"erased"`                // The account was erased, leaving no address to write to
+}
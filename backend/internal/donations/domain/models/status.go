@@ -5,23 +5,27 @@ import "errors"
 type Status string
 
 var (
-	ErrInvalidStatus = errors.New("invalid status")
-	ErrInvalidAmount = errors.New("invalid amount")
+	ErrInvalidStatus   = errors.New("invalid status")
+	ErrInvalidAmount   = errors.New("invalid amount")
+	ErrShelterRequired = errors.New("shelter ID is required")
+	ErrShelterNotFound = errors.New("shelter not found")
 )
 
 const (
-	StatusPending   Status = "pending"
-	StatusCompleted Status = "completed"
-	StatusFailed    Status = "failed"
-	StatusRefunded  Status = "refunded"
+	StatusPending           Status = "pending"
+	StatusCompleted         Status = "completed"
+	StatusFailed            Status = "failed"
+	StatusRefunded          Status = "refunded"
+	StatusPartiallyRefunded Status = "partially_refunded"
 )
 
 var (
 	validStatuses = map[Status]struct{}{
-		StatusPending:   {},
-		StatusCompleted: {},
-		StatusFailed:    {},
-		StatusRefunded:  {},
+		StatusPending:           {},
+		StatusCompleted:         {},
+		StatusFailed:            {},
+		StatusRefunded:          {},
+		StatusPartiallyRefunded: {},
 	}
 )
 
@@ -40,3 +44,14 @@ func (s Status) IsValid() bool {
 	_, ok := validStatuses[s]
 	return ok
 }
+
+// IsRefundable checks if money can still be returned for a donation in this status
+func (s Status) IsRefundable() bool {
+	return s.IsEquals(StatusCompleted) || s.IsEquals(StatusPartiallyRefunded)
+}
+
+// HasReceivedMoney checks if money has been received for a donation in this status, which can then
+// only change through the refund workflow
+func (s Status) HasReceivedMoney() bool {
+	return s.IsRefundable() || s.IsEquals(StatusRefunded)
+}
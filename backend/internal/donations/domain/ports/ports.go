@@ -1,21 +1,120 @@
 package ports
 
-import "github.com/solrac97gr/petparadise/internal/donations/domain/models"
+import (
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
 
 type DonationRepository interface {
 	Save(donation *models.Donation) error
-	FindByID(id string) (*models.Donation, error)
-	FindByUserID(userID string) ([]*models.Donation, error)
-	FindAll() ([]*models.Donation, error)
+	FindByID(id string, scope tenant.Scope) (*models.Donation, error)
+	FindByUserID(userID string, scope tenant.Scope) ([]*models.Donation, error)
+	FindByPetID(petID string) ([]*models.Donation, error)
+	FindAll(scope tenant.Scope) ([]*models.Donation, error)
+	FindSponsorsByPetID(petID string, statuses ...models.Status) ([]*models.Sponsor, error)
+	// Update updates a donation. Its shelter can't be changed.
 	Update(donation *models.Donation) error
-	Delete(id string) error
+	// UpdateStatus updates the status of a donation along with recording its charge in the ledger,
+	// if one is given, unless money has already been received for it
+	UpdateStatus(donation *models.Donation, charge *models.LedgerEntry) error
+	Delete(id string, scope tenant.Scope) error
+}
+
+// PetDirectory tells the donations module which shelter a pet belongs to
+type PetDirectory interface {
+	// GetPetShelter returns the shelter of a pet, empty if the pet doesn't exist
+	GetPetShelter(petID string) (string, error)
+}
+
+type LedgerRepository interface {
+	FindByDonationID(donationID string) ([]*models.LedgerEntry, error)
+}
+
+type RefundRepository interface {
+	Save(refund *models.Refund) error
+	// FindByID and FindByStatus look up the refunds of the donations of the shelters in scope
+	FindByID(id string, scope tenant.Scope) (*models.Refund, error)
+	FindByDonationID(donationID string) ([]*models.Refund, error)
+	FindByStatus(status models.RefundStatus, scope tenant.Scope) ([]*models.Refund, error)
+	Update(refund *models.Refund) error
+	Approve(refund *models.Refund, entry *models.LedgerEntry) error
+}
+
+type DonorRepository interface {
+	SaveProfile(profile *models.DonorProfile) error
+	FindProfileByUserID(userID string) (*models.DonorProfile, error)
+	FindPublicDonations(limit, offset int, scope tenant.Scope) ([]*models.PublicDonation, error)
+	FindTopDonors(since time.Time, limit int, scope tenant.Scope) ([]*models.LeaderboardEntry, error)
+}
+
+// ReportRepository aggregates the donations of the shelters in scope
+type ReportRepository interface {
+	TotalsByInterval(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.PeriodTotal, error)
+	TotalsByCampaign(reportRange *models.ReportRange, scope tenant.Scope) ([]*models.CampaignTotal, error)
+	DonorActivityByInterval(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.DonorActivity, error)
+	Summary(reportRange *models.ReportRange, scope tenant.Scope) (*models.ReportSummary, error)
+}
+
+type InKindRepository interface {
+	Save(donation *models.InKindDonation) error
+	FindByID(id string, scope tenant.Scope) (*models.InKindDonation, error)
+	// FindByUserID finds the in-kind donations of a donor to every shelter
+	FindByUserID(userID string) ([]*models.InKindDonation, error)
+	FindAll(scope tenant.Scope) ([]*models.InKindDonation, error)
+	MarkAcknowledged(id, acknowledgedAt string) error
+	FindDonorContact(userID string) (*models.DonorContact, error)
+}
+
+type SupplyRepository interface {
+	FindAll(scope tenant.Scope) ([]*models.SupplyItem, error)
+	Use(id string, quantity float64, updated string, scope tenant.Scope) (*models.SupplyItem, error)
 }
 
 type DonationService interface {
-	CreateDonation(userID string, amount float64, comment string, anonymous bool) (*models.Donation, error)
-	GetDonationByID(id string) (*models.Donation, error)
-	GetDonationsByUserID(userID string) ([]*models.Donation, error)
-	GetAllDonations() ([]*models.Donation, error)
-	UpdateDonation(id string, status models.Status) (*models.Donation, error)
-	DeleteDonation(id string) error
+	CreateDonation(userID, shelterID string, amount float64, comment, campaign string, anonymous bool) (*models.Donation, error)
+	CreateSponsorship(userID, petID string, amount float64, sponsorship models.SponsorshipType, comment, campaign string, anonymous bool) (*models.Donation, error)
+	GetDonationByID(id string, scope tenant.Scope) (*models.Donation, error)
+	GetDonationsByUserID(userID string, scope tenant.Scope) ([]*models.Donation, error)
+	GetAllDonations(scope tenant.Scope) ([]*models.Donation, error)
+	GetPetSponsorship(petID string) (*models.SponsorshipSummary, error)
+	NotifyPetAdopted(petID, petName string) error
+	UpdateDonation(id string, status models.Status, actorID string, scope tenant.Scope) (*models.Donation, error)
+	DeleteDonation(id string, scope tenant.Scope) error
+}
+
+type RefundService interface {
+	RequestRefund(donationID string, amount float64, reason, requestedBy string) (*models.Refund, error)
+	ApproveRefund(id, adminID string, scope tenant.Scope) (*models.Refund, error)
+	RejectRefund(id, adminID string, scope tenant.Scope) (*models.Refund, error)
+	GetRefundByID(id string, scope tenant.Scope) (*models.Refund, error)
+	GetRefundsByDonationID(donationID string) ([]*models.Refund, error)
+	GetRefundsByStatus(status models.RefundStatus, scope tenant.Scope) ([]*models.Refund, error)
+	GetLedger(donationID string) (*models.Ledger, error)
+}
+
+type DonorService interface {
+	GetDonorProfile(userID string) (*models.DonorProfile, error)
+	UpdateDonorProfile(userID, displayName string, hideAmounts bool) (*models.DonorProfile, error)
+	GetPublicDonations(limit, offset int, scope tenant.Scope) ([]*models.PublicDonation, error)
+	GetLeaderboard(period models.Period, limit int, scope tenant.Scope) (*models.Leaderboard, error)
+}
+
+type ReportService interface {
+	GetTotals(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.PeriodTotal, error)
+	GetCampaignTotals(reportRange *models.ReportRange, scope tenant.Scope) ([]*models.CampaignTotal, error)
+	GetDonorActivity(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.DonorActivity, error)
+	GetSummary(reportRange *models.ReportRange, scope tenant.Scope) (*models.ReportSummary, error)
+}
+
+type InKindService interface {
+	RecordInKindDonation(userID, shelterID, dropOffDate, comment, receivedBy string, items []*models.InKindItem) (*models.InKindDonation, error)
+	GetInKindDonationByID(id string, scope tenant.Scope) (*models.InKindDonation, error)
+	GetInKindDonationsByUserID(userID string) ([]*models.InKindDonation, error)
+	GetAllInKindDonations(scope tenant.Scope) ([]*models.InKindDonation, error)
+	GetAcknowledgement(id string, scope tenant.Scope) (*models.Acknowledgement, error)
+	SendAcknowledgement(id string, scope tenant.Scope) (*models.Acknowledgement, error)
+	GetSupplies(scope tenant.Scope) ([]*models.SupplyItem, error)
+	UseSupply(id string, quantity float64, scope tenant.Scope) (*models.SupplyItem, error)
 }
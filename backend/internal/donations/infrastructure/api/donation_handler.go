@@ -2,8 +2,13 @@ package api
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
 	"github.com/solrac97gr/petparadise/internal/donations/domain/ports"
+	userModels "github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
 )
 
 type donationHandler struct {
@@ -20,10 +25,14 @@ func NewDonationHandler(service ports.DonationService) DonationHandler {
 // CreateDonation handles the creation of a new donation
 func (h *donationHandler) CreateDonation(c *fiber.Ctx) error {
 	type createDonationRequest struct {
-		UserID    string  `json:"user_id"`
-		Amount    float64 `json:"amount"`
-		Comment   string  `json:"comment"`
-		Anonymous bool    `json:"anonymous"`
+		UserID      string  `json:"user_id"`
+		ShelterID   string  `json:"shelter_id"` // Sponsorships go to the pet's shelter
+		Amount      float64 `json:"amount"`
+		Comment     string  `json:"comment"`
+		Anonymous   bool    `json:"anonymous"`
+		PetID       string  `json:"pet_id"`
+		Sponsorship string  `json:"sponsorship"`
+		Campaign    string  `json:"campaign"`
 	}
 
 	var req createDonationRequest
@@ -45,22 +54,66 @@ func (h *donationHandler) CreateDonation(c *fiber.Ctx) error {
 		})
 	}
 
-	donation, err := h.service.CreateDonation(req.UserID, req.Amount, req.Comment, req.Anonymous)
+	if req.Sponsorship != "" && req.PetID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Pet ID is required for a sponsorship",
+		})
+	}
+
+	var donation *models.Donation
+	var err error
+	if req.PetID != "" {
+		if req.Sponsorship == "" {
+			req.Sponsorship = models.SponsorshipOneOff.String() // Default sponsorship
+		}
+
+		sponsorship := models.SponsorshipType(req.Sponsorship)
+		if !sponsorship.IsValid() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid sponsorship type",
+			})
+		}
+
+		donation, err = h.service.CreateSponsorship(req.UserID, req.PetID, req.Amount, sponsorship, req.Comment, req.Campaign, req.Anonymous)
+	} else {
+		if req.ShelterID == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Shelter ID is required",
+			})
+		}
+
+		if err := uuid.Validate(req.ShelterID); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid shelter ID",
+			})
+		}
+
+		donation, err = h.service.CreateDonation(req.UserID, req.ShelterID, req.Amount, req.Comment, req.Campaign, req.Anonymous)
+	}
 	if err != nil {
-		if err == models.ErrInvalidAmount {
+		if err == models.ErrInvalidAmount || err == models.ErrInvalidSponsorship || err == models.ErrInvalidCampaign ||
+			err == models.ErrShelterRequired || err == models.ErrShelterNotFound {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": err.Error(),
 			})
 		}
+		if err == models.ErrPetNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	audit.Record(c, "donation.create", "donation", donation.ID, nil, donation)
+
 	return c.Status(fiber.StatusCreated).JSON(donation)
 }
 
-// GetDonationByID handles getting a single donation by ID
+// GetDonationByID handles getting a single donation by ID. Donors see their own donations, and staff
+// with the donations:read permission those of their own shelter.
 func (h *donationHandler) GetDonationByID(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
@@ -69,14 +122,15 @@ func (h *donationHandler) GetDonationByID(c *fiber.Ctx) error {
 		})
 	}
 
-	donation, err := h.service.GetDonationByID(id)
+	donation, err := h.service.GetDonationByID(id, tenant.AllShelters())
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	if donation == nil {
+	// Donations the requester can't reach are hidden
+	if donation == nil || !auth.CanReachRecord(c, donation.UserID, donation.ShelterID, userModels.PermissionDonationsRead) {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Donation not found",
 		})
@@ -85,7 +139,8 @@ func (h *donationHandler) GetDonationByID(c *fiber.Ctx) error {
 	return c.JSON(donation)
 }
 
-// GetDonationsByUserID handles getting all donations for a user
+// GetDonationsByUserID handles getting all donations for a user: all of the requester's own, or for
+// staff with the donations:read permission those to their own shelter
 func (h *donationHandler) GetDonationsByUserID(c *fiber.Ctx) error {
 	userID := c.Params("userId")
 	if userID == "" {
@@ -94,7 +149,15 @@ func (h *donationHandler) GetDonationsByUserID(c *fiber.Ctx) error {
 		})
 	}
 
-	donations, err := h.service.GetDonationsByUserID(userID)
+	if requestingUserID, _ := c.Locals("userID").(string); requestingUserID != userID {
+		if allowed, err := auth.Allowed(c, userModels.PermissionDonationsRead); err != nil || !allowed {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Insufficient permissions to view these donations",
+			})
+		}
+	}
+
+	donations, err := h.service.GetDonationsByUserID(userID, auth.OwnerShelterScope(c, userID))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -106,7 +169,7 @@ func (h *donationHandler) GetDonationsByUserID(c *fiber.Ctx) error {
 
 // GetAllDonations handles getting all donations
 func (h *donationHandler) GetAllDonations(c *fiber.Ctx) error {
-	donations, err := h.service.GetAllDonations()
+	donations, err := h.service.GetAllDonations(auth.ShelterScope(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -149,13 +212,29 @@ func (h *donationHandler) UpdateDonationStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	donation, err := h.service.UpdateDonation(id, status)
+	actorID, _ := c.Locals("userID").(string)
+	scope := auth.ShelterScope(c)
+
+	// Staff only change the donations to their own shelter. Kept for the audit log.
+	before, err := h.service.GetDonationByID(id, scope)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	donation, err := h.service.UpdateDonation(id, status, actorID, scope)
 	if err != nil {
 		if err == models.ErrInvalidStatus {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": err.Error(),
 			})
 		}
+		if err == models.ErrUseRefundWorkflow {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -167,6 +246,8 @@ func (h *donationHandler) UpdateDonationStatus(c *fiber.Ctx) error {
 		})
 	}
 
+	audit.Record(c, "donation.status_update", "donation", donation.ID, before, donation)
+
 	return c.JSON(donation)
 }
 
@@ -179,12 +260,31 @@ func (h *donationHandler) DeleteDonation(c *fiber.Ctx) error {
 		})
 	}
 
-	err := h.service.DeleteDonation(id)
+	scope := auth.ShelterScope(c)
+
+	// Kept for the audit log
+	before, err := h.service.GetDonationByID(id, scope)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = h.service.DeleteDonation(id, scope)
 	if err != nil {
+		if err == models.ErrDonationHasLedger {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	if before != nil {
+		audit.Record(c, "donation.delete", "donation", id, before, nil)
+	}
+
 	return c.Status(fiber.StatusNoContent).Send(nil)
 }
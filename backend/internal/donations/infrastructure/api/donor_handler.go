@@ -0,0 +1,131 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+)
+
+// Page size limits for the public donation endpoints
+const (
+	defaultPublicLimit = 20
+	maxPublicLimit     = 100
+)
+
+type donorHandler struct {
+	service ports.DonorService
+}
+
+// NewDonorHandler creates a new donor handler
+func NewDonorHandler(service ports.DonorService) DonorHandler {
+	return &donorHandler{
+		service: service,
+	}
+}
+
+// GetPublicDonations handles getting the public donor wall
+func (h *donorHandler) GetPublicDonations(c *fiber.Ctx) error {
+	limit := publicLimit(c)
+
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	donations, err := h.service.GetPublicDonations(limit, offset, auth.PublicShelterScope(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(donations)
+}
+
+// GetLeaderboard handles getting the top donors of a period
+func (h *donorHandler) GetLeaderboard(c *fiber.Ctx) error {
+	period := models.Period(c.Query("period", models.PeriodMonth.String()))
+	if !period.IsValid() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid period",
+		})
+	}
+
+	leaderboard, err := h.service.GetLeaderboard(period, publicLimit(c), auth.PublicShelterScope(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(leaderboard)
+}
+
+// GetDonorProfile handles getting the authenticated user's donor profile
+func (h *donorHandler) GetDonorProfile(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+
+	profile, err := h.service.GetDonorProfile(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(profile)
+}
+
+// UpdateDonorProfile handles updating the authenticated user's donor profile
+func (h *donorHandler) UpdateDonorProfile(c *fiber.Ctx) error {
+	type updateDonorProfileRequest struct {
+		DisplayName string `json:"display_name"`
+		HideAmounts bool   `json:"hide_amounts"`
+	}
+
+	var req updateDonorProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	userID, _ := c.Locals("userID").(string)
+
+	// Kept for the audit log
+	before, err := h.service.GetDonorProfile(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	profile, err := h.service.UpdateDonorProfile(userID, req.DisplayName, req.HideAmounts)
+	if err != nil {
+		if err == models.ErrInvalidDisplayName {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	audit.Record(c, "donor_profile.update", "donor_profile", userID, before, profile)
+
+	return c.JSON(profile)
+}
+
+// publicLimit reads the page size of a public listing from the query string
+func publicLimit(c *fiber.Ctx) int {
+	limit := c.QueryInt("limit", defaultPublicLimit)
+	if limit <= 0 {
+		return defaultPublicLimit
+	}
+	if limit > maxPublicLimit {
+		return maxPublicLimit
+	}
+	return limit
+}
@@ -13,3 +13,41 @@ type DonationHandler interface {
 	UpdateDonationStatus(c *fiber.Ctx) error
 	DeleteDonation(c *fiber.Ctx) error
 }
+
+// RefundHandler interface defines methods for refund HTTP handlers
+type RefundHandler interface {
+	RequestRefund(c *fiber.Ctx) error
+	GetRefundsByDonationID(c *fiber.Ctx) error
+	GetRefundsByStatus(c *fiber.Ctx) error
+	ApproveRefund(c *fiber.Ctx) error
+	RejectRefund(c *fiber.Ctx) error
+	GetLedger(c *fiber.Ctx) error
+}
+
+// DonorHandler interface defines methods for public donor HTTP handlers
+type DonorHandler interface {
+	GetPublicDonations(c *fiber.Ctx) error
+	GetLeaderboard(c *fiber.Ctx) error
+	GetDonorProfile(c *fiber.Ctx) error
+	UpdateDonorProfile(c *fiber.Ctx) error
+}
+
+// ReportHandler interface defines methods for donation report HTTP handlers
+type ReportHandler interface {
+	GetSummary(c *fiber.Ctx) error
+	GetTotals(c *fiber.Ctx) error
+	GetCampaignTotals(c *fiber.Ctx) error
+	GetDonorActivity(c *fiber.Ctx) error
+}
+
+// InKindHandler interface defines methods for in-kind donation and supply inventory HTTP handlers
+type InKindHandler interface {
+	RecordInKindDonation(c *fiber.Ctx) error
+	GetAllInKindDonations(c *fiber.Ctx) error
+	GetMyInKindDonations(c *fiber.Ctx) error
+	GetInKindDonationByID(c *fiber.Ctx) error
+	GetAcknowledgement(c *fiber.Ctx) error
+	SendAcknowledgement(c *fiber.Ctx) error
+	GetSupplies(c *fiber.Ctx) error
+	UseSupply(c *fiber.Ctx) error
+}
@@ -0,0 +1,312 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/ports"
+	userModels "github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+type inKindHandler struct {
+	service ports.InKindService
+}
+
+// NewInKindHandler creates a new in-kind donation handler
+func NewInKindHandler(service ports.InKindService) InKindHandler {
+	return &inKindHandler{
+		service: service,
+	}
+}
+
+// RecordInKindDonation handles recording goods dropped off by a donor
+func (h *inKindHandler) RecordInKindDonation(c *fiber.Ctx) error {
+	type inKindItemRequest struct {
+		Category       string  `json:"category"`
+		Name           string  `json:"name"`
+		Quantity       float64 `json:"quantity"`
+		Unit           string  `json:"unit"`
+		EstimatedValue float64 `json:"estimated_value"`
+		Condition      string  `json:"condition"`
+	}
+
+	type recordInKindDonationRequest struct {
+		UserID      string              `json:"user_id"`
+		ShelterID   string              `json:"shelter_id"`
+		DropOffDate string              `json:"drop_off_date"`
+		Comment     string              `json:"comment"`
+		Items       []inKindItemRequest `json:"items"`
+	}
+
+	var req recordInKindDonationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.UserID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "UserID is required",
+		})
+	}
+
+	if req.DropOffDate == "" {
+		req.DropOffDate = time.Now().Format(models.ReportDateLayout) // Default to today
+	}
+
+	items := make([]*models.InKindItem, 0, len(req.Items))
+	for _, itemReq := range req.Items {
+		if itemReq.Unit == "" {
+			itemReq.Unit = models.UnitPiece.String() // Default unit
+		}
+		if itemReq.Condition == "" {
+			itemReq.Condition = models.ConditionNew.String() // Default condition
+		}
+
+		item, err := models.NewInKindItem(
+			"",
+			models.ItemCategory(itemReq.Category),
+			itemReq.Name,
+			itemReq.Quantity,
+			models.ItemUnit(itemReq.Unit),
+			itemReq.EstimatedValue,
+			models.ItemCondition(itemReq.Condition),
+		)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		items = append(items, item)
+	}
+
+	// Staff record drop-offs at their own shelter, platform admins at the one they pick
+	shelterID := auth.RecordShelter(c, req.ShelterID)
+	if shelterID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Shelter ID is required",
+		})
+	}
+
+	receivedBy, _ := c.Locals("userID").(string)
+
+	donation, err := h.service.RecordInKindDonation(req.UserID, shelterID, req.DropOffDate, req.Comment, receivedBy, items)
+	if err != nil {
+		return inKindErrorResponse(c, err)
+	}
+
+	audit.Record(c, "in_kind_donation.create", "in_kind_donation", donation.ID, nil, donation)
+
+	return c.Status(fiber.StatusCreated).JSON(donation)
+}
+
+// GetAllInKindDonations handles getting all in-kind donations
+func (h *inKindHandler) GetAllInKindDonations(c *fiber.Ctx) error {
+	donations, err := h.service.GetAllInKindDonations(auth.ShelterScope(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(donations)
+}
+
+// GetMyInKindDonations handles getting the authenticated user's in-kind donations
+func (h *inKindHandler) GetMyInKindDonations(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+
+	donations, err := h.service.GetInKindDonationsByUserID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(donations)
+}
+
+// GetInKindDonationByID handles getting a single in-kind donation by ID
+func (h *inKindHandler) GetInKindDonationByID(c *fiber.Ctx) error {
+	id := c.Params("inKindId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	donation, err := h.service.GetInKindDonationByID(id, tenant.AllShelters())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if donation == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "In-kind donation not found",
+		})
+	}
+
+	if !canAccessInKindDonation(c, donation) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Insufficient permissions to access this donation",
+		})
+	}
+
+	return c.JSON(donation)
+}
+
+// GetAcknowledgement handles getting the acknowledgement of an in-kind donation
+func (h *inKindHandler) GetAcknowledgement(c *fiber.Ctx) error {
+	id := c.Params("inKindId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	donation, err := h.service.GetInKindDonationByID(id, tenant.AllShelters())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if donation == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "In-kind donation not found",
+		})
+	}
+
+	if !canAccessInKindDonation(c, donation) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Insufficient permissions to access this donation",
+		})
+	}
+
+	acknowledgement, err := h.service.GetAcknowledgement(id, tenant.AllShelters())
+	if err != nil {
+		return inKindErrorResponse(c, err)
+	}
+
+	return c.JSON(acknowledgement)
+}
+
+// SendAcknowledgement handles emailing the acknowledgement of an in-kind donation to its donor again
+func (h *inKindHandler) SendAcknowledgement(c *fiber.Ctx) error {
+	id := c.Params("inKindId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	acknowledgement, err := h.service.SendAcknowledgement(id, auth.ShelterScope(c))
+	if err != nil {
+		return inKindErrorResponse(c, err)
+	}
+
+	audit.Record(c, "in_kind_donation.acknowledge", "in_kind_donation", id, nil, acknowledgement)
+
+	return c.JSON(acknowledgement)
+}
+
+// GetSupplies handles getting the supply inventory
+func (h *inKindHandler) GetSupplies(c *fiber.Ctx) error {
+	supplies, err := h.service.GetSupplies(auth.ShelterScope(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(supplies)
+}
+
+// UseSupply handles taking a quantity of a supply out of the inventory
+func (h *inKindHandler) UseSupply(c *fiber.Ctx) error {
+	id := c.Params("supplyId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Supply ID is required",
+		})
+	}
+
+	type useSupplyRequest struct {
+		Quantity float64 `json:"quantity"`
+	}
+
+	var req useSupplyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	scope := auth.ShelterScope(c)
+
+	// Kept for the audit log
+	supplies, err := h.service.GetSupplies(scope)
+	if err != nil {
+		return inKindErrorResponse(c, err)
+	}
+
+	var before *models.SupplyItem
+	for _, supply := range supplies {
+		if supply.ID == id {
+			before = supply
+		}
+	}
+
+	supply, err := h.service.UseSupply(id, req.Quantity, scope)
+	if err != nil {
+		return inKindErrorResponse(c, err)
+	}
+
+	audit.Record(c, "supply.use", "supply", supply.ID, before, supply)
+
+	return c.JSON(supply)
+}
+
+// canAccessInKindDonation checks that the requesting user is the donor or may manage the supplies
+// of the donation's shelter. A failed permission lookup denies access.
+func canAccessInKindDonation(c *fiber.Ctx, donation *models.InKindDonation) bool {
+	requestingUserID, _ := c.Locals("userID").(string)
+	if requestingUserID == donation.UserID {
+		return true
+	}
+
+	canManage, err := auth.Allowed(c, userModels.PermissionSuppliesManage)
+	return err == nil && canManage && auth.ShelterScope(c).Allows(donation.ShelterID)
+}
+
+// inKindErrorResponse maps in-kind donation and inventory errors to HTTP responses
+func inKindErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case models.ErrNoInKindItems, models.ErrInvalidDropOffDate, models.ErrInvalidQuantity, models.ErrShelterNotFound:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrInsufficientStock:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err.Error() == "donor not found" || err.Error() == "in-kind donation not found" || err.Error() == "supply not found" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
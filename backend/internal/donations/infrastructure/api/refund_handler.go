@@ -0,0 +1,272 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/ports"
+	userModels "github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+type refundHandler struct {
+	service   ports.RefundService
+	donations ports.DonationService
+}
+
+// NewRefundHandler creates a new refund handler
+func NewRefundHandler(service ports.RefundService, donations ports.DonationService) RefundHandler {
+	return &refundHandler{
+		service:   service,
+		donations: donations,
+	}
+}
+
+// RequestRefund handles requesting a partial or full refund of a donation
+func (h *refundHandler) RequestRefund(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	type requestRefundRequest struct {
+		Amount float64 `json:"amount"` // Leave empty to refund the whole remaining balance
+		Reason string  `json:"reason"`
+	}
+
+	var req requestRefundRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Reason is required",
+		})
+	}
+
+	if req.Amount < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Amount must be greater than 0",
+		})
+	}
+
+	if allowed, err := h.checkDonationAccess(c, id); !allowed {
+		return err
+	}
+
+	requestingUserID, _ := c.Locals("userID").(string)
+
+	refund, err := h.service.RequestRefund(id, req.Amount, req.Reason, requestingUserID)
+	if err != nil {
+		return refundErrorResponse(c, err)
+	}
+
+	audit.Record(c, "refund.request", "refund", refund.ID, nil, refund)
+
+	return c.Status(fiber.StatusCreated).JSON(refund)
+}
+
+// GetRefundsByDonationID handles getting all refunds of a donation
+func (h *refundHandler) GetRefundsByDonationID(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	if allowed, err := h.checkDonationAccess(c, id); !allowed {
+		return err
+	}
+
+	refunds, err := h.service.GetRefundsByDonationID(id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(refunds)
+}
+
+// GetRefundsByStatus handles getting all refunds with a specific status, pending requests by default
+func (h *refundHandler) GetRefundsByStatus(c *fiber.Ctx) error {
+	status := models.RefundStatus(c.Query("status", models.RefundStatusRequested.String()))
+	if !status.IsValid() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid status",
+		})
+	}
+
+	refunds, err := h.service.GetRefundsByStatus(status, auth.ShelterScope(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(refunds)
+}
+
+// ApproveRefund handles approving a refund request
+func (h *refundHandler) ApproveRefund(c *fiber.Ctx) error {
+	id := c.Params("refundId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Refund ID is required",
+		})
+	}
+
+	adminID, _ := c.Locals("userID").(string)
+	scope := auth.ShelterScope(c)
+
+	// Kept for the audit log
+	before, err := h.service.GetRefundByID(id, scope)
+	if err != nil {
+		return refundErrorResponse(c, err)
+	}
+
+	refund, err := h.service.ApproveRefund(id, adminID, scope)
+	if err != nil {
+		return refundErrorResponse(c, err)
+	}
+
+	audit.Record(c, "refund.approve", "refund", refund.ID, before, refund)
+
+	return c.JSON(refund)
+}
+
+// RejectRefund handles rejecting a refund request
+func (h *refundHandler) RejectRefund(c *fiber.Ctx) error {
+	id := c.Params("refundId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Refund ID is required",
+		})
+	}
+
+	adminID, _ := c.Locals("userID").(string)
+	scope := auth.ShelterScope(c)
+
+	// Kept for the audit log
+	before, err := h.service.GetRefundByID(id, scope)
+	if err != nil {
+		return refundErrorResponse(c, err)
+	}
+
+	refund, err := h.service.RejectRefund(id, adminID, scope)
+	if err != nil {
+		return refundErrorResponse(c, err)
+	}
+
+	audit.Record(c, "refund.reject", "refund", refund.ID, before, refund)
+
+	return c.JSON(refund)
+}
+
+// GetLedger handles getting the ledger of money movements of a donation
+func (h *refundHandler) GetLedger(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	donation, err := h.donations.GetDonationByID(id, auth.ShelterScope(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if donation == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Donation not found",
+		})
+	}
+
+	ledger, err := h.service.GetLedger(id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(ledger)
+}
+
+// checkDonationAccess checks that the donation exists and that the requesting user is its donor or may read
+// the donations to its shelter. When access is denied the error response has already been written and
+// allowed is false.
+func (h *refundHandler) checkDonationAccess(c *fiber.Ctx, donationID string) (allowed bool, err error) {
+	donation, err := h.donations.GetDonationByID(donationID, tenant.AllShelters())
+	if err != nil {
+		return false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if donation == nil {
+		return false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Donation not found",
+		})
+	}
+
+	requestingUserID, _ := c.Locals("userID").(string)
+	if requestingUserID == donation.UserID {
+		return true, nil
+	}
+
+	canRead, err := auth.Allowed(c, userModels.PermissionDonationsRead)
+	if err != nil {
+		return false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check permissions",
+		})
+	}
+
+	if !canRead {
+		return false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Insufficient permissions to access this donation",
+		})
+	}
+
+	// Donations to other shelters are hidden from staff
+	if !auth.ShelterScope(c).Allows(donation.ShelterID) {
+		return false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Donation not found",
+		})
+	}
+
+	return true, nil
+}
+
+// refundErrorResponse maps refund workflow errors to HTTP responses
+func refundErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case models.ErrInvalidRefundAmount, models.ErrRefundReasonRequired:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrRefundExceedsBalance, models.ErrDonationNotRefundable, models.ErrRefundNotPending:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err.Error() == "donation not found" || err.Error() == "refund not found" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
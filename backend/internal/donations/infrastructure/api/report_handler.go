@@ -0,0 +1,177 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/daterange"
+)
+
+// CSV headers of the report exports
+var (
+	periodTotalHeader   = []string{"period_start", "total", "count", "donors", "average_gift"}
+	campaignTotalHeader = []string{"campaign", "total", "count", "donors", "average_gift"}
+	donorActivityHeader = []string{"period_start", "new_donors", "returning_donors"}
+	reportSummaryHeader = []string{
+		"from", "to", "total", "count", "donors", "average_gift", "new_donors",
+		"returning_donors", "previous_donors", "retained_donors", "retention_rate",
+	}
+)
+
+// csvRecorder is implemented by the report rows that can be exported as CSV
+type csvRecorder interface {
+	CSVRecord() []string
+}
+
+type reportHandler struct {
+	service ports.ReportService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(service ports.ReportService) ReportHandler {
+	return &reportHandler{
+		service: service,
+	}
+}
+
+// GetSummary handles getting the headline donation figures of a date range
+func (h *reportHandler) GetSummary(c *fiber.Ctx) error {
+	reportRange, err := parseReportRange(c)
+	if err != nil {
+		return reportErrorResponse(c, err)
+	}
+
+	summary, err := h.service.GetSummary(reportRange, auth.ShelterScope(c))
+	if err != nil {
+		return reportErrorResponse(c, err)
+	}
+
+	if isCSVRequested(c) {
+		return sendCSV(c, "donation-summary", reportSummaryHeader, []*models.ReportSummary{summary})
+	}
+
+	return c.JSON(summary)
+}
+
+// GetTotals handles getting the donation totals of a date range by day, week or month
+func (h *reportHandler) GetTotals(c *fiber.Ctx) error {
+	reportRange, err := parseReportRange(c)
+	if err != nil {
+		return reportErrorResponse(c, err)
+	}
+
+	interval := models.Interval(c.Query("interval", models.IntervalMonth.String()))
+
+	totals, err := h.service.GetTotals(reportRange, interval, auth.ShelterScope(c))
+	if err != nil {
+		return reportErrorResponse(c, err)
+	}
+
+	if isCSVRequested(c) {
+		return sendCSV(c, "donation-totals", periodTotalHeader, totals)
+	}
+
+	return c.JSON(totals)
+}
+
+// GetCampaignTotals handles getting the donation totals of a date range by campaign
+func (h *reportHandler) GetCampaignTotals(c *fiber.Ctx) error {
+	reportRange, err := parseReportRange(c)
+	if err != nil {
+		return reportErrorResponse(c, err)
+	}
+
+	totals, err := h.service.GetCampaignTotals(reportRange, auth.ShelterScope(c))
+	if err != nil {
+		return reportErrorResponse(c, err)
+	}
+
+	if isCSVRequested(c) {
+		return sendCSV(c, "donation-campaigns", campaignTotalHeader, totals)
+	}
+
+	return c.JSON(totals)
+}
+
+// GetDonorActivity handles getting the new and returning donors of a date range by day, week or month
+func (h *reportHandler) GetDonorActivity(c *fiber.Ctx) error {
+	reportRange, err := parseReportRange(c)
+	if err != nil {
+		return reportErrorResponse(c, err)
+	}
+
+	interval := models.Interval(c.Query("interval", models.IntervalMonth.String()))
+
+	activity, err := h.service.GetDonorActivity(reportRange, interval, auth.ShelterScope(c))
+	if err != nil {
+		return reportErrorResponse(c, err)
+	}
+
+	if isCSVRequested(c) {
+		return sendCSV(c, "donor-activity", donorActivityHeader, activity)
+	}
+
+	return c.JSON(activity)
+}
+
+// parseReportRange reads the inclusive from and to dates (YYYY-MM-DD) of a report.
+// The range defaults to the year ending today.
+func parseReportRange(c *fiber.Ctx) (*models.ReportRange, error) {
+	today := daterange.Today()
+	from, to, err := daterange.Parse(c.Query("from"), c.Query("to"), today.AddDate(-1, 0, 1), today)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", models.ErrInvalidReportRange, err)
+	}
+
+	return models.NewReportRange(from, to)
+}
+
+// isCSVRequested checks if the client asked for a CSV export
+func isCSVRequested(c *fiber.Ctx) bool {
+	return c.Query("format") == "csv"
+}
+
+// sendCSV writes the report rows as a CSV attachment
+func sendCSV[T csvRecorder](c *fiber.Ctx, name string, header []string, rows []T) error {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if err := writer.Write(row.CSVRecord()); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+
+	return c.Send(buf.Bytes())
+}
+
+// reportErrorResponse maps report errors to HTTP responses
+func reportErrorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, models.ErrInvalidReportRange) || errors.Is(err, models.ErrInvalidInterval) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -4,33 +4,99 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/jmoiron/sqlx"
 	"github.com/solrac97gr/petparadise/internal/donations/aplication"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/ports"
+	"github.com/solrac97gr/petparadise/internal/donations/infrastructure/pets"
 	"github.com/solrac97gr/petparadise/internal/donations/infrastructure/repository"
+	petRepository "github.com/solrac97gr/petparadise/internal/pets/infrastructure/repository"
 	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/audit"
 	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
 )
 
-// SetupDonationRoutes sets up all donation routes
-func SetupDonationRoutes(router fiber.Router, db *sqlx.DB) {
-	// Initialize repository
+// SetupDonationRoutes sets up all donation routes and returns the donation service, which other
+// modules reach donations through
+func SetupDonationRoutes(router fiber.Router, db *sqlx.DB, mail mailer.Mailer) ports.DonationService {
+	// Personal details are only recorded as changed in the audit log
+	audit.SetPersonalFields("donation", "comment")
+	audit.SetPersonalFields("in_kind_donation", "comment")
+	audit.SetPersonalFields("donor_profile", "display_name")
+
+	// Initialize repositories
 	donationRepo := repository.NewPostgresRepository(db)
+	ledgerRepo := repository.NewPostgresLedgerRepository(db)
+	refundRepo := repository.NewPostgresRefundRepository(db)
+	donorRepo := repository.NewPostgresDonorRepository(db)
+	reportRepo := repository.NewPostgresReportRepository(db)
+	inKindRepo := repository.NewPostgresInKindRepository(db)
+	supplyRepo := repository.NewPostgresSupplyRepository(db)
+	petDirectory := pets.NewPetsDirectory(petRepository.NewPostgresRepository(db))
 
-	// Initialize service
-	donationService := aplication.NewDonationService(donationRepo)
+	// Initialize services
+	donationService := aplication.NewDonationService(donationRepo, petDirectory, mail)
+	refundService := aplication.NewRefundService(refundRepo, ledgerRepo, donationRepo)
+	donorService := aplication.NewDonorService(donorRepo)
+	reportService := aplication.NewReportService(reportRepo)
+	inKindService := aplication.NewInKindService(inKindRepo, supplyRepo, mail)
 
-	// Initialize handler
+	// Initialize handlers
 	donationHandler := NewDonationHandler(donationService)
+	refundHandler := NewRefundHandler(refundService, donationService)
+	donorHandler := NewDonorHandler(donorService)
+	reportHandler := NewReportHandler(reportService)
+	inKindHandler := NewInKindHandler(inKindService)
+
+	// Public routes - the donor wall and leaderboard respect donor anonymity, add shelter_id to
+	// narrow them down to one shelter
+	router.Get("/public", donorHandler.GetPublicDonations)
+	router.Get("/public/leaderboard", donorHandler.GetLeaderboard)
 
-	// All donation routes require authentication
+	// All other donation routes require authentication
 	protected := router.Use(auth.Protected())
 
+	// Donor profile routes - how the authenticated user appears publicly
+	protected.Get("/donor-profile", donorHandler.GetDonorProfile)
+	protected.Put("/donor-profile", donorHandler.UpdateDonorProfile)
+
+	// Refund review routes - registered before the parameterized routes
+	canRefund := auth.RequirePermission(models.PermissionDonationsRefund)
+	protected.Get("/refunds", canRefund, refundHandler.GetRefundsByStatus)
+	protected.Post("/refunds/:refundId/approve", canRefund, auth.MFARequired(), refundHandler.ApproveRefund)
+	protected.Post("/refunds/:refundId/reject", canRefund, refundHandler.RejectRefund)
+
+	// Report routes - add format=csv to export
+	canReport := auth.RequirePermission(models.PermissionDonationsReports)
+	protected.Get("/reports/summary", canReport, reportHandler.GetSummary)
+	protected.Get("/reports/totals", canReport, reportHandler.GetTotals)
+	protected.Get("/reports/campaigns", canReport, reportHandler.GetCampaignTotals)
+	protected.Get("/reports/donors", canReport, reportHandler.GetDonorActivity)
+
+	// In-kind donation routes - staff record drop-offs and manage the supply inventory,
+	// donors can see their own donations and acknowledgements
+	canManageSupplies := auth.RequirePermission(models.PermissionSuppliesManage)
+	protected.Post("/in-kind", canManageSupplies, inKindHandler.RecordInKindDonation)
+	protected.Get("/in-kind", canManageSupplies, inKindHandler.GetAllInKindDonations)
+	protected.Get("/in-kind/mine", inKindHandler.GetMyInKindDonations)
+	protected.Get("/in-kind/:inKindId", inKindHandler.GetInKindDonationByID)
+	protected.Get("/in-kind/:inKindId/acknowledgement", inKindHandler.GetAcknowledgement)
+	protected.Post("/in-kind/:inKindId/acknowledgement", canManageSupplies, inKindHandler.SendAcknowledgement)
+	protected.Get("/supplies", canManageSupplies, inKindHandler.GetSupplies)
+	protected.Post("/supplies/:supplyId/use", canManageSupplies, inKindHandler.UseSupply)
+
 	// User routes - authenticated users can make donations and see their own
 	protected.Post("/", donationHandler.CreateDonation)
 	protected.Get("/user/:userId", donationHandler.GetDonationsByUserID)
 	protected.Get("/:id", donationHandler.GetDonationByID)
 
-	// Admin routes - only administrators can see all donations and modify them
-	adminRoutes := protected.Use(auth.RoleRequired(models.RoleAdmin))
-	adminRoutes.Get("/", donationHandler.GetAllDonations)
-	adminRoutes.Patch("/:id/status", donationHandler.UpdateDonationStatus)
-	adminRoutes.Delete("/:id", donationHandler.DeleteDonation)
+	// Refund routes - donors can request refunds of their own donations
+	protected.Post("/:id/refunds", refundHandler.RequestRefund)
+	protected.Get("/:id/refunds", refundHandler.GetRefundsByDonationID)
+
+	// Admin routes - seeing all donations and modifying them need their own permissions
+	protected.Get("/", auth.RequirePermission(models.PermissionDonationsRead), donationHandler.GetAllDonations)
+	protected.Patch("/:id/status", auth.RequirePermission(models.PermissionDonationsWrite), donationHandler.UpdateDonationStatus)
+	protected.Delete("/:id", auth.RequirePermission(models.PermissionDonationsDelete), auth.MFARequired(), donationHandler.DeleteDonation)
+	protected.Get("/:id/ledger", auth.RequirePermission(models.PermissionDonationsRead), refundHandler.GetLedger)
+
+	return donationService
 }
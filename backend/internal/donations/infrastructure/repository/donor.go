@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// publicStatuses are the donation statuses shown publicly; refunded money is excluded through the ledger
+var publicStatuses = []string{models.StatusCompleted.String(), models.StatusPartiallyRefunded.String()}
+
+// publicDonorName masks anonymous donations and prefers the donor's display name over their account name
+const publicDonorName = `CASE WHEN d.anonymous THEN NULL ELSE COALESCE(NULLIF(p.display_name, ''), u.name) END`
+
+// PostgresDonorRepository implements the DonorRepository interface
+type PostgresDonorRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresDonorRepository creates a new PostgresDonorRepository
+func NewPostgresDonorRepository(db *sqlx.DB) *PostgresDonorRepository {
+	return &PostgresDonorRepository{
+		db: db,
+	}
+}
+
+// SaveProfile creates or replaces a donor's profile
+func (r *PostgresDonorRepository) SaveProfile(profile *models.DonorProfile) error {
+	query := `INSERT INTO donor_profiles (user_id, display_name, hide_amounts, updated)
+              VALUES ($1, $2, $3, $4)
+              ON CONFLICT (user_id) DO UPDATE SET display_name = $2, hide_amounts = $3, updated = $4`
+
+	_, err := r.db.Exec(
+		query,
+		profile.UserID,
+		profile.DisplayName,
+		profile.HideAmounts,
+		profile.Updated,
+	)
+
+	return err
+}
+
+// FindProfileByUserID finds a donor's profile by their user ID
+func (r *PostgresDonorRepository) FindProfileByUserID(userID string) (*models.DonorProfile, error) {
+	var profile models.DonorProfile
+
+	query := `SELECT user_id, display_name, hide_amounts, updated FROM donor_profiles WHERE user_id = $1`
+
+	err := r.db.QueryRow(query, userID).Scan(
+		&profile.UserID,
+		&profile.DisplayName,
+		&profile.HideAmounts,
+		&profile.Updated,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// FindPublicDonations finds the most recent completed donations to the shelters in scope, masking
+// anonymous donors and hiding the amounts of donors who opted out
+func (r *PostgresDonorRepository) FindPublicDonations(limit, offset int, scope tenant.Scope) ([]*models.PublicDonation, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + publicDonorName + `, COALESCE(p.hide_amounts, FALSE), n.net_amount,
+                     COALESCE(d.comment, ''), COALESCE(d.pet_id, ''), d.created
+              FROM donations d
+              JOIN users u ON u.id = d.user_id
+              LEFT JOIN donor_profiles p ON p.user_id = d.user_id
+              CROSS JOIN LATERAL (
+                  SELECT COALESCE(SUM(l.amount), 0) AS net_amount FROM donation_ledger l WHERE l.donation_id = d.id
+              ) n
+              WHERE d.status = ANY($1) AND n.net_amount > 0 AND ` + tenant.Condition("d.shelter_id", 4) + `
+              ORDER BY d.created DESC
+              LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(query, pq.Array(publicStatuses), limit, offset, all, shelterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	donations := []*models.PublicDonation{}
+
+	for rows.Next() {
+		var donation models.PublicDonation
+		var donorName sql.NullString
+		var hideAmount bool
+		var amount float64
+
+		err := rows.Scan(
+			&donorName,
+			&hideAmount,
+			&amount,
+			&donation.Comment,
+			&donation.PetID,
+			&donation.Created,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		donation.DonorName = maskDonorName(donorName)
+		if !hideAmount {
+			donation.Amount = &amount
+		}
+
+		donations = append(donations, &donation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return donations, nil
+}
+
+// FindTopDonors finds the donors with the highest net completed donations to the shelters in scope
+// since a given time. A donor's anonymous donations are ranked separately from their public ones
+// so they cannot be linked.
+func (r *PostgresDonorRepository) FindTopDonors(since time.Time, limit int, scope tenant.Scope) ([]*models.LeaderboardEntry, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + publicDonorName + `, COALESCE(p.hide_amounts, FALSE),
+                     SUM(n.net_amount) AS total, COUNT(*)
+              FROM donations d
+              JOIN users u ON u.id = d.user_id
+              LEFT JOIN donor_profiles p ON p.user_id = d.user_id
+              CROSS JOIN LATERAL (
+                  SELECT COALESCE(SUM(l.amount), 0) AS net_amount FROM donation_ledger l WHERE l.donation_id = d.id
+              ) n
+              WHERE d.status = ANY($1) AND d.created >= $2 AND ` + tenant.Condition("d.shelter_id", 4) + `
+              GROUP BY d.user_id, d.anonymous, u.name, p.display_name, p.hide_amounts
+              HAVING SUM(n.net_amount) > 0
+              ORDER BY total DESC
+              LIMIT $3`
+
+	rows, err := r.db.Query(query, pq.Array(publicStatuses), since, limit, all, shelterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.LeaderboardEntry{}
+
+	for rows.Next() {
+		var entry models.LeaderboardEntry
+		var donorName sql.NullString
+		var hideAmount bool
+		var amount float64
+
+		err := rows.Scan(
+			&donorName,
+			&hideAmount,
+			&amount,
+			&entry.DonationCount,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Rank = len(entries) + 1
+		entry.DonorName = maskDonorName(donorName)
+		if !hideAmount {
+			entry.Amount = &amount
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// maskDonorName returns the public name of a donor, which is NULL for anonymous donations
+func maskDonorName(name sql.NullString) string {
+	if !name.Valid {
+		return models.AnonymousDonorName
+	}
+	return name.String
+}
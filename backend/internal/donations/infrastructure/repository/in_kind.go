@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/database"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// inKindColumns lists the columns selected for an in-kind donation, in scan order
+const inKindColumns = `d.id, d.shelter_id, d.user_id, to_char(d.drop_off_date, 'YYYY-MM-DD'), COALESCE(d.comment, ''),
+              COALESCE(d.received_by::text, ''), d.acknowledged_at, d.created, d.updated`
+
+// inKindItemColumns lists the columns selected for an in-kind item, in scan order
+const inKindItemColumns = `id, donation_id, category, name, quantity, unit, estimated_value, condition`
+
+// PostgresInKindRepository implements the InKindRepository interface
+type PostgresInKindRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresInKindRepository creates a new PostgresInKindRepository
+func NewPostgresInKindRepository(db *sqlx.DB) *PostgresInKindRepository {
+	return &PostgresInKindRepository{
+		db: db,
+	}
+}
+
+// Save saves an in-kind donation with its items and adds the items to the supply inventory of its
+// shelter in a single transaction, so the inventory always matches the recorded donations
+func (r *PostgresInKindRepository) Save(donation *models.InKindDonation) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`INSERT INTO in_kind_donations (id, shelter_id, user_id, drop_off_date, comment, received_by, created, updated)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		donation.ID,
+		donation.ShelterID,
+		donation.UserID,
+		donation.DropOffDate,
+		donation.Comment,
+		nullString(donation.ReceivedBy),
+		donation.Created,
+		donation.Updated,
+	)
+
+	if database.IsForeignKeyViolation(err, "in_kind_donations_shelter_id_fkey") {
+		return models.ErrShelterNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, item := range donation.Items {
+		_, err = tx.Exec(
+			`INSERT INTO in_kind_items (id, donation_id, category, name, quantity, unit, estimated_value, condition)
+             VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			item.ID,
+			item.DonationID,
+			item.Category.String(),
+			item.Name,
+			item.Quantity,
+			item.Unit.String(),
+			item.EstimatedValue,
+			item.Condition.String(),
+		)
+		if err != nil {
+			return err
+		}
+
+		// Items of the same category, name (ignoring case) and unit share a stock entry in each shelter
+		_, err = tx.Exec(
+			`INSERT INTO supply_inventory (id, shelter_id, category, name, unit, quantity, updated)
+             VALUES ($1, $2, $3, $4, $5, $6, $7)
+             ON CONFLICT (shelter_id, category, lower(name), unit)
+             DO UPDATE SET quantity = supply_inventory.quantity + EXCLUDED.quantity, updated = EXCLUDED.updated`,
+			uuid.New().String(),
+			donation.ShelterID,
+			item.Category.String(),
+			item.Name,
+			item.Unit.String(),
+			item.Quantity,
+			donation.Created,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindByID finds an in-kind donation to the shelters in scope with its items by its ID
+func (r *PostgresInKindRepository) FindByID(id string, scope tenant.Scope) (*models.InKindDonation, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + inKindColumns + ` FROM in_kind_donations d WHERE d.id = $1 AND ` + tenant.Condition("d.shelter_id", 2)
+
+	donations, err := r.findMany(query, id, all, shelterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(donations) == 0 {
+		return nil, nil
+	}
+
+	return donations[0], nil
+}
+
+// FindByUserID finds all in-kind donations of a donor, most recent first
+func (r *PostgresInKindRepository) FindByUserID(userID string) ([]*models.InKindDonation, error) {
+	query := `SELECT ` + inKindColumns + ` FROM in_kind_donations d WHERE d.user_id = $1
+              ORDER BY d.drop_off_date DESC, d.created DESC`
+	return r.findMany(query, userID)
+}
+
+// FindAll finds all in-kind donations to the shelters in scope, most recent first
+func (r *PostgresInKindRepository) FindAll(scope tenant.Scope) ([]*models.InKindDonation, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + inKindColumns + ` FROM in_kind_donations d WHERE ` + tenant.Condition("d.shelter_id", 1) + `
+              ORDER BY d.drop_off_date DESC, d.created DESC`
+	return r.findMany(query, all, shelterID)
+}
+
+// MarkAcknowledged records when the donor was last thanked for an in-kind donation
+func (r *PostgresInKindRepository) MarkAcknowledged(id, acknowledgedAt string) error {
+	query := `UPDATE in_kind_donations SET acknowledged_at = $1, updated = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, acknowledgedAt, id)
+	return err
+}
+
+// FindDonorContact finds the name and email of a donor
+func (r *PostgresInKindRepository) FindDonorContact(userID string) (*models.DonorContact, error) {
+	var contact models.DonorContact
+
+	query := `SELECT id, name, email FROM users WHERE id = $1`
+
+	err := r.db.QueryRow(query, userID).Scan(
+		&contact.UserID,
+		&contact.Name,
+		&contact.Email,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &contact, nil
+}
+
+// findMany runs a query returning in-kind donation rows and loads their items
+func (r *PostgresInKindRepository) findMany(query string, args ...interface{}) ([]*models.InKindDonation, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var donations []*models.InKindDonation
+	byID := make(map[string]*models.InKindDonation)
+
+	for rows.Next() {
+		var donation models.InKindDonation
+		var acknowledgedAt sql.NullString
+
+		err := rows.Scan(
+			&donation.ID,
+			&donation.ShelterID,
+			&donation.UserID,
+			&donation.DropOffDate,
+			&donation.Comment,
+			&donation.ReceivedBy,
+			&acknowledgedAt,
+			&donation.Created,
+			&donation.Updated,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		donation.AcknowledgedAt = acknowledgedAt.String
+		donation.Items = []*models.InKindItem{}
+		donations = append(donations, &donation)
+		byID[donation.ID] = &donation
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(donations) == 0 {
+		return donations, nil
+	}
+
+	ids := make([]string, 0, len(donations))
+	for _, donation := range donations {
+		ids = append(ids, donation.ID)
+	}
+
+	itemRows, err := r.db.Query(`SELECT `+inKindItemColumns+` FROM in_kind_items WHERE donation_id = ANY($1) ORDER BY name`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var item models.InKindItem
+		var categoryStr, unitStr, conditionStr string
+
+		err := itemRows.Scan(
+			&item.ID,
+			&item.DonationID,
+			&categoryStr,
+			&item.Name,
+			&item.Quantity,
+			&unitStr,
+			&item.EstimatedValue,
+			&conditionStr,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		item.Category = models.ItemCategory(categoryStr)
+		item.Unit = models.ItemUnit(unitStr)
+		item.Condition = models.ItemCondition(conditionStr)
+
+		donation := byID[item.DonationID]
+		donation.Items = append(donation.Items, &item)
+		donation.EstimatedValue += item.EstimatedValue
+	}
+
+	if err = itemRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return donations, nil
+}
+
+// supplyColumns are the columns scanned by scanSupply
+const supplyColumns = `id, shelter_id, category, name, unit, quantity, updated`
+
+// PostgresSupplyRepository implements the SupplyRepository interface
+type PostgresSupplyRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresSupplyRepository creates a new PostgresSupplyRepository
+func NewPostgresSupplyRepository(db *sqlx.DB) *PostgresSupplyRepository {
+	return &PostgresSupplyRepository{
+		db: db,
+	}
+}
+
+// FindAll finds the supply inventory of the shelters in scope
+func (r *PostgresSupplyRepository) FindAll(scope tenant.Scope) ([]*models.SupplyItem, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + supplyColumns + ` FROM supply_inventory WHERE ` + tenant.Condition("shelter_id", 1) + `
+              ORDER BY category, name`
+
+	rows, err := r.db.Query(query, all, shelterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	supplies := []*models.SupplyItem{}
+
+	for rows.Next() {
+		supply, err := scanSupply(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		supplies = append(supplies, supply)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return supplies, nil
+}
+
+// Use takes a quantity out of the stock of a supply of the shelters in scope. The stock is never
+// allowed to go below zero.
+func (r *PostgresSupplyRepository) Use(id string, quantity float64, updated string, scope tenant.Scope) (*models.SupplyItem, error) {
+	all, shelterID := scope.Filter()
+
+	query := `UPDATE supply_inventory SET quantity = quantity - $1, updated = $2
+              WHERE id = $3 AND quantity >= $1 AND ` + tenant.Condition("shelter_id", 4) + `
+              RETURNING ` + supplyColumns
+
+	supply, err := scanSupply(r.db.QueryRow(query, quantity, updated, id, all, shelterID))
+	if err == nil {
+		return supply, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// Nothing was updated: either the supply does not exist or there is not enough of it
+	var exists bool
+	existsQuery := `SELECT EXISTS (SELECT 1 FROM supply_inventory WHERE id = $1 AND ` + tenant.Condition("shelter_id", 2) + `)`
+	if err := r.db.QueryRow(existsQuery, id, all, shelterID).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	return nil, models.ErrInsufficientStock
+}
+
+// scanSupply scans a supply inventory row into a supply item
+func scanSupply(row rowScanner) (*models.SupplyItem, error) {
+	var supply models.SupplyItem
+	var categoryStr, unitStr string
+
+	err := row.Scan(
+		&supply.ID,
+		&supply.ShelterID,
+		&categoryStr,
+		&supply.Name,
+		&unitStr,
+		&supply.Quantity,
+		&supply.Updated,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	supply.Category = models.ItemCategory(categoryStr)
+	supply.Unit = models.ItemUnit(unitStr)
+
+	return &supply, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+)
+
+// PostgresLedgerRepository implements the LedgerRepository interface
+type PostgresLedgerRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresLedgerRepository creates a new PostgresLedgerRepository
+func NewPostgresLedgerRepository(db *sqlx.DB) *PostgresLedgerRepository {
+	return &PostgresLedgerRepository{
+		db: db,
+	}
+}
+
+// FindByDonationID finds all ledger entries of a donation in the order they were recorded
+func (r *PostgresLedgerRepository) FindByDonationID(donationID string) ([]*models.LedgerEntry, error) {
+	query := `SELECT id, donation_id, entry_type, amount, COALESCE(refund_id::text, ''), COALESCE(actor_id::text, ''), created
+              FROM donation_ledger WHERE donation_id = $1 ORDER BY created, entry_type`
+
+	rows, err := r.db.Query(query, donationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.LedgerEntry
+
+	for rows.Next() {
+		var entry models.LedgerEntry
+		var typeStr string
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.DonationID,
+			&typeStr,
+			&entry.Amount,
+			&entry.RefundID,
+			&entry.ActorID,
+			&entry.Created,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Type = models.EntryType(typeStr)
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,9 @@
+-- Allow donations to be earmarked for a pet as a one-off or monthly sponsorship
+ALTER TABLE donations ADD COLUMN IF NOT EXISTS pet_id VARCHAR(36);
+ALTER TABLE donations ADD COLUMN IF NOT EXISTS sponsorship VARCHAR(20);
+ALTER TABLE donations DROP CONSTRAINT IF EXISTS donations_pet_id_fkey;
+ALTER TABLE donations ADD CONSTRAINT donations_pet_id_fkey
+    FOREIGN KEY (pet_id) REFERENCES pets(id) ON DELETE SET NULL;
+
+-- Add index for sponsorship lookups
+CREATE INDEX IF NOT EXISTS idx_donations_pet_id ON donations(pet_id);
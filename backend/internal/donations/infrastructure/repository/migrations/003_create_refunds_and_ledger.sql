@@ -0,0 +1,53 @@
+-- Create donation refunds table and the append-only ledger of donation money movements
+CREATE TABLE IF NOT EXISTS donation_refunds (
+    id UUID PRIMARY KEY,
+    donation_id UUID NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    requested_by UUID NOT NULL,
+    reviewed_by UUID,
+    created TIMESTAMP NOT NULL,
+    updated TIMESTAMP NOT NULL,
+    FOREIGN KEY (donation_id) REFERENCES donations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_donation_refunds_donation_id ON donation_refunds(donation_id);
+CREATE INDEX IF NOT EXISTS idx_donation_refunds_status ON donation_refunds(status);
+
+CREATE TABLE IF NOT EXISTS donation_ledger (
+    id UUID PRIMARY KEY,
+    donation_id UUID NOT NULL,
+    entry_type VARCHAR(20) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    refund_id UUID,
+    actor_id UUID,
+    created TIMESTAMP NOT NULL,
+    FOREIGN KEY (donation_id) REFERENCES donations(id) ON DELETE RESTRICT,
+    FOREIGN KEY (refund_id) REFERENCES donation_refunds(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_donation_ledger_donation_id ON donation_ledger(donation_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_donation_ledger_charge ON donation_ledger(donation_id) WHERE entry_type = 'charge';
+
+-- The ledger is append-only: entries can never be changed or removed
+CREATE OR REPLACE FUNCTION donation_ledger_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'donation_ledger is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_donation_ledger_append_only ON donation_ledger;
+CREATE TRIGGER trg_donation_ledger_append_only BEFORE UPDATE OR DELETE ON donation_ledger
+    FOR EACH ROW EXECUTE FUNCTION donation_ledger_append_only();
+
+-- Backfill the ledger for donations completed or refunded before it existed
+INSERT INTO donation_ledger (id, donation_id, entry_type, amount, created)
+SELECT gen_random_uuid(), d.id, 'charge', d.amount, d.updated FROM donations d
+WHERE d.status IN ('completed', 'refunded')
+    AND NOT EXISTS (SELECT 1 FROM donation_ledger l WHERE l.donation_id = d.id);
+
+INSERT INTO donation_ledger (id, donation_id, entry_type, amount, created)
+SELECT gen_random_uuid(), d.id, 'refund', -d.amount, d.updated FROM donations d
+WHERE d.status = 'refunded'
+    AND NOT EXISTS (SELECT 1 FROM donation_ledger l WHERE l.donation_id = d.id AND l.entry_type = 'refund');
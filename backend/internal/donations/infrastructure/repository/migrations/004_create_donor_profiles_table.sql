@@ -0,0 +1,8 @@
+-- Create donor profiles table holding how donors appear on public donation pages
+CREATE TABLE IF NOT EXISTS donor_profiles (
+    user_id UUID PRIMARY KEY,
+    display_name VARCHAR(100) NOT NULL DEFAULT '',
+    hide_amounts BOOLEAN NOT NULL DEFAULT FALSE,
+    updated TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+-- Allow donations to be attributed to a fundraising campaign for reporting
+ALTER TABLE donations ADD COLUMN IF NOT EXISTS campaign VARCHAR(100);
+
+-- Add index for campaign reports
+CREATE INDEX IF NOT EXISTS idx_donations_campaign ON donations(campaign);
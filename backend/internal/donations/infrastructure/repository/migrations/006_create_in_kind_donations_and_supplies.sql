@@ -0,0 +1,41 @@
+-- Goods such as food, bedding and medicine handed in by donors
+CREATE TABLE IF NOT EXISTS in_kind_donations (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    drop_off_date DATE NOT NULL,
+    comment TEXT,
+    received_by UUID,
+    acknowledged_at TIMESTAMP,
+    created TIMESTAMP NOT NULL,
+    updated TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_in_kind_donations_user_id ON in_kind_donations(user_id);
+CREATE INDEX IF NOT EXISTS idx_in_kind_donations_drop_off_date ON in_kind_donations(drop_off_date);
+
+CREATE TABLE IF NOT EXISTS in_kind_items (
+    id UUID PRIMARY KEY,
+    donation_id UUID NOT NULL,
+    category VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    quantity DECIMAL(10, 2) NOT NULL CHECK (quantity > 0),
+    unit VARCHAR(20) NOT NULL,
+    estimated_value DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (estimated_value >= 0),
+    condition VARCHAR(20) NOT NULL,
+    FOREIGN KEY (donation_id) REFERENCES in_kind_donations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_in_kind_items_donation_id ON in_kind_items(donation_id);
+
+-- Shelter supply inventory, fed by in-kind donations
+CREATE TABLE IF NOT EXISTS supply_inventory (
+    id UUID PRIMARY KEY,
+    category VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    unit VARCHAR(20) NOT NULL,
+    quantity DECIMAL(12, 2) NOT NULL CHECK (quantity >= 0),
+    updated TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_supply_inventory_item ON supply_inventory(category, lower(name), unit);
@@ -0,0 +1,9 @@
+-- Donations and in-kind donations are financial records: a donor can't be deleted while they
+-- have any. Deleted accounts are anonymized and kept as tombstones instead.
+ALTER TABLE donations DROP CONSTRAINT IF EXISTS donations_user_id_fkey;
+ALTER TABLE donations ADD CONSTRAINT donations_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE in_kind_donations DROP CONSTRAINT IF EXISTS in_kind_donations_user_id_fkey;
+ALTER TABLE in_kind_donations ADD CONSTRAINT in_kind_donations_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
@@ -0,0 +1,18 @@
+-- Donations, in-kind donations and supplies belong to a shelter; existing ones belong to the
+-- default shelter
+ALTER TABLE donations ADD COLUMN IF NOT EXISTS shelter_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+    REFERENCES shelters(id) ON DELETE RESTRICT;
+ALTER TABLE donations ALTER COLUMN shelter_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_donations_shelter_id ON donations(shelter_id);
+
+ALTER TABLE in_kind_donations ADD COLUMN IF NOT EXISTS shelter_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+    REFERENCES shelters(id) ON DELETE RESTRICT;
+ALTER TABLE in_kind_donations ALTER COLUMN shelter_id DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS idx_in_kind_donations_shelter_id ON in_kind_donations(shelter_id);
+
+-- Each shelter keeps its own inventory
+ALTER TABLE supply_inventory ADD COLUMN IF NOT EXISTS shelter_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+    REFERENCES shelters(id) ON DELETE RESTRICT;
+ALTER TABLE supply_inventory ALTER COLUMN shelter_id DROP DEFAULT;
+DROP INDEX IF EXISTS idx_supply_inventory_item;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_supply_inventory_shelter_item ON supply_inventory(shelter_id, category, lower(name), unit);
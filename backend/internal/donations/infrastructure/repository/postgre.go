@@ -5,9 +5,22 @@ import (
 	"errors"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/database"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
 )
 
+// donationColumns lists the columns selected for a donation, in scan order
+const donationColumns = `id, shelter_id, user_id, amount, status, created, updated, comment, anonymous,
+              COALESCE(pet_id, ''), COALESCE(sponsorship, ''), COALESCE(campaign, ''),
+              (SELECT COALESCE(SUM(l.amount), 0) FROM donation_ledger l WHERE l.donation_id = donations.id)`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 // PostgresRepository implements the DonationRepository interface
 type PostgresRepository struct {
 	db *sqlx.DB
@@ -22,12 +35,13 @@ func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
 
 // Save saves a donation into the database
 func (r *PostgresRepository) Save(donation *models.Donation) error {
-	query := `INSERT INTO donations (id, user_id, amount, status, created, updated, comment, anonymous) 
-              VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	query := `INSERT INTO donations (id, shelter_id, user_id, amount, status, created, updated, comment, anonymous, pet_id, sponsorship, campaign)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
 
 	_, err := r.db.Exec(
 		query,
 		donation.ID,
+		donation.ShelterID,
 		donation.UserID,
 		donation.Amount,
 		donation.Status.String(),
@@ -35,30 +49,28 @@ func (r *PostgresRepository) Save(donation *models.Donation) error {
 		donation.Updated,
 		donation.Comment,
 		donation.Anonymous,
+		nullString(donation.PetID),
+		nullString(donation.Sponsorship.String()),
+		nullString(donation.Campaign),
 	)
 
+	switch {
+	case database.IsForeignKeyViolation(err, "donations_pet_id_fkey"):
+		return models.ErrPetNotFound
+	case database.IsForeignKeyViolation(err, "donations_shelter_id_fkey"):
+		return models.ErrShelterNotFound
+	}
+
 	return err
 }
 
-// FindByID finds a donation by its ID
-func (r *PostgresRepository) FindByID(id string) (*models.Donation, error) {
-	var donation models.Donation
-	var statusStr string
-
-	query := `SELECT id, user_id, amount, status, created, updated, comment, anonymous 
-              FROM donations WHERE id = $1`
+// FindByID finds a donation to the shelters in scope by its ID
+func (r *PostgresRepository) FindByID(id string, scope tenant.Scope) (*models.Donation, error) {
+	all, shelterID := scope.Filter()
 
-	err := r.db.QueryRow(query, id).Scan(
-		&donation.ID,
-		&donation.UserID,
-		&donation.Amount,
-		&statusStr,
-		&donation.Created,
-		&donation.Updated,
-		&donation.Comment,
-		&donation.Anonymous,
-	)
+	query := `SELECT ` + donationColumns + ` FROM donations WHERE id = $1 AND ` + tenant.Condition("shelter_id", 2)
 
+	donation, err := scanDonation(r.db.QueryRow(query, id, all, shelterID))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -66,59 +78,179 @@ func (r *PostgresRepository) FindByID(id string) (*models.Donation, error) {
 		return nil, err
 	}
 
-	donation.Status = models.Status(statusStr)
+	return donation, nil
+}
 
-	return &donation, nil
+// FindByUserID finds all donations of a user to the shelters in scope
+func (r *PostgresRepository) FindByUserID(userID string, scope tenant.Scope) ([]*models.Donation, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + donationColumns + ` FROM donations WHERE user_id = $1 AND ` + tenant.Condition("shelter_id", 2)
+	return r.findMany(query, userID, all, shelterID)
 }
 
-// FindByUserID finds all donations for a user
-func (r *PostgresRepository) FindByUserID(userID string) ([]*models.Donation, error) {
-	query := `SELECT id, user_id, amount, status, created, updated, comment, anonymous 
-              FROM donations WHERE user_id = $1`
+// FindByPetID finds all donations earmarked for a pet
+func (r *PostgresRepository) FindByPetID(petID string) ([]*models.Donation, error) {
+	query := `SELECT ` + donationColumns + ` FROM donations WHERE pet_id = $1`
+	return r.findMany(query, petID)
+}
+
+// FindAll finds all donations to the shelters in scope
+func (r *PostgresRepository) FindAll(scope tenant.Scope) ([]*models.Donation, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + donationColumns + ` FROM donations WHERE ` + tenant.Condition("shelter_id", 1)
+	return r.findMany(query, all, shelterID)
+}
+
+// FindSponsorsByPetID finds the distinct users with donations in one of the given statuses earmarked for a pet.
+// A sponsor is only reported as anonymous when all of their donations to the pet are anonymous, and
+// their public name is masked the same way as on the donor wall. Erased accounts are still counted,
+// and reported as such.
+func (r *PostgresRepository) FindSponsorsByPetID(petID string, statuses ...models.Status) ([]*models.Sponsor, error) {
+	statusStrs := make([]string, len(statuses))
+	for i, status := range statuses {
+		statusStrs[i] = status.String()
+	}
 
-	rows, err := r.db.Query(query, userID)
+	query := `SELECT d.user_id, u.name, u.email, BOOL_AND(d.anonymous),
+                     CASE WHEN BOOL_AND(d.anonymous) THEN NULL ELSE COALESCE(NULLIF(p.display_name, ''), u.name) END,
+                     BOOL_OR(u.status = 'deleted')
+              FROM donations d
+              JOIN users u ON u.id = d.user_id
+              LEFT JOIN donor_profiles p ON p.user_id = d.user_id
+              WHERE d.pet_id = $1 AND d.status = ANY($2)
+              GROUP BY d.user_id, u.name, u.email, p.display_name
+              ORDER BY MIN(d.created)`
+
+	rows, err := r.db.Query(query, petID, pq.Array(statusStrs))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var donations []*models.Donation
+	var sponsors []*models.Sponsor
 
 	for rows.Next() {
-		var donation models.Donation
-		var statusStr string
+		var sponsor models.Sponsor
+		var publicName sql.NullString
 
 		err := rows.Scan(
-			&donation.ID,
-			&donation.UserID,
-			&donation.Amount,
-			&statusStr,
-			&donation.Created,
-			&donation.Updated,
-			&donation.Comment,
-			&donation.Anonymous,
+			&sponsor.UserID,
+			&sponsor.Name,
+			&sponsor.Email,
+			&sponsor.Anonymous,
+			&publicName,
+			&sponsor.Erased,
 		)
 
 		if err != nil {
 			return nil, err
 		}
 
-		donation.Status = models.Status(statusStr)
-		donations = append(donations, &donation)
+		sponsor.PublicName = maskDonorName(publicName)
+		sponsors = append(sponsors, &sponsor)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return donations, nil
+	return sponsors, nil
+}
+
+// Update updates a donation. Its shelter can't be changed.
+func (r *PostgresRepository) Update(donation *models.Donation) error {
+	query := `UPDATE donations SET user_id = $1, amount = $2, status = $3, updated = $4,
+              comment = $5, anonymous = $6, pet_id = $7, sponsorship = $8, campaign = $9 WHERE id = $10`
+
+	_, err := r.db.Exec(
+		query,
+		donation.UserID,
+		donation.Amount,
+		donation.Status.String(),
+		donation.Updated,
+		donation.Comment,
+		donation.Anonymous,
+		nullString(donation.PetID),
+		nullString(donation.Sponsorship.String()),
+		nullString(donation.Campaign),
+		donation.ID,
+	)
+
+	return err
 }
 
-// FindAll finds all donations
-func (r *PostgresRepository) FindAll() ([]*models.Donation, error) {
-	query := `SELECT id, user_id, amount, status, created, updated, comment, anonymous FROM donations`
+// UpdateStatus updates the status of a donation and appends its charge to the ledger, if one is
+// given, in a single transaction. The donation is locked so its status can't change meanwhile, and
+// once money has been received for it, it can only change through the refund workflow.
+// A donation can only be charged once, so a repeated charge entry is ignored.
+func (r *PostgresRepository) UpdateStatus(donation *models.Donation, charge *models.LedgerEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	rows, err := r.db.Query(query)
+	var currentStatus string
+	err = tx.QueryRow(`SELECT status FROM donations WHERE id = $1 FOR UPDATE`, donation.ID).Scan(&currentStatus)
+	if err != nil {
+		return err
+	}
+
+	if models.Status(currentStatus).HasReceivedMoney() && !donation.Status.IsEquals(models.Status(currentStatus)) {
+		return models.ErrUseRefundWorkflow
+	}
+
+	_, err = tx.Exec(
+		`UPDATE donations SET status = $1, updated = $2 WHERE id = $3`,
+		donation.Status.String(),
+		donation.Updated,
+		donation.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if charge != nil {
+		_, err = tx.Exec(
+			`INSERT INTO donation_ledger (id, donation_id, entry_type, amount, refund_id, actor_id, created)
+             VALUES ($1, $2, $3, $4, $5, $6, $7)
+             ON CONFLICT (donation_id) WHERE entry_type = 'charge' DO NOTHING`,
+			charge.ID,
+			charge.DonationID,
+			charge.Type.String(),
+			charge.Amount,
+			nullString(charge.RefundID),
+			nullString(charge.ActorID),
+			charge.Created,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a donation to the shelters in scope. Donations with money movements in the
+// ledger cannot be deleted.
+func (r *PostgresRepository) Delete(id string, scope tenant.Scope) error {
+	all, shelterID := scope.Filter()
+
+	query := `DELETE FROM donations WHERE id = $1 AND ` + tenant.Condition("shelter_id", 2)
+	_, err := r.db.Exec(query, id, all, shelterID)
+
+	if database.IsForeignKeyViolation(err, "donation_ledger_donation_id_fkey") {
+		return models.ErrDonationHasLedger
+	}
+
+	return err
+}
+
+// findMany runs a query returning donation rows
+func (r *PostgresRepository) findMany(query string, args ...interface{}) ([]*models.Donation, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -127,26 +259,12 @@ func (r *PostgresRepository) FindAll() ([]*models.Donation, error) {
 	var donations []*models.Donation
 
 	for rows.Next() {
-		var donation models.Donation
-		var statusStr string
-
-		err := rows.Scan(
-			&donation.ID,
-			&donation.UserID,
-			&donation.Amount,
-			&statusStr,
-			&donation.Created,
-			&donation.Updated,
-			&donation.Comment,
-			&donation.Anonymous,
-		)
-
+		donation, err := scanDonation(rows)
 		if err != nil {
 			return nil, err
 		}
 
-		donation.Status = models.Status(statusStr)
-		donations = append(donations, &donation)
+		donations = append(donations, donation)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -156,28 +274,37 @@ func (r *PostgresRepository) FindAll() ([]*models.Donation, error) {
 	return donations, nil
 }
 
-// Update updates a donation
-func (r *PostgresRepository) Update(donation *models.Donation) error {
-	query := `UPDATE donations SET user_id = $1, amount = $2, status = $3, updated = $4, 
-              comment = $5, anonymous = $6 WHERE id = $7`
+// scanDonation scans a row selected with donationColumns into a donation
+func scanDonation(row rowScanner) (*models.Donation, error) {
+	var donation models.Donation
+	var statusStr, sponsorshipStr string
 
-	_, err := r.db.Exec(
-		query,
-		donation.UserID,
-		donation.Amount,
-		donation.Status.String(),
-		donation.Updated,
-		donation.Comment,
-		donation.Anonymous,
-		donation.ID,
+	err := row.Scan(
+		&donation.ID,
+		&donation.ShelterID,
+		&donation.UserID,
+		&donation.Amount,
+		&statusStr,
+		&donation.Created,
+		&donation.Updated,
+		&donation.Comment,
+		&donation.Anonymous,
+		&donation.PetID,
+		&sponsorshipStr,
+		&donation.Campaign,
+		&donation.NetAmount,
 	)
+	if err != nil {
+		return nil, err
+	}
 
-	return err
+	donation.Status = models.Status(statusStr)
+	donation.Sponsorship = models.SponsorshipType(sponsorshipStr)
+
+	return &donation, nil
 }
 
-// Delete deletes a donation
-func (r *PostgresRepository) Delete(id string) error {
-	query := `DELETE FROM donations WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
+// nullString converts an empty string into a SQL NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
 }
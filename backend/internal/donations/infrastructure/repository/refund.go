@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// refundColumns lists the columns selected for a refund, in scan order
+const refundColumns = `id, donation_id, amount, reason, status, requested_by, COALESCE(reviewed_by::text, ''), created, updated`
+
+// PostgresRefundRepository implements the RefundRepository interface
+type PostgresRefundRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRefundRepository creates a new PostgresRefundRepository
+func NewPostgresRefundRepository(db *sqlx.DB) *PostgresRefundRepository {
+	return &PostgresRefundRepository{
+		db: db,
+	}
+}
+
+// Save saves a refund into the database
+func (r *PostgresRefundRepository) Save(refund *models.Refund) error {
+	query := `INSERT INTO donation_refunds (id, donation_id, amount, reason, status, requested_by, reviewed_by, created, updated)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err := r.db.Exec(
+		query,
+		refund.ID,
+		refund.DonationID,
+		refund.Amount,
+		refund.Reason,
+		refund.Status.String(),
+		refund.RequestedBy,
+		nullString(refund.ReviewedBy),
+		refund.Created,
+		refund.Updated,
+	)
+
+	return err
+}
+
+// FindByID finds a refund of the donations to the shelters in scope by its ID
+func (r *PostgresRefundRepository) FindByID(id string, scope tenant.Scope) (*models.Refund, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + refundColumns + ` FROM donation_refunds
+              WHERE id = $1 AND donation_id IN (SELECT id FROM donations WHERE ` + tenant.Condition("shelter_id", 2) + `)`
+
+	refund, err := scanRefund(r.db.QueryRow(query, id, all, shelterID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// FindByDonationID finds all refunds of a donation
+func (r *PostgresRefundRepository) FindByDonationID(donationID string) ([]*models.Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM donation_refunds WHERE donation_id = $1 ORDER BY created`
+	return r.findMany(query, donationID)
+}
+
+// FindByStatus finds all refunds of the donations to the shelters in scope with a specific status
+func (r *PostgresRefundRepository) FindByStatus(status models.RefundStatus, scope tenant.Scope) ([]*models.Refund, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + refundColumns + ` FROM donation_refunds
+              WHERE status = $1 AND donation_id IN (SELECT id FROM donations WHERE ` + tenant.Condition("shelter_id", 2) + `)
+              ORDER BY created`
+	return r.findMany(query, status.String(), all, shelterID)
+}
+
+// Update updates a refund
+func (r *PostgresRefundRepository) Update(refund *models.Refund) error {
+	query := `UPDATE donation_refunds SET status = $1, reviewed_by = $2, updated = $3 WHERE id = $4`
+
+	_, err := r.db.Exec(
+		query,
+		refund.Status.String(),
+		nullString(refund.ReviewedBy),
+		refund.Updated,
+		refund.ID,
+	)
+
+	return err
+}
+
+// Approve marks a requested refund as approved, appends its ledger entry and updates the
+// donation's status in a single transaction. The donation row is locked while its net amount
+// is checked so that concurrent approvals cannot return more money than was received.
+func (r *PostgresRefundRepository) Approve(refund *models.Refund, entry *models.LedgerEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var donationStatus string
+	err = tx.QueryRow(`SELECT status FROM donations WHERE id = $1 FOR UPDATE`, refund.DonationID).Scan(&donationStatus)
+	if err != nil {
+		return err
+	}
+
+	if !models.Status(donationStatus).IsRefundable() {
+		return models.ErrDonationNotRefundable
+	}
+
+	var netAmount float64
+	err = tx.QueryRow(`SELECT COALESCE(SUM(amount), 0) FROM donation_ledger WHERE donation_id = $1`, refund.DonationID).Scan(&netAmount)
+	if err != nil {
+		return err
+	}
+
+	remainingCents := toCents(netAmount) - toCents(refund.Amount)
+	if remainingCents < 0 {
+		return models.ErrRefundExceedsBalance
+	}
+
+	result, err := tx.Exec(
+		`UPDATE donation_refunds SET status = $1, reviewed_by = $2, updated = $3 WHERE id = $4 AND status = $5`,
+		refund.Status.String(),
+		nullString(refund.ReviewedBy),
+		refund.Updated,
+		refund.ID,
+		models.RefundStatusRequested.String(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.ErrRefundNotPending
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO donation_ledger (id, donation_id, entry_type, amount, refund_id, actor_id, created)
+         VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		entry.ID,
+		entry.DonationID,
+		entry.Type.String(),
+		entry.Amount,
+		nullString(entry.RefundID),
+		nullString(entry.ActorID),
+		entry.Created,
+	)
+	if err != nil {
+		return err
+	}
+
+	newStatus := models.StatusPartiallyRefunded
+	if remainingCents == 0 {
+		newStatus = models.StatusRefunded
+	}
+
+	_, err = tx.Exec(`UPDATE donations SET status = $1, updated = $2 WHERE id = $3`, newStatus.String(), refund.Updated, refund.DonationID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// findMany runs a query returning refund rows
+func (r *PostgresRefundRepository) findMany(query string, args ...interface{}) ([]*models.Refund, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []*models.Refund
+
+	for rows.Next() {
+		refund, err := scanRefund(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		refunds = append(refunds, refund)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// scanRefund scans a row selected with refundColumns into a refund
+func scanRefund(row rowScanner) (*models.Refund, error) {
+	var refund models.Refund
+	var statusStr string
+
+	err := row.Scan(
+		&refund.ID,
+		&refund.DonationID,
+		&refund.Amount,
+		&refund.Reason,
+		&statusStr,
+		&refund.RequestedBy,
+		&refund.ReviewedBy,
+		&refund.Created,
+		&refund.Updated,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	refund.Status = models.RefundStatus(statusStr)
+
+	return &refund, nil
+}
+
+// toCents converts a monetary amount into whole cents to avoid floating point comparisons
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// reportStatuses are the statuses of the donations counted in reports; refunded money is excluded through the ledger
+var reportStatuses = []string{models.StatusCompleted.String(), models.StatusPartiallyRefunded.String()}
+
+// reportGifts selects the completed donations to the shelters in scope ($4, $5) created in [$2, $3)
+// with their net amount. The range filter on created is served by idx_donations_created.
+// Times are stored in UTC, so periods are truncated in UTC whatever the time zone of the session.
+var reportGifts = `gifts AS (
+                  SELECT d.user_id, d.created, COALESCE(NULLIF(d.campaign, ''), '` + models.DefaultCampaign + `') AS campaign,
+                         COALESCE(SUM(l.amount), 0) AS net_amount
+                  FROM donations d
+                  LEFT JOIN donation_ledger l ON l.donation_id = d.id
+                  WHERE d.status = ANY($1) AND d.created >= $2 AND d.created < $3 AND ` + tenant.Condition("d.shelter_id", 4) + `
+                  GROUP BY d.id
+              )`
+
+// reportFirstGifts selects the date of each donor's first completed donation to the shelters in scope
+var reportFirstGifts = `first_gifts AS (
+                  SELECT user_id, MIN(created) AS first_gift FROM donations
+                  WHERE status = ANY($1) AND ` + tenant.Condition("shelter_id", 4) + `
+                  GROUP BY user_id
+              )`
+
+// PostgresReportRepository implements the ReportRepository interface
+type PostgresReportRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresReportRepository creates a new PostgresReportRepository
+func NewPostgresReportRepository(db *sqlx.DB) *PostgresReportRepository {
+	return &PostgresReportRepository{
+		db: db,
+	}
+}
+
+// TotalsByInterval aggregates the completed donations to the shelters in scope of the range by
+// day, week or month
+func (r *PostgresReportRepository) TotalsByInterval(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.PeriodTotal, error) {
+	all, shelterID := scope.Filter()
+
+	query := `WITH ` + reportGifts + `
+              SELECT date_trunc($6, created AT TIME ZONE 'UTC', 'UTC') AS period_start, SUM(net_amount), COUNT(*),
+                     COUNT(DISTINCT user_id), AVG(net_amount)
+              FROM gifts
+              GROUP BY period_start
+              ORDER BY period_start`
+
+	rows, err := r.db.Query(query, pq.Array(reportStatuses), reportRange.From, reportRange.To, all, shelterID, interval.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []*models.PeriodTotal{}
+
+	for rows.Next() {
+		var total models.PeriodTotal
+		var periodStart time.Time
+
+		err := rows.Scan(
+			&periodStart,
+			&total.Total,
+			&total.Count,
+			&total.Donors,
+			&total.AverageGift,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		total.PeriodStart = periodStart.UTC().Format(models.ReportDateLayout)
+		totals = append(totals, &total)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// TotalsByCampaign aggregates the completed donations to the shelters in scope of the range by
+// campaign, largest first
+func (r *PostgresReportRepository) TotalsByCampaign(reportRange *models.ReportRange, scope tenant.Scope) ([]*models.CampaignTotal, error) {
+	all, shelterID := scope.Filter()
+
+	query := `WITH ` + reportGifts + `
+              SELECT campaign, SUM(net_amount) AS total, COUNT(*), COUNT(DISTINCT user_id), AVG(net_amount)
+              FROM gifts
+              GROUP BY campaign
+              ORDER BY total DESC, campaign`
+
+	rows, err := r.db.Query(query, pq.Array(reportStatuses), reportRange.From, reportRange.To, all, shelterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []*models.CampaignTotal{}
+
+	for rows.Next() {
+		var total models.CampaignTotal
+
+		err := rows.Scan(
+			&total.Campaign,
+			&total.Total,
+			&total.Count,
+			&total.Donors,
+			&total.AverageGift,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		totals = append(totals, &total)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// DonorActivityByInterval counts, for each day, week or month of the range, the donors giving
+// to the shelters in scope for the first time and those who had already given before
+func (r *PostgresReportRepository) DonorActivityByInterval(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.DonorActivity, error) {
+	all, shelterID := scope.Filter()
+
+	query := `WITH ` + reportGifts + `, ` + reportFirstGifts + `,
+              period_donors AS (
+                  SELECT DISTINCT user_id, date_trunc($6, created AT TIME ZONE 'UTC', 'UTC') AS period_start FROM gifts
+              )
+              SELECT pd.period_start,
+                     COUNT(*) FILTER (WHERE date_trunc($6, f.first_gift AT TIME ZONE 'UTC', 'UTC') = pd.period_start),
+                     COUNT(*) FILTER (WHERE date_trunc($6, f.first_gift AT TIME ZONE 'UTC', 'UTC') < pd.period_start)
+              FROM period_donors pd
+              JOIN first_gifts f ON f.user_id = pd.user_id
+              GROUP BY pd.period_start
+              ORDER BY pd.period_start`
+
+	rows, err := r.db.Query(query, pq.Array(reportStatuses), reportRange.From, reportRange.To, all, shelterID, interval.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := []*models.DonorActivity{}
+
+	for rows.Next() {
+		var period models.DonorActivity
+		var periodStart time.Time
+
+		err := rows.Scan(
+			&periodStart,
+			&period.NewDonors,
+			&period.ReturningDonors,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		period.PeriodStart = periodStart.UTC().Format(models.ReportDateLayout)
+		activity = append(activity, &period)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return activity, nil
+}
+
+// Summary computes the headline figures of the donations to the shelters in scope of the range.
+// Retention compares the donors of the range with those of the range of the same length
+// immediately before it.
+func (r *PostgresReportRepository) Summary(reportRange *models.ReportRange, scope tenant.Scope) (*models.ReportSummary, error) {
+	all, shelterID := scope.Filter()
+
+	query := `WITH ` + reportGifts + `, ` + reportFirstGifts + `,
+              current_donors AS (
+                  SELECT DISTINCT user_id FROM gifts
+              ),
+              previous_donors AS (
+                  SELECT DISTINCT user_id FROM donations
+                  WHERE status = ANY($1) AND created >= $6 AND created < $2 AND ` + tenant.Condition("shelter_id", 4) + `
+              )
+              SELECT (SELECT COALESCE(SUM(net_amount), 0) FROM gifts),
+                     (SELECT COUNT(*) FROM gifts),
+                     (SELECT COUNT(*) FROM current_donors),
+                     (SELECT COALESCE(AVG(net_amount), 0) FROM gifts),
+                     (SELECT COUNT(*) FROM current_donors c JOIN first_gifts f ON f.user_id = c.user_id WHERE f.first_gift >= $2),
+                     (SELECT COUNT(*) FROM previous_donors),
+                     (SELECT COUNT(*) FROM previous_donors p JOIN current_donors c ON c.user_id = p.user_id)`
+
+	var summary models.ReportSummary
+
+	err := r.db.QueryRow(query, pq.Array(reportStatuses), reportRange.From, reportRange.To, all, shelterID, reportRange.Previous().From).Scan(
+		&summary.Total,
+		&summary.Count,
+		&summary.Donors,
+		&summary.AverageGift,
+		&summary.NewDonors,
+		&summary.PreviousDonors,
+		&summary.RetainedDonors,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
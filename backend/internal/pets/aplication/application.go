@@ -1,30 +1,36 @@
 package aplication
 
 import (
+	"log"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/solrac97gr/petparadise/internal/pets/domain/models"
 	"github.com/solrac97gr/petparadise/internal/pets/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
 )
 
 type PetService struct {
-	repository ports.PetRepository
+	repository   ports.PetRepository
+	sponsorships ports.SponsorshipGateway
+	sponsors     ports.SponsorNotifier
 }
 
 // NewPetService creates a new PetService instance
-func NewPetService(repository ports.PetRepository) *PetService {
+func NewPetService(repository ports.PetRepository, sponsorships ports.SponsorshipGateway, sponsors ports.SponsorNotifier) *PetService {
 	return &PetService{
-		repository: repository,
+		repository:   repository,
+		sponsorships: sponsorships,
+		sponsors:     sponsors,
 	}
 }
 
-// CreatePet creates a new pet
-func (s *PetService) CreatePet(name, species, breed string, age int, description string, images []string) (*models.Pet, error) {
+// CreatePet creates a new pet at a shelter
+func (s *PetService) CreatePet(shelterID, name, species, breed string, age int, description string, images []string) (*models.Pet, error) {
 	id := uuid.New().String()
 	now := time.Now().Format(time.RFC3339)
 
-	pet, err := models.NewPet(id, name, species, breed, age, description, models.StatusAvailable, images)
+	pet, err := models.NewPet(id, shelterID, name, species, breed, age, description, models.StatusAvailable, images)
 	if err != nil {
 		return nil, err
 	}
@@ -40,27 +46,49 @@ func (s *PetService) CreatePet(name, species, breed string, age int, description
 	return pet, nil
 }
 
-// GetPetByID returns a pet by its ID
-func (s *PetService) GetPetByID(id string) (*models.Pet, error) {
-	return s.repository.FindByID(id)
+// GetPetByID returns a pet of the shelters in scope by its ID
+func (s *PetService) GetPetByID(id string, scope tenant.Scope) (*models.Pet, error) {
+	return s.repository.FindByID(id, scope)
 }
 
-// GetPetsByStatus returns all pets with a specific status
-func (s *PetService) GetPetsByStatus(status models.Status) ([]*models.Pet, error) {
+// GetPetProfile returns the public profile of a pet, including its sponsorship totals
+func (s *PetService) GetPetProfile(id string) (*models.PetProfile, error) {
+	pet, err := s.repository.FindByID(id, tenant.AllShelters())
+	if err != nil {
+		return nil, err
+	}
+
+	if pet == nil {
+		return nil, nil
+	}
+
+	sponsorship, err := s.sponsorships.GetSponsorship(pet.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PetProfile{
+		Pet:         pet,
+		Sponsorship: sponsorship,
+	}, nil
+}
+
+// GetPetsByStatus returns all pets of the shelters in scope with a specific status
+func (s *PetService) GetPetsByStatus(status models.Status, scope tenant.Scope) ([]*models.Pet, error) {
 	if !status.IsValid() {
 		return nil, models.ErrInvalidStatus
 	}
-	return s.repository.FindByStatus(status)
+	return s.repository.FindByStatus(status, scope)
 }
 
-// GetAllPets returns all pets
-func (s *PetService) GetAllPets() ([]*models.Pet, error) {
-	return s.repository.FindAll()
+// GetAllPets returns all pets of the shelters in scope
+func (s *PetService) GetAllPets(scope tenant.Scope) ([]*models.Pet, error) {
+	return s.repository.FindAll(scope)
 }
 
-// UpdatePet updates a pet's information
-func (s *PetService) UpdatePet(id, name, species, breed string, age int, description string, status models.Status, images []string) (*models.Pet, error) {
-	pet, err := s.repository.FindByID(id)
+// UpdatePet updates the information of a pet of the shelters in scope
+func (s *PetService) UpdatePet(id, name, species, breed string, age int, description string, status models.Status, images []string, scope tenant.Scope) (*models.Pet, error) {
+	pet, err := s.repository.FindByID(id, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -85,6 +113,7 @@ func (s *PetService) UpdatePet(id, name, species, breed string, age int, descrip
 
 	pet.Description = description
 
+	previousStatus := pet.Status
 	if status != "" && !status.IsValid() {
 		return nil, models.ErrInvalidStatus
 	} else if status != "" {
@@ -102,16 +131,18 @@ func (s *PetService) UpdatePet(id, name, species, breed string, age int, descrip
 		return nil, err
 	}
 
+	s.notifyIfAdopted(pet, previousStatus)
+
 	return pet, nil
 }
 
-// UpdatePetStatus updates only a pet's status
-func (s *PetService) UpdatePetStatus(id string, status models.Status) (*models.Pet, error) {
+// UpdatePetStatus updates only the status of a pet of the shelters in scope
+func (s *PetService) UpdatePetStatus(id string, status models.Status, scope tenant.Scope) (*models.Pet, error) {
 	if !status.IsValid() {
 		return nil, models.ErrInvalidStatus
 	}
 
-	pet, err := s.repository.FindByID(id)
+	pet, err := s.repository.FindByID(id, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -120,6 +151,7 @@ func (s *PetService) UpdatePetStatus(id string, status models.Status) (*models.P
 		return nil, models.ErrInvalidStatus // In a real app, you'd have a more specific error like ErrPetNotFound
 	}
 
+	previousStatus := pet.Status
 	pet.Status = status
 	pet.Updated = time.Now().Format(time.RFC3339)
 
@@ -128,10 +160,28 @@ func (s *PetService) UpdatePetStatus(id string, status models.Status) (*models.P
 		return nil, err
 	}
 
+	s.notifyIfAdopted(pet, previousStatus)
+
 	return pet, nil
 }
 
-// DeletePet deletes a pet
-func (s *PetService) DeletePet(id string) error {
-	return s.repository.Delete(id)
+// DeletePet deletes a pet of the shelters in scope
+func (s *PetService) DeletePet(id string, scope tenant.Scope) error {
+	return s.repository.Delete(id, scope)
+}
+
+// notifyIfAdopted notifies the sponsors of a pet whose status has just changed to adopted. They are
+// notified in the background, so the status change doesn't wait for the emails; a failed
+// notification is logged and does not undo it.
+func (s *PetService) notifyIfAdopted(pet *models.Pet, previousStatus models.Status) {
+	if previousStatus.IsEquals(models.StatusAdopted) || !pet.Status.IsEquals(models.StatusAdopted) {
+		return
+	}
+
+	adopted := *pet
+	go func() {
+		if err := s.sponsors.NotifySponsors(&adopted); err != nil {
+			log.Printf("Failed to notify sponsors of pet %s: %v", adopted.ID, err)
+		}
+	}()
 }
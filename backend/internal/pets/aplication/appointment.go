@@ -0,0 +1,333 @@
+package aplication
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/pets/domain/models"
+	"github.com/solrac97gr/petparadise/internal/pets/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// AppointmentService implements the AppointmentService interface. Besides keeping the vets'
+// calendar, it moves pets to medical care while a procedure that needs it takes place and the
+// pet recovers, and back to their previous status afterwards.
+type AppointmentService struct {
+	appointments ports.AppointmentRepository
+	pets         ports.PetRepository
+	vets         ports.VetDirectory
+}
+
+// NewAppointmentService creates a new AppointmentService instance
+func NewAppointmentService(appointments ports.AppointmentRepository, pets ports.PetRepository, vets ports.VetDirectory) *AppointmentService {
+	return &AppointmentService{
+		appointments: appointments,
+		pets:         pets,
+		vets:         vets,
+	}
+}
+
+// ScheduleAppointment schedules a procedure on a pet of the shelters in scope with a vet of the
+// pet's shelter who is free at that time
+func (s *AppointmentService) ScheduleAppointment(petID, vetID string, procedure models.Procedure, startsAt, endsAt time.Time, notes, createdBy string, scope tenant.Scope) (*models.Appointment, error) {
+	pet, err := s.pets.FindByID(petID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if pet == nil {
+		return nil, models.ErrPetNotFound
+	}
+
+	if pet.Status.IsEquals(models.StatusAdopted) {
+		return nil, models.ErrPetAdopted
+	}
+
+	if err := s.checkVet(vetID, pet.ShelterID); err != nil {
+		return nil, err
+	}
+
+	appointment, err := models.NewAppointment(uuid.New().String(), petID, vetID, procedure, startsAt, endsAt, notes, createdBy, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.appointments.Save(appointment); err != nil {
+		return nil, err
+	}
+
+	return appointment, nil
+}
+
+// GetAppointmentByID returns an appointment of the pets of the shelters in scope by its ID
+func (s *AppointmentService) GetAppointmentByID(id string, scope tenant.Scope) (*models.Appointment, error) {
+	return s.appointments.FindByID(id, scope)
+}
+
+// GetAppointments returns the appointments of the pets of the shelters in scope matching the
+// filter, soonest first
+func (s *AppointmentService) GetAppointments(filter models.AppointmentFilter, scope tenant.Scope) ([]*models.Appointment, error) {
+	return s.appointments.Find(filter, scope)
+}
+
+// RescheduleAppointment changes the vet, procedure, time and notes of an appointment that hasn't started
+func (s *AppointmentService) RescheduleAppointment(id, vetID string, procedure models.Procedure, startsAt, endsAt time.Time, notes string, scope tenant.Scope) (*models.Appointment, error) {
+	appointment, err := s.findAppointment(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if vetID != appointment.VetID {
+		pet, err := s.pets.FindByID(appointment.PetID, tenant.AllShelters())
+		if err != nil {
+			return nil, err
+		}
+
+		if pet == nil {
+			return nil, models.ErrPetNotFound
+		}
+
+		if err := s.checkVet(vetID, pet.ShelterID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := appointment.Reschedule(vetID, procedure, startsAt, endsAt, notes, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.appointments.Reschedule(appointment); err != nil {
+		return nil, err
+	}
+
+	return appointment, nil
+}
+
+// UpdateAppointmentStatus marks a started appointment done or missed. A missed appointment lets
+// the pet out of medical care straight away, a done one once the pet has recovered.
+func (s *AppointmentService) UpdateAppointmentStatus(id string, status models.AppointmentStatus, scope tenant.Scope) (*models.Appointment, error) {
+	appointment, err := s.findAppointment(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := appointment.Close(status, now); err != nil {
+		return nil, err
+	}
+
+	// Marked done before the care job got to it, so the pet recovers in medical care all the same
+	if status == models.AppointmentDone && appointment.Procedure.NeedsCare() && appointment.CareStartedAt == nil {
+		if err := s.startCare(appointment, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.appointments.Update(appointment); err != nil {
+		return nil, err
+	}
+
+	if status == models.AppointmentMissed && appointment.InCare() {
+		if err := s.endCare(appointment, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return appointment, nil
+}
+
+// CancelAppointment deletes an appointment that hasn't started, freeing the vet's slot
+func (s *AppointmentService) CancelAppointment(id string, scope tenant.Scope) error {
+	appointment, err := s.findAppointment(id, scope)
+	if err != nil {
+		return err
+	}
+
+	if appointment.Status != models.AppointmentScheduled {
+		return models.ErrAppointmentClosed
+	}
+
+	if appointment.Started(time.Now()) {
+		return models.ErrAppointmentStarted
+	}
+
+	return s.appointments.Delete(id)
+}
+
+// UpdatePetCare moves the pets whose procedure has started to medical care, and the pets that
+// have recovered back to their previous status
+func (s *AppointmentService) UpdatePetCare(now time.Time) error {
+	due, err := s.appointments.FindCareDue(now)
+	if err != nil {
+		return err
+	}
+
+	for _, appointment := range due {
+		if err := s.startCare(appointment, now); err != nil {
+			log.Printf("Failed to move the pet of appointment %s to medical care: %v", appointment.ID, err)
+			continue
+		}
+
+		if err := s.appointments.Update(appointment); err != nil {
+			log.Printf("Failed to update appointment %s: %v", appointment.ID, err)
+		}
+	}
+
+	recovered, err := s.appointments.FindRecovered(now)
+	if err != nil {
+		return err
+	}
+
+	for _, appointment := range recovered {
+		if err := s.endCare(appointment, now); err != nil {
+			log.Printf("Failed to move the pet of appointment %s out of medical care: %v", appointment.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// StartCareScheduler updates the pets' medical care every interval, until the returned stop
+// function is called
+func (s *AppointmentService) StartCareScheduler(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				if err := s.UpdatePetCare(now); err != nil {
+					log.Printf("Failed to update the pets in medical care: %v", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// startCare moves the pet of an appointment to medical care, remembering the status it goes
+// back to. A pet already in medical care for another appointment goes back to the status that
+// appointment remembered, one put there by staff or adopted is left alone. The caller saves
+// the appointment.
+func (s *AppointmentService) startCare(appointment *models.Appointment, now time.Time) error {
+	pet, err := s.pets.FindByID(appointment.PetID, tenant.AllShelters())
+	if err != nil {
+		return err
+	}
+
+	if pet == nil {
+		return models.ErrPetNotFound
+	}
+
+	var previous models.Status
+
+	switch {
+	case pet.Status.IsEquals(models.StatusMedicalCare):
+		inCare, err := s.appointments.FindInCare(pet.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(inCare) > 0 {
+			previous = inCare[0].PreviousPetStatus
+		}
+	case !pet.Status.IsEquals(models.StatusAdopted):
+		previous = pet.Status
+		if err := s.updatePetStatus(pet, models.StatusMedicalCare); err != nil {
+			return err
+		}
+	}
+
+	appointment.StartCare(previous, now)
+
+	return nil
+}
+
+// endCare lets the pet of an appointment out of medical care. The pet goes back to its previous
+// status once no other appointment keeps it in care, unless staff changed its status meanwhile.
+func (s *AppointmentService) endCare(appointment *models.Appointment, now time.Time) error {
+	appointment.EndCare(now)
+	if err := s.appointments.Update(appointment); err != nil {
+		return err
+	}
+
+	if appointment.PreviousPetStatus == "" {
+		return nil
+	}
+
+	inCare, err := s.appointments.FindInCare(appointment.PetID)
+	if err != nil {
+		return err
+	}
+
+	if len(inCare) > 0 {
+		return nil
+	}
+
+	pet, err := s.pets.FindByID(appointment.PetID, tenant.AllShelters())
+	if err != nil {
+		return err
+	}
+
+	if pet == nil || !pet.Status.IsEquals(models.StatusMedicalCare) {
+		return nil
+	}
+
+	return s.updatePetStatus(pet, appointment.PreviousPetStatus)
+}
+
+// updatePetStatus changes the status of a pet on behalf of its appointments
+func (s *AppointmentService) updatePetStatus(pet *models.Pet, status models.Status) error {
+	previous := pet.Status
+	pet.Status = status
+	pet.Updated = time.Now().Format(time.RFC3339)
+
+	if err := s.pets.Update(pet); err != nil {
+		return err
+	}
+
+	audit.RecordSystem("pet.status_update", "pet", pet.ID,
+		map[string]any{"status": previous}, map[string]any{"status": status})
+
+	return nil
+}
+
+// checkVet checks that appointments of the pets of a shelter can be assigned to a user
+func (s *AppointmentService) checkVet(userID, shelterID string) error {
+	isVet, err := s.vets.IsVet(userID, shelterID)
+	if err != nil {
+		return err
+	}
+
+	if !isVet {
+		return models.ErrNotVet
+	}
+
+	return nil
+}
+
+// findAppointment finds an appointment of the pets of the shelters in scope, returning
+// ErrAppointmentNotFound if there is none
+func (s *AppointmentService) findAppointment(id string, scope tenant.Scope) (*models.Appointment, error) {
+	appointment, err := s.appointments.FindByID(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if appointment == nil {
+		return nil, models.ErrAppointmentNotFound
+	}
+
+	return appointment, nil
+}
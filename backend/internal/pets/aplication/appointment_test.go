@@ -0,0 +1,308 @@
+package aplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/pets/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// fakePetRepository keeps pets in memory
+type fakePetRepository struct {
+	pets map[string]*models.Pet
+}
+
+func newFakePetRepository(pets ...*models.Pet) *fakePetRepository {
+	repository := &fakePetRepository{pets: map[string]*models.Pet{}}
+	for _, pet := range pets {
+		repository.pets[pet.ID] = pet
+	}
+	return repository
+}
+
+func (r *fakePetRepository) Save(pet *models.Pet) error {
+	r.pets[pet.ID] = pet
+	return nil
+}
+
+func (r *fakePetRepository) FindByID(id string, scope tenant.Scope) (*models.Pet, error) {
+	pet, ok := r.pets[id]
+	if !ok || !scope.Allows(pet.ShelterID) {
+		return nil, nil
+	}
+	copied := *pet
+	return &copied, nil
+}
+
+func (r *fakePetRepository) FindByStatus(status models.Status, scope tenant.Scope) ([]*models.Pet, error) {
+	return nil, nil
+}
+
+func (r *fakePetRepository) FindAll(scope tenant.Scope) ([]*models.Pet, error) {
+	return nil, nil
+}
+
+func (r *fakePetRepository) Update(pet *models.Pet) error {
+	r.pets[pet.ID] = pet
+	return nil
+}
+
+func (r *fakePetRepository) Delete(id string, scope tenant.Scope) error {
+	delete(r.pets, id)
+	return nil
+}
+
+// fakeAppointmentRepository keeps appointments in memory, refusing overlapping ones like the
+// database does
+type fakeAppointmentRepository struct {
+	appointments map[string]*models.Appointment
+}
+
+func (r *fakeAppointmentRepository) Save(appointment *models.Appointment) error {
+	if err := r.checkAvailability(appointment); err != nil {
+		return err
+	}
+	r.appointments[appointment.ID] = appointment
+	return nil
+}
+
+func (r *fakeAppointmentRepository) FindByID(id string, scope tenant.Scope) (*models.Appointment, error) {
+	return r.appointments[id], nil
+}
+
+func (r *fakeAppointmentRepository) Find(filter models.AppointmentFilter, scope tenant.Scope) ([]*models.Appointment, error) {
+	return nil, nil
+}
+
+func (r *fakeAppointmentRepository) FindCareDue(now time.Time) ([]*models.Appointment, error) {
+	var due []*models.Appointment
+	for _, appointment := range r.appointments {
+		if appointment.Status == models.AppointmentScheduled && appointment.Started(now) &&
+			appointment.Procedure.NeedsCare() && appointment.CareStartedAt == nil {
+			due = append(due, appointment)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeAppointmentRepository) FindRecovered(now time.Time) ([]*models.Appointment, error) {
+	var recovered []*models.Appointment
+	for _, appointment := range r.appointments {
+		if appointment.InCare() && (appointment.Status == models.AppointmentMissed ||
+			(appointment.CareEndsAt != nil && !now.Before(*appointment.CareEndsAt))) {
+			recovered = append(recovered, appointment)
+		}
+	}
+	return recovered, nil
+}
+
+func (r *fakeAppointmentRepository) FindInCare(petID string) ([]*models.Appointment, error) {
+	var inCare []*models.Appointment
+	for _, appointment := range r.appointments {
+		if appointment.PetID == petID && appointment.InCare() {
+			inCare = append(inCare, appointment)
+		}
+	}
+	return inCare, nil
+}
+
+func (r *fakeAppointmentRepository) Reschedule(appointment *models.Appointment) error {
+	return r.Save(appointment)
+}
+
+func (r *fakeAppointmentRepository) Update(appointment *models.Appointment) error {
+	r.appointments[appointment.ID] = appointment
+	return nil
+}
+
+func (r *fakeAppointmentRepository) Delete(id string) error {
+	delete(r.appointments, id)
+	return nil
+}
+
+// checkAvailability refuses an appointment overlapping another one of the same vet or pet that
+// wasn't missed
+func (r *fakeAppointmentRepository) checkAvailability(appointment *models.Appointment) error {
+	for _, other := range r.appointments {
+		if other.ID == appointment.ID || other.Status == models.AppointmentMissed ||
+			!other.StartsAt.Before(appointment.EndsAt) || !other.EndsAt.After(appointment.StartsAt) {
+			continue
+		}
+
+		if other.VetID == appointment.VetID {
+			return models.ErrVetUnavailable
+		}
+
+		if other.PetID == appointment.PetID {
+			return models.ErrPetUnavailable
+		}
+	}
+	return nil
+}
+
+// fakeVetDirectory knows the vets of each shelter
+type fakeVetDirectory map[string]string
+
+func (d fakeVetDirectory) IsVet(userID, shelterID string) (bool, error) {
+	return d[userID] == shelterID, nil
+}
+
+func newTestAppointmentService(pets ...*models.Pet) (*AppointmentService, *fakeAppointmentRepository, *fakePetRepository) {
+	appointments := &fakeAppointmentRepository{appointments: map[string]*models.Appointment{}}
+	petRepository := newFakePetRepository(pets...)
+	vets := fakeVetDirectory{"vet-1": "shelter-1", "vet-2": "shelter-1", "vet-3": "shelter-2"}
+
+	return NewAppointmentService(appointments, petRepository, vets), appointments, petRepository
+}
+
+func TestScheduleAppointmentChecks(t *testing.T) {
+	service, _, _ := newTestAppointmentService(
+		&models.Pet{ID: "pet-1", ShelterID: "shelter-1", Status: models.StatusAvailable},
+		&models.Pet{ID: "pet-2", ShelterID: "shelter-1", Status: models.StatusAdopted},
+	)
+	startsAt := time.Now().Add(time.Hour)
+	scope := tenant.Shelter("shelter-1")
+
+	tests := []struct {
+		name    string
+		petID   string
+		vetID   string
+		scope   tenant.Scope
+		wantErr error
+	}{
+		{name: "Adopted pet", petID: "pet-2", vetID: "vet-1", scope: scope, wantErr: models.ErrPetAdopted},
+		{name: "Vet of another shelter", petID: "pet-1", vetID: "vet-3", scope: scope, wantErr: models.ErrNotVet},
+		{name: "Pet of another shelter", petID: "pet-1", vetID: "vet-1", scope: tenant.Shelter("shelter-2"), wantErr: models.ErrPetNotFound},
+		{name: "Valid", petID: "pet-1", vetID: "vet-1", scope: scope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ScheduleAppointment(tt.petID, tt.vetID, models.ProcedureCheckup, startsAt, startsAt.Add(time.Hour), "", "staff-1", tt.scope)
+			if err != tt.wantErr {
+				t.Errorf("ScheduleAppointment() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleAppointmentOverlap(t *testing.T) {
+	service, appointments, _ := newTestAppointmentService(
+		&models.Pet{ID: "pet-1", ShelterID: "shelter-1", Status: models.StatusAvailable},
+		&models.Pet{ID: "pet-2", ShelterID: "shelter-1", Status: models.StatusAvailable},
+	)
+	scope := tenant.Shelter("shelter-1")
+	startsAt := time.Now().Add(time.Hour).Truncate(time.Minute)
+	endsAt := startsAt.Add(time.Hour)
+
+	first, err := service.ScheduleAppointment("pet-1", "vet-1", models.ProcedureCheckup, startsAt, endsAt, "", "staff-1", scope)
+	if err != nil {
+		t.Fatalf("ScheduleAppointment() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		petID    string
+		vetID    string
+		startsAt time.Time
+		endsAt   time.Time
+		wantErr  error
+	}{
+		{name: "Same vet, overlapping", petID: "pet-2", vetID: "vet-1", startsAt: startsAt.Add(time.Minute * 30), endsAt: endsAt.Add(time.Minute * 30), wantErr: models.ErrVetUnavailable},
+		{name: "Same pet, overlapping", petID: "pet-1", vetID: "vet-2", startsAt: startsAt.Add(-time.Minute * 30), endsAt: startsAt.Add(time.Minute), wantErr: models.ErrPetUnavailable},
+		{name: "Same vet, right after", petID: "pet-2", vetID: "vet-1", startsAt: endsAt, endsAt: endsAt.Add(time.Hour)},
+		{name: "Same pet, right before", petID: "pet-1", vetID: "vet-2", startsAt: startsAt.Add(-time.Minute * 30), endsAt: startsAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ScheduleAppointment(tt.petID, tt.vetID, models.ProcedureCheckup, tt.startsAt, tt.endsAt, "", "staff-1", scope)
+			if err != tt.wantErr {
+				t.Errorf("ScheduleAppointment() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// A missed appointment frees its slot
+	appointments.appointments[first.ID].Status = models.AppointmentMissed
+
+	if _, err := service.ScheduleAppointment("pet-2", "vet-1", models.ProcedureCheckup, startsAt.Add(time.Minute*10), startsAt.Add(time.Minute*20), "", "staff-1", scope); err != nil {
+		t.Errorf("ScheduleAppointment() in a missed slot error = %v", err)
+	}
+}
+
+func TestUpdatePetCare(t *testing.T) {
+	service, appointments, pets := newTestAppointmentService(
+		&models.Pet{ID: "pet-1", ShelterID: "shelter-1", Status: models.StatusAvailable},
+	)
+	now := time.Now()
+
+	appointments.appointments["appointment-1"] = &models.Appointment{
+		ID:        "appointment-1",
+		PetID:     "pet-1",
+		VetID:     "vet-1",
+		Procedure: models.ProcedureSurgery,
+		StartsAt:  now.Add(-time.Minute),
+		EndsAt:    now.Add(time.Hour),
+		Status:    models.AppointmentScheduled,
+	}
+
+	if err := service.UpdatePetCare(now); err != nil {
+		t.Fatalf("UpdatePetCare() error = %v", err)
+	}
+
+	if got := pets.pets["pet-1"].Status; got != models.StatusMedicalCare {
+		t.Fatalf("pet status once the surgery started = %q, want %q", got, models.StatusMedicalCare)
+	}
+
+	appointment, err := service.UpdateAppointmentStatus("appointment-1", models.AppointmentDone, tenant.Shelter("shelter-1"))
+	if err != nil {
+		t.Fatalf("UpdateAppointmentStatus() error = %v", err)
+	}
+
+	if err := service.UpdatePetCare(now.Add(time.Hour * 24)); err != nil {
+		t.Fatalf("UpdatePetCare() error = %v", err)
+	}
+
+	if got := pets.pets["pet-1"].Status; got != models.StatusMedicalCare {
+		t.Errorf("pet status while recovering = %q, want %q", got, models.StatusMedicalCare)
+	}
+
+	if err := service.UpdatePetCare(appointment.CareEndsAt.Add(time.Minute)); err != nil {
+		t.Fatalf("UpdatePetCare() error = %v", err)
+	}
+
+	if got := pets.pets["pet-1"].Status; got != models.StatusAvailable {
+		t.Errorf("pet status once recovered = %q, want %q", got, models.StatusAvailable)
+	}
+}
+
+func TestMissedAppointmentEndsCare(t *testing.T) {
+	service, appointments, pets := newTestAppointmentService(
+		&models.Pet{ID: "pet-1", ShelterID: "shelter-1", Status: models.StatusQuarantined},
+	)
+	now := time.Now()
+
+	appointments.appointments["appointment-1"] = &models.Appointment{
+		ID:        "appointment-1",
+		PetID:     "pet-1",
+		VetID:     "vet-1",
+		Procedure: models.ProcedureSpayNeuter,
+		StartsAt:  now.Add(-time.Minute),
+		EndsAt:    now.Add(time.Hour),
+		Status:    models.AppointmentScheduled,
+	}
+
+	if err := service.UpdatePetCare(now); err != nil {
+		t.Fatalf("UpdatePetCare() error = %v", err)
+	}
+
+	if _, err := service.UpdateAppointmentStatus("appointment-1", models.AppointmentMissed, tenant.Shelter("shelter-1")); err != nil {
+		t.Fatalf("UpdateAppointmentStatus() error = %v", err)
+	}
+
+	if got := pets.pets["pet-1"].Status; got != models.StatusQuarantined {
+		t.Errorf("pet status after a missed appointment = %q, want %q", got, models.StatusQuarantined)
+	}
+}
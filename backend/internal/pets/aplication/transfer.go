@@ -0,0 +1,182 @@
+package aplication
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/pets/domain/models"
+	"github.com/solrac97gr/petparadise/internal/pets/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// TransferService implements the TransferService interface. The sending shelter requests and
+// cancels transfers; the receiving shelter approves, rejects and completes them.
+type TransferService struct {
+	transfers ports.TransferRepository
+	pets      ports.PetRepository
+}
+
+// NewTransferService creates a new TransferService instance
+func NewTransferService(transfers ports.TransferRepository, pets ports.PetRepository) *TransferService {
+	return &TransferService{
+		transfers: transfers,
+		pets:      pets,
+	}
+}
+
+// RequestTransfer asks another shelter to take in a pet of the shelters in scope on the transport date
+func (s *TransferService) RequestTransfer(petID, toShelterID string, reason models.TransferReason, notes string, transportDate time.Time, requestedBy string, scope tenant.Scope) (*models.Transfer, error) {
+	pet, err := s.pets.FindByID(petID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if pet == nil {
+		return nil, models.ErrPetNotFound
+	}
+
+	transfer, err := models.NewTransfer(uuid.New().String(), pet, toShelterID, reason, notes, transportDate, requestedBy, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.transfers.Save(transfer); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// GetTransferByID returns a transfer leaving or arriving at the shelters in scope by its ID
+func (s *TransferService) GetTransferByID(id string, scope tenant.Scope) (*models.Transfer, error) {
+	return s.transfers.FindByID(id, scope)
+}
+
+// GetTransfers returns the transfers leaving or arriving at the shelters in scope matching the
+// filter, latest first
+func (s *TransferService) GetTransfers(filter models.TransferFilter, scope tenant.Scope) ([]*models.Transfer, error) {
+	return s.transfers.Find(filter, scope)
+}
+
+// GetPetTransfers returns the full transfer history of a pet of the shelters in scope, oldest
+// first, including the transfers between shelters it belonged to before
+func (s *TransferService) GetPetTransfers(petID string, scope tenant.Scope) ([]*models.Transfer, error) {
+	pet, err := s.pets.FindByID(petID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if pet == nil {
+		return nil, models.ErrPetNotFound
+	}
+
+	return s.transfers.FindByPetID(petID)
+}
+
+// ApproveTransfer accepts a transfer arriving at the shelters in scope, optionally moving its transport date
+func (s *TransferService) ApproveTransfer(id string, transportDate *time.Time, reviewedBy string, scope tenant.Scope) (*models.Transfer, error) {
+	transfer, err := s.findIncoming(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := transfer.Status
+	if err := transfer.Approve(reviewedBy, transportDate, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.transfers.Update(transfer, previous); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// RejectTransfer turns down a transfer arriving at the shelters in scope
+func (s *TransferService) RejectTransfer(id, reviewedBy string, scope tenant.Scope) (*models.Transfer, error) {
+	transfer, err := s.findIncoming(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := transfer.Status
+	if err := transfer.Reject(reviewedBy, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.transfers.Update(transfer, previous); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// CancelTransfer withdraws a transfer leaving the shelters in scope that hasn't been completed
+func (s *TransferService) CancelTransfer(id string, scope tenant.Scope) (*models.Transfer, error) {
+	transfer, err := s.findTransfer(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if !scope.Allows(transfer.FromShelterID) {
+		return nil, models.ErrNotSendingShelter
+	}
+
+	previous := transfer.Status
+	if err := transfer.Cancel(time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.transfers.Update(transfer, previous); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// CompleteTransfer records that the pet of an approved transfer arrived at the shelters in scope,
+// making it belong to the receiving shelter
+func (s *TransferService) CompleteTransfer(id, completedBy string, scope tenant.Scope) (*models.Transfer, error) {
+	transfer, err := s.findIncoming(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := transfer.Complete(completedBy, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.transfers.Complete(transfer); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// findTransfer returns a transfer leaving or arriving at the shelters in scope that exists
+func (s *TransferService) findTransfer(id string, scope tenant.Scope) (*models.Transfer, error) {
+	transfer, err := s.transfers.FindByID(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer == nil {
+		return nil, models.ErrTransferNotFound
+	}
+
+	return transfer, nil
+}
+
+// findIncoming returns a transfer arriving at the shelters in scope that exists
+func (s *TransferService) findIncoming(id string, scope tenant.Scope) (*models.Transfer, error) {
+	transfer, err := s.findTransfer(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if !scope.Allows(transfer.ToShelterID) {
+		return nil, models.ErrNotReceivingShelter
+	}
+
+	return transfer, nil
+}
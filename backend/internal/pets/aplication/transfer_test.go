@@ -0,0 +1,138 @@
+package aplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/pets/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// fakeTransferRepository keeps transfers in memory, refusing updates of transfers whose status
+// changed like the database does
+type fakeTransferRepository struct {
+	transfers map[string]models.Transfer
+	pets      *fakePetRepository
+}
+
+func (r *fakeTransferRepository) Save(transfer *models.Transfer) error {
+	for _, other := range r.transfers {
+		if other.PetID == transfer.PetID && other.IsOpen() {
+			return models.ErrTransferPending
+		}
+	}
+	r.transfers[transfer.ID] = *transfer
+	return nil
+}
+
+func (r *fakeTransferRepository) FindByID(id string, scope tenant.Scope) (*models.Transfer, error) {
+	transfer, ok := r.transfers[id]
+	if !ok || !(scope.Allows(transfer.FromShelterID) || scope.Allows(transfer.ToShelterID)) {
+		return nil, nil
+	}
+	return &transfer, nil
+}
+
+func (r *fakeTransferRepository) Find(filter models.TransferFilter, scope tenant.Scope) ([]*models.Transfer, error) {
+	return nil, nil
+}
+
+func (r *fakeTransferRepository) FindByPetID(petID string) ([]*models.Transfer, error) {
+	return nil, nil
+}
+
+func (r *fakeTransferRepository) Update(transfer *models.Transfer, previous models.TransferStatus) error {
+	if r.transfers[transfer.ID].Status != previous {
+		return models.ErrTransferChanged
+	}
+	r.transfers[transfer.ID] = *transfer
+	return nil
+}
+
+func (r *fakeTransferRepository) Complete(transfer *models.Transfer) error {
+	if r.transfers[transfer.ID].Status != models.TransferApproved {
+		return models.ErrTransferChanged
+	}
+	r.transfers[transfer.ID] = *transfer
+	r.pets.pets[transfer.PetID].ShelterID = transfer.ToShelterID
+	return nil
+}
+
+func newTestTransferService() (*TransferService, *fakeTransferRepository, *fakePetRepository) {
+	pets := newFakePetRepository(&models.Pet{ID: "pet-1", ShelterID: "shelter-1", Status: models.StatusAvailable})
+	transfers := &fakeTransferRepository{transfers: map[string]models.Transfer{}, pets: pets}
+
+	return NewTransferService(transfers, pets), transfers, pets
+}
+
+func TestTransferShelters(t *testing.T) {
+	service, _, pets := newTestTransferService()
+	sending, receiving := tenant.Shelter("shelter-1"), tenant.Shelter("shelter-2")
+	today := time.Now().UTC().Truncate(time.Hour * 24)
+
+	if _, err := service.RequestTransfer("pet-1", "shelter-2", models.TransferReasonCapacity, "", today, "staff-1", receiving); err != models.ErrPetNotFound {
+		t.Errorf("RequestTransfer() by the receiving shelter error = %v, want %v", err, models.ErrPetNotFound)
+	}
+
+	transfer, err := service.RequestTransfer("pet-1", "shelter-2", models.TransferReasonCapacity, "", today, "staff-1", sending)
+	if err != nil {
+		t.Fatalf("RequestTransfer() error = %v", err)
+	}
+
+	if _, err := service.RequestTransfer("pet-1", "shelter-3", models.TransferReasonOther, "", today, "staff-1", sending); err != models.ErrTransferPending {
+		t.Errorf("RequestTransfer() twice error = %v, want %v", err, models.ErrTransferPending)
+	}
+
+	if _, err := service.ApproveTransfer(transfer.ID, nil, "staff-1", sending); err != models.ErrNotReceivingShelter {
+		t.Errorf("ApproveTransfer() by the sending shelter error = %v, want %v", err, models.ErrNotReceivingShelter)
+	}
+
+	if _, err := service.CancelTransfer(transfer.ID, receiving); err != models.ErrNotSendingShelter {
+		t.Errorf("CancelTransfer() by the receiving shelter error = %v, want %v", err, models.ErrNotSendingShelter)
+	}
+
+	if _, err := service.ApproveTransfer(transfer.ID, nil, "staff-2", receiving); err != nil {
+		t.Fatalf("ApproveTransfer() error = %v", err)
+	}
+
+	if _, err := service.CompleteTransfer(transfer.ID, "staff-2", receiving); err != nil {
+		t.Fatalf("CompleteTransfer() error = %v", err)
+	}
+
+	if got := pets.pets["pet-1"].ShelterID; got != "shelter-2" {
+		t.Errorf("pet shelter once transferred = %q, want %q", got, "shelter-2")
+	}
+}
+
+func TestTransferChangedMeanwhile(t *testing.T) {
+	service, transfers, _ := newTestTransferService()
+	sending, receiving := tenant.Shelter("shelter-1"), tenant.Shelter("shelter-2")
+
+	transfer, err := service.RequestTransfer("pet-1", "shelter-2", models.TransferReasonCapacity, "", time.Now(), "staff-1", sending)
+	if err != nil {
+		t.Fatalf("RequestTransfer() error = %v", err)
+	}
+
+	// The receiving shelter reads the transfer, then the sending shelter cancels it before the
+	// approval is stored
+	stale := transfers.transfers[transfer.ID]
+	if _, err := service.CancelTransfer(transfer.ID, sending); err != nil {
+		t.Fatalf("CancelTransfer() error = %v", err)
+	}
+
+	if err := stale.Approve("staff-2", nil, time.Now()); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+
+	if err := transfers.Update(&stale, models.TransferRequested); err != models.ErrTransferChanged {
+		t.Errorf("Update() of a cancelled transfer error = %v, want %v", err, models.ErrTransferChanged)
+	}
+
+	if _, err := service.ApproveTransfer(transfer.ID, nil, "staff-2", receiving); err != models.ErrTransferNotRequested {
+		t.Errorf("ApproveTransfer() of a cancelled transfer error = %v, want %v", err, models.ErrTransferNotRequested)
+	}
+
+	if got := transfers.transfers[transfer.ID].Status; got != models.TransferCancelled {
+		t.Errorf("transfer status = %q, want %q", got, models.TransferCancelled)
+	}
+}
@@ -0,0 +1,226 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrPetNotFound              = errors.New("pet not found")
+	ErrPetAdopted               = errors.New("appointments can't be scheduled for adopted pets")
+	ErrAppointmentNotFound      = errors.New("appointment not found")
+	ErrInvalidProcedure         = errors.New("invalid procedure")
+	ErrInvalidAppointmentTime   = errors.New("an appointment must start in the future, end after it starts and last at most 12 hours")
+	ErrInvalidAppointmentNotes  = errors.New("notes must be at most 1000 characters")
+	ErrInvalidAppointmentStatus = errors.New("status must be done or missed")
+	ErrNotVet                   = errors.New("appointments can only be assigned to an active vet of the pet's shelter")
+	ErrVetUnavailable           = errors.New("the vet has another appointment at that time")
+	ErrPetUnavailable           = errors.New("the pet has another appointment at that time")
+	ErrAppointmentStarted       = errors.New("an appointment that has started can't be changed or cancelled")
+	ErrAppointmentNotStarted    = errors.New("an appointment can't be marked done or missed before it starts")
+	ErrAppointmentClosed        = errors.New("the appointment has already been marked done or missed")
+)
+
+const (
+	// MaxAppointmentLength is the longest an appointment can last
+	MaxAppointmentLength = time.Hour * 12
+	// AppointmentCareInterval is how often pets are moved in and out of medical care
+	AppointmentCareInterval = time.Minute
+	maxNotesLength          = 1000
+)
+
+// Procedure is what a vet does during an appointment
+type Procedure string
+
+const (
+	ProcedureSpayNeuter  Procedure = "spay_neuter"
+	ProcedureSurgery     Procedure = "surgery"
+	ProcedureVaccination Procedure = "vaccination"
+	ProcedureCheckup     Procedure = "checkup"
+)
+
+// recoveryPeriods are how long a pet stays in medical care after each procedure that needs it.
+// The other procedures leave the pet's status alone.
+var recoveryPeriods = map[Procedure]time.Duration{
+	ProcedureSpayNeuter:  time.Hour * 24 * 10,
+	ProcedureSurgery:     time.Hour * 24 * 14,
+	ProcedureVaccination: 0,
+	ProcedureCheckup:     0,
+}
+
+// String converts the Procedure to a string
+func (p Procedure) String() string {
+	return string(p)
+}
+
+// IsValid checks if the procedure is valid
+func (p Procedure) IsValid() bool {
+	_, ok := recoveryPeriods[p]
+	return ok
+}
+
+// NeedsCare checks if the pet is in medical care from the start of the procedure until it recovers
+func (p Procedure) NeedsCare() bool {
+	return recoveryPeriods[p] > 0
+}
+
+// RecoveryPeriod returns how long the pet stays in medical care once the procedure is done
+func (p Procedure) RecoveryPeriod() time.Duration {
+	return recoveryPeriods[p]
+}
+
+// CareProcedures returns the procedures that put the pet in medical care
+func CareProcedures() []Procedure {
+	var procedures []Procedure
+	for procedure := range recoveryPeriods {
+		if procedure.NeedsCare() {
+			procedures = append(procedures, procedure)
+		}
+	}
+	return procedures
+}
+
+// AppointmentStatus is where an appointment stands
+type AppointmentStatus string
+
+const (
+	AppointmentScheduled AppointmentStatus = "scheduled"
+	AppointmentDone      AppointmentStatus = "done"
+	AppointmentMissed    AppointmentStatus = "missed"
+)
+
+// String converts the AppointmentStatus to a string
+func (s AppointmentStatus) String() string {
+	return string(s)
+}
+
+// Appointment is a procedure a vet performs on a pet. Procedures that need care move the pet to
+// medical care while they take place and the pet recovers: CareStartedAt is when the pet was
+// moved, CareEndsAt when it will have recovered and CareEndedAt when it was moved back.
+// PreviousPetStatus is the status the pet goes back to, empty if it is to be left alone.
+type Appointment struct {
+	ID                string            `json:"id"`
+	PetID             string            `json:"pet_id"`
+	VetID             string            `json:"vet_id"`
+	Procedure         Procedure         `json:"procedure"`
+	StartsAt          time.Time         `json:"starts_at"`
+	EndsAt            time.Time         `json:"ends_at"`
+	Notes             string            `json:"notes"`
+	Status            AppointmentStatus `json:"status"`
+	CareStartedAt     *time.Time        `json:"care_started_at,omitempty"`
+	CareEndsAt        *time.Time        `json:"care_ends_at,omitempty"`
+	CareEndedAt       *time.Time        `json:"care_ended_at,omitempty"`
+	PreviousPetStatus Status            `json:"previous_pet_status,omitempty"`
+	CreatedBy         string            `json:"created_by"`
+	Created           time.Time         `json:"created"`
+	Updated           time.Time         `json:"updated"`
+}
+
+// NewAppointment creates a new Appointment instance
+func NewAppointment(id, petID, vetID string, procedure Procedure, startsAt, endsAt time.Time, notes, createdBy string, now time.Time) (*Appointment, error) {
+	appointment := &Appointment{
+		ID:        id,
+		PetID:     petID,
+		Status:    AppointmentScheduled,
+		CreatedBy: createdBy,
+		Created:   now,
+	}
+
+	if err := appointment.Reschedule(vetID, procedure, startsAt, endsAt, notes, now); err != nil {
+		return nil, err
+	}
+
+	return appointment, nil
+}
+
+// Reschedule changes the vet, procedure, time and notes of an appointment that hasn't started
+func (a *Appointment) Reschedule(vetID string, procedure Procedure, startsAt, endsAt time.Time, notes string, now time.Time) error {
+	if a.Status != AppointmentScheduled {
+		return ErrAppointmentClosed
+	}
+
+	if a.Started(now) {
+		return ErrAppointmentStarted
+	}
+
+	if !procedure.IsValid() {
+		return ErrInvalidProcedure
+	}
+
+	if !startsAt.After(now) || !endsAt.After(startsAt) || endsAt.Sub(startsAt) > MaxAppointmentLength {
+		return ErrInvalidAppointmentTime
+	}
+
+	notes = strings.TrimSpace(notes)
+	if utf8.RuneCountInString(notes) > maxNotesLength {
+		return ErrInvalidAppointmentNotes
+	}
+
+	a.VetID = vetID
+	a.Procedure = procedure
+	a.StartsAt = startsAt
+	a.EndsAt = endsAt
+	a.Notes = notes
+	a.Updated = now
+
+	return nil
+}
+
+// Started checks if the appointment has started. A new appointment hasn't.
+func (a *Appointment) Started(now time.Time) bool {
+	return !a.StartsAt.IsZero() && !now.Before(a.StartsAt)
+}
+
+// Close marks a started appointment done or missed. Once done, a procedure that needs care
+// keeps the pet in medical care for its recovery period.
+func (a *Appointment) Close(status AppointmentStatus, now time.Time) error {
+	if status != AppointmentDone && status != AppointmentMissed {
+		return ErrInvalidAppointmentStatus
+	}
+
+	if a.Status != AppointmentScheduled {
+		return ErrAppointmentClosed
+	}
+
+	if !a.Started(now) {
+		return ErrAppointmentNotStarted
+	}
+
+	a.Status = status
+	a.Updated = now
+
+	if status == AppointmentDone && a.Procedure.NeedsCare() {
+		recovered := now.Add(a.Procedure.RecoveryPeriod())
+		a.CareEndsAt = &recovered
+	}
+
+	return nil
+}
+
+// InCare checks if the appointment is keeping the pet in medical care
+func (a *Appointment) InCare() bool {
+	return a.CareStartedAt != nil && a.CareEndedAt == nil
+}
+
+// StartCare records that the pet was moved to medical care, from the given status
+func (a *Appointment) StartCare(previousPetStatus Status, now time.Time) {
+	a.CareStartedAt = &now
+	a.PreviousPetStatus = previousPetStatus
+	a.Updated = now
+}
+
+// EndCare records that the appointment no longer keeps the pet in medical care
+func (a *Appointment) EndCare(now time.Time) {
+	a.CareEndedAt = &now
+	a.Updated = now
+}
+
+// AppointmentFilter narrows down the appointments starting in [From, To). Empty IDs match any.
+type AppointmentFilter struct {
+	PetID string
+	VetID string
+	From  time.Time
+	To    time.Time
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewAppointment(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	startsAt := now.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		procedure Procedure
+		startsAt  time.Time
+		endsAt    time.Time
+		notes     string
+		wantErr   error
+	}{
+		{name: "Valid", procedure: ProcedureCheckup, startsAt: startsAt, endsAt: startsAt.Add(time.Minute * 30)},
+		{name: "Longest", procedure: ProcedureSurgery, startsAt: startsAt, endsAt: startsAt.Add(MaxAppointmentLength)},
+		{name: "Unknown procedure", procedure: "grooming", startsAt: startsAt, endsAt: startsAt.Add(time.Hour), wantErr: ErrInvalidProcedure},
+		{name: "In the past", procedure: ProcedureCheckup, startsAt: now.Add(-time.Hour), endsAt: now.Add(time.Hour), wantErr: ErrInvalidAppointmentTime},
+		{name: "Starting now", procedure: ProcedureCheckup, startsAt: now, endsAt: now.Add(time.Hour), wantErr: ErrInvalidAppointmentTime},
+		{name: "Ends when it starts", procedure: ProcedureCheckup, startsAt: startsAt, endsAt: startsAt, wantErr: ErrInvalidAppointmentTime},
+		{name: "Too long", procedure: ProcedureSurgery, startsAt: startsAt, endsAt: startsAt.Add(MaxAppointmentLength + time.Minute), wantErr: ErrInvalidAppointmentTime},
+		{name: "Long notes", procedure: ProcedureCheckup, startsAt: startsAt, endsAt: startsAt.Add(time.Hour), notes: strings.Repeat("a", 1001), wantErr: ErrInvalidAppointmentNotes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appointment, err := NewAppointment("appointment-1", "pet-1", "vet-1", tt.procedure, tt.startsAt, tt.endsAt, tt.notes, "staff-1", now)
+			if err != tt.wantErr {
+				t.Fatalf("NewAppointment() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && appointment.Status != AppointmentScheduled {
+				t.Errorf("NewAppointment() status = %q, want %q", appointment.Status, AppointmentScheduled)
+			}
+		})
+	}
+}
+
+func TestAppointmentOnceStarted(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	appointment, err := NewAppointment("appointment-1", "pet-1", "vet-1", ProcedureSpayNeuter, now.Add(time.Hour), now.Add(time.Hour*2), "", "staff-1", now)
+	if err != nil {
+		t.Fatalf("NewAppointment() error = %v", err)
+	}
+
+	if err := appointment.Close(AppointmentDone, now); err != ErrAppointmentNotStarted {
+		t.Errorf("Close() before the start error = %v, want %v", err, ErrAppointmentNotStarted)
+	}
+
+	started := appointment.StartsAt
+	if !appointment.Started(started) {
+		t.Errorf("Started() at the start = false, want true")
+	}
+
+	if err := appointment.Reschedule("vet-2", ProcedureSpayNeuter, started.Add(time.Hour), started.Add(time.Hour*2), "", started); err != ErrAppointmentStarted {
+		t.Errorf("Reschedule() once started error = %v, want %v", err, ErrAppointmentStarted)
+	}
+
+	if err := appointment.Close(AppointmentScheduled, started); err != ErrInvalidAppointmentStatus {
+		t.Errorf("Close() as scheduled error = %v, want %v", err, ErrInvalidAppointmentStatus)
+	}
+
+	if err := appointment.Close(AppointmentDone, started); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if appointment.CareEndsAt == nil || !appointment.CareEndsAt.Equal(started.Add(ProcedureSpayNeuter.RecoveryPeriod())) {
+		t.Errorf("Close() care ends at = %v, want after the recovery period", appointment.CareEndsAt)
+	}
+
+	if err := appointment.Close(AppointmentMissed, started); err != ErrAppointmentClosed {
+		t.Errorf("Close() twice error = %v, want %v", err, ErrAppointmentClosed)
+	}
+}
+
+func TestAppointmentCare(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	for _, procedure := range []Procedure{ProcedureVaccination, ProcedureCheckup} {
+		if procedure.NeedsCare() {
+			t.Errorf("%s.NeedsCare() = true, want false", procedure)
+		}
+	}
+
+	if len(CareProcedures()) != 2 {
+		t.Errorf("CareProcedures() = %v, want spay_neuter and surgery", CareProcedures())
+	}
+
+	appointment := &Appointment{ID: "appointment-1", Procedure: ProcedureSurgery, Status: AppointmentScheduled}
+	appointment.StartCare(StatusAvailable, now)
+
+	if !appointment.InCare() || appointment.PreviousPetStatus != StatusAvailable {
+		t.Errorf("StartCare() = in care %v from %q, want in care from available", appointment.InCare(), appointment.PreviousPetStatus)
+	}
+
+	appointment.EndCare(now.Add(time.Hour))
+	if appointment.InCare() {
+		t.Errorf("EndCare() left the appointment in care")
+	}
+}
@@ -2,6 +2,7 @@ package models
 
 type Pet struct {
 	ID          string   `json:"id" db:"id"`
+	ShelterID   string   `json:"shelter_id" db:"shelter_id"`
 	Name        string   `json:"name" db:"name"`
 	Species     string   `json:"species" db:"species"`
 	Breed       string   `json:"breed" db:"breed"`
@@ -14,11 +15,15 @@ type Pet struct {
 }
 
 // NewPet creates a new Pet instance
-func NewPet(id, name, species, breed string, age int, description string, status Status, images []string) (*Pet, error) {
+func NewPet(id, shelterID, name, species, breed string, age int, description string, status Status, images []string) (*Pet, error) {
 	if !status.IsValid() {
 		return nil, ErrInvalidStatus
 	}
 
+	if shelterID == "" {
+		return nil, ErrShelterRequired
+	}
+
 	if name == "" {
 		return nil, ErrInvalidName
 	}
@@ -33,6 +38,7 @@ func NewPet(id, name, species, breed string, age int, description string, status
 
 	return &Pet{
 		ID:          id,
+		ShelterID:   shelterID,
 		Name:        name,
 		Species:     species,
 		Breed:       breed,
@@ -0,0 +1,16 @@
+package models
+
+// Sponsorship summarizes the donations earmarked for a pet's care
+type Sponsorship struct {
+	TotalAmount   float64  `json:"total_amount"`
+	OneOffAmount  float64  `json:"one_off_amount"`
+	MonthlyAmount float64  `json:"monthly_amount"`
+	SponsorCount  int      `json:"sponsor_count"`
+	Sponsors      []string `json:"sponsors"`
+}
+
+// PetProfile is the public view of a pet including its sponsorship totals
+type PetProfile struct {
+	*Pet
+	Sponsorship *Sponsorship `json:"sponsorship,omitempty"`
+}
@@ -5,10 +5,12 @@ import "errors"
 type Status string
 
 var (
-	ErrInvalidStatus  = errors.New("invalid status")
-	ErrInvalidName    = errors.New("invalid name")
-	ErrInvalidSpecies = errors.New("invalid species")
-	ErrInvalidAge     = errors.New("invalid age")
+	ErrInvalidStatus   = errors.New("invalid status")
+	ErrInvalidName     = errors.New("invalid name")
+	ErrInvalidSpecies  = errors.New("invalid species")
+	ErrInvalidAge      = errors.New("invalid age")
+	ErrShelterRequired = errors.New("shelter ID is required")
+	ErrShelterNotFound = errors.New("shelter not found")
 )
 
 const (
@@ -0,0 +1,239 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrTransferNotFound      = errors.New("transfer not found")
+	ErrPetNotTransferable    = errors.New("adopted pets and pets being adopted can't be transferred")
+	ErrSameShelter           = errors.New("a pet can't be transferred to the shelter it belongs to")
+	ErrInvalidTransferReason = errors.New("reason must be capacity, medical or other")
+	ErrInvalidTransferNotes  = errors.New("notes must be at most 1000 characters")
+	ErrInvalidTransportDate  = errors.New("the transport date can't be in the past")
+	ErrTransferPending       = errors.New("the pet already has a transfer in progress")
+	ErrTransferNotRequested  = errors.New("only requested transfers can be approved or rejected")
+	ErrTransferNotApproved   = errors.New("only approved transfers can be completed")
+	ErrTransferClosed        = errors.New("the transfer has already been rejected, cancelled or completed")
+	ErrTransferNotDue        = errors.New("a transfer can't be completed before its transport date")
+	ErrNotReceivingShelter   = errors.New("only the receiving shelter can approve, reject or complete a transfer")
+	ErrNotSendingShelter     = errors.New("only the sending shelter can cancel a transfer")
+	ErrPetHasAppointments    = errors.New("the pet's appointments in progress must be marked done or missed before it moves")
+	ErrTransferChanged       = errors.New("the transfer was changed meanwhile, reload it and try again")
+)
+
+// TransferReason is why a pet moves to another shelter
+type TransferReason string
+
+const (
+	TransferReasonCapacity TransferReason = "capacity"
+	TransferReasonMedical  TransferReason = "medical"
+	TransferReasonOther    TransferReason = "other"
+)
+
+// String converts the TransferReason to a string
+func (r TransferReason) String() string {
+	return string(r)
+}
+
+// IsValid checks if the reason is valid
+func (r TransferReason) IsValid() bool {
+	switch r {
+	case TransferReasonCapacity, TransferReasonMedical, TransferReasonOther:
+		return true
+	}
+	return false
+}
+
+// TransferStatus is where a transfer stands
+type TransferStatus string
+
+const (
+	TransferRequested TransferStatus = "requested"
+	TransferApproved  TransferStatus = "approved"
+	TransferRejected  TransferStatus = "rejected"
+	TransferCancelled TransferStatus = "cancelled"
+	TransferCompleted TransferStatus = "completed"
+)
+
+// String converts the TransferStatus to a string
+func (s TransferStatus) String() string {
+	return string(s)
+}
+
+// IsValid checks if the status is valid
+func (s TransferStatus) IsValid() bool {
+	switch s {
+	case TransferRequested, TransferApproved, TransferRejected, TransferCancelled, TransferCompleted:
+		return true
+	}
+	return false
+}
+
+// Transfer moves a pet from the shelter it belongs to to another one. The sending shelter
+// requests it, the receiving shelter approves or rejects it and, once the pet has arrived on or
+// after the transport date, completes it, which makes the pet belong to the receiving shelter.
+// Transfers are never deleted, so they keep the history of the shelters a pet went through.
+type Transfer struct {
+	ID            string         `json:"id"`
+	PetID         string         `json:"pet_id"`
+	FromShelterID string         `json:"from_shelter_id"`
+	ToShelterID   string         `json:"to_shelter_id"`
+	Reason        TransferReason `json:"reason"`
+	Notes         string         `json:"notes"`
+	TransportDate time.Time      `json:"transport_date"`
+	Status        TransferStatus `json:"status"`
+	RequestedBy   string         `json:"requested_by"`
+	ReviewedBy    string         `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time     `json:"reviewed_at,omitempty"`
+	CompletedBy   string         `json:"completed_by,omitempty"`
+	CompletedAt   *time.Time     `json:"completed_at,omitempty"`
+	Created       time.Time      `json:"created"`
+	Updated       time.Time      `json:"updated"`
+
+	// Not stored, set when the transfer is completed so the records it changed can be audited
+	CancelledAppointments []string `json:"cancelled_appointments,omitempty"`
+	MovedAdoptions        []string `json:"moved_adoptions,omitempty"`
+	MovedDonations        []string `json:"moved_donations,omitempty"`
+}
+
+// NewTransfer creates a new Transfer instance requesting to move the pet to another shelter on
+// the transport date
+func NewTransfer(id string, pet *Pet, toShelterID string, reason TransferReason, notes string, transportDate time.Time, requestedBy string, now time.Time) (*Transfer, error) {
+	if pet.Status.IsEquals(StatusAdopted) || pet.Status.IsEquals(StatusInProcess) {
+		return nil, ErrPetNotTransferable
+	}
+
+	if toShelterID == "" {
+		return nil, ErrShelterRequired
+	}
+
+	if toShelterID == pet.ShelterID {
+		return nil, ErrSameShelter
+	}
+
+	if !reason.IsValid() {
+		return nil, ErrInvalidTransferReason
+	}
+
+	notes = strings.TrimSpace(notes)
+	if utf8.RuneCountInString(notes) > maxNotesLength {
+		return nil, ErrInvalidTransferNotes
+	}
+
+	if transportDate.Before(today(now)) {
+		return nil, ErrInvalidTransportDate
+	}
+
+	return &Transfer{
+		ID:            id,
+		PetID:         pet.ID,
+		FromShelterID: pet.ShelterID,
+		ToShelterID:   toShelterID,
+		Reason:        reason,
+		Notes:         notes,
+		TransportDate: transportDate,
+		Status:        TransferRequested,
+		RequestedBy:   requestedBy,
+		Created:       now,
+		Updated:       now,
+	}, nil
+}
+
+// IsOpen checks if the transfer is still to be approved or completed
+func (t *Transfer) IsOpen() bool {
+	return t.Status == TransferRequested || t.Status == TransferApproved
+}
+
+// Approve accepts a requested transfer, moving its transport date if one is given
+func (t *Transfer) Approve(reviewedBy string, transportDate *time.Time, now time.Time) error {
+	if t.Status != TransferRequested {
+		return ErrTransferNotRequested
+	}
+
+	if transportDate != nil {
+		if transportDate.Before(today(now)) {
+			return ErrInvalidTransportDate
+		}
+		t.TransportDate = *transportDate
+	}
+
+	t.review(TransferApproved, reviewedBy, now)
+	return nil
+}
+
+// Reject turns down a requested transfer
+func (t *Transfer) Reject(reviewedBy string, now time.Time) error {
+	if t.Status != TransferRequested {
+		return ErrTransferNotRequested
+	}
+
+	t.review(TransferRejected, reviewedBy, now)
+	return nil
+}
+
+// Cancel withdraws a transfer that hasn't been completed
+func (t *Transfer) Cancel(now time.Time) error {
+	if !t.IsOpen() {
+		return ErrTransferClosed
+	}
+
+	t.Status = TransferCancelled
+	t.Updated = now
+	return nil
+}
+
+// Complete records that the pet of an approved transfer arrived at the receiving shelter, on or
+// after the transport date
+func (t *Transfer) Complete(completedBy string, now time.Time) error {
+	if t.Status != TransferApproved {
+		return ErrTransferNotApproved
+	}
+
+	if today(now).Before(t.TransportDate) {
+		return ErrTransferNotDue
+	}
+
+	t.Status = TransferCompleted
+	t.CompletedBy = completedBy
+	t.CompletedAt = &now
+	t.Updated = now
+	return nil
+}
+
+// review records the decision of the receiving shelter
+func (t *Transfer) review(status TransferStatus, reviewedBy string, now time.Time) {
+	t.Status = status
+	t.ReviewedBy = reviewedBy
+	t.ReviewedAt = &now
+	t.Updated = now
+}
+
+// today returns the start of the UTC day of now, as transport dates are days in UTC
+func today(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// TransferDirection narrows down transfers to those leaving or arriving at the shelters in scope
+type TransferDirection string
+
+const (
+	TransferIncoming TransferDirection = "incoming"
+	TransferOutgoing TransferDirection = "outgoing"
+)
+
+// IsValid checks if the direction is valid. Empty matches both.
+func (d TransferDirection) IsValid() bool {
+	return d == "" || d == TransferIncoming || d == TransferOutgoing
+}
+
+// TransferFilter narrows down the transfers listed. Empty fields match any.
+type TransferFilter struct {
+	PetID     string
+	Status    TransferStatus
+	Direction TransferDirection
+}
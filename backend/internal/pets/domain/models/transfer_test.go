@@ -0,0 +1,108 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewTransfer(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 22, 0, 0, 0, time.UTC)
+	pet := &Pet{ID: "pet-1", ShelterID: "shelter-1", Status: StatusAvailable}
+
+	tests := []struct {
+		name          string
+		pet           *Pet
+		toShelterID   string
+		reason        TransferReason
+		transportDate time.Time
+		wantErr       error
+	}{
+		{name: "Valid", pet: pet, toShelterID: "shelter-2", reason: TransferReasonCapacity, transportDate: today(now).AddDate(0, 0, 2)},
+		{name: "Today", pet: pet, toShelterID: "shelter-2", reason: TransferReasonMedical, transportDate: today(now)},
+		{name: "Adopted pet", pet: &Pet{ID: "pet-2", ShelterID: "shelter-1", Status: StatusAdopted}, toShelterID: "shelter-2", reason: TransferReasonOther, transportDate: today(now), wantErr: ErrPetNotTransferable},
+		{name: "Pet being adopted", pet: &Pet{ID: "pet-3", ShelterID: "shelter-1", Status: StatusInProcess}, toShelterID: "shelter-2", reason: TransferReasonOther, transportDate: today(now), wantErr: ErrPetNotTransferable},
+		{name: "No receiving shelter", pet: pet, reason: TransferReasonOther, transportDate: today(now), wantErr: ErrShelterRequired},
+		{name: "Same shelter", pet: pet, toShelterID: "shelter-1", reason: TransferReasonOther, transportDate: today(now), wantErr: ErrSameShelter},
+		{name: "Unknown reason", pet: pet, toShelterID: "shelter-2", reason: "boredom", transportDate: today(now), wantErr: ErrInvalidTransferReason},
+		{name: "Yesterday", pet: pet, toShelterID: "shelter-2", reason: TransferReasonOther, transportDate: today(now).AddDate(0, 0, -1), wantErr: ErrInvalidTransportDate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transfer, err := NewTransfer("transfer-1", tt.pet, tt.toShelterID, tt.reason, "", tt.transportDate, "staff-1", now)
+			if err != tt.wantErr {
+				t.Fatalf("NewTransfer() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && (transfer.Status != TransferRequested || transfer.FromShelterID != "shelter-1") {
+				t.Errorf("NewTransfer() = %q from %q, want requested from shelter-1", transfer.Status, transfer.FromShelterID)
+			}
+		})
+	}
+}
+
+func TestTransferLifecycle(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	newTransfer := func() *Transfer {
+		return &Transfer{ID: "transfer-1", Status: TransferRequested, TransportDate: today(now).AddDate(0, 0, 1)}
+	}
+
+	t.Run("Approve moves the transport date", func(t *testing.T) {
+		transfer := newTransfer()
+		past := today(now).AddDate(0, 0, -1)
+		if err := transfer.Approve("staff-2", &past, now); err != ErrInvalidTransportDate {
+			t.Errorf("Approve() in the past error = %v, want %v", err, ErrInvalidTransportDate)
+		}
+
+		later := today(now).AddDate(0, 0, 3)
+		if err := transfer.Approve("staff-2", &later, now); err != nil {
+			t.Fatalf("Approve() error = %v", err)
+		}
+
+		if !transfer.TransportDate.Equal(later) || transfer.ReviewedBy != "staff-2" {
+			t.Errorf("Approve() = %v by %q, want %v by staff-2", transfer.TransportDate, transfer.ReviewedBy, later)
+		}
+
+		if err := transfer.Reject("staff-2", now); err != ErrTransferNotRequested {
+			t.Errorf("Reject() once approved error = %v, want %v", err, ErrTransferNotRequested)
+		}
+	})
+
+	t.Run("Complete on the transport date", func(t *testing.T) {
+		transfer := newTransfer()
+		if err := transfer.Complete("staff-2", now); err != ErrTransferNotApproved {
+			t.Errorf("Complete() before approval error = %v, want %v", err, ErrTransferNotApproved)
+		}
+
+		if err := transfer.Approve("staff-2", nil, now); err != nil {
+			t.Fatalf("Approve() error = %v", err)
+		}
+
+		if err := transfer.Complete("staff-2", now); err != ErrTransferNotDue {
+			t.Errorf("Complete() before the transport date error = %v, want %v", err, ErrTransferNotDue)
+		}
+
+		if err := transfer.Complete("staff-2", transfer.TransportDate); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+
+		if err := transfer.Cancel(now); err != ErrTransferClosed {
+			t.Errorf("Cancel() once completed error = %v, want %v", err, ErrTransferClosed)
+		}
+	})
+
+	t.Run("Cancel while open", func(t *testing.T) {
+		transfer := newTransfer()
+		if err := transfer.Cancel(now); err != nil {
+			t.Fatalf("Cancel() error = %v", err)
+		}
+
+		if transfer.IsOpen() {
+			t.Errorf("IsOpen() once cancelled = true, want false")
+		}
+
+		if err := transfer.Approve("staff-2", nil, now); err != ErrTransferNotRequested {
+			t.Errorf("Approve() once cancelled error = %v, want %v", err, ErrTransferNotRequested)
+		}
+	})
+}
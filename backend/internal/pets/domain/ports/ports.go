@@ -1,22 +1,98 @@
 package ports
 
-import "github.com/solrac97gr/petparadise/internal/pets/domain/models"
+import (
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/pets/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
 
 type PetRepository interface {
 	Save(pet *models.Pet) error
-	FindByID(id string) (*models.Pet, error)
-	FindByStatus(status models.Status) ([]*models.Pet, error)
-	FindAll() ([]*models.Pet, error)
+	FindByID(id string, scope tenant.Scope) (*models.Pet, error)
+	FindByStatus(status models.Status, scope tenant.Scope) ([]*models.Pet, error)
+	FindAll(scope tenant.Scope) ([]*models.Pet, error)
+	// Update updates a pet. Its shelter can't be changed.
 	Update(pet *models.Pet) error
+	Delete(id string, scope tenant.Scope) error
+}
+
+// SponsorshipGateway gives the pets module access to the donations earmarked for a pet
+type SponsorshipGateway interface {
+	GetSponsorship(petID string) (*models.Sponsorship, error)
+}
+
+// SponsorNotifier lets the sponsors of a pet know what happened to it
+type SponsorNotifier interface {
+	NotifySponsors(pet *models.Pet) error
+}
+
+type AppointmentRepository interface {
+	// Save saves an appointment unless its vet or pet has another one at the same time
+	Save(appointment *models.Appointment) error
+	// FindByID and Find look up the appointments of the pets of the shelters in scope
+	FindByID(id string, scope tenant.Scope) (*models.Appointment, error)
+	Find(filter models.AppointmentFilter, scope tenant.Scope) ([]*models.Appointment, error)
+	// FindCareDue finds the scheduled appointments that have started and should move their pet to medical care
+	FindCareDue(now time.Time) ([]*models.Appointment, error)
+	// FindRecovered finds the appointments keeping a pet in medical care whose recovery period is over
+	FindRecovered(now time.Time) ([]*models.Appointment, error)
+	// FindInCare finds the appointments keeping a pet in medical care, earliest first
+	FindInCare(petID string) ([]*models.Appointment, error)
+	// Reschedule updates the vet, procedure, time and notes of an appointment, with the same checks as Save
+	Reschedule(appointment *models.Appointment) error
+	Update(appointment *models.Appointment) error
 	Delete(id string) error
 }
 
+type TransferRepository interface {
+	// Save saves a transfer unless its pet has another one in progress
+	Save(transfer *models.Transfer) error
+	// FindByID and Find look up the transfers leaving or arriving at the shelters in scope
+	FindByID(id string, scope tenant.Scope) (*models.Transfer, error)
+	Find(filter models.TransferFilter, scope tenant.Scope) ([]*models.Transfer, error)
+	// FindByPetID finds every transfer of a pet, oldest first
+	FindByPetID(petID string) ([]*models.Transfer, error)
+	// Update updates a transfer that is still in the previous status
+	Update(transfer *models.Transfer, previous models.TransferStatus) error
+	// Complete marks an approved transfer completed and moves its pet to the receiving shelter
+	Complete(transfer *models.Transfer) error
+}
+
+// VetDirectory tells the pets module which users are vets
+type VetDirectory interface {
+	// IsVet checks if a user is an active vet working at the shelter
+	IsVet(userID, shelterID string) (bool, error)
+}
+
 type PetService interface {
-	CreatePet(name, species, breed string, age int, description string, images []string) (*models.Pet, error)
-	GetPetByID(id string) (*models.Pet, error)
-	GetPetsByStatus(status models.Status) ([]*models.Pet, error)
-	GetAllPets() ([]*models.Pet, error)
-	UpdatePet(id, name, species, breed string, age int, description string, status models.Status, images []string) (*models.Pet, error)
-	UpdatePetStatus(id string, status models.Status) (*models.Pet, error)
-	DeletePet(id string) error
+	CreatePet(shelterID, name, species, breed string, age int, description string, images []string) (*models.Pet, error)
+	GetPetByID(id string, scope tenant.Scope) (*models.Pet, error)
+	GetPetProfile(id string) (*models.PetProfile, error)
+	GetPetsByStatus(status models.Status, scope tenant.Scope) ([]*models.Pet, error)
+	GetAllPets(scope tenant.Scope) ([]*models.Pet, error)
+	UpdatePet(id, name, species, breed string, age int, description string, status models.Status, images []string, scope tenant.Scope) (*models.Pet, error)
+	UpdatePetStatus(id string, status models.Status, scope tenant.Scope) (*models.Pet, error)
+	DeletePet(id string, scope tenant.Scope) error
+}
+
+type AppointmentService interface {
+	ScheduleAppointment(petID, vetID string, procedure models.Procedure, startsAt, endsAt time.Time, notes, createdBy string, scope tenant.Scope) (*models.Appointment, error)
+	GetAppointmentByID(id string, scope tenant.Scope) (*models.Appointment, error)
+	GetAppointments(filter models.AppointmentFilter, scope tenant.Scope) ([]*models.Appointment, error)
+	RescheduleAppointment(id, vetID string, procedure models.Procedure, startsAt, endsAt time.Time, notes string, scope tenant.Scope) (*models.Appointment, error)
+	UpdateAppointmentStatus(id string, status models.AppointmentStatus, scope tenant.Scope) (*models.Appointment, error)
+	CancelAppointment(id string, scope tenant.Scope) error
+	StartCareScheduler(interval time.Duration) (stop func())
+}
+
+type TransferService interface {
+	RequestTransfer(petID, toShelterID string, reason models.TransferReason, notes string, transportDate time.Time, requestedBy string, scope tenant.Scope) (*models.Transfer, error)
+	GetTransferByID(id string, scope tenant.Scope) (*models.Transfer, error)
+	GetTransfers(filter models.TransferFilter, scope tenant.Scope) ([]*models.Transfer, error)
+	GetPetTransfers(petID string, scope tenant.Scope) ([]*models.Transfer, error)
+	ApproveTransfer(id string, transportDate *time.Time, reviewedBy string, scope tenant.Scope) (*models.Transfer, error)
+	RejectTransfer(id, reviewedBy string, scope tenant.Scope) (*models.Transfer, error)
+	CancelTransfer(id string, scope tenant.Scope) (*models.Transfer, error)
+	CompleteTransfer(id, completedBy string, scope tenant.Scope) (*models.Transfer, error)
 }
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/pets/domain/models"
+	"github.com/solrac97gr/petparadise/internal/pets/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/daterange"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// defaultAppointmentListDays is how many days of appointments are listed when no range is given
+const defaultAppointmentListDays = 28
+
+// appointmentRequest is the body of the requests scheduling and rescheduling appointments
+type appointmentRequest struct {
+	PetID     string           `json:"pet_id"`
+	VetID     string           `json:"vet_id"`
+	Procedure models.Procedure `json:"procedure"`
+	StartsAt  time.Time        `json:"starts_at"`
+	EndsAt    time.Time        `json:"ends_at"`
+	Notes     string           `json:"notes"`
+}
+
+type appointmentHandler struct {
+	service ports.AppointmentService
+}
+
+// NewAppointmentHandler creates a new appointment handler
+func NewAppointmentHandler(service ports.AppointmentService) AppointmentHandler {
+	return &appointmentHandler{
+		service: service,
+	}
+}
+
+// ScheduleAppointment handles scheduling a procedure on a pet with a vet
+func (h *appointmentHandler) ScheduleAppointment(c *fiber.Ctx) error {
+	var req appointmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.PetID == "" || req.VetID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Pet ID and vet ID are required",
+		})
+	}
+
+	createdBy, _ := c.Locals("userID").(string)
+
+	appointment, err := h.service.ScheduleAppointment(req.PetID, req.VetID, req.Procedure, req.StartsAt, req.EndsAt, req.Notes, createdBy, auth.ShelterScope(c))
+	if err != nil {
+		return appointmentErrorResponse(c, err)
+	}
+
+	audit.Record(c, "appointment.create", "appointment", appointment.ID, nil, appointment)
+
+	return c.Status(fiber.StatusCreated).JSON(appointment)
+}
+
+// GetAppointments handles listing the appointments starting between the from and to dates
+// (YYYY-MM-DD, inclusive), soonest first, optionally of a single pet or vet. The range
+// defaults to the next four weeks.
+func (h *appointmentHandler) GetAppointments(c *fiber.Ctx) error {
+	filter, ok, err := parseAppointmentFilter(c)
+	if !ok {
+		return err
+	}
+
+	filter.PetID = c.Query("pet_id")
+	filter.VetID = c.Query("vet_id")
+
+	return h.sendAppointments(c, filter, auth.ShelterScope(c))
+}
+
+// GetMyAppointments handles getting the authenticated vet's calendar, with the same range as GetAppointments
+func (h *appointmentHandler) GetMyAppointments(c *fiber.Ctx) error {
+	filter, ok, err := parseAppointmentFilter(c)
+	if !ok {
+		return err
+	}
+
+	filter.VetID = c.Locals("userID").(string)
+
+	// Only the vet's own appointments are listed, whichever shelter the pets belong to
+	return h.sendAppointments(c, filter, tenant.AllShelters())
+}
+
+// GetAppointmentByID handles getting a single appointment by ID
+func (h *appointmentHandler) GetAppointmentByID(c *fiber.Ctx) error {
+	id := c.Params("appointmentId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	appointment, err := h.service.GetAppointmentByID(id, auth.ShelterScope(c))
+	if err != nil {
+		return appointmentErrorResponse(c, err)
+	}
+
+	if appointment == nil {
+		return appointmentErrorResponse(c, models.ErrAppointmentNotFound)
+	}
+
+	return c.JSON(appointment)
+}
+
+// RescheduleAppointment handles changing the vet, procedure, time and notes of an appointment
+// that hasn't started. The pet can't be changed.
+func (h *appointmentHandler) RescheduleAppointment(c *fiber.Ctx) error {
+	id := c.Params("appointmentId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	var req appointmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.VetID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Vet ID is required",
+		})
+	}
+
+	// Kept for the audit log
+	before, err := h.service.GetAppointmentByID(id, auth.ShelterScope(c))
+	if err != nil {
+		return appointmentErrorResponse(c, err)
+	}
+
+	appointment, err := h.service.RescheduleAppointment(id, req.VetID, req.Procedure, req.StartsAt, req.EndsAt, req.Notes, auth.ShelterScope(c))
+	if err != nil {
+		return appointmentErrorResponse(c, err)
+	}
+
+	audit.Record(c, "appointment.update", "appointment", appointment.ID, before, appointment)
+
+	return c.JSON(appointment)
+}
+
+// UpdateAppointmentStatus handles marking an appointment done or missed
+func (h *appointmentHandler) UpdateAppointmentStatus(c *fiber.Ctx) error {
+	type updateStatusRequest struct {
+		Status models.AppointmentStatus `json:"status"`
+	}
+
+	id := c.Params("appointmentId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	var req updateStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Kept for the audit log
+	before, err := h.service.GetAppointmentByID(id, auth.ShelterScope(c))
+	if err != nil {
+		return appointmentErrorResponse(c, err)
+	}
+
+	appointment, err := h.service.UpdateAppointmentStatus(id, req.Status, auth.ShelterScope(c))
+	if err != nil {
+		return appointmentErrorResponse(c, err)
+	}
+
+	audit.Record(c, "appointment.status_update", "appointment", appointment.ID, before, appointment)
+
+	return c.JSON(appointment)
+}
+
+// CancelAppointment handles cancelling an appointment that hasn't started
+func (h *appointmentHandler) CancelAppointment(c *fiber.Ctx) error {
+	id := c.Params("appointmentId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	// Kept for the audit log
+	before, err := h.service.GetAppointmentByID(id, auth.ShelterScope(c))
+	if err != nil {
+		return appointmentErrorResponse(c, err)
+	}
+
+	if err := h.service.CancelAppointment(id, auth.ShelterScope(c)); err != nil {
+		return appointmentErrorResponse(c, err)
+	}
+
+	audit.Record(c, "appointment.cancel", "appointment", id, before, nil)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// sendAppointments sends the appointments of the pets of the shelters in scope matching the filter
+func (h *appointmentHandler) sendAppointments(c *fiber.Ctx, filter models.AppointmentFilter, scope tenant.Scope) error {
+	appointments, err := h.service.GetAppointments(filter, scope)
+	if err != nil {
+		return appointmentErrorResponse(c, err)
+	}
+
+	return c.JSON(appointments)
+}
+
+// parseAppointmentFilter reads the inclusive from and to dates (YYYY-MM-DD) of an appointment
+// list. When it returns false, the error response has been sent.
+func parseAppointmentFilter(c *fiber.Ctx) (models.AppointmentFilter, bool, error) {
+	today := daterange.Today()
+	from, to, err := daterange.Parse(c.Query("from"), c.Query("to"), today, today.AddDate(0, 0, defaultAppointmentListDays-1))
+	if err != nil {
+		return models.AppointmentFilter{}, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return models.AppointmentFilter{From: from, To: to}, true, nil
+}
+
+// appointmentErrorResponse maps appointment errors to HTTP responses
+func appointmentErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case models.ErrInvalidProcedure, models.ErrInvalidAppointmentTime, models.ErrInvalidAppointmentNotes,
+		models.ErrInvalidAppointmentStatus, models.ErrNotVet:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrAppointmentNotFound, models.ErrPetNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrPetAdopted, models.ErrVetUnavailable, models.ErrPetUnavailable, models.ErrAppointmentStarted,
+		models.ErrAppointmentNotStarted, models.ErrAppointmentClosed:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -13,3 +13,26 @@ type PetHandler interface {
 	UpdatePetStatus(c *fiber.Ctx) error
 	DeletePet(c *fiber.Ctx) error
 }
+
+// AppointmentHandler interface defines methods for vet appointment HTTP handlers
+type AppointmentHandler interface {
+	ScheduleAppointment(c *fiber.Ctx) error
+	GetAppointments(c *fiber.Ctx) error
+	GetMyAppointments(c *fiber.Ctx) error
+	GetAppointmentByID(c *fiber.Ctx) error
+	RescheduleAppointment(c *fiber.Ctx) error
+	UpdateAppointmentStatus(c *fiber.Ctx) error
+	CancelAppointment(c *fiber.Ctx) error
+}
+
+// TransferHandler interface defines methods for inter-shelter pet transfer HTTP handlers
+type TransferHandler interface {
+	RequestTransfer(c *fiber.Ctx) error
+	GetTransfers(c *fiber.Ctx) error
+	GetPetTransfers(c *fiber.Ctx) error
+	GetTransferByID(c *fiber.Ctx) error
+	ApproveTransfer(c *fiber.Ctx) error
+	RejectTransfer(c *fiber.Ctx) error
+	CancelTransfer(c *fiber.Ctx) error
+	CompleteTransfer(c *fiber.Ctx) error
+}
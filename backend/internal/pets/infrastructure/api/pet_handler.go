@@ -4,6 +4,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/solrac97gr/petparadise/internal/pets/domain/models"
 	"github.com/solrac97gr/petparadise/internal/pets/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
 )
 
 type petHandler struct {
@@ -20,6 +22,7 @@ func NewPetHandler(service ports.PetService) PetHandler {
 // CreatePet handles the creation of a new pet
 func (h *petHandler) CreatePet(c *fiber.Ctx) error {
 	type createPetRequest struct {
+		ShelterID   string   `json:"shelter_id"`
 		Name        string   `json:"name"`
 		Species     string   `json:"species"`
 		Breed       string   `json:"breed"`
@@ -53,17 +56,32 @@ func (h *petHandler) CreatePet(c *fiber.Ctx) error {
 		})
 	}
 
-	pet, err := h.service.CreatePet(req.Name, req.Species, req.Breed, req.Age, req.Description, req.Images)
+	// Staff add pets to their own shelter, platform admins to the one they pick
+	shelterID := auth.RecordShelter(c, req.ShelterID)
+	if shelterID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Shelter ID is required",
+		})
+	}
+
+	pet, err := h.service.CreatePet(shelterID, req.Name, req.Species, req.Breed, req.Age, req.Description, req.Images)
 	if err != nil {
+		if err == models.ErrShelterNotFound {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	audit.Record(c, "pet.create", "pet", pet.ID, nil, pet)
+
 	return c.Status(fiber.StatusCreated).JSON(pet)
 }
 
-// GetPetByID handles getting a single pet by ID
+// GetPetByID handles getting a single pet's public profile by ID
 func (h *petHandler) GetPetByID(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
@@ -72,7 +90,7 @@ func (h *petHandler) GetPetByID(c *fiber.Ctx) error {
 		})
 	}
 
-	pet, err := h.service.GetPetByID(id)
+	pet, err := h.service.GetPetProfile(id)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -88,7 +106,7 @@ func (h *petHandler) GetPetByID(c *fiber.Ctx) error {
 	return c.JSON(pet)
 }
 
-// GetPetsByStatus handles getting all pets with a specific status
+// GetPetsByStatus handles getting all pets with a specific status, optionally of a single shelter
 func (h *petHandler) GetPetsByStatus(c *fiber.Ctx) error {
 	statusParam := c.Query("status")
 	if statusParam == "" {
@@ -104,7 +122,7 @@ func (h *petHandler) GetPetsByStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	pets, err := h.service.GetPetsByStatus(status)
+	pets, err := h.service.GetPetsByStatus(status, auth.PublicShelterScope(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -114,9 +132,9 @@ func (h *petHandler) GetPetsByStatus(c *fiber.Ctx) error {
 	return c.JSON(pets)
 }
 
-// GetAllPets handles getting all pets
+// GetAllPets handles getting all pets, optionally of a single shelter
 func (h *petHandler) GetAllPets(c *fiber.Ctx) error {
-	pets, err := h.service.GetAllPets()
+	pets, err := h.service.GetAllPets(auth.PublicShelterScope(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -167,7 +185,22 @@ func (h *petHandler) UpdatePet(c *fiber.Ctx) error {
 		}
 	}
 
-	pet, err := h.service.UpdatePet(id, req.Name, req.Species, req.Breed, age, req.Description, status, req.Images)
+	// Staff only change the pets of their own shelter. Kept for the audit log.
+	scope := auth.ShelterScope(c)
+	before, err := h.service.GetPetByID(id, scope)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if before == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Pet not found",
+		})
+	}
+
+	pet, err := h.service.UpdatePet(id, req.Name, req.Species, req.Breed, age, req.Description, status, req.Images, scope)
 	if err != nil {
 		if err == models.ErrInvalidStatus {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -185,6 +218,8 @@ func (h *petHandler) UpdatePet(c *fiber.Ctx) error {
 		})
 	}
 
+	audit.Record(c, "pet.update", "pet", pet.ID, before, pet)
+
 	return c.JSON(pet)
 }
 
@@ -221,7 +256,22 @@ func (h *petHandler) UpdatePetStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	pet, err := h.service.UpdatePetStatus(id, status)
+	// Staff only change the pets of their own shelter. Kept for the audit log.
+	scope := auth.ShelterScope(c)
+	before, err := h.service.GetPetByID(id, scope)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if before == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Pet not found",
+		})
+	}
+
+	pet, err := h.service.UpdatePetStatus(id, status, scope)
 	if err != nil {
 		if err == models.ErrInvalidStatus {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -239,6 +289,8 @@ func (h *petHandler) UpdatePetStatus(c *fiber.Ctx) error {
 		})
 	}
 
+	audit.Record(c, "pet.status_update", "pet", pet.ID, before, pet)
+
 	return c.JSON(pet)
 }
 
@@ -251,12 +303,25 @@ func (h *petHandler) DeletePet(c *fiber.Ctx) error {
 		})
 	}
 
-	err := h.service.DeletePet(id)
+	// Staff only delete the pets of their own shelter. Kept for the audit log.
+	scope := auth.ShelterScope(c)
+	before, err := h.service.GetPetByID(id, scope)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = h.service.DeletePet(id, scope)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	if before != nil {
+		audit.Record(c, "pet.delete", "pet", id, before, nil)
+	}
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
@@ -4,23 +4,27 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/jmoiron/sqlx"
 	"github.com/solrac97gr/petparadise/internal/pets/aplication"
+	"github.com/solrac97gr/petparadise/internal/pets/domain/ports"
 	"github.com/solrac97gr/petparadise/internal/pets/infrastructure/repository"
+	"github.com/solrac97gr/petparadise/internal/pets/infrastructure/vets"
 	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	userRepository "github.com/solrac97gr/petparadise/internal/users/infrastructure/repository"
 	"github.com/solrac97gr/petparadise/pkg/auth"
 )
 
-// SetupPetRoutes sets up all pet routes
-func SetupPetRoutes(router fiber.Router, db *sqlx.DB) {
+// SetupPetRoutes sets up all pet routes. Sponsorships and sponsor notifications come from the
+// module handling donations.
+func SetupPetRoutes(router fiber.Router, db *sqlx.DB, sponsorships ports.SponsorshipGateway, sponsors ports.SponsorNotifier) {
 	// Initialize repository
 	petRepo := repository.NewPostgresRepository(db)
 
 	// Initialize service
-	petService := aplication.NewPetService(petRepo)
+	petService := aplication.NewPetService(petRepo, sponsorships, sponsors)
 
 	// Initialize handler
 	petHandler := NewPetHandler(petService)
 
-	// Public routes - anyone can view pets
+	// Public routes - anyone can view pets, of every shelter or of the one in the shelter_id query parameter
 	router.Get("/", petHandler.GetAllPets)
 	router.Get("/:id", petHandler.GetPetByID)
 	router.Get("/status", petHandler.GetPetsByStatus)
@@ -28,10 +32,69 @@ func SetupPetRoutes(router fiber.Router, db *sqlx.DB) {
 	// Protected routes - require authentication
 	protectedRoutes := router.Use(auth.Protected())
 
-	// Staff routes - require authentication + proper role
-	staffRoutes := protectedRoutes.Use(auth.RoleRequired(models.RoleAdmin, models.RoleVet, models.RoleVolunteer))
-	staffRoutes.Post("/", petHandler.CreatePet)
-	staffRoutes.Put("/:id", petHandler.UpdatePet)
-	staffRoutes.Patch("/:id/status", petHandler.UpdatePetStatus)
-	staffRoutes.Delete("/:id", petHandler.DeletePet)
+	// Staff routes - require authentication + the permission for the action
+	protectedRoutes.Post("/", auth.RequirePermission(models.PermissionPetsWrite), petHandler.CreatePet)
+	protectedRoutes.Put("/:id", auth.RequirePermission(models.PermissionPetsWrite), petHandler.UpdatePet)
+	protectedRoutes.Patch("/:id/status", auth.RequirePermission(models.PermissionPetsWrite), petHandler.UpdatePetStatus)
+	protectedRoutes.Delete("/:id", auth.RequirePermission(models.PermissionPetsDelete), auth.MFARequired(), petHandler.DeletePet)
+}
+
+// SetupAppointmentRoutes sets up all vet appointment routes and returns the appointment service,
+// whose care scheduler is started by the app
+func SetupAppointmentRoutes(router fiber.Router, db *sqlx.DB) ports.AppointmentService {
+	// Initialize repositories
+	appointmentRepo := repository.NewPostgresAppointmentRepository(db)
+	petRepo := repository.NewPostgresRepository(db)
+
+	// Initialize vet directory backed by the users module
+	vetDirectory := vets.NewUsersDirectory(userRepository.NewPostgresRepository(db))
+
+	// Initialize service
+	appointmentService := aplication.NewAppointmentService(appointmentRepo, petRepo, vetDirectory)
+
+	// Initialize handler
+	appointmentHandler := NewAppointmentHandler(appointmentService)
+
+	// All appointment routes require authentication
+	protected := router.Use(auth.Protected())
+
+	// Vets see their own calendar
+	protected.Get("/mine", appointmentHandler.GetMyAppointments)
+
+	// Calendar routes - require the permission to manage appointments
+	canManage := auth.RequirePermission(models.PermissionAppointmentsManage)
+	protected.Get("/", canManage, appointmentHandler.GetAppointments)
+	protected.Get("/:appointmentId", canManage, appointmentHandler.GetAppointmentByID)
+	protected.Post("/", canManage, appointmentHandler.ScheduleAppointment)
+	protected.Put("/:appointmentId", canManage, appointmentHandler.RescheduleAppointment)
+	protected.Patch("/:appointmentId/status", canManage, appointmentHandler.UpdateAppointmentStatus)
+	protected.Delete("/:appointmentId", canManage, appointmentHandler.CancelAppointment)
+
+	return appointmentService
+}
+
+// SetupTransferRoutes sets up all inter-shelter pet transfer routes
+func SetupTransferRoutes(router fiber.Router, db *sqlx.DB) {
+	// Initialize repositories
+	transferRepo := repository.NewPostgresTransferRepository(db)
+	petRepo := repository.NewPostgresRepository(db)
+
+	// Initialize service
+	transferService := aplication.NewTransferService(transferRepo, petRepo)
+
+	// Initialize handler
+	transferHandler := NewTransferHandler(transferService)
+
+	// All transfer routes require authentication + the permission to manage transfers. Which side
+	// of a transfer can act on it is checked against the requester's shelter.
+	protected := router.Use(auth.Protected(), auth.RequirePermission(models.PermissionTransfersManage))
+
+	protected.Get("/", transferHandler.GetTransfers)
+	protected.Get("/pet/:petId", transferHandler.GetPetTransfers)
+	protected.Get("/:transferId", transferHandler.GetTransferByID)
+	protected.Post("/", transferHandler.RequestTransfer)
+	protected.Post("/:transferId/approve", transferHandler.ApproveTransfer)
+	protected.Post("/:transferId/reject", transferHandler.RejectTransfer)
+	protected.Post("/:transferId/cancel", transferHandler.CancelTransfer)
+	protected.Post("/:transferId/complete", transferHandler.CompleteTransfer)
 }
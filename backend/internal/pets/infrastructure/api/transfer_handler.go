@@ -0,0 +1,314 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/pets/domain/models"
+	"github.com/solrac97gr/petparadise/internal/pets/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+)
+
+// transferRequest is the body of the request asking another shelter to take in a pet
+type transferRequest struct {
+	PetID         string                `json:"pet_id"`
+	ToShelterID   string                `json:"to_shelter_id"`
+	Reason        models.TransferReason `json:"reason"`
+	Notes         string                `json:"notes"`
+	TransportDate string                `json:"transport_date"`
+}
+
+type transferHandler struct {
+	service ports.TransferService
+}
+
+// NewTransferHandler creates a new transfer handler
+func NewTransferHandler(service ports.TransferService) TransferHandler {
+	return &transferHandler{
+		service: service,
+	}
+}
+
+// RequestTransfer handles asking another shelter to take in a pet on the transport date (YYYY-MM-DD)
+func (h *transferHandler) RequestTransfer(c *fiber.Ctx) error {
+	var req transferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.PetID == "" || req.ToShelterID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Pet ID and receiving shelter ID are required",
+		})
+	}
+
+	if err := uuid.Validate(req.ToShelterID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid shelter ID",
+		})
+	}
+
+	transportDate, err := time.Parse(time.DateOnly, req.TransportDate)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid transport date, use YYYY-MM-DD",
+		})
+	}
+
+	requestedBy, _ := c.Locals("userID").(string)
+
+	transfer, err := h.service.RequestTransfer(req.PetID, req.ToShelterID, req.Reason, req.Notes, transportDate, requestedBy, auth.ShelterScope(c))
+	if err != nil {
+		return transferErrorResponse(c, err)
+	}
+
+	audit.Record(c, "transfer.request", "transfer", transfer.ID, nil, transfer)
+
+	return c.Status(fiber.StatusCreated).JSON(transfer)
+}
+
+// GetTransfers handles listing the transfers leaving or arriving at the requester's shelter,
+// latest first, optionally of a single pet, status or direction (incoming or outgoing)
+func (h *transferHandler) GetTransfers(c *fiber.Ctx) error {
+	filter := models.TransferFilter{
+		PetID:     c.Query("pet_id"),
+		Status:    models.TransferStatus(c.Query("status")),
+		Direction: models.TransferDirection(c.Query("direction")),
+	}
+
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid status",
+		})
+	}
+
+	if !filter.Direction.IsValid() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Direction must be incoming or outgoing",
+		})
+	}
+
+	transfers, err := h.service.GetTransfers(filter, auth.ShelterScope(c))
+	if err != nil {
+		return transferErrorResponse(c, err)
+	}
+
+	return c.JSON(transfers)
+}
+
+// GetPetTransfers handles getting the full transfer history of a pet, oldest first
+func (h *transferHandler) GetPetTransfers(c *fiber.Ctx) error {
+	petID := c.Params("petId")
+	if petID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Pet ID is required",
+		})
+	}
+
+	transfers, err := h.service.GetPetTransfers(petID, auth.ShelterScope(c))
+	if err != nil {
+		return transferErrorResponse(c, err)
+	}
+
+	return c.JSON(transfers)
+}
+
+// GetTransferByID handles getting a single transfer by ID
+func (h *transferHandler) GetTransferByID(c *fiber.Ctx) error {
+	id := c.Params("transferId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	transfer, err := h.service.GetTransferByID(id, auth.ShelterScope(c))
+	if err != nil {
+		return transferErrorResponse(c, err)
+	}
+
+	if transfer == nil {
+		return transferErrorResponse(c, models.ErrTransferNotFound)
+	}
+
+	return c.JSON(transfer)
+}
+
+// ApproveTransfer handles the receiving shelter accepting a transfer, optionally moving its
+// transport date (YYYY-MM-DD)
+func (h *transferHandler) ApproveTransfer(c *fiber.Ctx) error {
+	type approveRequest struct {
+		TransportDate string `json:"transport_date"`
+	}
+
+	id := c.Params("transferId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	var req approveRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	var transportDate *time.Time
+	if req.TransportDate != "" {
+		parsed, err := time.Parse(time.DateOnly, req.TransportDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid transport date, use YYYY-MM-DD",
+			})
+		}
+		transportDate = &parsed
+	}
+
+	// Kept for the audit log
+	before, err := h.service.GetTransferByID(id, auth.ShelterScope(c))
+	if err != nil {
+		return transferErrorResponse(c, err)
+	}
+
+	reviewedBy, _ := c.Locals("userID").(string)
+
+	transfer, err := h.service.ApproveTransfer(id, transportDate, reviewedBy, auth.ShelterScope(c))
+	if err != nil {
+		return transferErrorResponse(c, err)
+	}
+
+	audit.Record(c, "transfer.approve", "transfer", transfer.ID, before, transfer)
+
+	return c.JSON(transfer)
+}
+
+// RejectTransfer handles the receiving shelter turning down a transfer
+func (h *transferHandler) RejectTransfer(c *fiber.Ctx) error {
+	id := c.Params("transferId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	// Kept for the audit log
+	before, err := h.service.GetTransferByID(id, auth.ShelterScope(c))
+	if err != nil {
+		return transferErrorResponse(c, err)
+	}
+
+	reviewedBy, _ := c.Locals("userID").(string)
+
+	transfer, err := h.service.RejectTransfer(id, reviewedBy, auth.ShelterScope(c))
+	if err != nil {
+		return transferErrorResponse(c, err)
+	}
+
+	audit.Record(c, "transfer.reject", "transfer", transfer.ID, before, transfer)
+
+	return c.JSON(transfer)
+}
+
+// CancelTransfer handles the sending shelter withdrawing a transfer that hasn't been completed
+func (h *transferHandler) CancelTransfer(c *fiber.Ctx) error {
+	id := c.Params("transferId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	// Kept for the audit log
+	before, err := h.service.GetTransferByID(id, auth.ShelterScope(c))
+	if err != nil {
+		return transferErrorResponse(c, err)
+	}
+
+	transfer, err := h.service.CancelTransfer(id, auth.ShelterScope(c))
+	if err != nil {
+		return transferErrorResponse(c, err)
+	}
+
+	audit.Record(c, "transfer.cancel", "transfer", transfer.ID, before, transfer)
+
+	return c.JSON(transfer)
+}
+
+// CompleteTransfer handles the receiving shelter recording that the pet arrived, which makes the
+// pet belong to it
+func (h *transferHandler) CompleteTransfer(c *fiber.Ctx) error {
+	id := c.Params("transferId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	// Kept for the audit log
+	before, err := h.service.GetTransferByID(id, auth.ShelterScope(c))
+	if err != nil {
+		return transferErrorResponse(c, err)
+	}
+
+	completedBy, _ := c.Locals("userID").(string)
+
+	transfer, err := h.service.CompleteTransfer(id, completedBy, auth.ShelterScope(c))
+	if err != nil {
+		return transferErrorResponse(c, err)
+	}
+
+	audit.Record(c, "transfer.complete", "transfer", transfer.ID, before, transfer)
+	audit.Record(c, "pet.shelter_update", "pet", transfer.PetID,
+		map[string]any{"shelter_id": transfer.FromShelterID}, map[string]any{"shelter_id": transfer.ToShelterID})
+
+	// The records the pet took along or left behind
+	for _, id := range transfer.CancelledAppointments {
+		audit.Record(c, "appointment.cancel", "appointment", id, map[string]any{"pet_id": transfer.PetID}, nil)
+	}
+	for _, id := range transfer.MovedAdoptions {
+		audit.Record(c, "adoption.shelter_update", "adoption", id,
+			map[string]any{"shelter_id": transfer.FromShelterID}, map[string]any{"shelter_id": transfer.ToShelterID})
+	}
+	for _, id := range transfer.MovedDonations {
+		audit.Record(c, "donation.shelter_update", "donation", id,
+			map[string]any{"shelter_id": transfer.FromShelterID}, map[string]any{"shelter_id": transfer.ToShelterID})
+	}
+
+	return c.JSON(transfer)
+}
+
+// transferErrorResponse maps transfer errors to HTTP responses
+func transferErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case models.ErrSameShelter, models.ErrShelterRequired, models.ErrInvalidTransferReason, models.ErrInvalidTransferNotes,
+		models.ErrInvalidTransportDate:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrNotReceivingShelter, models.ErrNotSendingShelter:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrTransferNotFound, models.ErrPetNotFound, models.ErrShelterNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrPetNotTransferable, models.ErrTransferPending, models.ErrTransferNotRequested, models.ErrTransferNotApproved,
+		models.ErrTransferClosed, models.ErrTransferNotDue, models.ErrPetHasAppointments, models.ErrTransferChanged:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/solrac97gr/petparadise/internal/pets/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// appointmentColumns are the columns scanned by scanAppointment
+const appointmentColumns = `id, pet_id, vet_id, procedure, starts_at, ends_at, notes, status, care_started_at, care_ends_at,
+              care_ended_at, previous_pet_status, created_by, created, updated`
+
+// PostgresAppointmentRepository implements the AppointmentRepository interface.
+type PostgresAppointmentRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresAppointmentRepository creates a new PostgresAppointmentRepository
+func NewPostgresAppointmentRepository(db *sqlx.DB) *PostgresAppointmentRepository {
+	return &PostgresAppointmentRepository{
+		db: db,
+	}
+}
+
+// Save saves an appointment unless its vet or pet has another one at the same time
+func (r *PostgresAppointmentRepository) Save(appointment *models.Appointment) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkAvailability(tx, appointment); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO appointments (id, pet_id, vet_id, procedure, starts_at, ends_at, notes, status, created_by, created, updated)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err = tx.Exec(
+		query,
+		appointment.ID,
+		appointment.PetID,
+		appointment.VetID,
+		appointment.Procedure.String(),
+		appointment.StartsAt.UTC(),
+		appointment.EndsAt.UTC(),
+		appointment.Notes,
+		appointment.Status.String(),
+		appointment.CreatedBy,
+		appointment.Created.UTC(),
+		appointment.Updated.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindByID finds an appointment of the pets of the shelters in scope by its ID
+func (r *PostgresAppointmentRepository) FindByID(id string, scope tenant.Scope) (*models.Appointment, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + appointmentColumns + ` FROM appointments
+              WHERE id = $1 AND pet_id IN (SELECT id FROM pets WHERE ` + tenant.Condition("shelter_id", 2) + `)`
+
+	return scanAppointment(r.db.QueryRow(query, id, all, shelterID))
+}
+
+// Find finds the appointments of the pets of the shelters in scope matching the filter, soonest first
+func (r *PostgresAppointmentRepository) Find(filter models.AppointmentFilter, scope tenant.Scope) ([]*models.Appointment, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + appointmentColumns + ` FROM appointments
+              WHERE starts_at >= $1 AND starts_at < $2 AND ($3 = '' OR pet_id = $3) AND ($4 = '' OR vet_id::text = $4)
+              AND pet_id IN (SELECT id FROM pets WHERE ` + tenant.Condition("shelter_id", 5) + `)
+              ORDER BY starts_at, vet_id`
+
+	return r.findMany(query, filter.From.UTC(), filter.To.UTC(), filter.PetID, filter.VetID, all, shelterID)
+}
+
+// FindCareDue finds the scheduled appointments that have started and should move their pet to medical care
+func (r *PostgresAppointmentRepository) FindCareDue(now time.Time) ([]*models.Appointment, error) {
+	procedures := []string{}
+	for _, procedure := range models.CareProcedures() {
+		procedures = append(procedures, procedure.String())
+	}
+
+	query := `SELECT ` + appointmentColumns + ` FROM appointments
+              WHERE status = $1 AND care_started_at IS NULL AND starts_at <= $2 AND procedure = ANY($3)
+              ORDER BY starts_at`
+
+	return r.findMany(query, models.AppointmentScheduled.String(), now.UTC(), pq.Array(procedures))
+}
+
+// FindRecovered finds the appointments keeping a pet in medical care whose recovery period is over
+func (r *PostgresAppointmentRepository) FindRecovered(now time.Time) ([]*models.Appointment, error) {
+	query := `SELECT ` + appointmentColumns + ` FROM appointments
+              WHERE care_started_at IS NOT NULL AND care_ended_at IS NULL AND care_ends_at <= $1
+              ORDER BY care_ends_at`
+
+	return r.findMany(query, now.UTC())
+}
+
+// FindInCare finds the appointments keeping a pet in medical care, earliest first
+func (r *PostgresAppointmentRepository) FindInCare(petID string) ([]*models.Appointment, error) {
+	query := `SELECT ` + appointmentColumns + ` FROM appointments
+              WHERE pet_id = $1 AND care_started_at IS NOT NULL AND care_ended_at IS NULL
+              ORDER BY care_started_at`
+
+	return r.findMany(query, petID)
+}
+
+// Reschedule updates the vet, procedure, time and notes of an appointment unless its vet or pet
+// has another appointment at the new time
+func (r *PostgresAppointmentRepository) Reschedule(appointment *models.Appointment) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkAvailability(tx, appointment); err != nil {
+		return err
+	}
+
+	query := `UPDATE appointments SET vet_id = $1, procedure = $2, starts_at = $3, ends_at = $4, notes = $5, updated = $6
+              WHERE id = $7`
+
+	_, err = tx.Exec(
+		query,
+		appointment.VetID,
+		appointment.Procedure.String(),
+		appointment.StartsAt.UTC(),
+		appointment.EndsAt.UTC(),
+		appointment.Notes,
+		appointment.Updated.UTC(),
+		appointment.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Update updates the status and medical care of an appointment
+func (r *PostgresAppointmentRepository) Update(appointment *models.Appointment) error {
+	query := `UPDATE appointments SET status = $1, care_started_at = $2, care_ends_at = $3, care_ended_at = $4,
+              previous_pet_status = $5, updated = $6 WHERE id = $7`
+
+	_, err := r.db.Exec(
+		query,
+		appointment.Status.String(),
+		nullTime(appointment.CareStartedAt),
+		nullTime(appointment.CareEndsAt),
+		nullTime(appointment.CareEndedAt),
+		appointment.PreviousPetStatus.String(),
+		appointment.Updated.UTC(),
+		appointment.ID,
+	)
+
+	return err
+}
+
+// Delete deletes an appointment
+func (r *PostgresAppointmentRepository) Delete(id string) error {
+	_, err := r.db.Exec(`DELETE FROM appointments WHERE id = $1`, id)
+	return err
+}
+
+// findMany runs a query returning rows of appointmentColumns
+func (r *PostgresAppointmentRepository) findMany(query string, args ...any) ([]*models.Appointment, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appointments := []*models.Appointment{}
+	for rows.Next() {
+		appointment, err := scanAppointment(rows)
+		if err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, appointment)
+	}
+
+	return appointments, rows.Err()
+}
+
+// checkAvailability checks that neither the vet nor the pet of an appointment has another one
+// overlapping it. Missed appointments free their slot. The vet and pet stay locked until the
+// transaction ends, so concurrent bookings can't take the same slot.
+func checkAvailability(tx *sqlx.Tx, appointment *models.Appointment) error {
+	// Locked in a fixed order so two bookings can't wait on each other
+	keys := []string{"vet:" + appointment.VetID, "pet:" + appointment.PetID}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, key); err != nil {
+			return err
+		}
+	}
+
+	query := `SELECT
+                  EXISTS (SELECT 1 FROM appointments WHERE vet_id = $1 AND id <> $3 AND status <> $4 AND starts_at < $6 AND ends_at > $5),
+                  EXISTS (SELECT 1 FROM appointments WHERE pet_id = $2 AND id <> $3 AND status <> $4 AND starts_at < $6 AND ends_at > $5)`
+
+	var vetBusy, petBusy bool
+	err := tx.QueryRow(
+		query,
+		appointment.VetID,
+		appointment.PetID,
+		appointment.ID,
+		models.AppointmentMissed.String(),
+		appointment.StartsAt.UTC(),
+		appointment.EndsAt.UTC(),
+	).Scan(&vetBusy, &petBusy)
+	if err != nil {
+		return err
+	}
+
+	if vetBusy {
+		return models.ErrVetUnavailable
+	}
+
+	if petBusy {
+		return models.ErrPetUnavailable
+	}
+
+	return nil
+}
+
+// scanAppointment scans a row of appointmentColumns, returning nil if there is no row
+func scanAppointment(row interface{ Scan(dest ...any) error }) (*models.Appointment, error) {
+	var appointment models.Appointment
+	var procedure, status, previousPetStatus string
+	var careStartedAt, careEndsAt, careEndedAt sql.NullTime
+
+	err := row.Scan(
+		&appointment.ID,
+		&appointment.PetID,
+		&appointment.VetID,
+		&procedure,
+		&appointment.StartsAt,
+		&appointment.EndsAt,
+		&appointment.Notes,
+		&status,
+		&careStartedAt,
+		&careEndsAt,
+		&careEndedAt,
+		&previousPetStatus,
+		&appointment.CreatedBy,
+		&appointment.Created,
+		&appointment.Updated,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	appointment.Procedure = models.Procedure(procedure)
+	appointment.Status = models.AppointmentStatus(status)
+	appointment.PreviousPetStatus = models.Status(previousPetStatus)
+
+	if careStartedAt.Valid {
+		appointment.CareStartedAt = &careStartedAt.Time
+	}
+
+	if careEndsAt.Valid {
+		appointment.CareEndsAt = &careEndsAt.Time
+	}
+
+	if careEndedAt.Valid {
+		appointment.CareEndedAt = &careEndedAt.Time
+	}
+
+	return &appointment, nil
+}
+
+// nullTime converts an optional time to a UTC column value
+func nullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,28 @@
+-- Vet appointments. Procedures that need care move the pet to medical care from when they
+-- start until the pet has recovered, remembering the status the pet goes back to.
+CREATE TABLE IF NOT EXISTS appointments (
+    id UUID PRIMARY KEY,
+    pet_id VARCHAR(36) NOT NULL,
+    vet_id UUID NOT NULL,
+    procedure VARCHAR(20) NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    care_started_at TIMESTAMP,
+    care_ends_at TIMESTAMP,
+    care_ended_at TIMESTAMP,
+    previous_pet_status VARCHAR(50) NOT NULL DEFAULT '',
+    created_by UUID NOT NULL,
+    created TIMESTAMP NOT NULL,
+    updated TIMESTAMP NOT NULL,
+    FOREIGN KEY (pet_id) REFERENCES pets(id) ON DELETE CASCADE,
+    FOREIGN KEY (vet_id) REFERENCES users(id) ON DELETE RESTRICT,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_appointments_vet_id_starts_at ON appointments(vet_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_appointments_pet_id_starts_at ON appointments(pet_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_appointments_starts_at ON appointments(starts_at);
+-- The appointments keeping a pet in medical care, looked up by the care job
+CREATE INDEX IF NOT EXISTS idx_appointments_in_care ON appointments(pet_id) WHERE care_started_at IS NOT NULL AND care_ended_at IS NULL;
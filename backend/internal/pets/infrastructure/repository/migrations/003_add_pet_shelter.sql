@@ -0,0 +1,6 @@
+-- Every pet belongs to a shelter; existing pets belong to the default shelter
+ALTER TABLE pets ADD COLUMN IF NOT EXISTS shelter_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+    REFERENCES shelters(id) ON DELETE RESTRICT;
+ALTER TABLE pets ALTER COLUMN shelter_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_pets_shelter_id ON pets(shelter_id);
@@ -0,0 +1,29 @@
+-- Transfers of pets between shelters. They are never deleted, so they keep the history of the
+-- shelters a pet went through; completing one moves the pet to the receiving shelter.
+CREATE TABLE IF NOT EXISTS pet_transfers (
+    id UUID PRIMARY KEY,
+    pet_id VARCHAR(36) NOT NULL,
+    from_shelter_id UUID NOT NULL,
+    to_shelter_id UUID NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    transport_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    requested_by UUID NOT NULL,
+    reviewed_by UUID,
+    reviewed_at TIMESTAMP,
+    completed_by UUID,
+    completed_at TIMESTAMP,
+    created TIMESTAMP NOT NULL,
+    updated TIMESTAMP NOT NULL,
+    FOREIGN KEY (pet_id) REFERENCES pets(id) ON DELETE CASCADE,
+    FOREIGN KEY (from_shelter_id) REFERENCES shelters(id) ON DELETE RESTRICT,
+    FOREIGN KEY (to_shelter_id) REFERENCES shelters(id) ON DELETE RESTRICT,
+    CHECK (to_shelter_id <> from_shelter_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pet_transfers_pet_id ON pet_transfers(pet_id, created);
+CREATE INDEX IF NOT EXISTS idx_pet_transfers_from_shelter_id ON pet_transfers(from_shelter_id);
+CREATE INDEX IF NOT EXISTS idx_pet_transfers_to_shelter_id ON pet_transfers(to_shelter_id);
+-- A pet has at most one transfer in progress
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pet_transfers_open ON pet_transfers(pet_id) WHERE status IN ('requested', 'approved');
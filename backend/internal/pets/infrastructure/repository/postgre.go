@@ -7,8 +7,13 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/solrac97gr/petparadise/internal/pets/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/database"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
 )
 
+// petColumns are the columns scanned by scanPet
+const petColumns = `id, shelter_id, name, species, breed, age, description, status, created, updated, images`
+
 // PostgresRepository implements the PetRepository interface
 type PostgresRepository struct {
 	db *sqlx.DB
@@ -28,12 +33,13 @@ func (r *PostgresRepository) Save(pet *models.Pet) error {
 		return err
 	}
 
-	query := `INSERT INTO pets (id, name, species, breed, age, description, status, created, updated, images) 
-              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	query := `INSERT INTO pets (id, shelter_id, name, species, breed, age, description, status, created, updated, images)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 
 	_, err = r.db.Exec(
 		query,
 		pet.ID,
+		pet.ShelterID,
 		pet.Name,
 		pet.Species,
 		pet.Breed,
@@ -45,110 +51,80 @@ func (r *PostgresRepository) Save(pet *models.Pet) error {
 		imagesJSON,
 	)
 
+	if database.IsForeignKeyViolation(err, "pets_shelter_id_fkey") {
+		return models.ErrShelterNotFound
+	}
+
 	return err
 }
 
-// FindByID finds a pet by its ID
-func (r *PostgresRepository) FindByID(id string) (*models.Pet, error) {
-	var pet models.Pet
-	var imagesJSON string
-	var statusStr string
+// FindByID finds a pet of the shelters in scope by its ID
+func (r *PostgresRepository) FindByID(id string, scope tenant.Scope) (*models.Pet, error) {
+	all, shelterID := scope.Filter()
 
-	query := `SELECT id, name, species, breed, age, description, status, created, updated, images 
-              FROM pets WHERE id = $1`
+	query := `SELECT ` + petColumns + `
+              FROM pets WHERE id = $1 AND ` + tenant.Condition("shelter_id", 2)
 
-	err := r.db.QueryRow(query, id).Scan(
-		&pet.ID,
-		&pet.Name,
-		&pet.Species,
-		&pet.Breed,
-		&pet.Age,
-		&pet.Description,
-		&statusStr,
-		&pet.Created,
-		&pet.Updated,
-		&imagesJSON,
-	)
+	return scanPet(r.db.QueryRow(query, id, all, shelterID))
+}
 
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, err
-	}
+// FindByStatus finds all pets of the shelters in scope with a specific status
+func (r *PostgresRepository) FindByStatus(status models.Status, scope tenant.Scope) ([]*models.Pet, error) {
+	all, shelterID := scope.Filter()
 
-	pet.Status = models.Status(statusStr)
+	query := `SELECT ` + petColumns + `
+              FROM pets WHERE status = $1 AND ` + tenant.Condition("shelter_id", 2)
 
-	var images []string
-	err = json.Unmarshal([]byte(imagesJSON), &images)
-	if err != nil {
-		return nil, err
-	}
+	return r.findMany(query, status.String(), all, shelterID)
+}
 
-	pet.Images = images
+// FindAll finds all pets of the shelters in scope
+func (r *PostgresRepository) FindAll(scope tenant.Scope) ([]*models.Pet, error) {
+	all, shelterID := scope.Filter()
 
-	return &pet, nil
-}
+	query := `SELECT ` + petColumns + ` FROM pets WHERE ` + tenant.Condition("shelter_id", 1)
 
-// FindByStatus finds all pets with a specific status
-func (r *PostgresRepository) FindByStatus(status models.Status) ([]*models.Pet, error) {
-	query := `SELECT id, name, species, breed, age, description, status, created, updated, images 
-              FROM pets WHERE status = $1`
+	return r.findMany(query, all, shelterID)
+}
 
-	rows, err := r.db.Query(query, status.String())
+// Update updates a pet. Its shelter can't be changed.
+func (r *PostgresRepository) Update(pet *models.Pet) error {
+	imagesJSON, err := json.Marshal(pet.Images)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
-
-	var pets []*models.Pet
-
-	for rows.Next() {
-		var pet models.Pet
-		var imagesJSON string
-		var statusStr string
-
-		err := rows.Scan(
-			&pet.ID,
-			&pet.Name,
-			&pet.Species,
-			&pet.Breed,
-			&pet.Age,
-			&pet.Description,
-			&statusStr,
-			&pet.Created,
-			&pet.Updated,
-			&imagesJSON,
-		)
-
-		if err != nil {
-			return nil, err
-		}
 
-		pet.Status = models.Status(statusStr)
+	query := `UPDATE pets SET name = $1, species = $2, breed = $3, age = $4, description = $5,
+              status = $6, updated = $7, images = $8 WHERE id = $9`
 
-		var images []string
-		err = json.Unmarshal([]byte(imagesJSON), &images)
-		if err != nil {
-			return nil, err
-		}
+	_, err = r.db.Exec(
+		query,
+		pet.Name,
+		pet.Species,
+		pet.Breed,
+		pet.Age,
+		pet.Description,
+		pet.Status.String(),
+		pet.Updated,
+		imagesJSON,
+		pet.ID,
+	)
 
-		pet.Images = images
-		pets = append(pets, &pet)
-	}
+	return err
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
+// Delete deletes a pet of the shelters in scope
+func (r *PostgresRepository) Delete(id string, scope tenant.Scope) error {
+	all, shelterID := scope.Filter()
 
-	return pets, nil
+	query := `DELETE FROM pets WHERE id = $1 AND ` + tenant.Condition("shelter_id", 2)
+	_, err := r.db.Exec(query, id, all, shelterID)
+	return err
 }
 
-// FindAll finds all pets
-func (r *PostgresRepository) FindAll() ([]*models.Pet, error) {
-	query := `SELECT id, name, species, breed, age, description, status, created, updated, images FROM pets`
-
-	rows, err := r.db.Query(query)
+// findMany runs a query returning rows of petColumns
+func (r *PostgresRepository) findMany(query string, args ...any) ([]*models.Pet, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -157,37 +133,11 @@ func (r *PostgresRepository) FindAll() ([]*models.Pet, error) {
 	var pets []*models.Pet
 
 	for rows.Next() {
-		var pet models.Pet
-		var imagesJSON string
-		var statusStr string
-
-		err := rows.Scan(
-			&pet.ID,
-			&pet.Name,
-			&pet.Species,
-			&pet.Breed,
-			&pet.Age,
-			&pet.Description,
-			&statusStr,
-			&pet.Created,
-			&pet.Updated,
-			&imagesJSON,
-		)
-
-		if err != nil {
-			return nil, err
-		}
-
-		pet.Status = models.Status(statusStr)
-
-		var images []string
-		err = json.Unmarshal([]byte(imagesJSON), &images)
+		pet, err := scanPet(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		pet.Images = images
-		pets = append(pets, &pet)
+		pets = append(pets, pet)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -197,35 +147,42 @@ func (r *PostgresRepository) FindAll() ([]*models.Pet, error) {
 	return pets, nil
 }
 
-// Update updates a pet
-func (r *PostgresRepository) Update(pet *models.Pet) error {
-	imagesJSON, err := json.Marshal(pet.Images)
+// scanPet scans a row of petColumns, returning nil if there is no row
+func scanPet(row interface{ Scan(dest ...any) error }) (*models.Pet, error) {
+	var pet models.Pet
+	var imagesJSON string
+	var statusStr string
+
+	err := row.Scan(
+		&pet.ID,
+		&pet.ShelterID,
+		&pet.Name,
+		&pet.Species,
+		&pet.Breed,
+		&pet.Age,
+		&pet.Description,
+		&statusStr,
+		&pet.Created,
+		&pet.Updated,
+		&imagesJSON,
+	)
+
 	if err != nil {
-		return err
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
 	}
 
-	query := `UPDATE pets SET name = $1, species = $2, breed = $3, age = $4, description = $5, 
-              status = $6, updated = $7, images = $8 WHERE id = $9`
+	pet.Status = models.Status(statusStr)
 
-	_, err = r.db.Exec(
-		query,
-		pet.Name,
-		pet.Species,
-		pet.Breed,
-		pet.Age,
-		pet.Description,
-		pet.Status.String(),
-		pet.Updated,
-		imagesJSON,
-		pet.ID,
-	)
+	var images []string
+	err = json.Unmarshal([]byte(imagesJSON), &images)
+	if err != nil {
+		return nil, err
+	}
 
-	return err
-}
+	pet.Images = images
 
-// Delete deletes a pet
-func (r *PostgresRepository) Delete(id string) error {
-	query := `DELETE FROM pets WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
+	return &pet, nil
 }
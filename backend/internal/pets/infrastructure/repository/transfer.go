@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/pets/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/database"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// transferColumns are the columns scanned by scanTransfer
+const transferColumns = `id, pet_id, from_shelter_id, to_shelter_id, reason, notes, transport_date, status, requested_by,
+              reviewed_by, reviewed_at, completed_by, completed_at, created, updated`
+
+// transferScope is the condition matching the transfers leaving or arriving at the shelters in
+// scope, which is passed in $1 and $2
+var transferScope = `(` + tenant.Condition("from_shelter_id", 1) + ` OR ` + tenant.Condition("to_shelter_id", 1) + `)`
+
+// PostgresTransferRepository implements the TransferRepository interface.
+type PostgresTransferRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresTransferRepository creates a new PostgresTransferRepository
+func NewPostgresTransferRepository(db *sqlx.DB) *PostgresTransferRepository {
+	return &PostgresTransferRepository{
+		db: db,
+	}
+}
+
+// Save saves a transfer unless its pet has another one in progress
+func (r *PostgresTransferRepository) Save(transfer *models.Transfer) error {
+	query := `INSERT INTO pet_transfers (id, pet_id, from_shelter_id, to_shelter_id, reason, notes, transport_date, status,
+              requested_by, created, updated)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := r.db.Exec(
+		query,
+		transfer.ID,
+		transfer.PetID,
+		transfer.FromShelterID,
+		transfer.ToShelterID,
+		transfer.Reason.String(),
+		transfer.Notes,
+		transfer.TransportDate.UTC(),
+		transfer.Status.String(),
+		transfer.RequestedBy,
+		transfer.Created.UTC(),
+		transfer.Updated.UTC(),
+	)
+
+	switch {
+	case database.IsUniqueViolation(err, "idx_pet_transfers_open"):
+		return models.ErrTransferPending
+	case database.IsForeignKeyViolation(err, "pet_transfers_to_shelter_id_fkey"):
+		return models.ErrShelterNotFound
+	}
+
+	return err
+}
+
+// FindByID finds a transfer leaving or arriving at the shelters in scope by its ID
+func (r *PostgresTransferRepository) FindByID(id string, scope tenant.Scope) (*models.Transfer, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + transferColumns + ` FROM pet_transfers WHERE ` + transferScope + ` AND id = $3`
+
+	return scanTransfer(r.db.QueryRow(query, all, shelterID, id))
+}
+
+// Find finds the transfers leaving or arriving at the shelters in scope matching the filter, latest first
+func (r *PostgresTransferRepository) Find(filter models.TransferFilter, scope tenant.Scope) ([]*models.Transfer, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + transferColumns + ` FROM pet_transfers
+              WHERE ($3 = '' OR pet_id = $3) AND ($4 = '' OR status = $4)
+              AND (($5 AND ` + tenant.Condition("to_shelter_id", 1) + `) OR ($6 AND ` + tenant.Condition("from_shelter_id", 1) + `))
+              ORDER BY created DESC`
+
+	return r.findMany(
+		query,
+		all,
+		shelterID,
+		filter.PetID,
+		filter.Status.String(),
+		filter.Direction != models.TransferOutgoing,
+		filter.Direction != models.TransferIncoming,
+	)
+}
+
+// FindByPetID finds every transfer of a pet, whichever shelters it went through, oldest first
+func (r *PostgresTransferRepository) FindByPetID(petID string) ([]*models.Transfer, error) {
+	query := `SELECT ` + transferColumns + ` FROM pet_transfers WHERE pet_id = $1 ORDER BY created`
+
+	return r.findMany(query, petID)
+}
+
+// Update updates the status, transport date and review of a transfer that is still in the
+// previous status, so concurrent reviews and cancellations can't overwrite each other
+func (r *PostgresTransferRepository) Update(transfer *models.Transfer, previous models.TransferStatus) error {
+	query := `UPDATE pet_transfers SET transport_date = $1, status = $2, reviewed_by = $3, reviewed_at = $4, updated = $5
+              WHERE id = $6 AND status = $7`
+
+	result, err := r.db.Exec(
+		query,
+		transfer.TransportDate.UTC(),
+		transfer.Status.String(),
+		nullString(transfer.ReviewedBy),
+		nullTime(transfer.ReviewedAt),
+		transfer.Updated.UTC(),
+		transfer.ID,
+		previous.String(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkTransferUpdated(result)
+}
+
+// Complete marks an approved transfer completed and moves its pet to the receiving shelter, unless
+// the pet no longer belongs to the sending shelter, is being adopted or has an appointment in
+// progress. The appointments that haven't started are cancelled, as the vets work at the sending
+// shelter, while the open adoptions of the pet and its sponsorships not yet received go along with it.
+// The records changed are set on the transfer.
+func (r *PostgresTransferRepository) Complete(transfer *models.Transfer) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Takes the lock appointments are booked under, so none can be booked for the pet until the move is over
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, "pet:"+transfer.PetID); err != nil {
+		return err
+	}
+
+	// Locks the pet, so it can't be adopted while it moves
+	var status string
+	err = tx.QueryRow(
+		`SELECT status FROM pets WHERE id = $1 AND shelter_id = $2 FOR UPDATE`,
+		transfer.PetID,
+		transfer.FromShelterID,
+	).Scan(&status)
+	if err == sql.ErrNoRows {
+		return models.ErrPetNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if models.Status(status).IsEquals(models.StatusAdopted) || models.Status(status).IsEquals(models.StatusInProcess) {
+		return models.ErrPetNotTransferable
+	}
+
+	completedAt := transfer.CompletedAt.UTC()
+
+	var cancelled []string
+	err = tx.Select(
+		&cancelled,
+		`DELETE FROM appointments WHERE pet_id = $1 AND status = $2 AND starts_at > $3 RETURNING id`,
+		transfer.PetID,
+		models.AppointmentScheduled.String(),
+		completedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	var started bool
+	err = tx.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM appointments WHERE pet_id = $1 AND status = $2)`,
+		transfer.PetID,
+		models.AppointmentScheduled.String(),
+	).Scan(&started)
+	if err != nil {
+		return err
+	}
+
+	if started {
+		return models.ErrPetHasAppointments
+	}
+
+	// Only completes a transfer that is still approved, in case it was cancelled or completed meanwhile
+	query := `UPDATE pet_transfers SET status = $1, completed_by = $2, completed_at = $3, updated = $4
+              WHERE id = $5 AND status = $6`
+
+	result, err := tx.Exec(
+		query,
+		transfer.Status.String(),
+		transfer.CompletedBy,
+		completedAt,
+		transfer.Updated.UTC(),
+		transfer.ID,
+		models.TransferApproved.String(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := checkTransferUpdated(result); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE pets SET shelter_id = $1, updated = $2 WHERE id = $3`,
+		transfer.ToShelterID,
+		completedAt,
+		transfer.PetID,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Closed adoptions stay with the shelter that handled them
+	var adoptions []string
+	err = tx.Select(
+		&adoptions,
+		`UPDATE adoptions SET shelter_id = $1, updated = $2 WHERE pet_id = $3 AND shelter_id = $4 AND status IN ('pending', 'approved')
+              RETURNING id`,
+		transfer.ToShelterID,
+		completedAt,
+		transfer.PetID,
+		transfer.FromShelterID,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Received donations stay with the shelter that got the money, keeping its ledger and reports right
+	var donations []string
+	err = tx.Select(
+		&donations,
+		`UPDATE donations SET shelter_id = $1, updated = $2 WHERE pet_id = $3 AND shelter_id = $4 AND status = 'pending'
+              RETURNING id`,
+		transfer.ToShelterID,
+		completedAt,
+		transfer.PetID,
+		transfer.FromShelterID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	transfer.CancelledAppointments = cancelled
+	transfer.MovedAdoptions = adoptions
+	transfer.MovedDonations = donations
+	return nil
+}
+
+// checkTransferUpdated reports a transfer whose status changed before it could be updated
+func checkTransferUpdated(result sql.Result) error {
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return models.ErrTransferChanged
+	}
+
+	return nil
+}
+
+// findMany runs a query returning rows of transferColumns
+func (r *PostgresTransferRepository) findMany(query string, args ...any) ([]*models.Transfer, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := []*models.Transfer{}
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, rows.Err()
+}
+
+// scanTransfer scans a row of transferColumns, returning nil if there is no row
+func scanTransfer(row interface{ Scan(dest ...any) error }) (*models.Transfer, error) {
+	var transfer models.Transfer
+	var reason, status string
+	var reviewedBy, completedBy sql.NullString
+	var reviewedAt, completedAt sql.NullTime
+
+	err := row.Scan(
+		&transfer.ID,
+		&transfer.PetID,
+		&transfer.FromShelterID,
+		&transfer.ToShelterID,
+		&reason,
+		&transfer.Notes,
+		&transfer.TransportDate,
+		&status,
+		&transfer.RequestedBy,
+		&reviewedBy,
+		&reviewedAt,
+		&completedBy,
+		&completedAt,
+		&transfer.Created,
+		&transfer.Updated,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	transfer.Reason = models.TransferReason(reason)
+	transfer.Status = models.TransferStatus(status)
+	transfer.ReviewedBy = reviewedBy.String
+	transfer.CompletedBy = completedBy.String
+
+	if reviewedAt.Valid {
+		transfer.ReviewedAt = &reviewedAt.Time
+	}
+
+	if completedAt.Valid {
+		transfer.CompletedAt = &completedAt.Time
+	}
+
+	return &transfer, nil
+}
+
+// nullString converts an optional ID to a column value
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,40 @@
+package sponsorship
+
+import (
+	donationPorts "github.com/solrac97gr/petparadise/internal/donations/domain/ports"
+	"github.com/solrac97gr/petparadise/internal/pets/domain/models"
+)
+
+// DonationsGateway implements the SponsorshipGateway and SponsorNotifier interfaces on top of the
+// donations module
+type DonationsGateway struct {
+	donations donationPorts.DonationService
+}
+
+// NewDonationsGateway creates a new DonationsGateway
+func NewDonationsGateway(donations donationPorts.DonationService) *DonationsGateway {
+	return &DonationsGateway{
+		donations: donations,
+	}
+}
+
+// GetSponsorship returns the sponsorship totals for a pet
+func (g *DonationsGateway) GetSponsorship(petID string) (*models.Sponsorship, error) {
+	summary, err := g.donations.GetPetSponsorship(petID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Sponsorship{
+		TotalAmount:   summary.TotalAmount,
+		OneOffAmount:  summary.OneOffAmount,
+		MonthlyAmount: summary.MonthlyAmount,
+		SponsorCount:  summary.SponsorCount,
+		Sponsors:      summary.Sponsors,
+	}, nil
+}
+
+// NotifySponsors lets the sponsors of a pet know that it has been adopted
+func (g *DonationsGateway) NotifySponsors(pet *models.Pet) error {
+	return g.donations.NotifyPetAdopted(pet.ID, pet.Name)
+}
@@ -0,0 +1,29 @@
+package vets
+
+import (
+	userModels "github.com/solrac97gr/petparadise/internal/users/domain/models"
+	userPorts "github.com/solrac97gr/petparadise/internal/users/domain/ports"
+)
+
+// UsersDirectory implements the VetDirectory interface on top of the users module
+type UsersDirectory struct {
+	users userPorts.UserRepository
+}
+
+// NewUsersDirectory creates a new UsersDirectory
+func NewUsersDirectory(users userPorts.UserRepository) *UsersDirectory {
+	return &UsersDirectory{
+		users: users,
+	}
+}
+
+// IsVet checks if a user is an active vet working at the shelter
+func (d *UsersDirectory) IsVet(userID, shelterID string) (bool, error) {
+	user, err := d.users.FindByID(userID)
+	if err != nil {
+		return false, err
+	}
+
+	return user != nil && user.Role.IsEquals(userModels.RoleVet) && user.Status.IsEquals(userModels.StatusActive) &&
+		user.ShelterID == shelterID, nil
+}
@@ -0,0 +1,67 @@
+package aplication
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/shelters/domain/models"
+	"github.com/solrac97gr/petparadise/internal/shelters/domain/ports"
+)
+
+// ShelterService implements the ShelterService interface
+type ShelterService struct {
+	repository ports.ShelterRepository
+}
+
+// NewShelterService creates a new ShelterService instance
+func NewShelterService(repository ports.ShelterRepository) *ShelterService {
+	return &ShelterService{
+		repository: repository,
+	}
+}
+
+// CreateShelter adds a shelter to the platform
+func (s *ShelterService) CreateShelter(name, address, phone, email string) (*models.Shelter, error) {
+	shelter, err := models.NewShelter(uuid.New().String(), name, address, phone, email, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.Save(shelter); err != nil {
+		return nil, err
+	}
+
+	return shelter, nil
+}
+
+// GetShelterByID returns a shelter by its ID
+func (s *ShelterService) GetShelterByID(id string) (*models.Shelter, error) {
+	return s.repository.FindByID(id)
+}
+
+// GetAllShelters returns every shelter, by name
+func (s *ShelterService) GetAllShelters() ([]*models.Shelter, error) {
+	return s.repository.FindAll()
+}
+
+// UpdateShelter changes the name and contact details of a shelter
+func (s *ShelterService) UpdateShelter(id, name, address, phone, email string) (*models.Shelter, error) {
+	shelter, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if shelter == nil {
+		return nil, models.ErrShelterNotFound
+	}
+
+	if err := shelter.Update(name, address, phone, email, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.Update(shelter); err != nil {
+		return nil, err
+	}
+
+	return shelter, nil
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrShelterNotFound     = errors.New("shelter not found")
+	ErrInvalidShelterName  = errors.New("name is required and must be at most 100 characters")
+	ErrInvalidShelterField = errors.New("address, phone and email must be at most 255 characters")
+	ErrInvalidShelterEmail = errors.New("invalid shelter email")
+)
+
+// DefaultShelterID is the shelter the records from before there were several shelters belong to
+const DefaultShelterID = "00000000-0000-0000-0000-000000000001"
+
+const (
+	maxNameLength  = 100
+	maxFieldLength = 255
+)
+
+// Shelter is one of the organizations sharing the platform. Pets, adoptions, donations, shifts
+// and staff belong to a shelter, and staff only reach the records of their own.
+type Shelter struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Address string    `json:"address"`
+	Phone   string    `json:"phone"`
+	Email   string    `json:"email"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// NewShelter creates a new Shelter instance
+func NewShelter(id, name, address, phone, email string, now time.Time) (*Shelter, error) {
+	shelter := &Shelter{
+		ID:      id,
+		Created: now,
+	}
+
+	if err := shelter.Update(name, address, phone, email, now); err != nil {
+		return nil, err
+	}
+
+	return shelter, nil
+}
+
+// Update changes the name and contact details of the shelter
+func (s *Shelter) Update(name, address, phone, email string, now time.Time) error {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return ErrInvalidShelterName
+	}
+
+	address = strings.TrimSpace(address)
+	phone = strings.TrimSpace(phone)
+	email = strings.TrimSpace(email)
+	for _, field := range []string{address, phone, email} {
+		if utf8.RuneCountInString(field) > maxFieldLength {
+			return ErrInvalidShelterField
+		}
+	}
+
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return ErrInvalidShelterEmail
+		}
+	}
+
+	s.Name = name
+	s.Address = address
+	s.Phone = phone
+	s.Email = email
+	s.Updated = now
+
+	return nil
+}
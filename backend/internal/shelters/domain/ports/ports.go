@@ -0,0 +1,17 @@
+package ports
+
+import "github.com/solrac97gr/petparadise/internal/shelters/domain/models"
+
+type ShelterRepository interface {
+	Save(shelter *models.Shelter) error
+	FindByID(id string) (*models.Shelter, error)
+	FindAll() ([]*models.Shelter, error)
+	Update(shelter *models.Shelter) error
+}
+
+type ShelterService interface {
+	CreateShelter(name, address, phone, email string) (*models.Shelter, error)
+	GetShelterByID(id string) (*models.Shelter, error)
+	GetAllShelters() ([]*models.Shelter, error)
+	UpdateShelter(id, name, address, phone, email string) (*models.Shelter, error)
+}
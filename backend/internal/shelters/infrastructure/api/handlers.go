@@ -0,0 +1,12 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+type ShelterHandler interface {
+	CreateShelter(c *fiber.Ctx) error
+	GetAllShelters(c *fiber.Ctx) error
+	GetShelterByID(c *fiber.Ctx) error
+	UpdateShelter(c *fiber.Ctx) error
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/shelters/aplication"
+	"github.com/solrac97gr/petparadise/internal/shelters/infrastructure/repository"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+)
+
+// SetupShelterRoutes sets up all shelter routes
+func SetupShelterRoutes(router fiber.Router, db *sqlx.DB) {
+	// Initialize repository
+	shelterRepo := repository.NewPostgresRepository(db)
+
+	// Initialize service
+	shelterService := aplication.NewShelterService(shelterRepo)
+
+	// Initialize handler
+	shelterHandler := NewShelterHandler(shelterService)
+
+	// Public routes - anyone can see the shelters, to pick the one to adopt from or donate to
+	router.Get("/", shelterHandler.GetAllShelters)
+	router.Get("/:shelterId", shelterHandler.GetShelterByID)
+
+	// Protected routes - require authentication
+	protected := router.Use(auth.Protected())
+
+	// Platform admins add shelters, shelter admins keep their own shelter's details up to date
+	canManage := auth.RequirePermission(models.PermissionSheltersManage)
+	protected.Post("/", canManage, auth.RequirePlatformAdmin(), shelterHandler.CreateShelter)
+	protected.Put("/:shelterId", canManage, shelterHandler.UpdateShelter)
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/shelters/domain/models"
+	"github.com/solrac97gr/petparadise/internal/shelters/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+)
+
+// shelterRequest is the body of the requests creating and updating shelters
+type shelterRequest struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Phone   string `json:"phone"`
+	Email   string `json:"email"`
+}
+
+type shelterHandler struct {
+	service ports.ShelterService
+}
+
+// NewShelterHandler creates a new shelter handler
+func NewShelterHandler(service ports.ShelterService) ShelterHandler {
+	return &shelterHandler{
+		service: service,
+	}
+}
+
+// CreateShelter handles adding a shelter to the platform
+func (h *shelterHandler) CreateShelter(c *fiber.Ctx) error {
+	var req shelterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	shelter, err := h.service.CreateShelter(req.Name, req.Address, req.Phone, req.Email)
+	if err != nil {
+		return shelterErrorResponse(c, err)
+	}
+
+	audit.Record(c, "shelter.create", "shelter", shelter.ID, nil, shelter)
+
+	return c.Status(fiber.StatusCreated).JSON(shelter)
+}
+
+// GetAllShelters handles listing every shelter
+func (h *shelterHandler) GetAllShelters(c *fiber.Ctx) error {
+	shelters, err := h.service.GetAllShelters()
+	if err != nil {
+		return shelterErrorResponse(c, err)
+	}
+
+	return c.JSON(shelters)
+}
+
+// GetShelterByID handles getting a single shelter by ID
+func (h *shelterHandler) GetShelterByID(c *fiber.Ctx) error {
+	id := c.Params("shelterId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	shelter, err := h.service.GetShelterByID(id)
+	if err != nil {
+		return shelterErrorResponse(c, err)
+	}
+
+	if shelter == nil {
+		return shelterErrorResponse(c, models.ErrShelterNotFound)
+	}
+
+	return c.JSON(shelter)
+}
+
+// UpdateShelter handles changing the name and contact details of a shelter. Shelter admins can
+// only update their own shelter.
+func (h *shelterHandler) UpdateShelter(c *fiber.Ctx) error {
+	id := c.Params("shelterId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	if !auth.ShelterScope(c).Allows(id) {
+		return shelterErrorResponse(c, models.ErrShelterNotFound)
+	}
+
+	var req shelterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Kept for the audit log
+	before, err := h.service.GetShelterByID(id)
+	if err != nil {
+		return shelterErrorResponse(c, err)
+	}
+
+	shelter, err := h.service.UpdateShelter(id, req.Name, req.Address, req.Phone, req.Email)
+	if err != nil {
+		return shelterErrorResponse(c, err)
+	}
+
+	audit.Record(c, "shelter.update", "shelter", shelter.ID, before, shelter)
+
+	return c.JSON(shelter)
+}
+
+// shelterErrorResponse maps shelter errors to HTTP responses
+func shelterErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case models.ErrInvalidShelterName, models.ErrInvalidShelterField, models.ErrInvalidShelterEmail:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrShelterNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
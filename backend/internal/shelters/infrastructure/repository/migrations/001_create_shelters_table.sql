@@ -0,0 +1,15 @@
+-- The shelters sharing the platform. Records from before there were several shelters belong to
+-- the default shelter, created here.
+CREATE TABLE IF NOT EXISTS shelters (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    address VARCHAR(255) NOT NULL DEFAULT '',
+    phone VARCHAR(255) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created TIMESTAMP NOT NULL,
+    updated TIMESTAMP NOT NULL
+);
+
+INSERT INTO shelters (id, name, created, updated)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Pet Paradise', NOW() AT TIME ZONE 'UTC', NOW() AT TIME ZONE 'UTC')
+ON CONFLICT (id) DO NOTHING;
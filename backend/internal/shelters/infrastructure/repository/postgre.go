@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/shelters/domain/models"
+)
+
+// shelterColumns are the columns scanned by scanShelter
+const shelterColumns = `id, name, address, phone, email, created, updated`
+
+// PostgresRepository implements the ShelterRepository interface.
+type PostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRepository creates a new PostgresRepository
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+	}
+}
+
+// Save saves a shelter
+func (r *PostgresRepository) Save(shelter *models.Shelter) error {
+	query := `INSERT INTO shelters (id, name, address, phone, email, created, updated)
+              VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.db.Exec(
+		query,
+		shelter.ID,
+		shelter.Name,
+		shelter.Address,
+		shelter.Phone,
+		shelter.Email,
+		shelter.Created.UTC(),
+		shelter.Updated.UTC(),
+	)
+
+	return err
+}
+
+// FindByID finds a shelter by its ID
+func (r *PostgresRepository) FindByID(id string) (*models.Shelter, error) {
+	return scanShelter(r.db.QueryRow(`SELECT `+shelterColumns+` FROM shelters WHERE id = $1`, id))
+}
+
+// FindAll finds every shelter, by name
+func (r *PostgresRepository) FindAll() ([]*models.Shelter, error) {
+	rows, err := r.db.Query(`SELECT ` + shelterColumns + ` FROM shelters ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shelters := []*models.Shelter{}
+	for rows.Next() {
+		shelter, err := scanShelter(rows)
+		if err != nil {
+			return nil, err
+		}
+		shelters = append(shelters, shelter)
+	}
+
+	return shelters, rows.Err()
+}
+
+// Update updates the name and contact details of a shelter
+func (r *PostgresRepository) Update(shelter *models.Shelter) error {
+	query := `UPDATE shelters SET name = $1, address = $2, phone = $3, email = $4, updated = $5 WHERE id = $6`
+
+	_, err := r.db.Exec(
+		query,
+		shelter.Name,
+		shelter.Address,
+		shelter.Phone,
+		shelter.Email,
+		shelter.Updated.UTC(),
+		shelter.ID,
+	)
+
+	return err
+}
+
+// scanShelter scans a row of shelterColumns, returning nil if there is no row
+func scanShelter(row interface{ Scan(dest ...any) error }) (*models.Shelter, error) {
+	var shelter models.Shelter
+
+	err := row.Scan(
+		&shelter.ID,
+		&shelter.Name,
+		&shelter.Address,
+		&shelter.Phone,
+		&shelter.Email,
+		&shelter.Created,
+		&shelter.Updated,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &shelter, nil
+}
@@ -0,0 +1,245 @@
+package aplication
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
+)
+
+// AccountDeletionService implements the AccountDeletionService interface. Accounts aren't
+// deleted outright: after a grace period during which the deletion can be cancelled, their
+// personal data is erased and the user is kept as a tombstone, so financial records and
+// adoptions stay linked to it.
+type AccountDeletionService struct {
+	repository ports.AccountDeletionRepository
+	users      ports.UserRepository
+	modules    []ports.PersonalDataModule // Personal data kept by the other modules
+	mailer     mailer.Mailer
+}
+
+// NewAccountDeletionService creates a new AccountDeletionService instance
+func NewAccountDeletionService(repository ports.AccountDeletionRepository, users ports.UserRepository, modules []ports.PersonalDataModule,
+	mail mailer.Mailer) *AccountDeletionService {
+	return &AccountDeletionService{
+		repository: repository,
+		users:      users,
+		modules:    modules,
+		mailer:     mail,
+	}
+}
+
+// RequestDeletion schedules the erasure of an account after AccountDeletionGracePeriod and lets
+// the user know. requestedBy is the user themselves or the admin deleting the account.
+func (s *AccountDeletionService) RequestDeletion(userID, requestedBy string) (*models.AccountDeletion, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.repository.FindPendingByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pending != nil {
+		return nil, models.ErrAccountDeletionPending
+	}
+
+	deletion := models.NewAccountDeletion(uuid.New().String(), userID, requestedBy, time.Now())
+	if err := s.repository.Save(deletion); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Deletion of user %s requested by %s, scheduled for %s", userID, requestedBy, deletion.ScheduledFor.Format(time.RFC3339))
+
+	err = s.mailer.Send(&mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Your Pet Paradise account will be deleted",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account is scheduled to be deleted on %s. Your personal details will then be erased; "+
+			"the record of your donations and adoptions is kept without them.\n\nIf you change your mind, log in and cancel the deletion before then. "+
+			"If you didn't ask for it, please contact us right away.\n\nPet Paradise",
+			user.Name, deletion.ScheduledFor.Format("January 2, 2006")),
+	})
+	if err != nil {
+		log.Printf("Failed to send account deletion email to user %s: %v", userID, err)
+	}
+
+	return deletion, nil
+}
+
+// GetPendingDeletion returns the deletion of an account that is still in its grace period
+func (s *AccountDeletionService) GetPendingDeletion(userID string) (*models.AccountDeletion, error) {
+	if _, err := s.findUser(userID); err != nil {
+		return nil, err
+	}
+
+	deletion, err := s.repository.FindPendingByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if deletion == nil {
+		return nil, models.ErrAccountDeletionNotFound
+	}
+
+	return deletion, nil
+}
+
+// CancelDeletion cancels the deletion of an account during its grace period
+func (s *AccountDeletionService) CancelDeletion(userID string) (*models.AccountDeletion, error) {
+	deletion, err := s.GetPendingDeletion(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	cancelled, err := s.repository.Cancel(deletion.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cancelled {
+		return nil, models.ErrAccountDeletionNotFound
+	}
+
+	log.Printf("Deletion of user %s cancelled", userID)
+
+	deletion.CancelledAt = &now
+	deletion.Status = deletion.CurrentStatus()
+	return deletion, nil
+}
+
+// EraseDueAccounts erases the accounts whose grace period is over. An account that can't be
+// erased, or whose data the other modules couldn't erase, is logged and tried again on the next run.
+func (s *AccountDeletionService) EraseDueAccounts(now time.Time) error {
+	deletions, err := s.repository.FindDue(now)
+	if err != nil {
+		return err
+	}
+
+	for _, deletion := range deletions {
+		if err := s.erase(deletion, now); err != nil {
+			log.Printf("Failed to erase user %s: %v", deletion.UserID, err)
+		}
+	}
+
+	return nil
+}
+
+// StartEraser erases the accounts whose grace period is over every interval, until the
+// returned stop function is called
+func (s *AccountDeletionService) StartEraser(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				if err := s.EraseDueAccounts(now); err != nil {
+					log.Printf("Failed to find the accounts to erase: %v", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// erase scrubs the personal data of an account. The deletion is claimed first, erasing the account
+// itself, so it can't be cancelled once the other modules start erasing what they keep; their
+// erasure is tried again on the next run until it succeeds.
+func (s *AccountDeletionService) erase(deletion *models.AccountDeletion, now time.Time) error {
+	user, err := s.users.FindByID(deletion.UserID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	if deletion.ErasedAt == nil {
+		erased, err := s.repository.Erase(deletion, now)
+		if err != nil {
+			return err
+		}
+
+		// Cancelled in the meantime
+		if !erased {
+			return nil
+		}
+
+		s.accountErased(user)
+	}
+
+	for _, module := range s.modules {
+		if err := module.ErasePersonalData(user.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repository.Complete(deletion.ID, now); err != nil {
+		return err
+	}
+
+	audit.RecordSystem("user.erase_complete", "user", user.ID, nil, map[string]any{"completed_at": now})
+	return nil
+}
+
+// accountErased ends the sessions of a user whose account was just erased and sends the last email
+// to their address, which is no longer kept
+func (s *AccountDeletionService) accountErased(user *models.User) {
+	// The sessions are gone, access tokens issued before now are refused too
+	if err := auth.RevokeAllUserTokens(user.ID); err != nil {
+		log.Printf("Failed to revoke the tokens of erased user %s: %v", user.ID, err)
+	}
+
+	log.Printf("User %s erased", user.ID)
+	audit.RecordSystem("user.erase", "user", user.ID,
+		map[string]any{"status": user.Status}, map[string]any{"status": models.StatusDeleted})
+
+	err := s.mailer.Send(&mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Your Pet Paradise account has been deleted",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account has been deleted and your personal details erased. "+
+			"Thank you for everything you did for our animals.\n\nPet Paradise", user.Name),
+	})
+	if err != nil {
+		log.Printf("Failed to send account erased email to user %s: %v", user.ID, err)
+	}
+}
+
+// findUser returns a user that exists and hasn't been erased
+func (s *AccountDeletionService) findUser(userID string) (*models.User, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	if user.Status.IsEquals(models.StatusDeleted) {
+		return nil, models.ErrAccountDeleted
+	}
+
+	return user, nil
+}
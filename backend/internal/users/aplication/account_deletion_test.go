@@ -0,0 +1,230 @@
+package aplication
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
+)
+
+// fakeAccountDeletionRepository keeps deletions in memory, claiming them as the Postgres one does
+type fakeAccountDeletionRepository struct {
+	deletions map[string]*models.AccountDeletion
+	users     *fakeUserRepository
+}
+
+func (r *fakeAccountDeletionRepository) Save(deletion *models.AccountDeletion) error {
+	r.deletions[deletion.ID] = deletion
+	return nil
+}
+
+func (r *fakeAccountDeletionRepository) FindPendingByUserID(userID string) (*models.AccountDeletion, error) {
+	for _, deletion := range r.deletions {
+		if deletion.UserID == userID && deletion.CancelledAt == nil && deletion.ErasedAt == nil {
+			copied := *deletion
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeAccountDeletionRepository) FindDue(now time.Time) ([]*models.AccountDeletion, error) {
+	var due []*models.AccountDeletion
+	for _, deletion := range r.deletions {
+		pending := deletion.CancelledAt == nil && deletion.ErasedAt == nil && !deletion.ScheduledFor.After(now)
+		unfinished := deletion.ErasedAt != nil && deletion.CompletedAt == nil
+		if pending || unfinished {
+			copied := *deletion
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeAccountDeletionRepository) Cancel(id string, cancelledAt time.Time) (bool, error) {
+	deletion, ok := r.deletions[id]
+	if !ok || deletion.CancelledAt != nil || deletion.ErasedAt != nil {
+		return false, nil
+	}
+	deletion.CancelledAt = &cancelledAt
+	return true, nil
+}
+
+func (r *fakeAccountDeletionRepository) Erase(deletion *models.AccountDeletion, erasedAt time.Time) (bool, error) {
+	stored, ok := r.deletions[deletion.ID]
+	if !ok || stored.CancelledAt != nil || stored.ErasedAt != nil {
+		return false, nil
+	}
+	stored.ErasedAt = &erasedAt
+
+	user := r.users.users[deletion.UserID]
+	user.Name = "Deleted user"
+	user.Email = models.TombstoneEmail(user.ID)
+	user.Status = models.StatusDeleted
+	return true, nil
+}
+
+func (r *fakeAccountDeletionRepository) Complete(id string, completedAt time.Time) error {
+	deletion, ok := r.deletions[id]
+	if ok && deletion.ErasedAt != nil && deletion.CompletedAt == nil {
+		deletion.CompletedAt = &completedAt
+	}
+	return nil
+}
+
+// fakeEraser is a module that records the users it erased, failing as many times as asked first
+type fakeEraser struct {
+	failures int
+	erased   []string
+}
+
+func (e *fakeEraser) ExportPersonalData(userID string) ([]*models.ExportSection, error) {
+	return nil, nil
+}
+
+func (e *fakeEraser) ErasePersonalData(userID string) error {
+	if e.failures > 0 {
+		e.failures--
+		return errors.New("module unavailable")
+	}
+	e.erased = append(e.erased, userID)
+	return nil
+}
+
+// fakeMailer keeps the messages it was asked to send
+type fakeMailer struct {
+	sent []*mailer.Message
+}
+
+func (m *fakeMailer) Send(msg *mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// newAccountDeletionTest creates a service with a user whose deletion is due at now
+func newAccountDeletionTest(t *testing.T, eraser *fakeEraser, now time.Time) (*AccountDeletionService, *fakeAccountDeletionRepository, *fakeMailer, *models.AccountDeletion) {
+	t.Helper()
+
+	users := newFakeUserRepository(&models.User{
+		ID:     "user-1",
+		Name:   "Jane Doe",
+		Email:  "jane@example.com",
+		Role:   models.RoleUser,
+		Status: models.StatusActive,
+	})
+	deletions := &fakeAccountDeletionRepository{deletions: map[string]*models.AccountDeletion{}, users: users}
+	mail := &fakeMailer{}
+	service := NewAccountDeletionService(deletions, users, []ports.PersonalDataModule{eraser}, mail)
+
+	deletion, err := service.RequestDeletion("user-1", "user-1")
+	if err != nil {
+		t.Fatalf("RequestDeletion() error = %v", err)
+	}
+	deletions.deletions[deletion.ID].ScheduledFor = now
+
+	return service, deletions, mail, deletion
+}
+
+func TestEraseDueAccounts(t *testing.T) {
+	now := time.Now().UTC()
+	eraser := &fakeEraser{}
+	service, deletions, mail, deletion := newAccountDeletionTest(t, eraser, now)
+
+	if err := service.EraseDueAccounts(now); err != nil {
+		t.Fatalf("EraseDueAccounts() error = %v", err)
+	}
+
+	stored := deletions.deletions[deletion.ID]
+	if stored.ErasedAt == nil || stored.CompletedAt == nil {
+		t.Errorf("EraseDueAccounts() left erased_at = %v, completed_at = %v, want both set", stored.ErasedAt, stored.CompletedAt)
+	}
+
+	if len(eraser.erased) != 1 || eraser.erased[0] != "user-1" {
+		t.Errorf("erased users = %v, want [user-1]", eraser.erased)
+	}
+
+	user := deletions.users.users["user-1"]
+	if user.Status != models.StatusDeleted || user.Email != models.TombstoneEmail("user-1") {
+		t.Errorf("user = %s, %s, want a tombstone", user.Status, user.Email)
+	}
+
+	// The request and the erasure
+	if len(mail.sent) != 2 || mail.sent[1].To[0] != "jane@example.com" {
+		t.Errorf("sent %d emails, want the erasure one to the old address", len(mail.sent))
+	}
+
+	if _, err := service.CancelDeletion("user-1"); err == nil {
+		t.Error("CancelDeletion() of an erased account succeeded")
+	}
+}
+
+func TestCancelledDeletionIsNotErased(t *testing.T) {
+	now := time.Now().UTC()
+	eraser := &fakeEraser{}
+	service, deletions, _, deletion := newAccountDeletionTest(t, eraser, now)
+
+	if _, err := service.CancelDeletion("user-1"); err != nil {
+		t.Fatalf("CancelDeletion() error = %v", err)
+	}
+
+	// Cancelled after the job found it due
+	due := *deletions.deletions[deletion.ID]
+	due.CancelledAt = nil
+	if err := service.erase(&due, now); err != nil {
+		t.Fatalf("erase() error = %v", err)
+	}
+
+	if len(eraser.erased) != 0 {
+		t.Errorf("erased users = %v, want none", eraser.erased)
+	}
+
+	if stored := deletions.deletions[deletion.ID]; stored.ErasedAt != nil {
+		t.Errorf("erased_at = %v, want the cancelled deletion left alone", stored.ErasedAt)
+	}
+
+	if user := deletions.users.users["user-1"]; user.Status != models.StatusActive {
+		t.Errorf("user status = %s, want %s", user.Status, models.StatusActive)
+	}
+}
+
+func TestFailedErasureIsRetried(t *testing.T) {
+	now := time.Now().UTC()
+	eraser := &fakeEraser{failures: 1}
+	service, deletions, mail, deletion := newAccountDeletionTest(t, eraser, now)
+
+	if err := service.EraseDueAccounts(now); err != nil {
+		t.Fatalf("EraseDueAccounts() error = %v", err)
+	}
+
+	stored := deletions.deletions[deletion.ID]
+	if stored.ErasedAt == nil || stored.CompletedAt != nil {
+		t.Fatalf("after a failed eraser erased_at = %v, completed_at = %v, want only erased_at set", stored.ErasedAt, stored.CompletedAt)
+	}
+
+	if _, err := service.CancelDeletion("user-1"); err == nil {
+		t.Error("CancelDeletion() of a claimed deletion succeeded")
+	}
+
+	if err := service.EraseDueAccounts(now.Add(time.Hour)); err != nil {
+		t.Fatalf("EraseDueAccounts() error = %v", err)
+	}
+
+	if stored.CompletedAt == nil {
+		t.Error("completed_at not set after the retry")
+	}
+
+	if len(eraser.erased) != 1 {
+		t.Errorf("erased users = %v, want one erasure", eraser.erased)
+	}
+
+	// The retry doesn't claim the deletion nor email the user again
+	if !stored.ErasedAt.Equal(now) {
+		t.Errorf("erased_at = %v, want %v", stored.ErasedAt, now)
+	}
+	if len(mail.sent) != 2 {
+		t.Errorf("sent %d emails, want 2", len(mail.sent))
+	}
+}
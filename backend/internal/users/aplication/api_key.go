@@ -0,0 +1,85 @@
+package aplication
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// APIKeyService implements the APIKeyService interface, letting admins manage the API keys of
+// machine clients. The keys are kept by the auth package's API key store.
+type APIKeyService struct{}
+
+// NewAPIKeyService creates a new APIKeyService instance
+func NewAPIKeyService() *APIKeyService {
+	return &APIKeyService{}
+}
+
+// CreateAPIKey creates an API key for a shelter, or for every shelter when platformWide is set,
+// and returns it along with the key itself, which is only shown now. Keys can only be granted
+// permissions the role of the admin creating them has.
+func (s *APIKeyService) CreateAPIKey(name string, permissions []models.Permission, expiresAt *time.Time, creatorID string, creatorRole models.Role, shelterID string, platformWide bool) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, "", models.ErrInvalidAPIKeyName
+	}
+
+	if platformWide && shelterID != "" {
+		return nil, "", models.ErrAPIKeyPlatformWideShelter
+	}
+
+	if !platformWide && shelterID == "" {
+		return nil, "", models.ErrAPIKeyShelterRequired
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", models.ErrInvalidAPIKeyExpiry
+	}
+
+	for _, permission := range permissions {
+		if !permission.IsValid() {
+			return nil, "", models.ErrInvalidPermission
+		}
+
+		if !permission.IsGrantableToAPIKey() {
+			return nil, "", models.ErrAPIKeyPermissionNotGrantable
+		}
+	}
+
+	// Kept in the order of AllPermissions, without duplicates
+	granted := make([]models.Permission, 0, len(permissions))
+	for _, permission := range models.AllPermissions {
+		if slices.Contains(permissions, permission) {
+			granted = append(granted, permission)
+		}
+	}
+
+	if len(granted) == 0 {
+		return nil, "", models.ErrNoAPIKeyPermissions
+	}
+
+	held, err := auth.HasPermission(creatorRole, granted...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !held {
+		return nil, "", models.ErrAPIKeyPermissionNotHeld
+	}
+
+	return auth.CreateAPIKey(name, granted, creatorID, shelterID, platformWide, expiresAt)
+}
+
+// GetAPIKeys returns every API key of the shelters in scope, revoked and expired ones included
+func (s *APIKeyService) GetAPIKeys(scope tenant.Scope) ([]*models.APIKey, error) {
+	return auth.GetAPIKeys(scope)
+}
+
+// RevokeAPIKey revokes an API key of a shelter in scope
+func (s *APIKeyService) RevokeAPIKey(id string, scope tenant.Scope) (*models.APIKey, error) {
+	return auth.RevokeAPIKey(id, scope)
+}
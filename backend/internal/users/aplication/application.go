@@ -2,27 +2,55 @@ package aplication
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/solrac97gr/petparadise/internal/users/domain/models"
 	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // UserService implements the UserService interface
 type UserService struct {
-	repository ports.UserRepository
+	repository       ports.UserRepository
+	verifications    ports.VerificationRepository
+	passwordResets   ports.PasswordResetRepository
+	passwordHistory  ports.PasswordHistoryRepository
+	throttles        ports.LoginThrottleRepository
+	mailer           mailer.Mailer
+	passwordPolicy   models.PasswordPolicy
+	appBaseURL       string
+	passwordResetURL string
 }
 
-// NewUserService creates a new UserService instance
-func NewUserService(repository ports.UserRepository) *UserService {
+// NewUserService creates a new UserService instance. New passwords must meet passwordPolicy.
+// Verification links sent by email point to appBaseURL, and password reset links to the
+// passwordResetURL page of the web app.
+func NewUserService(repository ports.UserRepository, verifications ports.VerificationRepository, passwordResets ports.PasswordResetRepository,
+	passwordHistory ports.PasswordHistoryRepository, throttles ports.LoginThrottleRepository, mail mailer.Mailer, passwordPolicy models.PasswordPolicy,
+	appBaseURL, passwordResetURL string) *UserService {
 	return &UserService{
-		repository: repository,
+		repository:       repository,
+		verifications:    verifications,
+		passwordResets:   passwordResets,
+		passwordHistory:  passwordHistory,
+		throttles:        throttles,
+		mailer:           mail,
+		passwordPolicy:   passwordPolicy,
+		appBaseURL:       strings.TrimRight(appBaseURL, "/"),
+		passwordResetURL: passwordResetURL,
 	}
 }
 
-// CreateUser creates a new user
+// CreateUser creates a new user. The account stays pending until the user follows the
+// verification link sent to their email address.
 func (s *UserService) CreateUser(name, email, password string, role models.Role, address, phone string, documents []string) (*models.User, error) {
 	// Check if email is already in use
 	existingUser, err := s.repository.FindByEmail(email)
@@ -34,6 +62,10 @@ func (s *UserService) CreateUser(name, email, password string, role models.Role,
 		return nil, errors.New("email already in use")
 	}
 
+	if err := s.passwordPolicy.Validate(password, email, name); err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -48,7 +80,7 @@ func (s *UserService) CreateUser(name, email, password string, role models.Role,
 		name,
 		email,
 		string(hashedPassword),
-		models.StatusActive,
+		models.StatusPending,
 		role,
 		address,
 		phone,
@@ -66,9 +98,126 @@ func (s *UserService) CreateUser(name, email, password string, role models.Role,
 		return nil, err
 	}
 
+	// The account is kept if the email can't be sent; the user can ask for it again
+	if err := s.sendVerificationEmail(user, time.Now()); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+	}
+
 	return user, nil
 }
 
+// VerifyEmail activates the pending account a verification token was issued for.
+// Verifying an account that is already active succeeds without changes.
+func (s *UserService) VerifyEmail(token string) (*models.User, error) {
+	claims, err := auth.ValidateEmailVerificationToken(token)
+	if err == auth.ErrExpiredToken {
+		return nil, errors.New("verification token has expired")
+	} else if err != nil {
+		return nil, errors.New("invalid verification token")
+	}
+
+	user, err := s.repository.FindByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Links sent to a previous address of the user are no longer valid
+	if user == nil || !strings.EqualFold(user.Email, claims.Email) {
+		return nil, errors.New("invalid verification token")
+	}
+
+	if user.Status.IsEquals(models.StatusActive) {
+		return user, nil
+	}
+
+	if !user.Status.IsEquals(models.StatusPending) {
+		return nil, errors.New("user account is not active")
+	}
+
+	user.Status = models.StatusActive
+	user.Updated = time.Now().Format(time.RFC3339)
+
+	err = s.repository.Update(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ResendVerificationEmail sends a new verification link to a pending account. Requests are rate
+// limited per address, and addresses that don't belong to a pending account are silently
+// ignored so the endpoint can't be used to find out who is registered.
+func (s *UserService) ResendVerificationEmail(email string) error {
+	now := time.Now()
+
+	if err := s.checkVerificationRateLimit(email, now); err != nil {
+		return err
+	}
+
+	user, err := s.repository.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	if user == nil || !user.Status.IsEquals(models.StatusPending) {
+		// Count the request anyway so limited and unknown addresses look the same
+		return s.verifications.RecordVerificationEmail(email, now)
+	}
+
+	return s.sendVerificationEmail(user, now)
+}
+
+// checkVerificationRateLimit returns a RateLimitError when no more verification emails can be sent to an address yet
+func (s *UserService) checkVerificationRateLimit(email string, now time.Time) error {
+	sent, err := s.verifications.FindVerificationEmailsSince(email, now.Add(-models.VerificationEmailWindow))
+	if err != nil {
+		return err
+	}
+
+	if len(sent) == 0 {
+		return nil
+	}
+
+	retryAt := sent[len(sent)-1].Add(models.VerificationResendCooldown)
+
+	if len(sent) >= models.MaxVerificationEmails {
+		// Wait until enough emails have left the window
+		windowRetryAt := sent[len(sent)-models.MaxVerificationEmails].Add(models.VerificationEmailWindow)
+		if windowRetryAt.After(retryAt) {
+			retryAt = windowRetryAt
+		}
+	}
+
+	if retryAt.After(now) {
+		return &models.RateLimitError{RetryAfter: retryAt.Sub(now)}
+	}
+
+	return nil
+}
+
+// sendVerificationEmail records and sends a verification link to the user
+func (s *UserService) sendVerificationEmail(user *models.User, now time.Time) error {
+	token, err := auth.GenerateEmailVerificationToken(user)
+	if err != nil {
+		return err
+	}
+
+	if err := s.verifications.RecordVerificationEmail(user.Email, now); err != nil {
+		return err
+	}
+
+	link := s.appBaseURL + "/api/users/verify?token=" + url.QueryEscape(token)
+
+	return s.mailer.Send(&mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Verify your Pet Paradise email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\n"+
+			"The link expires in %d hours. If you didn't create an account, you can ignore this email.\n\nPet Paradise",
+			user.Name, link, int(auth.EmailVerificationExpiration.Hours())),
+	})
+}
+
 // GetUserByID returns a user by their ID
 func (s *UserService) GetUserByID(id string) (*models.User, error) {
 	return s.repository.FindByID(id)
@@ -79,18 +228,25 @@ func (s *UserService) GetUserByEmail(email string) (*models.User, error) {
 	return s.repository.FindByEmail(email)
 }
 
-// GetUsersByStatus returns all users with a specific status
-func (s *UserService) GetUsersByStatus(status models.Status) ([]*models.User, error) {
+// GetUsersByStatus returns all users with a specific status, among the staff of the shelters in
+// scope and the users who adopted or donated there without working at a shelter
+func (s *UserService) GetUsersByStatus(status models.Status, scope tenant.Scope) ([]*models.User, error) {
 	if !status.IsValid() {
 		return nil, models.ErrInvalidStatus
 	}
 
-	return s.repository.FindByStatus(status)
+	return s.repository.FindByStatus(status, scope)
+}
+
+// GetAllUsers returns the staff of the shelters in scope and the users who adopted or donated
+// there without working at a shelter
+func (s *UserService) GetAllUsers(scope tenant.Scope) ([]*models.User, error) {
+	return s.repository.FindAll(scope)
 }
 
-// GetAllUsers returns all users
-func (s *UserService) GetAllUsers() ([]*models.User, error) {
-	return s.repository.FindAll()
+// IsUserInScope checks if the shelters in scope can reach a user, as GetAllUsers would list them
+func (s *UserService) IsUserInScope(id string, scope tenant.Scope) (bool, error) {
+	return s.repository.IsInScope(id, scope)
 }
 
 // UpdateUser updates a user's information
@@ -104,6 +260,10 @@ func (s *UserService) UpdateUser(id, name, email, address, phone string, documen
 		return nil, errors.New("user not found")
 	}
 
+	if user.Status.IsEquals(models.StatusDeleted) {
+		return nil, models.ErrAccountDeleted
+	}
+
 	// If email is changed, check if the new email is already in use
 	if email != user.Email {
 		existingUser, err := s.repository.FindByEmail(email)
@@ -141,8 +301,9 @@ func (s *UserService) UpdateUser(id, name, email, address, phone string, documen
 	return user, nil
 }
 
-// UpdateUserRole updates a user's role
-func (s *UserService) UpdateUserRole(id string, role models.Role) (*models.User, error) {
+// UpdateUserRole updates a user's role and the shelter they work at. An admin without a shelter
+// is a platform admin.
+func (s *UserService) UpdateUserRole(id string, role models.Role, shelterID string) (*models.User, error) {
 	if !role.IsValid() {
 		return nil, models.ErrInvalidRole
 	}
@@ -156,9 +317,19 @@ func (s *UserService) UpdateUserRole(id string, role models.Role) (*models.User,
 		return nil, errors.New("user not found")
 	}
 
+	if user.Status.IsEquals(models.StatusDeleted) {
+		return nil, models.ErrAccountDeleted
+	}
+
 	user.Role = role
+	user.ShelterID = shelterID
 	user.Updated = time.Now().Format(time.RFC3339)
 
+	// Users with the user role are the public, who don't work at a shelter
+	if role.IsEquals(models.RoleUser) {
+		user.ShelterID = ""
+	}
+
 	err = s.repository.Update(user)
 	if err != nil {
 		return nil, err
@@ -182,6 +353,10 @@ func (s *UserService) UpdateUserStatus(id string, status models.Status) (*models
 		return nil, errors.New("user not found")
 	}
 
+	if user.Status.IsEquals(models.StatusDeleted) {
+		return nil, models.ErrAccountDeleted
+	}
+
 	user.Status = status
 	user.Updated = time.Now().Format(time.RFC3339)
 
@@ -210,44 +385,71 @@ func (s *UserService) ChangePassword(id, oldPassword, newPassword string) error
 		return errors.New("incorrect password")
 	}
 
+	if err := s.checkNewPassword(user, newPassword); err != nil {
+		return err
+	}
+
 	// Hash new password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
 
+	now := time.Now()
+	previousHash := user.Password
+
 	user.Password = string(hashedPassword)
-	user.Updated = time.Now().Format(time.RFC3339)
+	user.Updated = now.Format(time.RFC3339)
 
-	return s.repository.Update(user)
-}
+	if err := s.repository.Update(user); err != nil {
+		return err
+	}
 
-// DeleteUser deletes a user
-func (s *UserService) DeleteUser(id string) error {
-	return s.repository.Delete(id)
+	return s.rememberPassword(user.ID, previousHash, now)
 }
 
-// Authenticate authenticates a user
-func (s *UserService) Authenticate(email, password string) (*models.User, error) {
+// Authenticate authenticates a user. Failed logins are throttled per email address and per IP
+// address, see LoginThrottlePolicy. The failures of the email address are only forgotten by
+// ForgetLoginFailures once the login is complete, as users with two-factor authentication still
+// have to enter a code.
+func (s *UserService) Authenticate(email, password, ip string) (*models.User, error) {
+	now := time.Now()
+
+	// Logins wait after failed attempts, and the password isn't checked while they do
+	if err := s.CheckLoginThrottle(email, ip, now); err != nil {
+		return nil, err
+	}
+
 	user, err := s.repository.FindByEmail(email)
 	if err != nil {
 		return nil, err
 	}
 
 	if user == nil {
+		// Unknown addresses are throttled too, so they behave like registered ones
+		if err := s.RecordLoginFailure(email, ip, nil, now); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("invalid email or password")
 	}
 
-	// Check if user is active
-	if !user.Status.IsEquals(models.StatusActive) {
-		return nil, errors.New("user account is not active")
-	}
-
 	// Check password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
+		if err := s.RecordLoginFailure(email, ip, user, now); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("invalid email or password")
 	}
 
+	// Only told to whoever knows the password, so the status of an address can't be probed
+	if user.Status.IsEquals(models.StatusPending) {
+		return nil, errors.New("email address not verified")
+	}
+
+	if !user.Status.IsEquals(models.StatusActive) {
+		return nil, errors.New("user account is not active")
+	}
+
 	return user, nil
 }
@@ -0,0 +1,185 @@
+package aplication
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeUserRepository keeps users in memory
+type fakeUserRepository struct {
+	users map[string]*models.User
+}
+
+func newFakeUserRepository(users ...*models.User) *fakeUserRepository {
+	repository := &fakeUserRepository{users: map[string]*models.User{}}
+	for _, user := range users {
+		repository.users[user.ID] = user
+	}
+	return repository
+}
+
+func (r *fakeUserRepository) Save(user *models.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUserRepository) FindByID(id string) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepository) FindByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepository) FindByStatus(status models.Status, scope tenant.Scope) ([]*models.User, error) {
+	return nil, nil
+}
+
+func (r *fakeUserRepository) FindAll(scope tenant.Scope) ([]*models.User, error) {
+	return nil, nil
+}
+
+func (r *fakeUserRepository) IsInScope(id string, scope tenant.Scope) (bool, error) {
+	user, ok := r.users[id]
+	return ok && scope.Allows(user.ShelterID), nil
+}
+
+func (r *fakeUserRepository) Update(user *models.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+// fakeLoginThrottleRepository keeps failed logins in memory
+type fakeLoginThrottleRepository struct {
+	throttles map[string]*models.LoginThrottle
+}
+
+func newFakeLoginThrottleRepository() *fakeLoginThrottleRepository {
+	return &fakeLoginThrottleRepository{throttles: map[string]*models.LoginThrottle{}}
+}
+
+func (r *fakeLoginThrottleRepository) Find(kind models.ThrottleKind, subject string) (*models.LoginThrottle, error) {
+	return r.throttles[string(kind)+":"+subject], nil
+}
+
+func (r *fakeLoginThrottleRepository) RecordFailure(kind models.ThrottleKind, subject string, at time.Time) (*models.LoginThrottle, error) {
+	throttle, ok := r.throttles[string(kind)+":"+subject]
+	if !ok {
+		throttle = &models.LoginThrottle{Kind: kind, Subject: subject}
+		r.throttles[string(kind)+":"+subject] = throttle
+	}
+	throttle.Failures++
+	throttle.LastFailure = at
+	return throttle, nil
+}
+
+func (r *fakeLoginThrottleRepository) Lock(kind models.ThrottleKind, subject string, until time.Time) error {
+	r.throttles[string(kind)+":"+subject].LockedUntil = &until
+	return nil
+}
+
+func (r *fakeLoginThrottleRepository) Delete(kind models.ThrottleKind, subject string) error {
+	delete(r.throttles, string(kind)+":"+subject)
+	return nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("correct horse battery"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		status       models.Status
+		password     string
+		wantErr      string
+		wantFailures int
+	}{
+		{name: "Active user", status: models.StatusActive, password: "correct horse battery"},
+		{name: "Wrong password", status: models.StatusActive, password: "wrong", wantErr: "invalid email or password", wantFailures: 1},
+		{name: "Unverified address", status: models.StatusPending, password: "correct horse battery", wantErr: "email address not verified"},
+		{name: "Unverified address and wrong password", status: models.StatusPending, password: "wrong", wantErr: "invalid email or password", wantFailures: 1},
+		{name: "Suspended user", status: models.StatusSuspended, password: "correct horse battery", wantErr: "user account is not active"},
+		{name: "Suspended user and wrong password", status: models.StatusSuspended, password: "wrong", wantErr: "invalid email or password", wantFailures: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFakeUserRepository(&models.User{
+				ID:       "user-1",
+				Email:    "jane@example.com",
+				Password: string(hashedPassword),
+				Status:   tt.status,
+			})
+			throttles := newFakeLoginThrottleRepository()
+			service := NewUserService(users, nil, nil, nil, throttles, nil, models.PasswordPolicy{}, "", "")
+
+			_, err := service.Authenticate("jane@example.com", tt.password, "")
+			if gotErr := errorString(err); gotErr != tt.wantErr {
+				t.Errorf("Authenticate() error = %q, want %q", gotErr, tt.wantErr)
+			}
+
+			var failures int
+			if throttle := throttles.throttles[string(models.ThrottleAccount)+":jane@example.com"]; throttle != nil {
+				failures = throttle.Failures
+			}
+			if failures != tt.wantFailures {
+				t.Errorf("Authenticate() recorded %d failures, want %d", failures, tt.wantFailures)
+			}
+		})
+	}
+}
+
+// errorString returns the message of an error, empty for none
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// fakePasswordHistoryRepository keeps the previous password hashes of each user in memory, latest first
+type fakePasswordHistoryRepository struct {
+	hashes map[string][]string
+}
+
+func newFakePasswordHistoryRepository() *fakePasswordHistoryRepository {
+	return &fakePasswordHistoryRepository{hashes: map[string][]string{}}
+}
+
+func (r *fakePasswordHistoryRepository) Add(userID, passwordHash string, at time.Time) error {
+	r.hashes[userID] = append([]string{passwordHash}, r.hashes[userID]...)
+	return nil
+}
+
+func (r *fakePasswordHistoryRepository) FindRecent(userID string, limit int) ([]string, error) {
+	hashes := r.hashes[userID]
+	if len(hashes) > limit {
+		hashes = hashes[:limit]
+	}
+	return hashes, nil
+}
+
+func (r *fakePasswordHistoryRepository) Prune(userID string, keep int) error {
+	if len(r.hashes[userID]) > keep {
+		r.hashes[userID] = r.hashes[userID][:keep]
+	}
+	return nil
+}
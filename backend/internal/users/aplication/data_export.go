@@ -0,0 +1,405 @@
+package aplication
+
+import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
+)
+
+// DataExportService implements the DataExportService interface, giving users an archive of the
+// personal data every module keeps about them. The archive holds a data.json file with every
+// record and a CSV file per kind of record.
+type DataExportService struct {
+	repository  ports.DataExportRepository
+	users       ports.UserRepository
+	identities  ports.IdentityRepository
+	invitations ports.InvitationRepository
+	modules     []ports.PersonalDataModule // Records kept by the other modules
+	mailer      mailer.Mailer
+	appBaseURL  string // Public URL of the API, used to build download links
+}
+
+// NewDataExportService creates a new DataExportService instance
+func NewDataExportService(repository ports.DataExportRepository, users ports.UserRepository, identities ports.IdentityRepository,
+	invitations ports.InvitationRepository, modules []ports.PersonalDataModule, mail mailer.Mailer, appBaseURL string) *DataExportService {
+	return &DataExportService{
+		repository:  repository,
+		users:       users,
+		identities:  identities,
+		invitations: invitations,
+		modules:     modules,
+		mailer:      mail,
+		appBaseURL:  appBaseURL,
+	}
+}
+
+// RequestExport returns the latest export of a user while it is being generated or can be
+// downloaded, and starts a new one otherwise. The request waits up to DataExportWait for a new
+// export; larger accounts get it by email once it has been generated in the background.
+func (s *DataExportService) RequestExport(userID string) (*models.DataExport, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	now := time.Now()
+
+	latest, err := s.repository.FindLatestByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if latest != nil {
+		latest.Status = latest.StatusAt(now)
+		if latest.Status == models.DataExportPending || latest.Status == models.DataExportReady {
+			return s.withDownloadURL(latest)
+		}
+	}
+
+	if _, err := s.repository.PurgeExpired(now); err != nil {
+		log.Printf("Failed to purge expired data exports: %v", err)
+	}
+
+	export := models.NewDataExport(uuid.New().String(), userID, now)
+	if err := s.repository.Save(export); err != nil {
+		return nil, err
+	}
+
+	log.Printf("User %s requested data export %s", userID, export.ID)
+
+	done := make(chan *models.DataExport, 1)
+	go func() {
+		done <- s.generate(user, *export)
+	}()
+
+	select {
+	case generated := <-done:
+		return s.withDownloadURL(generated)
+	case <-time.After(models.DataExportWait):
+		go func() {
+			if generated := <-done; generated.Status == models.DataExportReady {
+				if err := s.sendExportReadyEmail(user, generated); err != nil {
+					log.Printf("Failed to send data export email to user %s: %v", user.ID, err)
+				}
+			}
+		}()
+		return export, nil
+	}
+}
+
+// GetArchive returns a ready export and its archive from the token of its download link
+func (s *DataExportService) GetArchive(token string) (*models.DataExport, []byte, error) {
+	claims, err := auth.ValidateDataExportToken(token)
+	if err != nil {
+		return nil, nil, models.ErrInvalidDataExportURL
+	}
+
+	export, err := s.repository.FindByID(claims.ExportID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if export == nil || export.UserID != claims.UserID || export.StatusAt(time.Now()) != models.DataExportReady {
+		return nil, nil, models.ErrInvalidDataExportURL
+	}
+
+	archive, err := s.repository.FindArchive(export.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if archive == nil {
+		return nil, nil, models.ErrDataExportNotReady
+	}
+
+	log.Printf("Data export %s of user %s downloaded", export.ID, export.UserID)
+
+	return export, archive, nil
+}
+
+// generate gathers the data of the user, stores the archive and returns the completed export.
+// Failures are logged and leave the export failed, so the user can request a new one.
+func (s *DataExportService) generate(user *models.User, export models.DataExport) *models.DataExport {
+	archive, err := s.buildArchive(user, export.Created)
+	now := time.Now()
+
+	if err == nil {
+		expiresAt := now.Add(models.DataExportExpiration)
+		if err = s.repository.Complete(export.ID, archive, now, expiresAt); err == nil {
+			export.Status = models.DataExportReady
+			export.CompletedAt = &now
+			export.ExpiresAt = &expiresAt
+			export.Size = int64(len(archive))
+			return &export
+		}
+	}
+
+	log.Printf("Failed to generate data export %s of user %s: %v", export.ID, user.ID, err)
+
+	if err := s.repository.Fail(export.ID, now); err != nil {
+		log.Printf("Failed to mark data export %s as failed: %v", export.ID, err)
+	}
+
+	export.Status = models.DataExportFailed
+	export.CompletedAt = &now
+	return &export
+}
+
+// buildArchive gathers the records of the user from every module into a zip archive
+func (s *DataExportService) buildArchive(user *models.User, exportedAt time.Time) ([]byte, error) {
+	sections, err := s.userSections(user)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, module := range s.modules {
+		found, err := module.ExportPersonalData(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, found...)
+	}
+
+	data := map[string]any{
+		"exported_at": exportedAt.UTC(),
+	}
+	for _, section := range sections {
+		if section.Records != nil {
+			data[section.Name] = section.Records
+		}
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	file, err := archive.Create("data.json")
+	if err != nil {
+		return nil, err
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return nil, err
+	}
+
+	for _, section := range sections {
+		if len(section.Header) == 0 {
+			continue
+		}
+
+		file, err := archive.Create(section.Name + ".csv")
+		if err != nil {
+			return nil, err
+		}
+
+		writer := csv.NewWriter(file)
+		if err := writer.Write(section.Header); err != nil {
+			return nil, err
+		}
+		if err := writer.WriteAll(section.Rows); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// userSections returns the records the users module keeps about the user: their profile and
+// documents, the identity providers they log in with, their active sessions, the shelter
+// invitations they sent or accepted and the audit log entries of what they did or what was done
+// to their account
+func (s *DataExportService) userSections(user *models.User) ([]*models.ExportSection, error) {
+	identities, err := s.identities.FindByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.invitations.FindByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := auditEntries(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := auth.GetUserSessions(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Empty lists are exported as such rather than as null
+	userDocuments := append([]string{}, user.Documents...)
+	if sessions == nil {
+		sessions = []*models.Session{}
+	}
+
+	profile := &models.ExportSection{
+		Name:    "profile",
+		Records: user,
+		Header:  []string{"id", "name", "email", "role", "status", "address", "phone", "created", "updated"},
+		Rows: [][]string{{
+			user.ID, user.Name, user.Email, user.Role.String(), user.Status.String(), user.Address, user.Phone, user.Created, user.Updated,
+		}},
+	}
+
+	documents := &models.ExportSection{
+		Name:    "documents",
+		Records: userDocuments,
+		Header:  []string{"document"},
+	}
+	for _, document := range userDocuments {
+		documents.Rows = append(documents.Rows, []string{document})
+	}
+
+	linkedIdentities := &models.ExportSection{
+		Name:    "identities",
+		Records: identities,
+		Header:  []string{"provider", "subject", "email", "created"},
+	}
+	for _, identity := range identities {
+		linkedIdentities.Rows = append(linkedIdentities.Rows, []string{
+			identity.Provider, identity.Subject, identity.Email, identity.Created.UTC().Format(time.RFC3339),
+		})
+	}
+
+	activeSessions := &models.ExportSection{
+		Name:    "sessions",
+		Records: sessions,
+		Header:  []string{"id", "user_agent", "ip_address", "amr", "created", "expires_at"},
+	}
+	for _, session := range sessions {
+		activeSessions.Rows = append(activeSessions.Rows, []string{
+			session.ID, session.UserAgent, session.IPAddress, strings.Join(session.AMR, " "),
+			session.Created.UTC().Format(time.RFC3339), session.ExpiresAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	now := time.Now()
+	shelterInvitations := &models.ExportSection{
+		Name:    "invitations",
+		Records: invitations,
+		Header:  []string{"id", "email", "role", "shelter_id", "invited_by", "accepted_user_id", "status", "created", "expires_at"},
+	}
+	for _, invitation := range invitations {
+		invitation.Status = invitation.StatusAt(now)
+		shelterInvitations.Rows = append(shelterInvitations.Rows, []string{
+			invitation.ID, invitation.Email, invitation.Role.String(), invitation.ShelterID, invitation.InvitedBy, invitation.AcceptedUserID,
+			string(invitation.Status), invitation.Created.UTC().Format(time.RFC3339), invitation.ExpiresAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	auditLog := &models.ExportSection{
+		Name:    "audit_log",
+		Records: entries,
+		Header:  []string{"sequence", "actor", "action", "entity_type", "entity_id", "changes", "ip_address", "request_id", "created"},
+	}
+	for _, entry := range entries {
+		auditLog.Rows = append(auditLog.Rows, []string{
+			strconv.FormatInt(entry.Sequence, 10), entry.Actor, entry.Action, entry.EntityType, entry.EntityID, string(entry.Changes),
+			entry.IPAddress, entry.RequestID, entry.Created.UTC().Format(time.RFC3339),
+		})
+	}
+
+	return []*models.ExportSection{profile, documents, linkedIdentities, activeSessions, shelterInvitations, auditLog}, nil
+}
+
+// auditPageSize is how many audit log entries are loaded at a time for an export
+const auditPageSize = 200
+
+// auditEntries returns the audit log entries of the changes the user made and of the changes
+// made to their account, newest first
+func auditEntries(userID string) ([]*audit.Entry, error) {
+	// The changes the user made to their own account are found by both filters
+	entries := []*audit.Entry{}
+	seen := map[int64]bool{}
+
+	for _, filter := range []audit.Filter{
+		{Actor: userID},
+		{EntityType: "user", EntityID: userID},
+	} {
+		filter.Limit = auditPageSize
+		for {
+			page, err := audit.Find(filter)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, entry := range page {
+				if !seen[entry.Sequence] {
+					seen[entry.Sequence] = true
+					entries = append(entries, entry)
+				}
+			}
+
+			if len(page) < filter.Limit {
+				break
+			}
+			filter.Offset += filter.Limit
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b *audit.Entry) int {
+		return cmp.Compare(b.Sequence, a.Sequence)
+	})
+
+	return entries, nil
+}
+
+// withDownloadURL sets the download link of an export whose archive is ready
+func (s *DataExportService) withDownloadURL(export *models.DataExport) (*models.DataExport, error) {
+	if export.Status != models.DataExportReady {
+		return export, nil
+	}
+
+	token, err := auth.GenerateDataExportToken(export)
+	if err != nil {
+		return nil, err
+	}
+
+	export.DownloadURL = strings.TrimRight(s.appBaseURL, "/") + "/api/users/exports/download?token=" + url.QueryEscape(token)
+	return export, nil
+}
+
+// sendExportReadyEmail sends the download link of an export generated in the background to its user
+func (s *DataExportService) sendExportReadyEmail(user *models.User, export *models.DataExport) error {
+	export, err := s.withDownloadURL(export)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(&mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Your Pet Paradise data export is ready",
+		Body: fmt.Sprintf("Hi %s,\n\nThe export of your personal data you asked for is ready. To download it, open the link below:\n\n%s\n\n"+
+			"The link expires in %d hours. If you didn't ask for it, please contact us right away.\n\nPet Paradise",
+			user.Name, export.DownloadURL, int(models.DataExportExpiration.Hours())),
+	})
+}
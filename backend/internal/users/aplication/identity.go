@@ -0,0 +1,250 @@
+package aplication
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/oidc"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// IdentityService implements the IdentityService interface, logging users in through
+// OpenID Connect providers
+type IdentityService struct {
+	repository ports.IdentityRepository
+	users      ports.UserRepository
+	providers  map[string]*oidc.Provider
+}
+
+// NewIdentityService creates a new IdentityService instance for the given providers
+func NewIdentityService(repository ports.IdentityRepository, users ports.UserRepository, providers []*oidc.Provider) *IdentityService {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &IdentityService{
+		repository: repository,
+		users:      users,
+		providers:  byName,
+	}
+}
+
+// Providers returns the names of the configured identity providers
+func (s *IdentityService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartLogin remembers a new login at a provider and returns the provider's login page URL
+// along with the state the provider will send back
+func (s *IdentityService) StartLogin(ctx context.Context, providerName string) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", models.ErrUnknownIdentityProvider
+	}
+
+	var values [3]string
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			return "", "", err
+		}
+		values[i] = value
+	}
+
+	state := models.NewOIDCLoginState(values[0], providerName, values[1], values[2], time.Now())
+
+	authURL, err := provider.AuthCodeURL(ctx, state.State, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		log.Printf("Failed to reach identity provider %s: %v", providerName, err)
+		return "", "", models.ErrIdentityProviderUnavailable
+	}
+
+	if err := s.repository.SaveLoginState(state); err != nil {
+		return "", "", err
+	}
+
+	return authURL, state.State, nil
+}
+
+// CompleteLogin exchanges the code a provider redirected the user back with and returns the
+// user the identity belongs to. Identities are linked to existing users by verified email, and
+// users are created with the user role on their first login.
+func (s *IdentityService) CompleteLogin(ctx context.Context, providerName, state, code string) (*models.User, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, models.ErrUnknownIdentityProvider
+	}
+
+	loginState, err := s.repository.ConsumeLoginState(state, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if loginState == nil || loginState.Provider != providerName {
+		return nil, models.ErrInvalidLoginState
+	}
+
+	tokens, err := provider.Exchange(ctx, code, loginState.CodeVerifier)
+	if err != nil {
+		log.Printf("Failed to exchange code with identity provider %s: %v", providerName, err)
+		return nil, models.ErrExternalLoginFailed
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, loginState.Nonce)
+	if err != nil {
+		log.Printf("Rejected ID token from identity provider %s: %v", providerName, err)
+		return nil, models.ErrExternalLoginFailed
+	}
+
+	return s.loginIdentity(providerName, claims)
+}
+
+// loginIdentity finds or creates the user an identity belongs to
+func (s *IdentityService) loginIdentity(providerName string, claims *oidc.IDTokenClaims) (*models.User, error) {
+	identity, err := s.repository.FindByProviderSubject(providerName, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	// Identities that are already linked keep their user, even if the email changed at the provider
+	if identity != nil {
+		user, err := s.users.FindByID(identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		if user == nil {
+			return nil, errors.New("user not found")
+		}
+
+		if !user.Status.IsEquals(models.StatusActive) {
+			return nil, errors.New("user account is not active")
+		}
+
+		return user, nil
+	}
+
+	// An unverified email could belong to someone else, so it can't be used to find the account
+	if claims.Email == "" || !bool(claims.EmailVerified) {
+		return nil, models.ErrIdentityEmailNotVerified
+	}
+
+	now := time.Now()
+
+	user, err := s.users.FindByEmail(claims.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		user, err = s.provisionUser(claims, now)
+		if err != nil {
+			return nil, err
+		}
+	} else if user.Status.IsEquals(models.StatusPending) {
+		// The provider has verified the address the account is waiting to have verified. Whoever
+		// registered it may not own the address, so the password they chose is replaced and
+		// anything issued to the account is revoked before it becomes active.
+		hashedPassword, err := randomPasswordHash()
+		if err != nil {
+			return nil, err
+		}
+
+		user.Password = hashedPassword
+		user.Status = models.StatusActive
+		user.Updated = now.Format(time.RFC3339)
+
+		if err := auth.RevokeAllUserTokens(user.ID); err != nil {
+			return nil, err
+		}
+
+		if err := s.users.Update(user); err != nil {
+			return nil, err
+		}
+	} else if !user.Status.IsEquals(models.StatusActive) {
+		return nil, errors.New("user account is not active")
+	}
+
+	err = s.repository.Save(&models.ExternalIdentity{
+		Provider: providerName,
+		Subject:  claims.Subject,
+		UserID:   user.ID,
+		Email:    claims.Email,
+		Created:  now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// provisionUser creates an active user account for an identity's first login. The account gets a
+// random password nobody knows; the user can set one through the password reset flow.
+func (s *IdentityService) provisionUser(claims *oidc.IDTokenClaims, now time.Time) (*models.User, error) {
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	hashedPassword, err := randomPasswordHash()
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := models.NewUser(
+		uuid.New().String(),
+		name,
+		claims.Email,
+		hashedPassword,
+		models.StatusActive,
+		models.RoleUser,
+		"",
+		"",
+		[]string{},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user for external identity: %w", err)
+	}
+
+	user.Created = now.Format(time.RFC3339)
+	user.Updated = user.Created
+
+	if err := s.users.Save(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// randomPasswordHash hashes a random password nobody knows
+func randomPasswordHash() (string, error) {
+	password := make([]byte, 32)
+	if _, err := rand.Read(password); err != nil {
+		return "", err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(base64.RawURLEncoding.EncodeToString(password)), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hashedPassword), nil
+}
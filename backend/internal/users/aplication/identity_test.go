@@ -0,0 +1,126 @@
+package aplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/oidc"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeIdentityRepository keeps linked identities in memory
+type fakeIdentityRepository struct {
+	identities []*models.ExternalIdentity
+}
+
+func (r *fakeIdentityRepository) Save(identity *models.ExternalIdentity) error {
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *fakeIdentityRepository) FindByProviderSubject(provider, subject string) (*models.ExternalIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeIdentityRepository) FindByUserID(userID string) ([]*models.ExternalIdentity, error) {
+	return nil, nil
+}
+
+func (r *fakeIdentityRepository) SaveLoginState(state *models.OIDCLoginState) error {
+	return nil
+}
+
+func (r *fakeIdentityRepository) ConsumeLoginState(state string, now time.Time) (*models.OIDCLoginState, error) {
+	return nil, nil
+}
+
+func identityClaims(subject, email string, verified bool) *oidc.IDTokenClaims {
+	return &oidc.IDTokenClaims{
+		Email:            email,
+		EmailVerified:    oidc.Bool(verified),
+		Name:             "Jane Doe",
+		RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+	}
+}
+
+func TestLoginIdentityPendingAccount(t *testing.T) {
+	// Someone registered the address and knows the password, but never verified it
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("chosen-by-someone-else"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+
+	users := newFakeUserRepository(&models.User{
+		ID:       "user-1",
+		Name:     "Jane",
+		Email:    "jane@example.com",
+		Password: string(hashedPassword),
+		Status:   models.StatusPending,
+		Role:     models.RoleUser,
+	})
+	identities := &fakeIdentityRepository{}
+	service := NewIdentityService(identities, users, nil)
+
+	user, err := service.loginIdentity("google", identityClaims("subject-1", "Jane@example.com", true))
+	if err != nil {
+		t.Fatalf("loginIdentity() error = %v", err)
+	}
+
+	if user.ID != "user-1" || !user.Status.IsEquals(models.StatusActive) {
+		t.Errorf("loginIdentity() = %s %s, want the pending user activated", user.ID, user.Status)
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(users.users["user-1"].Password), []byte("chosen-by-someone-else")) == nil {
+		t.Errorf("loginIdentity() kept the password chosen when registering")
+	}
+
+	if len(identities.identities) != 1 || identities.identities[0].UserID != "user-1" {
+		t.Errorf("loginIdentity() linked %v, want the identity linked to user-1", identities.identities)
+	}
+}
+
+func TestLoginIdentity(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  models.Status
+		claims  *oidc.IDTokenClaims
+		wantID  string
+		wantErr bool
+	}{
+		{name: "Active account", status: models.StatusActive, claims: identityClaims("subject-1", "jane@example.com", true), wantID: "user-1"},
+		{name: "New address", status: models.StatusActive, claims: identityClaims("subject-2", "john@example.com", true)},
+		{name: "Unverified address", status: models.StatusActive, claims: identityClaims("subject-1", "jane@example.com", false), wantErr: true},
+		{name: "Suspended account", status: models.StatusSuspended, claims: identityClaims("subject-1", "jane@example.com", true), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFakeUserRepository(&models.User{ID: "user-1", Name: "Jane", Email: "jane@example.com", Status: tt.status, Role: models.RoleUser})
+			service := NewIdentityService(&fakeIdentityRepository{}, users, nil)
+
+			user, err := service.loginIdentity("google", tt.claims)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loginIdentity() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if tt.wantID != "" && user.ID != tt.wantID {
+				t.Errorf("loginIdentity() user = %s, want %s", user.ID, tt.wantID)
+			}
+
+			if tt.wantID == "" && (user.ID == "user-1" || !user.Status.IsEquals(models.StatusActive)) {
+				t.Errorf("loginIdentity() = %s %s, want a new active user", user.ID, user.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package aplication
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// InvitationService implements the InvitationService interface. Staff accounts are only created
+// through invitations, each recording the admin who sent it and the account it created.
+type InvitationService struct {
+	repository     ports.InvitationRepository
+	users          ports.UserRepository
+	mailer         mailer.Mailer
+	passwordPolicy models.PasswordPolicy
+	invitationURL  string // Page of the web app where invited staff members create their account
+}
+
+// NewInvitationService creates a new InvitationService instance
+func NewInvitationService(repository ports.InvitationRepository, users ports.UserRepository, mail mailer.Mailer, passwordPolicy models.PasswordPolicy,
+	invitationURL string) *InvitationService {
+	return &InvitationService{
+		repository:     repository,
+		users:          users,
+		mailer:         mail,
+		passwordPolicy: passwordPolicy,
+		invitationURL:  invitationURL,
+	}
+}
+
+// Invite invites a staff member with the given role at a shelter and emails them the link to
+// create their account. Addresses that already have an account can't be invited; change their
+// role instead.
+func (s *InvitationService) Invite(email string, role models.Role, inviterID, shelterID string) (*models.Invitation, error) {
+	email = strings.TrimSpace(email)
+
+	existingUser, err := s.users.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingUser != nil {
+		return nil, errors.New("email already in use")
+	}
+
+	now := time.Now()
+	invitation, err := models.NewInvitation(uuid.New().String(), email, role, shelterID, inviterID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.Save(invitation); err != nil {
+		return nil, err
+	}
+
+	// The invitation is kept if the email can't be sent; it can be revoked and sent again
+	if err := s.sendInvitationEmail(invitation); err != nil {
+		log.Printf("Failed to send invitation %s: %v", invitation.ID, err)
+	}
+
+	log.Printf("User %s invited %s as %s (invitation %s)", inviterID, invitation.Email, invitation.Role, invitation.ID)
+
+	invitation.Status = invitation.StatusAt(now)
+	return invitation, nil
+}
+
+// GetInvitations returns every invitation to a shelter in scope, newest first
+func (s *InvitationService) GetInvitations(scope tenant.Scope) ([]*models.Invitation, error) {
+	invitations, err := s.repository.FindAll(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, invitation := range invitations {
+		invitation.Status = invitation.StatusAt(now)
+	}
+
+	return invitations, nil
+}
+
+// RevokeInvitation revokes an invitation to a shelter in scope that hasn't been accepted yet
+func (s *InvitationService) RevokeInvitation(id string, scope tenant.Scope) (*models.Invitation, error) {
+	invitation, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation == nil || !scope.Allows(invitation.ShelterID) {
+		return nil, models.ErrInvitationNotFound
+	}
+
+	now := time.Now()
+	revoked, err := s.repository.Revoke(id, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !revoked {
+		return nil, models.ErrInvitationNotPending
+	}
+
+	invitation.RevokedAt = &now
+	invitation.Status = invitation.StatusAt(now)
+	return invitation, nil
+}
+
+// AcceptInvitation creates the staff account an invitation is for. The account is active right
+// away, since the invitation link proves the staff member owns the address.
+func (s *InvitationService) AcceptInvitation(token, name, password, address, phone string) (*models.User, error) {
+	claims, err := auth.ValidateInvitationToken(token)
+	if err != nil {
+		return nil, models.ErrInvalidInvitation
+	}
+
+	invitation, err := s.repository.FindByID(claims.InvitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation == nil || !strings.EqualFold(invitation.Email, claims.Email) {
+		return nil, models.ErrInvalidInvitation
+	}
+
+	now := time.Now()
+	switch invitation.StatusAt(now) {
+	case models.InvitationExpired:
+		return nil, models.ErrInvalidInvitation
+	case models.InvitationAccepted, models.InvitationRevoked:
+		return nil, models.ErrInvitationNotPending
+	}
+
+	existingUser, err := s.users.FindByEmail(invitation.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingUser != nil {
+		return nil, errors.New("email already in use")
+	}
+
+	if err := s.passwordPolicy.Validate(password, invitation.Email, name); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := models.NewUser(
+		uuid.New().String(),
+		name,
+		invitation.Email,
+		string(hashedPassword),
+		models.StatusActive,
+		invitation.Role,
+		address,
+		phone,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	user.ShelterID = invitation.ShelterID
+	user.Created = now.Format(time.RFC3339)
+	user.Updated = user.Created
+
+	// Claim the invitation before creating the account, so it can't be used twice concurrently
+	accepted, err := s.repository.Accept(invitation.ID, user.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !accepted {
+		return nil, models.ErrInvitationNotPending
+	}
+
+	if err := s.users.Save(user); err != nil {
+		if releaseErr := s.repository.ReleaseAcceptance(invitation.ID); releaseErr != nil {
+			log.Printf("Failed to release invitation %s: %v", invitation.ID, releaseErr)
+		}
+		return nil, err
+	}
+
+	log.Printf("User %s joined as %s through invitation %s from user %s", user.ID, user.Role, invitation.ID, invitation.InvitedBy)
+
+	return user, nil
+}
+
+// sendInvitationEmail sends the invitation link to the invited staff member
+func (s *InvitationService) sendInvitationEmail(invitation *models.Invitation) error {
+	token, err := auth.GenerateInvitationToken(invitation)
+	if err != nil {
+		return err
+	}
+
+	link := s.invitationURL + "?token=" + url.QueryEscape(token)
+
+	return s.mailer.Send(&mailer.Message{
+		To:      []string{invitation.Email},
+		Subject: "You're invited to join the Pet Paradise team",
+		Body: fmt.Sprintf("Hi,\n\nYou have been invited to join Pet Paradise as %s. To create your account, open the link below:\n\n%s\n\n"+
+			"The link expires in %d hours. If you weren't expecting this invitation, you can ignore this email.\n\nPet Paradise",
+			invitation.Role, link, int(models.InvitationExpiration.Hours())),
+	})
+}
@@ -0,0 +1,120 @@
+package aplication
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
+)
+
+// CheckLoginThrottle returns a LoginThrottledError if logins for the email address or from the IP
+// address must still wait after failed attempts
+func (s *UserService) CheckLoginThrottle(email, ip string, now time.Time) error {
+	var throttled *models.LoginThrottledError
+
+	for kind, subject := range loginThrottleSubjects(email, ip) {
+		throttle, err := s.throttles.Find(kind, subject)
+		if err != nil {
+			return err
+		}
+
+		if throttle == nil {
+			continue
+		}
+
+		wait := throttle.RetryAfter(now)
+		if wait > 0 && (throttled == nil || wait > throttled.RetryAfter) {
+			throttled = &models.LoginThrottledError{
+				RetryAfter: wait,
+				Locked:     throttle.IsLocked(now),
+			}
+		}
+	}
+
+	if throttled != nil {
+		return throttled
+	}
+
+	return nil
+}
+
+// RecordLoginFailure counts a failed login against the email address and the IP address, locking
+// them out once their policy's threshold is reached. The owner of the address is told when their
+// account gets locked.
+func (s *UserService) RecordLoginFailure(email, ip string, user *models.User, now time.Time) error {
+	for kind, subject := range loginThrottleSubjects(email, ip) {
+		throttle, err := s.throttles.RecordFailure(kind, subject, now)
+		if err != nil {
+			return err
+		}
+
+		policy := kind.Policy()
+		if throttle.Failures < policy.LockAfter || throttle.IsLocked(now) {
+			continue
+		}
+
+		lockedUntil := now.Add(policy.LockDuration)
+		if err := s.throttles.Lock(kind, subject, lockedUntil); err != nil {
+			return err
+		}
+
+		// Failures after a lock has run out lock the account again, the owner is only told the first time
+		if kind == models.ThrottleAccount && user != nil && throttle.Failures == policy.LockAfter {
+			go func() {
+				if err := s.sendAccountLockedEmail(user, lockedUntil); err != nil {
+					log.Printf("Failed to send account locked email to user %s: %v", user.ID, err)
+				}
+			}()
+		}
+	}
+
+	return nil
+}
+
+// ForgetLoginFailures forgets the failed logins of an email address once a login succeeded. Failures
+// from the IP address still count, one known password mustn't reset them.
+func (s *UserService) ForgetLoginFailures(email string) error {
+	return s.throttles.Delete(models.ThrottleAccount, strings.ToLower(email))
+}
+
+// UnlockUser lifts the lockout of a user's account and forgets its failed logins
+func (s *UserService) UnlockUser(id string) error {
+	user, err := s.repository.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	return s.throttles.Delete(models.ThrottleAccount, strings.ToLower(user.Email))
+}
+
+// sendAccountLockedEmail tells a user that their account was locked after too many failed logins
+func (s *UserService) sendAccountLockedEmail(user *models.User, lockedUntil time.Time) error {
+	return s.mailer.Send(&mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Your Pet Paradise account has been locked",
+		Body: fmt.Sprintf("Hi %s,\n\nThere were too many failed attempts to log in to your account, so logins are locked until %s.\n\n"+
+			"If this wasn't you, someone may be trying to guess your password. You can unlock your account right away "+
+			"by resetting your password, or ask us to unlock it.\n\nPet Paradise", user.Name, lockedUntil.UTC().Format(time.RFC1123)),
+	})
+}
+
+// loginThrottleSubjects returns what a login attempt is counted against
+func loginThrottleSubjects(email, ip string) map[models.ThrottleKind]string {
+	subjects := map[models.ThrottleKind]string{
+		models.ThrottleAccount: strings.ToLower(email),
+	}
+
+	if ip != "" {
+		subjects[models.ThrottleIP] = ip
+	}
+
+	return subjects
+}
@@ -0,0 +1,311 @@
+package aplication
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+)
+
+// MFAService implements the MFAService interface
+type MFAService struct {
+	repository ports.MFARepository
+	users      ports.UserRepository
+	logins     ports.LoginThrottler
+	issuer     string
+}
+
+// NewMFAService creates a new MFAService instance. Authenticator apps show accounts under issuer.
+func NewMFAService(repository ports.MFARepository, users ports.UserRepository, logins ports.LoginThrottler, issuer string) *MFAService {
+	return &MFAService{
+		repository: repository,
+		users:      users,
+		logins:     logins,
+		issuer:     issuer,
+	}
+}
+
+// GetStatus returns the two-factor authentication status of a user
+func (s *MFAService) GetStatus(userID string) (*models.MFAStatus, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.MFAStatus{
+		Required: auth.IsMFARequired(user.Role),
+	}
+
+	mfa, err := s.repository.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa == nil || !mfa.Enabled {
+		return status, nil
+	}
+
+	status.Enabled = true
+	status.RecoveryCodesLeft, err = s.repository.CountRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// IsEnabled checks if a user has to enter a second factor when logging in
+func (s *MFAService) IsEnabled(userID string) (bool, error) {
+	mfa, err := s.repository.FindByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+
+	return mfa != nil && mfa.Enabled, nil
+}
+
+// Enroll starts the enrollment of a user with a new secret. Starting over replaces the secret
+// of an enrollment that hasn't been confirmed yet.
+func (s *MFAService) Enroll(userID string) (*models.MFAEnrollment, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mfa, err := s.repository.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa != nil && mfa.Enabled {
+		return nil, models.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	err = s.repository.Save(&models.MFA{
+		UserID:  userID,
+		Secret:  secret,
+		Created: now,
+		Updated: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication once the user proves their app generates valid codes,
+// and returns the recovery codes. They are only shown this once.
+func (s *MFAService) Confirm(userID, code string) ([]string, error) {
+	mfa, err := s.repository.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa == nil {
+		return nil, models.ErrMFANotEnrolled
+	}
+
+	if mfa.Enabled {
+		return nil, models.ErrMFAAlreadyEnabled
+	}
+
+	step, ok := auth.ValidateTOTP(mfa.Secret, code, time.Now(), mfa.LastUsedStep)
+	if !ok {
+		return nil, models.ErrInvalidMFACode
+	}
+
+	mfa.Enabled = true
+	mfa.LastUsedStep = step
+	mfa.FailedAttempts = 0
+	mfa.Updated = time.Now().Format(time.RFC3339)
+
+	if err := s.repository.Save(mfa); err != nil {
+		return nil, err
+	}
+
+	return s.newRecoveryCodes(userID)
+}
+
+// Disable turns two-factor authentication off, given a current code or a recovery code.
+// Users whose role requires it can't turn it off.
+func (s *MFAService) Disable(userID, code string) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if auth.IsMFARequired(user.Role) {
+		return models.ErrMFARequiredByRole
+	}
+
+	if _, err := s.verifyEnabled(userID, code); err != nil {
+		return err
+	}
+
+	return s.repository.Delete(userID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user, given a current code or a recovery code
+func (s *MFAService) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	if _, err := s.verifyEnabled(userID, code); err != nil {
+		return nil, err
+	}
+
+	return s.newRecoveryCodes(userID)
+}
+
+// VerifyLogin checks the second factor of a login from the IP address and returns the authentication
+// methods it adds. Invalid codes count as failed logins of the user's email address and of the IP
+// address, so they are throttled like wrong passwords and a LoginThrottledError is returned while
+// logins must wait. After MaxMFAAttempts invalid codes in a row ErrTooManyMFAAttempts is returned
+// and the count starts over.
+func (s *MFAService) VerifyLogin(userID, code, ip string) ([]string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	// Codes aren't checked while logins wait, otherwise logging in again would reset the attempts
+	if err := s.logins.CheckLoginThrottle(user.Email, ip, now); err != nil {
+		return nil, err
+	}
+
+	amr, err := s.verifyEnabled(userID, code)
+	if err == nil {
+		return amr, s.logins.ForgetLoginFailures(user.Email)
+	}
+
+	if err != models.ErrInvalidMFACode {
+		return nil, err
+	}
+
+	if err := s.logins.RecordLoginFailure(user.Email, ip, user, now); err != nil {
+		return nil, err
+	}
+
+	attempts, recordErr := s.repository.RecordFailedAttempt(userID)
+	if recordErr != nil {
+		return nil, recordErr
+	}
+
+	if attempts >= models.MaxMFAAttempts {
+		if err := s.repository.ResetFailedAttempts(userID); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrTooManyMFAAttempts
+	}
+
+	return nil, err
+}
+
+// verifyEnabled checks a TOTP or recovery code of a user who enabled two-factor authentication and
+// returns the authentication methods it proves. Each code can only be used once.
+func (s *MFAService) verifyEnabled(userID, code string) ([]string, error) {
+	mfa, err := s.repository.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa == nil || !mfa.Enabled {
+		return nil, models.ErrMFANotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+
+	if len(code) == auth.TOTPDigits {
+		step, ok := auth.ValidateTOTP(mfa.Secret, code, time.Now(), mfa.LastUsedStep)
+		if !ok {
+			return nil, models.ErrInvalidMFACode
+		}
+
+		// Another request may have used the same code in the meantime
+		used, err := s.repository.UseStep(userID, step)
+		if err != nil {
+			return nil, err
+		}
+
+		if !used {
+			return nil, models.ErrInvalidMFACode
+		}
+
+		return []string{auth.AMROTP, auth.AMRMFA}, nil
+	}
+
+	used, err := s.repository.UseRecoveryCode(userID, hashRecoveryCode(code), time.Now().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	if !used {
+		return nil, models.ErrInvalidMFACode
+	}
+
+	if err := s.repository.ResetFailedAttempts(userID); err != nil {
+		return nil, err
+	}
+
+	return []string{auth.AMRMFA}, nil
+}
+
+// newRecoveryCodes generates and stores a new set of recovery codes, returning them in plain text
+func (s *MFAService) newRecoveryCodes(userID string) ([]string, error) {
+	codes := make([]string, models.RecoveryCodeCount)
+	hashes := make([]string, models.RecoveryCodeCount)
+
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+
+		// Ten characters, shown as two groups of five
+		encoded := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+		codes[i] = encoded[:5] + "-" + encoded[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	if err := s.repository.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *MFAService) findUser(userID string) (*models.User, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	return user, nil
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring case and dashes the user may have left out
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
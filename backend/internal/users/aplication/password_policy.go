@@ -0,0 +1,51 @@
+package aplication
+
+import (
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// checkNewPassword checks the new password of a user against the password policy and their
+// latest passwords, returning a PasswordPolicyError if it can't be used
+func (s *UserService) checkNewPassword(user *models.User, password string) error {
+	if err := s.passwordPolicy.Validate(password, user.Email, user.Name); err != nil {
+		return err
+	}
+
+	if s.passwordPolicy.HistorySize <= 0 {
+		return nil
+	}
+
+	// The current password counts as one of the latest
+	hashes := []string{user.Password}
+
+	previous, err := s.passwordHistory.FindRecent(user.ID, s.passwordPolicy.HistorySize-1)
+	if err != nil {
+		return err
+	}
+	hashes = append(hashes, previous...)
+
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return s.passwordPolicy.ReusedPasswordError()
+		}
+	}
+
+	return nil
+}
+
+// rememberPassword records the password a user is replacing, keeping only as many previous
+// passwords as the policy checks
+func (s *UserService) rememberPassword(userID, previousHash string, now time.Time) error {
+	if s.passwordPolicy.HistorySize <= 1 {
+		return nil
+	}
+
+	if err := s.passwordHistory.Add(userID, previousHash, now); err != nil {
+		return err
+	}
+
+	return s.passwordHistory.Prune(userID, s.passwordPolicy.HistorySize-1)
+}
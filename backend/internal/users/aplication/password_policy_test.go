@@ -0,0 +1,63 @@
+package aplication
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCheckNewPassword(t *testing.T) {
+	hash := func(password string) string {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		if err != nil {
+			t.Fatalf("GenerateFromPassword() error = %v", err)
+		}
+		return string(hashed)
+	}
+
+	user := &models.User{ID: "user-1", Name: "Jane Doe", Email: "jane@example.com", Password: hash("current password")}
+	history := newFakePasswordHistoryRepository()
+	for _, previous := range []string{"oldest password", "older password", "old password"} {
+		if err := history.Add(user.ID, hash(previous), time.Now()); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		historySize int
+		password    string
+		wantCode    string
+	}{
+		{name: "New password", historySize: 3, password: "violet tractor umbrella"},
+		{name: "Current password", historySize: 3, password: "current password", wantCode: models.PasswordReused},
+		{name: "Latest previous password", historySize: 3, password: "old password", wantCode: models.PasswordReused},
+		{name: "Password out of the history", historySize: 3, password: "oldest password"},
+		{name: "History not checked", historySize: 0, password: "current password"},
+		{name: "Policy checked first", historySize: 3, password: "short", wantCode: models.PasswordTooShort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := models.PasswordPolicy{MinLength: 8, HistorySize: tt.historySize}
+			service := NewUserService(newFakeUserRepository(user), nil, nil, history, nil, nil, policy, "", "")
+
+			err := service.checkNewPassword(user, tt.password)
+
+			var code string
+			var policyErr *models.PasswordPolicyError
+			if errors.As(err, &policyErr) {
+				code = policyErr.Violations[0].Code
+			} else if err != nil {
+				t.Fatalf("checkNewPassword() error = %v, want a PasswordPolicyError", err)
+			}
+
+			if code != tt.wantCode {
+				t.Errorf("checkNewPassword() violation = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
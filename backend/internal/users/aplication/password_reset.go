@@ -0,0 +1,185 @@
+package aplication
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// RequestPasswordReset emails a password reset link to the owner of an address. Nothing tells
+// the caller whether the address is registered: unknown addresses, accounts that can't log in
+// and repeated requests within PasswordResetCooldown are silently ignored.
+func (s *UserService) RequestPasswordReset(email string) error {
+	user, err := s.repository.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	if user == nil || !canResetPassword(user) {
+		return nil
+	}
+
+	now := time.Now()
+
+	latest, err := s.passwordResets.FindLatestByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+
+	if latest != nil && latest.IsUsable(now) && now.Sub(latest.Created) < models.PasswordResetCooldown {
+		return nil
+	}
+
+	token, tokenHash, err := newPasswordResetToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.passwordResets.Save(models.NewPasswordResetToken(tokenHash, user.ID, now)); err != nil {
+		return err
+	}
+
+	// Sent in the background so the response time doesn't reveal that the address is registered
+	go func() {
+		if err := s.sendPasswordResetEmail(user, token); err != nil {
+			log.Printf("Failed to send password reset email to user %s: %v", user.ID, err)
+		}
+	}()
+
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token. The token can only be used once, the
+// user's other reset tokens are invalidated, every session of the user is revoked and a login
+// lockout is lifted. Since the link proves the user owns their address, a pending account is
+// activated too. It returns the user whose password was reset.
+func (s *UserService) ResetPassword(token, newPassword string) (*models.User, error) {
+	invalidToken := errors.New("invalid or expired reset token")
+	now := time.Now()
+
+	stored, err := s.passwordResets.FindByHash(hashPasswordResetToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if stored == nil || !stored.IsUsable(now) {
+		return nil, invalidToken
+	}
+
+	user, err := s.repository.FindByID(stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || !canResetPassword(user) {
+		return nil, invalidToken
+	}
+
+	if err := s.checkNewPassword(user, newPassword); err != nil {
+		return nil, err
+	}
+
+	// Hash new password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the token up before changing anything, so a token can't be used twice concurrently
+	consumed, err := s.passwordResets.Consume(stored.TokenHash, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !consumed {
+		return nil, invalidToken
+	}
+
+	previousHash := user.Password
+
+	user.Password = string(hashedPassword)
+	user.Status = models.StatusActive
+	user.Updated = now.Format(time.RFC3339)
+
+	if err := s.repository.Update(user); err != nil {
+		return nil, err
+	}
+
+	if err := s.rememberPassword(user.ID, previousHash, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.passwordResets.InvalidateByUserID(user.ID, now); err != nil {
+		return nil, err
+	}
+
+	// Whoever knew the old password must log in again
+	if err := auth.RevokeAllUserTokens(user.ID); err != nil {
+		return nil, err
+	}
+
+	// Guessing the old password no longer matters
+	if err := s.throttles.Delete(models.ThrottleAccount, strings.ToLower(user.Email)); err != nil {
+		return nil, err
+	}
+
+	err = s.mailer.Send(&mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Your Pet Paradise password was changed",
+		Body: fmt.Sprintf("Hi %s,\n\nThe password of your account was just reset and you have been logged out everywhere. "+
+			"If this wasn't you, please contact us right away.\n\nPet Paradise", user.Name),
+	})
+	if err != nil {
+		log.Printf("Failed to send password changed email to user %s: %v", user.ID, err)
+	}
+
+	return user, nil
+}
+
+// sendPasswordResetEmail sends a password reset link to the user
+func (s *UserService) sendPasswordResetEmail(user *models.User, token string) error {
+	link := s.passwordResetURL + "?token=" + url.QueryEscape(token)
+
+	return s.mailer.Send(&mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your Pet Paradise password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. To choose a new password, open the link below:\n\n%s\n\n"+
+			"The link expires in %d minutes and can only be used once. If you didn't ask for it, you can ignore this email.\n\nPet Paradise",
+			user.Name, link, int(models.PasswordResetExpiration.Minutes())),
+	})
+}
+
+// canResetPassword checks if the user is allowed to reset their password; suspended and inactive
+// accounts can't use a reset to get back in
+func canResetPassword(user *models.User) bool {
+	return user.Status.IsEquals(models.StatusActive) || user.Status.IsEquals(models.StatusPending)
+}
+
+// newPasswordResetToken generates a random reset token along with the hash that is stored
+func newPasswordResetToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashPasswordResetToken(token), nil
+}
+
+// hashPasswordResetToken hashes a reset token; the tokens are random enough not to need a slow hash
+func hashPasswordResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
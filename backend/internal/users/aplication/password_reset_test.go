@@ -0,0 +1,216 @@
+package aplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakePasswordResetRepository keeps reset tokens in memory by their hash
+type fakePasswordResetRepository struct {
+	tokens map[string]*models.PasswordResetToken
+}
+
+func (r *fakePasswordResetRepository) Save(token *models.PasswordResetToken) error {
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *fakePasswordResetRepository) FindByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *fakePasswordResetRepository) FindLatestByUserID(userID string) (*models.PasswordResetToken, error) {
+	var latest *models.PasswordResetToken
+	for _, token := range r.tokens {
+		if token.UserID == userID && (latest == nil || token.Created.After(latest.Created)) {
+			latest = token
+		}
+	}
+	return latest, nil
+}
+
+func (r *fakePasswordResetRepository) Consume(tokenHash string, usedAt time.Time) (bool, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok || token.UsedAt != nil {
+		return false, nil
+	}
+	token.UsedAt = &usedAt
+	return true, nil
+}
+
+func (r *fakePasswordResetRepository) InvalidateByUserID(userID string, usedAt time.Time) error {
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.UsedAt == nil {
+			token.UsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+// passwordResetTest is a user service with a user who can reset their password
+type passwordResetTest struct {
+	service   *UserService
+	users     *fakeUserRepository
+	resets    *fakePasswordResetRepository
+	throttles *fakeLoginThrottleRepository
+}
+
+func newPasswordResetTest(t *testing.T, status models.Status) *passwordResetTest {
+	t.Helper()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("forgotten password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+
+	test := &passwordResetTest{
+		users: newFakeUserRepository(&models.User{
+			ID:       "user-1",
+			Name:     "Jane Doe",
+			Email:    "jane@example.com",
+			Password: string(hashedPassword),
+			Role:     models.RoleUser,
+			Status:   status,
+		}),
+		resets:    &fakePasswordResetRepository{tokens: map[string]*models.PasswordResetToken{}},
+		throttles: newFakeLoginThrottleRepository(),
+	}
+	test.service = NewUserService(test.users, nil, test.resets, newFakePasswordHistoryRepository(), test.throttles, &fakeMailer{},
+		models.PasswordPolicy{MinLength: 8}, "", "")
+
+	return test
+}
+
+// issueToken stores a reset token for the user created at the given time, returning the raw token
+func (tt *passwordResetTest) issueToken(t *testing.T, created time.Time) string {
+	t.Helper()
+
+	token, tokenHash, err := newPasswordResetToken()
+	if err != nil {
+		t.Fatalf("newPasswordResetToken() error = %v", err)
+	}
+
+	if err := tt.resets.Save(models.NewPasswordResetToken(tokenHash, "user-1", created)); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	return token
+}
+
+func TestResetPassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  models.Status
+		created time.Duration // Before now
+		used    bool
+		token   string // Instead of the issued one
+		wantErr bool
+	}{
+		{name: "Fresh token", status: models.StatusActive, created: time.Minute},
+		{name: "Pending account", status: models.StatusPending, created: time.Minute},
+		{name: "Expired token", status: models.StatusActive, created: models.PasswordResetExpiration + time.Minute, wantErr: true},
+		{name: "Used token", status: models.StatusActive, created: time.Minute, used: true, wantErr: true},
+		{name: "Unknown token", status: models.StatusActive, created: time.Minute, token: "made-up", wantErr: true},
+		{name: "Suspended account", status: models.StatusSuspended, created: time.Minute, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newPasswordResetTest(t, tt.status)
+
+			token := test.issueToken(t, time.Now().Add(-tt.created))
+			if tt.used {
+				if _, err := test.resets.Consume(hashPasswordResetToken(token), time.Now()); err != nil {
+					t.Fatalf("Consume() error = %v", err)
+				}
+			}
+			if tt.token != "" {
+				token = tt.token
+			}
+
+			user, err := test.service.ResetPassword(token, "violet tractor umbrella")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResetPassword() error = %v, want an error: %v", err, tt.wantErr)
+			}
+
+			stored := test.users.users["user-1"]
+			changed := bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte("violet tractor umbrella")) == nil
+			if changed == tt.wantErr {
+				t.Errorf("ResetPassword() changed the password: %v, want %v", changed, !tt.wantErr)
+			}
+
+			if err == nil && user.Status != models.StatusActive {
+				t.Errorf("ResetPassword() status = %s, want %s", user.Status, models.StatusActive)
+			}
+		})
+	}
+}
+
+func TestResetPasswordIsSingleUse(t *testing.T) {
+	test := newPasswordResetTest(t, models.StatusActive)
+	token := test.issueToken(t, time.Now().Add(-time.Minute))
+	other := test.issueToken(t, time.Now().Add(-time.Minute*2))
+
+	if _, err := test.service.ResetPassword(token, "violet tractor umbrella"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+
+	if _, err := test.service.ResetPassword(token, "another fine password"); err == nil {
+		t.Error("ResetPassword() with the same token again succeeded")
+	}
+
+	// The user's other links stop working too
+	if _, err := test.service.ResetPassword(other, "another fine password"); err == nil {
+		t.Error("ResetPassword() with another token of the user succeeded")
+	}
+
+	stored := test.users.users["user-1"]
+	if bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte("violet tractor umbrella")) != nil {
+		t.Error("the password of the first reset was overwritten")
+	}
+}
+
+func TestResetPasswordRevokesTokens(t *testing.T) {
+	auth.InitJWTSecret(&config.Config{JWTSecret: "test-secret"})
+	auth.SetRefreshTokenStore(auth.NewMemoryRefreshTokenStore())
+	auth.SetSessionStore(auth.NewMemorySessionStore())
+	auth.SetRevocationStore(auth.NewMemoryRevocationStore())
+
+	test := newPasswordResetTest(t, models.StatusActive)
+	loadUser := func(id string) (*models.User, error) {
+		return test.users.FindByID(id)
+	}
+
+	pair, err := auth.GenerateTokenPair(test.users.users["user-1"])
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	// Failed logins of whoever was guessing the old password
+	if _, err := test.throttles.RecordFailure(models.ThrottleAccount, "jane@example.com", time.Now()); err != nil {
+		t.Fatalf("RecordFailure() error = %v", err)
+	}
+
+	token := test.issueToken(t, time.Now().Add(-time.Minute))
+	if _, err := test.service.ResetPassword(token, "violet tractor umbrella"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+
+	if _, err := auth.RefreshTokens(pair.RefreshToken, loadUser); err == nil {
+		t.Error("RefreshTokens() with a token issued before the reset succeeded")
+	}
+
+	if throttle, _ := test.throttles.Find(models.ThrottleAccount, "jane@example.com"); throttle != nil {
+		t.Errorf("failed logins = %d after the reset, want them forgotten", throttle.Failures)
+	}
+}
@@ -0,0 +1,63 @@
+package aplication
+
+import (
+	"slices"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+)
+
+// PermissionService implements the PermissionService interface, letting admins edit the
+// permissions of each role. The permissions are kept by the auth package's permission store.
+type PermissionService struct{}
+
+// NewPermissionService creates a new PermissionService instance
+func NewPermissionService() *PermissionService {
+	return &PermissionService{}
+}
+
+// GetRolePermissions returns the permissions of every role
+func (s *PermissionService) GetRolePermissions() (map[models.Role][]models.Permission, error) {
+	rolePermissions := make(map[models.Role][]models.Permission, len(models.AllRoles))
+	for _, role := range models.AllRoles {
+		permissions, err := auth.RolePermissions(role)
+		if err != nil {
+			return nil, err
+		}
+		rolePermissions[role] = permissions
+	}
+
+	return rolePermissions, nil
+}
+
+// SetRolePermissions replaces the permissions of a role. The admin role always keeps the
+// permission to edit roles, so admins can't lock themselves out.
+func (s *PermissionService) SetRolePermissions(role models.Role, permissions []models.Permission, actorID string) ([]models.Permission, error) {
+	if !role.IsValid() {
+		return nil, models.ErrInvalidRole
+	}
+
+	// Kept in the order of AllPermissions, without duplicates
+	for _, permission := range permissions {
+		if !permission.IsValid() {
+			return nil, models.ErrInvalidPermission
+		}
+	}
+
+	granted := make([]models.Permission, 0, len(permissions))
+	for _, permission := range models.AllPermissions {
+		if slices.Contains(permissions, permission) {
+			granted = append(granted, permission)
+		}
+	}
+
+	if role.IsEquals(models.RoleAdmin) && !slices.Contains(granted, models.PermissionRolesManage) {
+		return nil, models.ErrAdminRoleLockout
+	}
+
+	if err := auth.SetRolePermissions(role, granted, actorID); err != nil {
+		return nil, err
+	}
+
+	return granted, nil
+}
@@ -0,0 +1,64 @@
+package aplication
+
+import (
+	"errors"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+)
+
+// SessionService implements the SessionService interface, letting users see where they are
+// logged in and end sessions. The sessions are kept by the auth package's session store.
+type SessionService struct {
+	users ports.UserRepository
+}
+
+// NewSessionService creates a new SessionService instance
+func NewSessionService(users ports.UserRepository) *SessionService {
+	return &SessionService{
+		users: users,
+	}
+}
+
+// GetSessions returns the active sessions of a user, newest first. currentSessionID is the
+// session of the request, marked as current.
+func (s *SessionService) GetSessions(userID, currentSessionID string) ([]*models.Session, error) {
+	if err := s.checkUserExists(userID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := auth.GetUserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// EndSession ends an active session of a user, logging the device out
+func (s *SessionService) EndSession(userID, sessionID string) error {
+	if err := s.checkUserExists(userID); err != nil {
+		return err
+	}
+
+	return auth.EndSession(userID, sessionID)
+}
+
+// checkUserExists returns an error if the user doesn't exist
+func (s *SessionService) checkUserExists(userID string) error {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAccountDeletionNotFound = errors.New("no account deletion is pending")
+	ErrAccountDeletionPending  = errors.New("account deletion has already been requested")
+	ErrAccountDeleted          = errors.New("account has been deleted")
+)
+
+const (
+	AccountDeletionGracePeriod = time.Hour * 24 * 30 // How long a user has to cancel the deletion of their account
+	AccountErasureInterval     = time.Hour           // How often the accounts whose grace period is over are erased
+)
+
+// DeletedUserName replaces the name of erased accounts
+const DeletedUserName = "Deleted user"
+
+// AccountDeletionStatus is where an account deletion stands
+type AccountDeletionStatus string
+
+const (
+	AccountDeletionPending   AccountDeletionStatus = "pending"
+	AccountDeletionCancelled AccountDeletionStatus = "cancelled"
+	AccountDeletionErased    AccountDeletionStatus = "erased"
+)
+
+// AccountDeletion is the request to delete an account. Once its grace period is over, the
+// personal data of the account is erased and the user is left as a tombstone, so donations and
+// adoptions keep pointing at it.
+type AccountDeletion struct {
+	ID           string                `json:"id"`
+	UserID       string                `json:"user_id"`
+	RequestedBy  string                `json:"requested_by"` // The user, or the admin who deleted the account
+	RequestedAt  time.Time             `json:"requested_at"`
+	ScheduledFor time.Time             `json:"scheduled_for"` // When the account is erased, unless the deletion is cancelled
+	CancelledAt  *time.Time            `json:"cancelled_at,omitempty"`
+	ErasedAt     *time.Time            `json:"erased_at,omitempty"`
+	CompletedAt  *time.Time            `json:"completed_at,omitempty"` // When the other modules erased what they keep too
+	Status       AccountDeletionStatus `json:"status"`                 // Not stored, set from the times when the deletion is loaded
+}
+
+// NewAccountDeletion creates a new AccountDeletion scheduled after AccountDeletionGracePeriod
+func NewAccountDeletion(id, userID, requestedBy string, now time.Time) *AccountDeletion {
+	return &AccountDeletion{
+		ID:           id,
+		UserID:       userID,
+		RequestedBy:  requestedBy,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(AccountDeletionGracePeriod),
+		Status:       AccountDeletionPending,
+	}
+}
+
+// CurrentStatus returns where the deletion stands
+func (d *AccountDeletion) CurrentStatus() AccountDeletionStatus {
+	switch {
+	case d.ErasedAt != nil:
+		return AccountDeletionErased
+	case d.CancelledAt != nil:
+		return AccountDeletionCancelled
+	}
+	return AccountDeletionPending
+}
+
+// TombstoneEmail returns the address an erased account is left with. It is unique, so the
+// original address can be registered again, and can't receive mail.
+func TombstoneEmail(userID string) string {
+	return "deleted-" + userID + "@deleted.invalid"
+}
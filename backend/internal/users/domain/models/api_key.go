@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+var (
+	ErrAPIKeyNotFound               = errors.New("API key not found")
+	ErrInvalidAPIKeyName            = errors.New("API key name must be between 1 and 100 characters")
+	ErrInvalidAPIKeyExpiry          = errors.New("API key expiry must be in the future")
+	ErrNoAPIKeyPermissions          = errors.New("API keys need at least one permission")
+	ErrAPIKeyPermissionNotGrantable = errors.New("API keys can't be granted permissions to manage roles or API keys")
+	ErrAPIKeyPermissionNotHeld      = errors.New("API keys can only be granted permissions your role has")
+	ErrAPIKeyShelterRequired        = errors.New("API keys need a shelter_id unless platform_wide is set")
+	ErrAPIKeyPlatformWideShelter    = errors.New("platform-wide API keys can't have a shelter_id")
+)
+
+// APIKeyLastUsedInterval is how often the last use of an API key is recorded, so busy keys
+// don't cause a write on every request
+const APIKeyLastUsedInterval = time.Minute
+
+// APIKey lets a machine client, such as a partner clinic, call the API without a user account.
+// Only the hash of the key is stored; the key itself is shown once, when it is created.
+type APIKey struct {
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	Prefix       string       `json:"prefix"` // Start of the key, identifies it in listings and logs
+	KeyHash      string       `json:"-"`
+	Permissions  []Permission `json:"permissions"`
+	CreatedBy    string       `json:"created_by"`
+	ShelterID    string       `json:"shelter_id,omitempty"` // Shelter the key works for, empty for platform-wide keys
+	PlatformWide bool         `json:"platform_wide"`        // Works across shelters like a platform admin, only when asked for explicitly
+	Created      time.Time    `json:"created"`
+	ExpiresAt    *time.Time   `json:"expires_at,omitempty"` // Never expires when nil
+	LastUsedAt   *time.Time   `json:"last_used_at,omitempty"`
+	RevokedAt    *time.Time   `json:"revoked_at,omitempty"`
+}
+
+// IsExpired checks if the key has expired
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// IsRevoked checks if the key has been revoked
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// HasPermission checks if the key was granted every one of the given permissions
+func (k *APIKey) HasPermission(required ...Permission) bool {
+	for _, permission := range required {
+		if !slices.Contains(k.Permissions, permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsGrantableToAPIKey checks if API keys can be granted the permission. Keys can't manage
+// roles or other keys, so a leaked key can't be used to widen its own access.
+func (p Permission) IsGrantableToAPIKey() bool {
+	return p != PermissionRolesManage && p != PermissionAPIKeysManage
+}
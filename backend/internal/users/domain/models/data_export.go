@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrDataExportNotFound   = errors.New("data export not found")
+	ErrDataExportNotReady   = errors.New("data export is not ready")
+	ErrInvalidDataExportURL = errors.New("invalid or expired download link")
+)
+
+const (
+	DataExportExpiration = time.Hour * 24  // How long the archive of an export can be downloaded
+	DataExportTimeout    = time.Hour       // After which an export still being generated is considered failed
+	DataExportWait       = time.Second * 2 // How long a request waits for its export before it is finished in the background
+)
+
+// DataExportStatus is where a data export stands
+type DataExportStatus string
+
+const (
+	DataExportPending DataExportStatus = "pending"
+	DataExportReady   DataExportStatus = "ready"
+	DataExportFailed  DataExportStatus = "failed"
+	DataExportExpired DataExportStatus = "expired"
+)
+
+// DataExport is an archive of the personal data of a user, generated in the background and
+// downloadable through an expiring link once ready
+type DataExport struct {
+	ID          string           `json:"id"`
+	UserID      string           `json:"user_id"`
+	Status      DataExportStatus `json:"status"`
+	Created     time.Time        `json:"created"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty"`   // Set when the archive is ready
+	Size        int64            `json:"size"`                   // Of the archive, in bytes
+	DownloadURL string           `json:"download_url,omitempty"` // Not stored, set while the archive can be downloaded
+}
+
+// NewDataExport creates a new pending DataExport
+func NewDataExport(id, userID string, now time.Time) *DataExport {
+	return &DataExport{
+		ID:      id,
+		UserID:  userID,
+		Status:  DataExportPending,
+		Created: now,
+	}
+}
+
+// StatusAt returns where the export stands at the given time. Ready archives expire, and exports
+// left pending by a restart are considered failed.
+func (e *DataExport) StatusAt(now time.Time) DataExportStatus {
+	switch {
+	case e.Status == DataExportReady && e.ExpiresAt != nil && !now.Before(*e.ExpiresAt):
+		return DataExportExpired
+	case e.Status == DataExportPending && now.Sub(e.Created) >= DataExportTimeout:
+		return DataExportFailed
+	}
+	return e.Status
+}
+
+// ExportSection is one kind of record in a data export. Its records are written to the JSON
+// file of the archive, and its rows to a CSV file named after it. Records is left nil for rows
+// that are nested in the records of another section.
+type ExportSection struct {
+	Name    string
+	Records any
+	Header  []string
+	Rows    [][]string
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnknownIdentityProvider     = errors.New("unknown identity provider")
+	ErrIdentityProviderUnavailable = errors.New("identity provider is unavailable")
+	ErrInvalidLoginState           = errors.New("invalid or expired login state")
+	ErrExternalLoginFailed         = errors.New("external login failed")
+	ErrIdentityEmailNotVerified    = errors.New("the identity provider has not verified this email address")
+)
+
+// OIDCLoginExpiration is how long a user has to log in at the identity provider
+const OIDCLoginExpiration = time.Minute * 10
+
+// ExternalIdentity links a user to their account at an OpenID Connect provider
+type ExternalIdentity struct {
+	Provider string    `json:"provider"`
+	Subject  string    `json:"subject"` // ID of the account at the provider
+	UserID   string    `json:"user_id"`
+	Email    string    `json:"email"` // Email address the identity was linked with
+	Created  time.Time `json:"created"`
+}
+
+// OIDCLoginState is what the application remembers of a login sent to an identity provider,
+// until the provider redirects the user back with the state
+type OIDCLoginState struct {
+	State        string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
+
+// NewOIDCLoginState creates a new OIDCLoginState that expires after OIDCLoginExpiration
+func NewOIDCLoginState(state, provider, nonce, codeVerifier string, now time.Time) *OIDCLoginState {
+	return &OIDCLoginState{
+		State:        state,
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    now.Add(OIDCLoginExpiration),
+	}
+}
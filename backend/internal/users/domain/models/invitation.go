@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvitationNotFound    = errors.New("invitation not found")
+	ErrInvalidInvitation     = errors.New("invalid or expired invitation")
+	ErrInvitationNotPending  = errors.New("invitation has already been accepted or revoked")
+	ErrInvalidInvitationRole = errors.New("invitations are for staff roles: admin, volunteer or vet")
+	ErrRegistrationRole      = errors.New("registration is only open to the user role, staff accounts are created through invitations")
+)
+
+// InvitationExpiration is how long an invited staff member has to accept the invitation
+const InvitationExpiration = time.Hour * 72
+
+// InvitationStatus is where an invitation stands
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationRevoked  InvitationStatus = "revoked"
+	InvitationExpired  InvitationStatus = "expired"
+)
+
+// Invitation lets an admin bring a staff member in with a given role, at a shelter. It is kept
+// once used, as a record of who invited whom.
+type Invitation struct {
+	ID             string           `json:"id"`
+	Email          string           `json:"email"`
+	Role           Role             `json:"role"`
+	ShelterID      string           `json:"shelter_id,omitempty"` // Shelter the staff member joins, empty for platform admins
+	InvitedBy      string           `json:"invited_by"`
+	Created        time.Time        `json:"created"`
+	ExpiresAt      time.Time        `json:"expires_at"`
+	AcceptedAt     *time.Time       `json:"accepted_at,omitempty"`
+	AcceptedUserID string           `json:"accepted_user_id,omitempty"` // Account created with the invitation
+	RevokedAt      *time.Time       `json:"revoked_at,omitempty"`
+	Status         InvitationStatus `json:"status"` // Not stored, set from StatusAt when the invitation is loaded
+}
+
+// NewInvitation creates a new Invitation that expires after InvitationExpiration
+func NewInvitation(id, email string, role Role, shelterID, invitedBy string, now time.Time) (*Invitation, error) {
+	if role.IsEquals(RoleUser) || !role.IsValid() {
+		return nil, ErrInvalidInvitationRole
+	}
+
+	return &Invitation{
+		ID:        id,
+		Email:     email,
+		Role:      role,
+		ShelterID: shelterID,
+		InvitedBy: invitedBy,
+		Created:   now,
+		ExpiresAt: now.Add(InvitationExpiration),
+	}, nil
+}
+
+// StatusAt returns where the invitation stands at the given time
+func (i *Invitation) StatusAt(now time.Time) InvitationStatus {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case !now.Before(i.ExpiresAt):
+		return InvitationExpired
+	}
+	return InvitationPending
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ThrottleKind is what failed logins are counted against
+type ThrottleKind string
+
+const (
+	ThrottleAccount ThrottleKind = "account" // Counted per email address, registered or not
+	ThrottleIP      ThrottleKind = "ip"      // Counted per client IP address, across accounts
+)
+
+// LoginThrottlePolicy decides how long logins must wait after a number of failures. Once
+// BackoffAfter failures are reached, each failure doubles the wait, starting at BaseDelay and
+// capped at MaxDelay. Reaching LockAfter failures locks logins out for LockDuration.
+type LoginThrottlePolicy struct {
+	BackoffAfter int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	LockAfter    int
+	LockDuration time.Duration
+	Window       time.Duration // Failures older than this are forgotten
+}
+
+var (
+	// AccountLoginPolicy applies to the failed logins of an email address
+	AccountLoginPolicy = LoginThrottlePolicy{
+		BackoffAfter: 3,
+		BaseDelay:    time.Second,
+		MaxDelay:     time.Minute * 5,
+		LockAfter:    10,
+		LockDuration: time.Minute * 30,
+		Window:       time.Hour * 24,
+	}
+
+	// IPLoginPolicy applies to the failed logins from an IP address. It is looser than the account
+	// policy since many users can share an address.
+	IPLoginPolicy = LoginThrottlePolicy{
+		BackoffAfter: 20,
+		BaseDelay:    time.Second,
+		MaxDelay:     time.Minute * 15,
+		LockAfter:    100,
+		LockDuration: time.Hour,
+		Window:       time.Hour,
+	}
+)
+
+// Policy returns the throttle policy of the kind
+func (k ThrottleKind) Policy() LoginThrottlePolicy {
+	if k == ThrottleIP {
+		return IPLoginPolicy
+	}
+	return AccountLoginPolicy
+}
+
+// Delay returns how long to wait after the last of the given number of failures
+func (p LoginThrottlePolicy) Delay(failures int) time.Duration {
+	if failures < p.BackoffAfter {
+		return 0
+	}
+
+	exponent := failures - p.BackoffAfter
+	if exponent > 30 {
+		return p.MaxDelay
+	}
+
+	delay := p.BaseDelay << exponent
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// LoginThrottle holds the recent failed logins of an account or IP address
+type LoginThrottle struct {
+	Kind        ThrottleKind
+	Subject     string // Lowercased email address or IP address
+	Failures    int
+	LastFailure time.Time
+	LockedUntil *time.Time
+}
+
+// IsLocked checks if logins are locked out
+func (t *LoginThrottle) IsLocked(now time.Time) bool {
+	return t.LockedUntil != nil && now.Before(*t.LockedUntil)
+}
+
+// RetryAfter returns how long logins must wait, or zero if they can be attempted now
+func (t *LoginThrottle) RetryAfter(now time.Time) time.Duration {
+	policy := t.Kind.Policy()
+	if now.Sub(t.LastFailure) >= policy.Window {
+		return 0
+	}
+
+	wait := t.LastFailure.Add(policy.Delay(t.Failures)).Sub(now)
+	if t.IsLocked(now) {
+		wait = max(wait, t.LockedUntil.Sub(now))
+	}
+
+	return max(wait, 0)
+}
+
+// LoginThrottledError is returned when a login is attempted before the wait after failed logins is over
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool // Whether the account or address is locked out, rather than backing off
+}
+
+// Error returns the error message
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed login attempts, logins are locked for %d seconds", e.RetryAfterSeconds())
+	}
+	return fmt.Sprintf("too many failed login attempts, try again in %d seconds", e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds returns the wait rounded up to whole seconds, as used by the Retry-After header
+func (e *LoginThrottledError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
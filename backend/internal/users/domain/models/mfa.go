@@ -0,0 +1,42 @@
+package models
+
+import "errors"
+
+var (
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrMFANotEnrolled     = errors.New("two-factor authentication enrollment has not been started")
+	ErrInvalidMFACode     = errors.New("invalid authentication code")
+	ErrTooManyMFAAttempts = errors.New("too many invalid authentication codes, please log in again")
+	ErrMFARequiredByRole  = errors.New("two-factor authentication is required for your role")
+)
+
+const (
+	RecoveryCodeCount = 10 // Recovery codes generated at a time
+	MaxMFAAttempts    = 5  // Invalid codes accepted for one login before the user must start over
+)
+
+// MFA holds the two-factor authentication settings of a user.
+// The account is only protected once the enrollment has been confirmed with a first code.
+type MFA struct {
+	UserID         string `json:"-" db:"user_id"`
+	Secret         string `json:"-" db:"secret"`
+	Enabled        bool   `json:"enabled" db:"enabled"`
+	LastUsedStep   int64  `json:"-" db:"last_used_step"` // Time step of the last accepted code, so codes can't be replayed
+	FailedAttempts int    `json:"-" db:"failed_attempts"`
+	Created        string `json:"created" db:"created"`
+	Updated        string `json:"updated" db:"updated"`
+}
+
+// MFAEnrollment is what a user needs to add their account to an authenticator app
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth:// URI, usually shown as a QR code
+}
+
+// MFAStatus describes the two-factor authentication of a user
+type MFAStatus struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"` // Whether the user's role must use it on sensitive routes
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
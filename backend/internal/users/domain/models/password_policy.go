@@ -0,0 +1,179 @@
+package models
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Codes of the rules a password can break, returned with each PasswordViolation
+const (
+	PasswordTooShort         = "too_short"
+	PasswordTooLong          = "too_long"
+	PasswordMissingUppercase = "missing_uppercase"
+	PasswordMissingLowercase = "missing_lowercase"
+	PasswordMissingDigit     = "missing_digit"
+	PasswordMissingSymbol    = "missing_symbol"
+	PasswordPersonalInfo     = "personal_info"
+	PasswordCommon           = "common_password"
+	PasswordReused           = "reused_password"
+)
+
+// MaxPasswordBytes is the longest password bcrypt can hash. Longer passwords are refused rather
+// than silently truncated.
+const MaxPasswordBytes = 72
+
+// PasswordPolicy decides which passwords users can choose
+type PasswordPolicy struct {
+	MinLength        int // In characters
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	CheckCommon      bool // Refuse the breached and common passwords of the bundled list
+	HistorySize      int  // How many of the user's latest passwords can't be reused, the current one included
+}
+
+// PasswordViolation is a rule a password breaks
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError is returned when a password breaks the password policy, with every rule it breaks
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+// Error returns the error message
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return "password does not meet the password policy: " + strings.Join(messages, "; ")
+}
+
+// Validate checks a password against the policy. Personal details of the user, such as their
+// email address and name, can't be part of the password.
+func (p PasswordPolicy) Validate(password string, personal ...string) error {
+	var violations []PasswordViolation
+	add := func(code, message string) {
+		violations = append(violations, PasswordViolation{Code: code, Message: message})
+	}
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		add(PasswordTooShort, fmt.Sprintf("must be at least %d characters long", p.MinLength))
+	}
+
+	if len(password) > MaxPasswordBytes {
+		add(PasswordTooLong, fmt.Sprintf("must be at most %d bytes long", MaxPasswordBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUppercase && !hasUpper {
+		add(PasswordMissingUppercase, "must contain an uppercase letter")
+	}
+	if p.RequireLowercase && !hasLower {
+		add(PasswordMissingLowercase, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		add(PasswordMissingDigit, "must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		add(PasswordMissingSymbol, "must contain a symbol")
+	}
+
+	if containsPersonalInfo(password, personal) {
+		add(PasswordPersonalInfo, "must not contain your email address or name")
+	}
+
+	if p.CheckCommon && IsCommonPassword(password) {
+		add(PasswordCommon, "is too common or has appeared in a data breach")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+// ReusedPasswordError returns the error for a password that is one of the user's latest passwords
+func (p PasswordPolicy) ReusedPasswordError() *PasswordPolicyError {
+	return &PasswordPolicyError{Violations: []PasswordViolation{{
+		Code:    PasswordReused,
+		Message: fmt.Sprintf("must not be one of your last %d passwords", p.HistorySize),
+	}}}
+}
+
+// minPersonalInfoLength is the shortest personal detail looked for in passwords, so short
+// names don't rule out every password containing them
+const minPersonalInfoLength = 3
+
+// containsPersonalInfo checks if the password contains one of the personal details, or the part
+// before the @ of an email address, ignoring case and spaces
+func containsPersonalInfo(password string, personal []string) bool {
+	normalized := normalizePassword(password)
+
+	for _, detail := range personal {
+		candidates := []string{detail}
+		if at := strings.LastIndex(detail, "@"); at > 0 {
+			candidates = append(candidates, detail[:at])
+		}
+
+		for _, candidate := range candidates {
+			candidate = normalizePassword(candidate)
+			if utf8.RuneCountInString(candidate) >= minPersonalInfoLength && strings.Contains(normalized, candidate) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// normalizePassword lowercases a password and drops its spaces
+func normalizePassword(password string) string {
+	return strings.Join(strings.Fields(strings.ToLower(password)), "")
+}
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+var (
+	commonPasswords     map[string]struct{}
+	commonPasswordsOnce sync.Once
+)
+
+// IsCommonPassword checks, ignoring case, if a password is in the bundled list of breached and
+// common passwords. The list is only loaded the first time it is needed.
+func IsCommonPassword(password string) bool {
+	commonPasswordsOnce.Do(func() {
+		commonPasswords = make(map[string]struct{})
+		for _, line := range strings.Split(commonPasswordList, "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				commonPasswords[strings.ToLower(line)] = struct{}{}
+			}
+		}
+	})
+
+	_, ok := commonPasswords[strings.ToLower(password)]
+	return ok
+}
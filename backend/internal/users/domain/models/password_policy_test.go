@@ -0,0 +1,103 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 12, CheckCommon: true}
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		want     []string
+	}{
+		{name: "Long enough", policy: policy, password: "violet tractor umbrella"},
+		{name: "Too short", policy: policy, password: "tractor7", want: []string{PasswordTooShort}},
+		{name: "Length counts characters, not bytes", policy: policy, password: "ñandú ñandú ñ"},
+		{name: "Too long for bcrypt", policy: policy, password: strings.Repeat("a", MaxPasswordBytes+1), want: []string{PasswordTooLong}},
+		{name: "Exactly as long as bcrypt allows", policy: policy, password: strings.Repeat("ab", MaxPasswordBytes/2)},
+		{name: "Breached password", policy: PasswordPolicy{CheckCommon: true}, password: "password1", want: []string{PasswordCommon}},
+		{name: "Breached password in another case", policy: PasswordPolicy{CheckCommon: true}, password: "PassWord1", want: []string{PasswordCommon}},
+		{name: "Breached password too short", policy: policy, password: "123456", want: []string{PasswordTooShort, PasswordCommon}},
+		{name: "Breached list not checked", policy: PasswordPolicy{}, password: "password1"},
+		{name: "Contains the name", policy: policy, password: "I am Jane Doe forever", want: []string{PasswordPersonalInfo}},
+		{name: "Contains the email address", policy: policy, password: "jane.doe@example.com!", want: []string{PasswordPersonalInfo}},
+		{name: "Contains the local part of the email", policy: policy, password: "secret-JANE.DOE-secret", want: []string{PasswordPersonalInfo}},
+		{name: "Contains the name with other spacing", policy: policy, password: "xx janedoe rocks xx", want: []string{PasswordPersonalInfo}},
+		{
+			name:     "Character classes",
+			policy:   PasswordPolicy{RequireUppercase: true, RequireLowercase: true, RequireDigit: true, RequireSymbol: true},
+			password: "lowercase only",
+			want:     []string{PasswordMissingUppercase, PasswordMissingDigit},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.password, "jane.doe@example.com", "Jane Doe")
+
+			var got []string
+			var policyErr *PasswordPolicyError
+			if errors.As(err, &policyErr) {
+				for _, violation := range policyErr.Violations {
+					got = append(got, violation.Code)
+				}
+			} else if err != nil {
+				t.Fatalf("Validate() error = %v, want a PasswordPolicyError", err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Validate() violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainsPersonalInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		personal []string
+		want     bool
+	}{
+		{name: "Name", password: "bestmariaever", personal: []string{"Maria"}, want: true},
+		{name: "Short names are ignored", password: "bestjoever", personal: []string{"Jo"}, want: false},
+		{name: "Local part of the email", password: "xxmaria.lopezxx", personal: []string{"maria.lopez@example.com"}, want: true},
+		{name: "Domain alone", password: "example.com rules", personal: []string{"maria.lopez@example.com"}, want: false},
+		{name: "Nothing in common", password: "violet tractor umbrella", personal: []string{"Maria Lopez", "maria.lopez@example.com"}, want: false},
+		{name: "No personal details", password: "violet tractor umbrella", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsPersonalInfo(tt.password, tt.personal); got != tt.want {
+				t.Errorf("containsPersonalInfo(%q, %v) = %v, want %v", tt.password, tt.personal, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCommonPassword(t *testing.T) {
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{password: "123456", want: true},
+		{password: "password", want: true},
+		{password: "PASSWORD", want: true},
+		{password: "violet tractor umbrella", want: false},
+		{password: "", want: false},
+		{password: "# Breached and common passwords refused by the password policy, compared ignoring case.", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsCommonPassword(tt.password); got != tt.want {
+			t.Errorf("IsCommonPassword(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+const (
+	PasswordResetExpiration = time.Minute * 30 // How long a reset link stays valid
+	PasswordResetCooldown   = time.Minute      // Minimum time between two reset emails to the same user
+)
+
+// PasswordResetToken is a single-use token letting a user choose a new password.
+// Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	TokenHash string
+	UserID    string
+	Created   time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// NewPasswordResetToken creates a new PasswordResetToken that expires after PasswordResetExpiration
+func NewPasswordResetToken(tokenHash, userID string, now time.Time) *PasswordResetToken {
+	return &PasswordResetToken{
+		TokenHash: tokenHash,
+		UserID:    userID,
+		Created:   now,
+		ExpiresAt: now.Add(PasswordResetExpiration),
+	}
+}
+
+// IsUsable checks if the token has not been used and has not expired
+func (t *PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
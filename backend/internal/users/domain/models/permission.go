@@ -0,0 +1,96 @@
+package models
+
+import "errors"
+
+var (
+	ErrInvalidPermission = errors.New("invalid permission")
+	ErrAdminRoleLockout  = errors.New("the admin role must keep the roles:manage permission")
+)
+
+// Permission is a named action a role can be allowed to perform, written as resource:action
+type Permission string
+
+const (
+	PermissionUsersRead          Permission = "users:read"          // See other users and their details
+	PermissionUsersManage        Permission = "users:manage"        // Change users' roles, statuses and passwords, unlock them and revoke their tokens
+	PermissionUsersDelete        Permission = "users:delete"        // Delete users
+	PermissionRolesManage        Permission = "roles:manage"        // Edit the permissions of each role
+	PermissionAPIKeysManage      Permission = "api-keys:manage"     // Create, list and revoke API keys
+	PermissionPetsWrite          Permission = "pets:write"          // Create and update pets and their status
+	PermissionPetsDelete         Permission = "pets:delete"         // Delete pets
+	PermissionAdoptionsRead      Permission = "adoptions:read"      // See every adoption
+	PermissionAdoptionsApprove   Permission = "adoptions:approve"   // Update adoptions, including approving and rejecting them
+	PermissionAdoptionsDelete    Permission = "adoptions:delete"    // Delete adoptions
+	PermissionDonationsRead      Permission = "donations:read"      // See every donation, its ledger and its refunds
+	PermissionDonationsWrite     Permission = "donations:write"     // Change the status of donations
+	PermissionDonationsDelete    Permission = "donations:delete"    // Delete donations
+	PermissionDonationsRefund    Permission = "donations:refund"    // Review refund requests
+	PermissionDonationsReports   Permission = "donations:reports"   // See donation reports
+	PermissionSuppliesManage     Permission = "supplies:manage"     // Record in-kind donations and manage the supply inventory
+	PermissionVolunteersManage   Permission = "volunteers:manage"   // Schedule shifts, record volunteers' hours and see hours reports
+	PermissionAppointmentsManage Permission = "appointments:manage" // Schedule vet appointments and mark them done or missed
+	PermissionTransfersManage    Permission = "transfers:manage"    // Request, approve and complete pet transfers between shelters
+	PermissionSheltersManage     Permission = "shelters:manage"     // Update the shelter's details, and add shelters as a platform admin
+	PermissionAuditRead          Permission = "audit:read"          // See and verify the audit log
+)
+
+// AllPermissions lists every permission, in the order they are shown to admins
+var AllPermissions = []Permission{
+	PermissionUsersRead,
+	PermissionUsersManage,
+	PermissionUsersDelete,
+	PermissionRolesManage,
+	PermissionAPIKeysManage,
+	PermissionPetsWrite,
+	PermissionPetsDelete,
+	PermissionAdoptionsRead,
+	PermissionAdoptionsApprove,
+	PermissionAdoptionsDelete,
+	PermissionDonationsRead,
+	PermissionDonationsWrite,
+	PermissionDonationsDelete,
+	PermissionDonationsRefund,
+	PermissionDonationsReports,
+	PermissionSuppliesManage,
+	PermissionVolunteersManage,
+	PermissionAppointmentsManage,
+	PermissionTransfersManage,
+	PermissionSheltersManage,
+	PermissionAuditRead,
+}
+
+// staffPermissions are the default permissions of vets and volunteers
+var staffPermissions = []Permission{
+	PermissionPetsWrite,
+	PermissionPetsDelete,
+	PermissionAdoptionsRead,
+	PermissionAdoptionsApprove,
+	PermissionAdoptionsDelete,
+	PermissionSuppliesManage,
+}
+
+// vetPermissions are the default permissions of vets, who also keep the appointments calendar
+var vetPermissions = append([]Permission{PermissionAppointmentsManage}, staffPermissions...)
+
+// DefaultRolePermissions are the permissions of the roles that admins haven't edited
+var DefaultRolePermissions = map[Role][]Permission{
+	RoleAdmin:     AllPermissions,
+	RoleVet:       vetPermissions,
+	RoleVolunteer: staffPermissions,
+	RoleUser:      {},
+}
+
+// String converts the Permission to a string
+func (p Permission) String() string {
+	return string(p)
+}
+
+// IsValid checks if the permission is valid
+func (p Permission) IsValid() bool {
+	for _, permission := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
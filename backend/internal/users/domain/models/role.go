@@ -9,6 +9,9 @@ const (
 	RoleVet       Role = "vet"
 )
 
+// AllRoles lists every role
+var AllRoles = []Role{RoleAdmin, RoleUser, RoleVolunteer, RoleVet}
+
 var (
 	validRoles = map[Role]struct{}{
 		RoleAdmin:     {},
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is a login of a user on a device. It lasts as long as the refresh tokens rotated from
+// the login, which share its ID as their family.
+type Session struct {
+	ID              string     `json:"id"`
+	UserID          string     `json:"user_id"`
+	UserAgent       string     `json:"user_agent"`
+	IPAddress       string     `json:"ip_address"` // Address of the last login or refresh
+	AMR             []string   `json:"amr"`        // How the user authenticated
+	Created         time.Time  `json:"created"`
+	LastRefreshedAt *time.Time `json:"last_refreshed_at,omitempty"`
+	ExpiresAt       time.Time  `json:"expires_at"` // When the latest refresh token expires
+	RevokedAt       *time.Time `json:"revoked_at,omitempty"`
+	Current         bool       `json:"current"` // Not stored, set for the session of the request
+}
+
+// IsActive checks if the session has not been ended and its refresh token has not expired
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
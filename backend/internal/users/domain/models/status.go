@@ -17,6 +17,10 @@ const (
 	StatusInactive  Status = "inactive"
 	StatusSuspended Status = "suspended"
 	StatusPending   Status = "pending"
+
+	// StatusDeleted marks the tombstone left by an erased account. It is only set by the
+	// erasure, so it isn't a status users can be given.
+	StatusDeleted Status = "deleted"
 )
 
 var (
@@ -1,5 +1,10 @@
 package models
 
+import "errors"
+
+// ErrShelterNotFound is returned when a user, invitation or API key is assigned to a shelter that doesn't exist
+var ErrShelterNotFound = errors.New("shelter not found")
+
 type User struct {
 	ID        string   `json:"id" db:"id"`
 	Name      string   `json:"name" db:"name"`
@@ -12,6 +17,7 @@ type User struct {
 	Address   string   `json:"address" db:"address"`
 	Phone     string   `json:"phone" db:"phone"`
 	Documents []string `json:"documents" db:"documents"`
+	ShelterID string   `json:"shelter_id,omitempty" db:"shelter_id"` // Shelter the user works at, empty for platform admins and the public
 }
 
 // NewUser creates a new User instance
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Limits on how often verification emails can be sent to the same address
+const (
+	VerificationResendCooldown = time.Minute // Minimum time between two emails
+	VerificationEmailWindow    = time.Hour   // Window in which MaxVerificationEmails applies
+	MaxVerificationEmails      = 5
+)
+
+// RateLimitError is returned when an action was attempted too often and must wait before being retried
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+// Error returns the error message
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("too many requests, try again in %d seconds", e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds returns the wait rounded up to whole seconds, as used by the Retry-After header
+func (e *RateLimitError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
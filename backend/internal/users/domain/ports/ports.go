@@ -1,27 +1,193 @@
 package ports
 
-import "github.com/solrac97gr/petparadise/internal/users/domain/models"
+import (
+	"context"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
 
 type UserRepository interface {
 	Save(user *models.User) error
 	FindByID(id string) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
-	FindByStatus(status models.Status) ([]*models.User, error)
-	FindAll() ([]*models.User, error)
+	FindByStatus(status models.Status, scope tenant.Scope) ([]*models.User, error)
+	FindAll(scope tenant.Scope) ([]*models.User, error)
+	IsInScope(id string, scope tenant.Scope) (bool, error)
 	Update(user *models.User) error
-	Delete(id string) error
+}
+
+type VerificationRepository interface {
+	RecordVerificationEmail(email string, sentAt time.Time) error
+	FindVerificationEmailsSince(email string, since time.Time) ([]time.Time, error)
+}
+
+type PasswordResetRepository interface {
+	Save(token *models.PasswordResetToken) error
+	FindByHash(tokenHash string) (*models.PasswordResetToken, error)
+	FindLatestByUserID(userID string) (*models.PasswordResetToken, error)
+	Consume(tokenHash string, usedAt time.Time) (bool, error)
+	InvalidateByUserID(userID string, usedAt time.Time) error
+}
+
+type PasswordHistoryRepository interface {
+	Add(userID, passwordHash string, at time.Time) error
+	FindRecent(userID string, limit int) ([]string, error)
+	Prune(userID string, keep int) error
+}
+
+type InvitationRepository interface {
+	Save(invitation *models.Invitation) error
+	FindByID(id string) (*models.Invitation, error)
+	FindAll(scope tenant.Scope) ([]*models.Invitation, error)
+	// FindByUserID finds the invitations the user sent or accepted, newest first
+	FindByUserID(userID string) ([]*models.Invitation, error)
+	Accept(id, userID string, acceptedAt time.Time) (bool, error)
+	ReleaseAcceptance(id string) error
+	Revoke(id string, revokedAt time.Time) (bool, error)
+}
+
+type MFARepository interface {
+	Save(mfa *models.MFA) error
+	FindByUserID(userID string) (*models.MFA, error)
+	Delete(userID string) error
+	UseStep(userID string, step int64) (bool, error)
+	RecordFailedAttempt(userID string) (int, error)
+	ResetFailedAttempts(userID string) error
+	ReplaceRecoveryCodes(userID string, codeHashes []string) error
+	UseRecoveryCode(userID, codeHash, usedAt string) (bool, error)
+	CountRecoveryCodes(userID string) (int, error)
+}
+
+type IdentityRepository interface {
+	Save(identity *models.ExternalIdentity) error
+	FindByProviderSubject(provider, subject string) (*models.ExternalIdentity, error)
+	FindByUserID(userID string) ([]*models.ExternalIdentity, error)
+	SaveLoginState(state *models.OIDCLoginState) error
+	ConsumeLoginState(state string, now time.Time) (*models.OIDCLoginState, error)
+}
+
+type LoginThrottleRepository interface {
+	Find(kind models.ThrottleKind, subject string) (*models.LoginThrottle, error)
+	RecordFailure(kind models.ThrottleKind, subject string, at time.Time) (*models.LoginThrottle, error)
+	Lock(kind models.ThrottleKind, subject string, until time.Time) error
+	Delete(kind models.ThrottleKind, subject string) error
+}
+
+// LoginThrottler counts failed logins, so second factors can't be guessed faster than passwords
+type LoginThrottler interface {
+	CheckLoginThrottle(email, ip string, now time.Time) error
+	RecordLoginFailure(email, ip string, user *models.User, now time.Time) error
+	ForgetLoginFailures(email string) error
+}
+
+type DataExportRepository interface {
+	Save(export *models.DataExport) error
+	FindByID(id string) (*models.DataExport, error)
+	FindLatestByUserID(userID string) (*models.DataExport, error)
+	FindArchive(id string) ([]byte, error)
+	Complete(id string, archive []byte, completedAt, expiresAt time.Time) error
+	Fail(id string, failedAt time.Time) error
+	PurgeExpired(now time.Time) (int64, error)
+}
+
+type AccountDeletionRepository interface {
+	Save(deletion *models.AccountDeletion) error
+	FindPendingByUserID(userID string) (*models.AccountDeletion, error)
+	FindDue(now time.Time) ([]*models.AccountDeletion, error)
+	Cancel(id string, cancelledAt time.Time) (bool, error)
+	Erase(deletion *models.AccountDeletion, erasedAt time.Time) (bool, error)
+	Complete(id string, completedAt time.Time) error
+}
+
+// PersonalDataEraser scrubs the personal data another module keeps about a user whose account is
+// erased, keeping the records themselves. Erasing twice must be harmless, as a failed erasure is
+// tried again.
+type PersonalDataEraser interface {
+	ErasePersonalData(userID string) error
+}
+
+// PersonalDataSource gives the data export access to the records another module keeps about a user
+type PersonalDataSource interface {
+	ExportPersonalData(userID string) ([]*models.ExportSection, error)
+}
+
+// PersonalDataModule is another module keeping records about users. The data export and the
+// account erasure go through the same modules, so neither can leave one out.
+type PersonalDataModule interface {
+	PersonalDataSource
+	PersonalDataEraser
 }
 
 type UserService interface {
 	CreateUser(name, email, password string, role models.Role, address, phone string, documents []string) (*models.User, error)
 	GetUserByID(id string) (*models.User, error)
 	GetUserByEmail(email string) (*models.User, error)
-	GetUsersByStatus(status models.Status) ([]*models.User, error)
-	GetAllUsers() ([]*models.User, error)
+	GetUsersByStatus(status models.Status, scope tenant.Scope) ([]*models.User, error)
+	GetAllUsers(scope tenant.Scope) ([]*models.User, error)
+	IsUserInScope(id string, scope tenant.Scope) (bool, error)
 	UpdateUser(id, name, email, address, phone string, documents []string) (*models.User, error)
-	UpdateUserRole(id string, role models.Role) (*models.User, error)
+	UpdateUserRole(id string, role models.Role, shelterID string) (*models.User, error)
 	UpdateUserStatus(id string, status models.Status) (*models.User, error)
 	ChangePassword(id, oldPassword, newPassword string) error
-	DeleteUser(id string) error
-	Authenticate(email, password string) (*models.User, error)
+	Authenticate(email, password, ip string) (*models.User, error)
+	ForgetLoginFailures(email string) error
+	UnlockUser(id string) error
+	VerifyEmail(token string) (*models.User, error)
+	ResendVerificationEmail(email string) error
+	RequestPasswordReset(email string) error
+	ResetPassword(token, newPassword string) (*models.User, error)
+}
+
+type MFAService interface {
+	GetStatus(userID string) (*models.MFAStatus, error)
+	IsEnabled(userID string) (bool, error)
+	Enroll(userID string) (*models.MFAEnrollment, error)
+	Confirm(userID, code string) ([]string, error)
+	Disable(userID, code string) error
+	RegenerateRecoveryCodes(userID, code string) ([]string, error)
+	VerifyLogin(userID, code, ip string) ([]string, error)
+}
+
+type IdentityService interface {
+	Providers() []string
+	StartLogin(ctx context.Context, provider string) (authURL, state string, err error)
+	CompleteLogin(ctx context.Context, provider, state, code string) (*models.User, error)
+}
+
+type PermissionService interface {
+	GetRolePermissions() (map[models.Role][]models.Permission, error)
+	SetRolePermissions(role models.Role, permissions []models.Permission, actorID string) ([]models.Permission, error)
+}
+
+type APIKeyService interface {
+	CreateAPIKey(name string, permissions []models.Permission, expiresAt *time.Time, creatorID string, creatorRole models.Role, shelterID string, platformWide bool) (*models.APIKey, string, error)
+	GetAPIKeys(scope tenant.Scope) ([]*models.APIKey, error)
+	RevokeAPIKey(id string, scope tenant.Scope) (*models.APIKey, error)
+}
+
+type SessionService interface {
+	GetSessions(userID, currentSessionID string) ([]*models.Session, error)
+	EndSession(userID, sessionID string) error
+}
+
+type DataExportService interface {
+	RequestExport(userID string) (*models.DataExport, error)
+	GetArchive(token string) (*models.DataExport, []byte, error)
+}
+
+type AccountDeletionService interface {
+	RequestDeletion(userID, requestedBy string) (*models.AccountDeletion, error)
+	GetPendingDeletion(userID string) (*models.AccountDeletion, error)
+	CancelDeletion(userID string) (*models.AccountDeletion, error)
+	EraseDueAccounts(now time.Time) error
+	StartEraser(interval time.Duration) (stop func())
+}
+
+type InvitationService interface {
+	Invite(email string, role models.Role, inviterID, shelterID string) (*models.Invitation, error)
+	GetInvitations(scope tenant.Scope) ([]*models.Invitation, error)
+	RevokeInvitation(id string, scope tenant.Scope) (*models.Invitation, error)
+	AcceptInvitation(token, name, password, address, phone string) (*models.User, error)
 }
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+)
+
+type accountDeletionHandler struct {
+	service ports.AccountDeletionService
+}
+
+// NewAccountDeletionHandler creates a new account deletion handler
+func NewAccountDeletionHandler(service ports.AccountDeletionService) AccountDeletionHandler {
+	return &accountDeletionHandler{
+		service: service,
+	}
+}
+
+// DeleteMyAccount handles the current user asking for their account to be deleted. The account
+// is erased once the grace period is over, so the response is 202 with the scheduled deletion.
+func (h *accountDeletionHandler) DeleteMyAccount(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	return h.requestDeletion(c, userID, userID)
+}
+
+// GetMyAccountDeletion handles getting the pending deletion of the current user's account
+func (h *accountDeletionHandler) GetMyAccountDeletion(c *fiber.Ctx) error {
+	deletion, err := h.service.GetPendingDeletion(c.Locals("userID").(string))
+	if err != nil {
+		return accountDeletionErrorResponse(c, err)
+	}
+
+	return c.JSON(deletion)
+}
+
+// CancelMyAccountDeletion handles the current user cancelling the deletion of their account
+func (h *accountDeletionHandler) CancelMyAccountDeletion(c *fiber.Ctx) error {
+	deletion, err := h.service.CancelDeletion(c.Locals("userID").(string))
+	if err != nil {
+		return accountDeletionErrorResponse(c, err)
+	}
+
+	return h.recordCancellation(c, deletion)
+}
+
+// DeleteUser handles deleting any user, with the same grace period
+func (h *accountDeletionHandler) DeleteUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	return h.requestDeletion(c, id, c.Locals("userID").(string))
+}
+
+// CancelUserDeletion handles cancelling the deletion of any user
+func (h *accountDeletionHandler) CancelUserDeletion(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	deletion, err := h.service.CancelDeletion(id)
+	if err != nil {
+		return accountDeletionErrorResponse(c, err)
+	}
+
+	log.Printf("Deletion of user %s cancelled by %s", id, c.Locals("userID"))
+
+	return h.recordCancellation(c, deletion)
+}
+
+// requestDeletion schedules the deletion of a user on behalf of requestedBy
+func (h *accountDeletionHandler) requestDeletion(c *fiber.Ctx, userID, requestedBy string) error {
+	deletion, err := h.service.RequestDeletion(userID, requestedBy)
+	if err != nil {
+		return accountDeletionErrorResponse(c, err)
+	}
+
+	audit.Record(c, "account_deletion.request", "account_deletion", deletion.ID, nil, deletion)
+
+	return c.Status(fiber.StatusAccepted).JSON(deletion)
+}
+
+// recordCancellation records a cancelled deletion in the audit log and returns it
+func (h *accountDeletionHandler) recordCancellation(c *fiber.Ctx, deletion *models.AccountDeletion) error {
+	before := *deletion
+	before.CancelledAt = nil
+	before.Status = models.AccountDeletionPending
+
+	audit.Record(c, "account_deletion.cancel", "account_deletion", deletion.ID, &before, deletion)
+
+	return c.JSON(deletion)
+}
+
+// accountDeletionErrorResponse maps account deletion errors to HTTP responses
+func accountDeletionErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case err == models.ErrAccountDeletionPending || err == models.ErrAccountDeleted:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case err == models.ErrAccountDeletionNotFound || err.Error() == "user not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
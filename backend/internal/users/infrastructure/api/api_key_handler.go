@@ -0,0 +1,129 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+)
+
+type apiKeyHandler struct {
+	service ports.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(service ports.APIKeyService) APIKeyHandler {
+	return &apiKeyHandler{
+		service: service,
+	}
+}
+
+// CreateAPIKey handles creating an API key. The key is only in this response.
+func (h *apiKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	type createAPIKeyRequest struct {
+		Name         string              `json:"name"`
+		Permissions  []models.Permission `json:"permissions"`
+		ExpiresAt    *time.Time          `json:"expires_at"`    // RFC 3339, never expires when left out
+		ShelterID    string              `json:"shelter_id"`    // Platform admins only, shelter admins' keys work for their shelter
+		PlatformWide bool                `json:"platform_wide"` // Platform admins only, for keys working across shelters
+	}
+
+	var req createAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.ShelterID != "" && uuid.Validate(req.ShelterID) != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid shelter ID",
+		})
+	}
+
+	if req.PlatformWide && !auth.IsPlatformAdmin(c) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only platform admins can create platform-wide API keys",
+			"code":  "platform_admin_required",
+		})
+	}
+
+	creatorRole, _ := c.Locals("role").(models.Role)
+	shelterID := auth.RecordShelter(c, req.ShelterID)
+	key, rawKey, err := h.service.CreateAPIKey(req.Name, req.Permissions, req.ExpiresAt, c.Locals("userID").(string), creatorRole, shelterID, req.PlatformWide)
+	if err != nil {
+		return apiKeyErrorResponse(c, err)
+	}
+
+	// Keys reaching every shelter get their own action, so they stand out in the audit log
+	action := "api_key.create"
+	if key.PlatformWide {
+		action = "api_key.create_platform_wide"
+	}
+	audit.Record(c, action, "api_key", key.ID, nil, key)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"api_key": key,
+		"key":     rawKey,
+		"message": "Store the key now, it can't be shown again",
+	})
+}
+
+// GetAPIKeys handles listing the API keys of the shelters the requester can reach
+func (h *apiKeyHandler) GetAPIKeys(c *fiber.Ctx) error {
+	keys, err := h.service.GetAPIKeys(auth.ShelterScope(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(keys)
+}
+
+// RevokeAPIKey handles revoking an API key
+func (h *apiKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	id := c.Params("keyId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	key, err := h.service.RevokeAPIKey(id, auth.ShelterScope(c))
+	if err != nil {
+		return apiKeyErrorResponse(c, err)
+	}
+
+	audit.Record(c, "api_key.revoke", "api_key", key.ID, nil, map[string]any{"revoked_at": key.RevokedAt})
+
+	return c.JSON(key)
+}
+
+// apiKeyErrorResponse maps API key errors to HTTP responses
+func apiKeyErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case models.ErrInvalidAPIKeyName, models.ErrInvalidAPIKeyExpiry, models.ErrNoAPIKeyPermissions,
+		models.ErrInvalidPermission, models.ErrAPIKeyPermissionNotGrantable, models.ErrShelterNotFound,
+		models.ErrAPIKeyShelterRequired, models.ErrAPIKeyPlatformWideShelter:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrAPIKeyPermissionNotHeld:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrAPIKeyNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+)
+
+type dataExportHandler struct {
+	service ports.DataExportService
+}
+
+// NewDataExportHandler creates a new personal data export handler
+func NewDataExportHandler(service ports.DataExportService) DataExportHandler {
+	return &dataExportHandler{
+		service: service,
+	}
+}
+
+// ExportMyData handles exporting the personal data of the current user. The response is 200
+// with a download link once the archive is ready, and 202 while it is being generated.
+func (h *dataExportHandler) ExportMyData(c *fiber.Ctx) error {
+	export, err := h.service.RequestExport(c.Locals("userID").(string))
+	if err != nil {
+		return dataExportErrorResponse(c, err)
+	}
+
+	if export.Status != models.DataExportReady {
+		return c.Status(fiber.StatusAccepted).JSON(export)
+	}
+
+	return c.JSON(export)
+}
+
+// DownloadDataExport handles downloading the archive of a data export through its link
+func (h *dataExportHandler) DownloadDataExport(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token is required",
+		})
+	}
+
+	export, archive, err := h.service.GetArchive(token)
+	if err != nil {
+		return dataExportErrorResponse(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="petparadise-export-%s.zip"`, export.Created.Format("2006-01-02")))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	return c.Send(archive)
+}
+
+// dataExportErrorResponse maps data export errors to HTTP responses
+func dataExportErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case err == models.ErrInvalidDataExportURL || err == models.ErrDataExportNotReady:
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case err.Error() == "user not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -14,9 +14,66 @@ type UserHandler interface {
 	UpdateUserRole(c *fiber.Ctx) error
 	UpdateUserStatus(c *fiber.Ctx) error
 	ChangePassword(c *fiber.Ctx) error
-	DeleteUser(c *fiber.Ctx) error
+	UnlockUser(c *fiber.Ctx) error
 	Login(c *fiber.Ctx) error
 	Logout(c *fiber.Ctx) error
 	RefreshToken(c *fiber.Ctx) error
 	RevokeUserTokens(c *fiber.Ctx) error
+	VerifyEmail(c *fiber.Ctx) error
+	ResendVerificationEmail(c *fiber.Ctx) error
+	ForgotPassword(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+}
+
+type MFAHandler interface {
+	GetMFAStatus(c *fiber.Ctx) error
+	EnrollMFA(c *fiber.Ctx) error
+	ConfirmMFA(c *fiber.Ctx) error
+	DisableMFA(c *fiber.Ctx) error
+	RegenerateRecoveryCodes(c *fiber.Ctx) error
+	VerifyMFALogin(c *fiber.Ctx) error
+}
+
+type OIDCHandler interface {
+	GetOIDCProviders(c *fiber.Ctx) error
+	StartOIDCLogin(c *fiber.Ctx) error
+	OIDCCallback(c *fiber.Ctx) error
+}
+
+type PermissionHandler interface {
+	GetRolePermissions(c *fiber.Ctx) error
+	UpdateRolePermissions(c *fiber.Ctx) error
+}
+
+type APIKeyHandler interface {
+	CreateAPIKey(c *fiber.Ctx) error
+	GetAPIKeys(c *fiber.Ctx) error
+	RevokeAPIKey(c *fiber.Ctx) error
+}
+
+type SessionHandler interface {
+	GetMySessions(c *fiber.Ctx) error
+	EndMySession(c *fiber.Ctx) error
+	GetUserSessions(c *fiber.Ctx) error
+	EndUserSession(c *fiber.Ctx) error
+}
+
+type InvitationHandler interface {
+	CreateInvitation(c *fiber.Ctx) error
+	GetInvitations(c *fiber.Ctx) error
+	RevokeInvitation(c *fiber.Ctx) error
+	AcceptInvitation(c *fiber.Ctx) error
+}
+
+type DataExportHandler interface {
+	ExportMyData(c *fiber.Ctx) error
+	DownloadDataExport(c *fiber.Ctx) error
+}
+
+type AccountDeletionHandler interface {
+	DeleteMyAccount(c *fiber.Ctx) error
+	GetMyAccountDeletion(c *fiber.Ctx) error
+	CancelMyAccountDeletion(c *fiber.Ctx) error
+	DeleteUser(c *fiber.Ctx) error
+	CancelUserDeletion(c *fiber.Ctx) error
 }
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+)
+
+type invitationHandler struct {
+	service ports.InvitationService
+}
+
+// NewInvitationHandler creates a new staff invitation handler
+func NewInvitationHandler(service ports.InvitationService) InvitationHandler {
+	return &invitationHandler{
+		service: service,
+	}
+}
+
+// CreateInvitation handles inviting a staff member
+func (h *invitationHandler) CreateInvitation(c *fiber.Ctx) error {
+	type createInvitationRequest struct {
+		Email     string `json:"email"`
+		Role      string `json:"role"`
+		ShelterID string `json:"shelter_id"` // Platform admins only, shelter admins invite staff to their own shelter
+	}
+
+	var req createInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Email is required",
+		})
+	}
+
+	shelterID := auth.RecordShelter(c, req.ShelterID)
+	invitation, err := h.service.Invite(req.Email, models.Role(req.Role), c.Locals("userID").(string), shelterID)
+	if err != nil {
+		return invitationErrorResponse(c, err)
+	}
+
+	audit.Record(c, "invitation.create", "invitation", invitation.ID, nil, invitation)
+
+	return c.Status(fiber.StatusCreated).JSON(invitation)
+}
+
+// GetInvitations handles listing the invitations to the shelters the requester can reach, with
+// who sent them and the accounts they created
+func (h *invitationHandler) GetInvitations(c *fiber.Ctx) error {
+	invitations, err := h.service.GetInvitations(auth.ShelterScope(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(invitations)
+}
+
+// RevokeInvitation handles revoking an invitation that hasn't been accepted yet
+func (h *invitationHandler) RevokeInvitation(c *fiber.Ctx) error {
+	id := c.Params("invitationId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	invitation, err := h.service.RevokeInvitation(id, auth.ShelterScope(c))
+	if err != nil {
+		return invitationErrorResponse(c, err)
+	}
+
+	audit.Record(c, "invitation.revoke", "invitation", invitation.ID, nil, map[string]any{"revoked_at": invitation.RevokedAt})
+
+	return c.JSON(invitation)
+}
+
+// AcceptInvitation handles creating a staff account with an invitation token
+func (h *invitationHandler) AcceptInvitation(c *fiber.Ctx) error {
+	type acceptInvitationRequest struct {
+		Token    string `json:"token"`
+		Name     string `json:"name"`
+		Password string `json:"password"`
+		Address  string `json:"address"`
+		Phone    string `json:"phone"`
+	}
+
+	var req acceptInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token is required",
+		})
+	}
+
+	if req.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Name is required",
+		})
+	}
+
+	if req.Password == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Password is required",
+		})
+	}
+
+	user, err := h.service.AcceptInvitation(req.Token, req.Name, req.Password, req.Address, req.Phone)
+	if err != nil {
+		return invitationErrorResponse(c, err)
+	}
+
+	// Don't return the password, even though it's already marked as json:"-"
+	user.Password = ""
+
+	audit.Record(c, "invitation.accept", "user", user.ID, nil, user)
+
+	return c.Status(fiber.StatusCreated).JSON(user)
+}
+
+// invitationErrorResponse maps invitation errors to HTTP responses
+func invitationErrorResponse(c *fiber.Ctx, err error) error {
+	var policyErr *models.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		return passwordPolicyErrorResponse(c, policyErr)
+	}
+
+	switch err {
+	case models.ErrInvalidInvitation, models.ErrInvalidInvitationRole, models.ErrShelterNotFound:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrInvitationNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrInvitationNotPending:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err.Error() == "email already in use" {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+)
+
+type mfaHandler struct {
+	service     ports.MFAService
+	userService ports.UserService
+}
+
+// NewMFAHandler creates a new two-factor authentication handler
+func NewMFAHandler(service ports.MFAService, userService ports.UserService) MFAHandler {
+	return &mfaHandler{
+		service:     service,
+		userService: userService,
+	}
+}
+
+type mfaCodeRequest struct {
+	Code string `json:"code"` // A code from the authenticator app or a recovery code
+}
+
+// parseMFACode reads the code from the request body
+func parseMFACode(c *fiber.Ctx) (string, error) {
+	var req mfaCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return "", errors.New("Invalid request body")
+	}
+
+	if req.Code == "" {
+		return "", errors.New("Code is required")
+	}
+
+	return req.Code, nil
+}
+
+// GetMFAStatus handles getting the two-factor authentication status of the current user
+func (h *mfaHandler) GetMFAStatus(c *fiber.Ctx) error {
+	status, err := h.service.GetStatus(c.Locals("userID").(string))
+	if err != nil {
+		return mfaErrorResponse(c, err)
+	}
+
+	return c.JSON(status)
+}
+
+// EnrollMFA handles starting the two-factor authentication enrollment of the current user
+func (h *mfaHandler) EnrollMFA(c *fiber.Ctx) error {
+	enrollment, err := h.service.Enroll(c.Locals("userID").(string))
+	if err != nil {
+		return mfaErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(enrollment)
+}
+
+// ConfirmMFA handles enabling two-factor authentication with a first code from the authenticator app
+func (h *mfaHandler) ConfirmMFA(c *fiber.Ctx) error {
+	code, err := parseMFACode(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	userID := c.Locals("userID").(string)
+
+	recoveryCodes, err := h.service.Confirm(userID, code)
+	if err != nil {
+		return mfaErrorResponse(c, err)
+	}
+
+	audit.Record(c, "mfa.enable", "user", userID, map[string]any{"mfa_enabled": false}, map[string]any{"mfa_enabled": true})
+
+	return c.JSON(fiber.Map{
+		"message":        "Two-factor authentication enabled, store the recovery codes somewhere safe",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// DisableMFA handles turning two-factor authentication off for the current user
+func (h *mfaHandler) DisableMFA(c *fiber.Ctx) error {
+	code, err := parseMFACode(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	userID := c.Locals("userID").(string)
+
+	if err := h.service.Disable(userID, code); err != nil {
+		return mfaErrorResponse(c, err)
+	}
+
+	audit.Record(c, "mfa.disable", "user", userID, map[string]any{"mfa_enabled": true}, map[string]any{"mfa_enabled": false})
+
+	return c.JSON(fiber.Map{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes handles replacing the recovery codes of the current user
+func (h *mfaHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	code, err := parseMFACode(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	userID := c.Locals("userID").(string)
+
+	recoveryCodes, err := h.service.RegenerateRecoveryCodes(userID, code)
+	if err != nil {
+		return mfaErrorResponse(c, err)
+	}
+
+	// The codes themselves are never recorded
+	audit.Record(c, "mfa.regenerate_recovery_codes", "user", userID, nil, nil)
+
+	return c.JSON(fiber.Map{
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// VerifyMFALogin handles the second login step, exchanging an MFA challenge and a code for a token pair
+func (h *mfaHandler) VerifyMFALogin(c *fiber.Ctx) error {
+	type verifyMFALoginRequest struct {
+		MFAToken string `json:"mfa_token"`
+		Code     string `json:"code"`
+	}
+
+	var req verifyMFALoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.MFAToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "MFA token is required",
+		})
+	}
+
+	if req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Code is required",
+		})
+	}
+
+	claims, err := auth.ValidateMFAChallengeToken(req.MFAToken)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid or expired MFA token, please log in again",
+			"code":  "mfa_token_invalid",
+		})
+	}
+
+	amr, err := h.service.VerifyLogin(claims.UserID, req.Code, c.IP())
+	var throttledErr *models.LoginThrottledError
+	if errors.As(err, &throttledErr) {
+		return loginThrottledResponse(c, throttledErr)
+	}
+	if err == models.ErrTooManyMFAAttempts {
+		// The password has to be checked again before more codes can be tried
+		if err := auth.RevokeMFAChallenge(claims); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to revoke MFA token",
+			})
+		}
+	}
+	if err != nil {
+		return mfaErrorResponse(c, err)
+	}
+
+	// The challenge can only be exchanged once
+	if err := auth.RevokeMFAChallenge(claims); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke MFA token",
+		})
+	}
+
+	// The user may have been deactivated since passing the first step
+	user, err := h.userService.GetUserByID(claims.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if user == nil || !user.Status.IsEquals(models.StatusActive) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user account is not active",
+		})
+	}
+
+	tokenPair, err := auth.StartSession(user, auth.DeviceFromRequest(c), append(claims.AMR, amr...)...)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate authentication token",
+		})
+	}
+
+	// Don't return the password
+	user.Password = ""
+
+	return c.JSON(fiber.Map{
+		"user":   user,
+		"tokens": tokenPair,
+	})
+}
+
+// mfaErrorResponse maps two-factor authentication errors to HTTP responses
+func mfaErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case models.ErrInvalidMFACode, models.ErrTooManyMFAAttempts:
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrMFAAlreadyEnabled, models.ErrMFANotEnabled, models.ErrMFANotEnrolled:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrMFARequiredByRole:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err.Error() == "user not found" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+)
+
+// oidcStateCookie binds a login started at an identity provider to the browser that started it
+const oidcStateCookie = "oidc_state"
+
+type oidcHandler struct {
+	service    ports.IdentityService
+	mfaService ports.MFAService
+}
+
+// NewOIDCHandler creates a new OpenID Connect login handler
+func NewOIDCHandler(service ports.IdentityService, mfaService ports.MFAService) OIDCHandler {
+	return &oidcHandler{
+		service:    service,
+		mfaService: mfaService,
+	}
+}
+
+// GetOIDCProviders handles listing the identity providers users can log in with
+func (h *oidcHandler) GetOIDCProviders(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"providers": h.service.Providers(),
+	})
+}
+
+// StartOIDCLogin handles redirecting the user to the login page of an identity provider
+func (h *oidcHandler) StartOIDCLogin(c *fiber.Ctx) error {
+	authURL, state, err := h.service.StartLogin(c.UserContext(), c.Params("provider"))
+	if err != nil {
+		return oidcErrorResponse(c, err)
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/users/oidc",
+		Expires:  time.Now().Add(models.OIDCLoginExpiration),
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode, // Sent along with the provider's redirect back
+	})
+
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// OIDCCallback handles the redirect back from an identity provider, logging the user in
+func (h *oidcHandler) OIDCCallback(c *fiber.Ctx) error {
+	state := c.Query("state")
+	cookieState := c.Cookies(oidcStateCookie)
+	c.ClearCookie(oidcStateCookie)
+
+	// The user refused the login or the provider failed
+	if providerErr := c.Query("error"); providerErr != "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": models.ErrExternalLoginFailed.Error(),
+			"code":  providerErr,
+		})
+	}
+
+	if c.Query("code") == "" || state == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Code and state are required",
+		})
+	}
+
+	// A callback opened in another browser could log the victim into the attacker's account
+	if cookieState != state {
+		return oidcErrorResponse(c, models.ErrInvalidLoginState)
+	}
+
+	user, err := h.service.CompleteLogin(c.UserContext(), c.Params("provider"), state, c.Query("code"))
+	if err != nil {
+		return oidcErrorResponse(c, err)
+	}
+
+	// Users with two-factor authentication still need their second factor
+	mfaEnabled, err := h.mfaService.IsEnabled(user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if mfaEnabled {
+		mfaToken, err := auth.GenerateMFAChallengeToken(user, auth.AMRExternal)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to generate authentication token",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int64(auth.MFAChallengeExpiration.Seconds()),
+		})
+	}
+
+	tokenPair, err := auth.StartSession(user, auth.DeviceFromRequest(c), auth.AMRExternal)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate authentication token",
+		})
+	}
+
+	// Don't return the password
+	user.Password = ""
+
+	return c.JSON(fiber.Map{
+		"user":                    user,
+		"tokens":                  tokenPair,
+		"mfa_enrollment_required": auth.IsMFARequired(user.Role),
+	})
+}
+
+func oidcErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case models.ErrUnknownIdentityProvider:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrInvalidLoginState:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrIdentityProviderUnavailable:
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrExternalLoginFailed:
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrIdentityEmailNotVerified:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+			"code":  "email_unverified",
+		})
+	}
+
+	if err.Error() == "user account is not active" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+)
+
+type permissionHandler struct {
+	service ports.PermissionService
+}
+
+// NewPermissionHandler creates a new role permission handler
+func NewPermissionHandler(service ports.PermissionService) PermissionHandler {
+	return &permissionHandler{
+		service: service,
+	}
+}
+
+// GetRolePermissions handles listing the permissions of every role, along with every permission there is
+func (h *permissionHandler) GetRolePermissions(c *fiber.Ctx) error {
+	roles, err := h.service.GetRolePermissions()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"permissions": models.AllPermissions,
+		"roles":       roles,
+	})
+}
+
+// UpdateRolePermissions handles replacing the permissions of a role
+func (h *permissionHandler) UpdateRolePermissions(c *fiber.Ctx) error {
+	type updateRolePermissionsRequest struct {
+		Permissions []models.Permission `json:"permissions"`
+	}
+
+	var req updateRolePermissionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Kept for the audit log
+	before, err := h.service.GetRolePermissions()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	role := models.Role(c.Params("role"))
+	permissions, err := h.service.SetRolePermissions(role, req.Permissions, c.Locals("userID").(string))
+	if err != nil {
+		switch err {
+		case models.ErrInvalidRole, models.ErrInvalidPermission:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case models.ErrAdminRoleLockout:
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	audit.Record(c, "role.permissions_update", "role", role.String(),
+		map[string]any{"permissions": before[role]}, map[string]any{"permissions": permissions})
+
+	return c.JSON(fiber.Map{
+		"role":        role,
+		"permissions": permissions,
+	})
+}
@@ -3,27 +3,145 @@ package api
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/jmoiron/sqlx"
+	adoptionAplication "github.com/solrac97gr/petparadise/internal/adoptions/aplication"
+	adoptionPets "github.com/solrac97gr/petparadise/internal/adoptions/infrastructure/pets"
+	adoptionRepository "github.com/solrac97gr/petparadise/internal/adoptions/infrastructure/repository"
+	donationAplication "github.com/solrac97gr/petparadise/internal/donations/aplication"
+	donationPets "github.com/solrac97gr/petparadise/internal/donations/infrastructure/pets"
+	donationRepository "github.com/solrac97gr/petparadise/internal/donations/infrastructure/repository"
+	petAplication "github.com/solrac97gr/petparadise/internal/pets/aplication"
+	petRepository "github.com/solrac97gr/petparadise/internal/pets/infrastructure/repository"
+	petVets "github.com/solrac97gr/petparadise/internal/pets/infrastructure/vets"
 	"github.com/solrac97gr/petparadise/internal/users/aplication"
 	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/internal/users/infrastructure/personaldata"
 	"github.com/solrac97gr/petparadise/internal/users/infrastructure/repository"
+	volunteerAplication "github.com/solrac97gr/petparadise/internal/volunteers/aplication"
+	volunteerRepository "github.com/solrac97gr/petparadise/internal/volunteers/infrastructure/repository"
+	"github.com/solrac97gr/petparadise/pkg/audit"
 	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/config"
+	"github.com/solrac97gr/petparadise/pkg/mailer"
+	"github.com/solrac97gr/petparadise/pkg/oidc"
 )
 
-// SetupUserRoutes sets up all user routes
-func SetupUserRoutes(router fiber.Router, db *sqlx.DB) {
-	// Initialize repository
+// SetupUserRoutes sets up all user routes and returns the account deletion service, whose eraser
+// is started by the app
+func SetupUserRoutes(router fiber.Router, db *sqlx.DB, mail mailer.Mailer, cfg *config.Config) ports.AccountDeletionService {
+	// Personal details are only recorded as changed in the audit log
+	audit.SetPersonalFields("user", "name", "email", "address", "phone", "documents")
+	audit.SetPersonalFields("invitation", "email")
+
+	// Initialize repositories
 	userRepo := repository.NewPostgresRepository(db)
+	verificationRepo := repository.NewPostgresVerificationRepository(db)
+	passwordResetRepo := repository.NewPostgresPasswordResetRepository(db)
+	passwordHistoryRepo := repository.NewPostgresPasswordHistoryRepository(db)
+	loginThrottleRepo := repository.NewPostgresLoginThrottleRepository(db)
+
+	mfaRepo := repository.NewPostgresMFARepository(db)
+	identityRepo := repository.NewPostgresIdentityRepository(db)
+	invitationRepo := repository.NewPostgresInvitationRepository(db)
+	dataExportRepo := repository.NewPostgresDataExportRepository(db)
+	accountDeletionRepo := repository.NewPostgresAccountDeletionRepository(db)
+
+	// Initialize the OpenID Connect providers users can log in with
+	var providers []*oidc.Provider
+	for _, provider := range cfg.OIDCProviders {
+		providers = append(providers, oidc.NewProvider(oidc.Config{
+			Name:         provider.Name,
+			Issuer:       provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  provider.RedirectURL,
+		}, nil))
+	}
+
+	// Initialize the other modules keeping personal data about users, which export it and erase
+	// it when an account is deleted
+	ledgerRepo := donationRepository.NewPostgresLedgerRepository(db)
+	donationRepo := donationRepository.NewPostgresRepository(db)
+	petRepo := petRepository.NewPostgresRepository(db)
+	adoptionsSource := personaldata.NewAdoptionsSource(adoptionAplication.NewAdoptionService(
+		adoptionRepository.NewPostgresRepository(db),
+		adoptionPets.NewPetsDirectory(petRepo),
+	))
+	donationsSource := personaldata.NewDonationsSource(
+		donationAplication.NewDonationService(donationRepo, donationPets.NewPetsDirectory(petRepo), mail),
+		donationAplication.NewRefundService(donationRepository.NewPostgresRefundRepository(db), ledgerRepo, donationRepo),
+		donationAplication.NewInKindService(donationRepository.NewPostgresInKindRepository(db), donationRepository.NewPostgresSupplyRepository(db), mail),
+		donationAplication.NewDonorService(donationRepository.NewPostgresDonorRepository(db)),
+	)
+	volunteersSource := personaldata.NewVolunteersSource(volunteerAplication.NewShiftService(
+		volunteerRepository.NewPostgresShiftRepository(db),
+		volunteerRepository.NewPostgresSignUpRepository(db),
+	))
+	appointmentsSource := personaldata.NewAppointmentsSource(petAplication.NewAppointmentService(
+		petRepository.NewPostgresAppointmentRepository(db),
+		petRepo,
+		petVets.NewUsersDirectory(userRepo),
+	))
+	personalDataModules := []ports.PersonalDataModule{adoptionsSource, donationsSource, volunteersSource, appointmentsSource}
+
+	passwordPolicy := models.PasswordPolicy{
+		MinLength:        cfg.PasswordPolicy.MinLength,
+		RequireUppercase: cfg.PasswordPolicy.RequireUppercase,
+		RequireLowercase: cfg.PasswordPolicy.RequireLowercase,
+		RequireDigit:     cfg.PasswordPolicy.RequireDigit,
+		RequireSymbol:    cfg.PasswordPolicy.RequireSymbol,
+		CheckCommon:      cfg.PasswordPolicy.CheckCommon,
+		HistorySize:      cfg.PasswordPolicy.HistorySize,
+	}
 
-	// Initialize service
-	userService := aplication.NewUserService(userRepo)
+	// Initialize services
+	userService := aplication.NewUserService(userRepo, verificationRepo, passwordResetRepo, passwordHistoryRepo, loginThrottleRepo, mail, passwordPolicy,
+		cfg.AppBaseURL, cfg.PasswordResetURL)
+	mfaService := aplication.NewMFAService(mfaRepo, userRepo, userService, cfg.MFAIssuer)
+	identityService := aplication.NewIdentityService(identityRepo, userRepo, providers)
+	permissionService := aplication.NewPermissionService()
+	apiKeyService := aplication.NewAPIKeyService()
+	sessionService := aplication.NewSessionService(userRepo)
+	invitationService := aplication.NewInvitationService(invitationRepo, userRepo, mail, passwordPolicy, cfg.InvitationURL)
+	dataExportService := aplication.NewDataExportService(dataExportRepo, userRepo, identityRepo, invitationRepo, personalDataModules, mail,
+		cfg.AppBaseURL)
+	accountDeletionService := aplication.NewAccountDeletionService(accountDeletionRepo, userRepo, personalDataModules, mail)
 
-	// Initialize handler
-	userHandler := NewUserHandler(userService)
+	// Initialize handlers
+	userHandler := NewUserHandler(userService, mfaService)
+	mfaHandler := NewMFAHandler(mfaService, userService)
+	oidcHandler := NewOIDCHandler(identityService, mfaService)
+	permissionHandler := NewPermissionHandler(permissionService)
+	apiKeyHandler := NewAPIKeyHandler(apiKeyService)
+	sessionHandler := NewSessionHandler(sessionService)
+	invitationHandler := NewInvitationHandler(invitationService)
+	dataExportHandler := NewDataExportHandler(dataExportService)
+	accountDeletionHandler := NewAccountDeletionHandler(accountDeletionService)
 
 	// Public routes
-	router.Post("/register", userHandler.CreateUser)  // Registration endpoint
-	router.Post("/login", userHandler.Login)          // Login endpoint
-	router.Post("/refresh", userHandler.RefreshToken) // Token refresh endpoint
+	router.Post("/register", userHandler.CreateUser)     // Registration endpoint, for the user role only
+	router.Post("/login", userHandler.Login)             // Login endpoint
+	router.Post("/login/mfa", mfaHandler.VerifyMFALogin) // Second login step for users with 2FA
+	router.Post("/refresh", userHandler.RefreshToken)    // Token refresh endpoint
+
+	// Email verification (public, pending users can't log in yet)
+	router.Get("/verify", userHandler.VerifyEmail)
+	router.Post("/verify/resend", userHandler.ResendVerificationEmail)
+
+	// Password reset (public)
+	router.Post("/password/forgot", userHandler.ForgotPassword)
+	router.Post("/password/reset", userHandler.ResetPassword)
+
+	// Staff members create their account with the invitation they received (public)
+	router.Post("/invitations/accept", invitationHandler.AcceptInvitation)
+
+	// Personal data export downloads, the link is emailed to users (public)
+	router.Get("/exports/download", dataExportHandler.DownloadDataExport)
+
+	// Login through external identity providers (public)
+	router.Get("/oidc/providers", oidcHandler.GetOIDCProviders)
+	router.Get("/oidc/:provider/login", oidcHandler.StartOIDCLogin)
+	router.Get("/oidc/:provider/callback", oidcHandler.OIDCCallback)
 
 	// Protected routes
 	protectedRoutes := router.Use(auth.Protected())
@@ -32,24 +150,69 @@ func SetupUserRoutes(router fiber.Router, db *sqlx.DB) {
 	// Logout route (protected)
 	protectedRoutes.Post("/logout", userHandler.Logout)
 
-	// User management routes (protected)
-	protectedRoutes.Get("/", userHandler.GetAllUsers)
+	// Revoke all tokens for a user (users with the users:manage permission, or users for their own account)
+	protectedRoutes.Post("/:id/revoke-tokens", userHandler.RevokeUserTokens)
+
+	// Two-factor authentication of the current user
+	protectedRoutes.Get("/me/mfa", mfaHandler.GetMFAStatus)
+	protectedRoutes.Post("/me/mfa/enroll", mfaHandler.EnrollMFA)
+	protectedRoutes.Post("/me/mfa/confirm", mfaHandler.ConfirmMFA)
+	protectedRoutes.Post("/me/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+	protectedRoutes.Delete("/me/mfa", mfaHandler.DisableMFA)
+
+	// Login sessions of the current user
+	protectedRoutes.Get("/me/sessions", sessionHandler.GetMySessions)
+	protectedRoutes.Delete("/me/sessions/:sessionId", sessionHandler.EndMySession)
+
+	// Personal data export of the current user
+	protectedRoutes.Get("/me/export", dataExportHandler.ExportMyData)
+
+	// Deletion of the current user's account, which can be cancelled during the grace period
+	protectedRoutes.Delete("/me", accountDeletionHandler.DeleteMyAccount)
+	protectedRoutes.Get("/me/deletion", accountDeletionHandler.GetMyAccountDeletion)
+	protectedRoutes.Delete("/me/deletion", accountDeletionHandler.CancelMyAccountDeletion)
+
+	// Role permissions, edited at runtime. They apply to every shelter, so only platform admins edit them.
+	protectedRoutes.Get("/roles", auth.RequirePermission(models.PermissionRolesManage), permissionHandler.GetRolePermissions)
+	protectedRoutes.Put("/roles/:role/permissions", auth.RequirePermission(models.PermissionRolesManage), auth.RequirePlatformAdmin(), auth.MFARequired(), permissionHandler.UpdateRolePermissions)
+
+	// Staff invitations
+	protectedRoutes.Get("/invitations", auth.RequirePermission(models.PermissionUsersManage), invitationHandler.GetInvitations)
+	protectedRoutes.Post("/invitations", auth.RequirePermission(models.PermissionUsersManage), auth.MFARequired(), invitationHandler.CreateInvitation)
+	protectedRoutes.Delete("/invitations/:invitationId", auth.RequirePermission(models.PermissionUsersManage), invitationHandler.RevokeInvitation)
+
+	// API keys of machine clients
+	canManageAPIKeys := auth.RequirePermission(models.PermissionAPIKeysManage)
+	protectedRoutes.Get("/api-keys", canManageAPIKeys, apiKeyHandler.GetAPIKeys)
+	protectedRoutes.Post("/api-keys", canManageAPIKeys, auth.MFARequired(), apiKeyHandler.CreateAPIKey)
+	protectedRoutes.Delete("/api-keys/:keyId", canManageAPIKeys, auth.MFARequired(), apiKeyHandler.RevokeAPIKey)
+
+	// User management routes (protected). Users can always see and update their own account,
+	// the handlers check the permissions needed for other users'.
+	canReadUsers := auth.RequirePermission(models.PermissionUsersRead)
+	protectedRoutes.Get("/", canReadUsers, userHandler.GetAllUsers)
 	// Specific routes MUST come before parameterized routes
-	protectedRoutes.Get("/email", userHandler.GetUserByEmail)
-	protectedRoutes.Get("/status", userHandler.GetUsersByStatus)
+	protectedRoutes.Get("/email", canReadUsers, userHandler.GetUserByEmail)
+	protectedRoutes.Get("/status", canReadUsers, userHandler.GetUsersByStatus)
 	// Parameterized routes come after specific routes
 	protectedRoutes.Get("/:id", userHandler.GetUserByID)
 	protectedRoutes.Put("/:id", userHandler.UpdateUser)
 
-	// Admin only routes
-	adminRoutes := protectedRoutes.Use(auth.RoleRequired(models.RoleAdmin))
-	adminRoutes.Patch("/:id/role", userHandler.UpdateUserRole)
-	adminRoutes.Patch("/:id/status", userHandler.UpdateUserStatus)
-	adminRoutes.Delete("/:id", userHandler.DeleteUser)
+	// User administration routes, sensitive ones need a second factor where the role requires it,
+	// and staff only administer the users of their own shelter
+	canManageUsers := auth.RequirePermission(models.PermissionUsersManage)
+	inScope := requireUserInScope(userService)
+	protectedRoutes.Patch("/:id/role", canManageUsers, inScope, auth.MFARequired(), userHandler.UpdateUserRole)
+	protectedRoutes.Patch("/:id/status", canManageUsers, inScope, auth.MFARequired(), userHandler.UpdateUserStatus)
+	canDeleteUsers := auth.RequirePermission(models.PermissionUsersDelete)
+	protectedRoutes.Delete("/:id", canDeleteUsers, inScope, auth.MFARequired(), accountDeletionHandler.DeleteUser)
+	protectedRoutes.Delete("/:id/deletion", canDeleteUsers, inScope, accountDeletionHandler.CancelUserDeletion)
+	protectedRoutes.Post("/:id/unlock", canManageUsers, inScope, userHandler.UnlockUser)
+	protectedRoutes.Get("/:id/sessions", canManageUsers, inScope, sessionHandler.GetUserSessions)
+	protectedRoutes.Delete("/:id/sessions/:sessionId", canManageUsers, inScope, sessionHandler.EndUserSession)
 
-	// User password management (protected)
+	// User password management (users with the users:manage permission, or users for their own account)
 	protectedRoutes.Post("/:id/password", userHandler.ChangePassword)
 
-	// Revoke all tokens for a user (protected)
-	protectedRoutes.Post("/:id/revoke-tokens", userHandler.RevokeUserTokens)
+	return accountDeletionService
 }
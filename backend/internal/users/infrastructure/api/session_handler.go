@@ -0,0 +1,101 @@
+package api
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+)
+
+type sessionHandler struct {
+	service ports.SessionService
+}
+
+// NewSessionHandler creates a new login session handler
+func NewSessionHandler(service ports.SessionService) SessionHandler {
+	return &sessionHandler{
+		service: service,
+	}
+}
+
+// GetMySessions handles listing the active sessions of the current user
+func (h *sessionHandler) GetMySessions(c *fiber.Ctx) error {
+	currentSessionID, _ := c.Locals("sessionID").(string)
+
+	sessions, err := h.service.GetSessions(c.Locals("userID").(string), currentSessionID)
+	if err != nil {
+		return sessionErrorResponse(c, err)
+	}
+
+	return c.JSON(sessions)
+}
+
+// EndMySession handles ending a session of the current user, such as a lost device
+func (h *sessionHandler) EndMySession(c *fiber.Ctx) error {
+	return h.endSession(c, c.Locals("userID").(string))
+}
+
+// GetUserSessions handles listing the active sessions of any user
+func (h *sessionHandler) GetUserSessions(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	sessions, err := h.service.GetSessions(id, "")
+	if err != nil {
+		return sessionErrorResponse(c, err)
+	}
+
+	return c.JSON(sessions)
+}
+
+// EndUserSession handles ending a session of any user
+func (h *sessionHandler) EndUserSession(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	return h.endSession(c, id)
+}
+
+// endSession ends the session in the URL of the given user
+func (h *sessionHandler) endSession(c *fiber.Ctx, userID string) error {
+	sessionID := c.Params("sessionId")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Session ID is required",
+		})
+	}
+
+	if err := h.service.EndSession(userID, sessionID); err != nil {
+		return sessionErrorResponse(c, err)
+	}
+
+	log.Printf("Session %s of user %s ended by %s", sessionID, userID, c.Locals("userID"))
+	audit.Record(c, "session.end", "session", sessionID, map[string]any{"user_id": userID}, nil)
+
+	return c.JSON(fiber.Map{
+		"message": "Session ended successfully",
+	})
+}
+
+// sessionErrorResponse maps session errors to HTTP responses
+func sessionErrorResponse(c *fiber.Ctx, err error) error {
+	if err == models.ErrSessionNotFound || err.Error() == "user not found" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
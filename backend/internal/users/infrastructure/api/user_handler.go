@@ -1,22 +1,27 @@
 package api
 
 import (
+	"errors"
 	"log"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/solrac97gr/petparadise/internal/users/domain/models"
 	"github.com/solrac97gr/petparadise/internal/users/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
 	"github.com/solrac97gr/petparadise/pkg/auth"
 )
 
 type userHandler struct {
-	service ports.UserService
+	service    ports.UserService
+	mfaService ports.MFAService
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(service ports.UserService) UserHandler {
+func NewUserHandler(service ports.UserService, mfaService ports.MFAService) UserHandler {
 	return &userHandler{
-		service: service,
+		service:    service,
+		mfaService: mfaService,
 	}
 }
 
@@ -57,19 +62,20 @@ func (h *userHandler) CreateUser(c *fiber.Ctx) error {
 		})
 	}
 
-	if req.Role == "" {
-		req.Role = models.RoleUser.String() // Default role
-	}
-
-	role := models.Role(req.Role)
-	if !role.IsValid() {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid role",
+	// Staff accounts are created through invitations, never by choosing a role at registration
+	if req.Role != "" && !models.Role(req.Role).IsEquals(models.RoleUser) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": models.ErrRegistrationRole.Error(),
 		})
 	}
 
-	user, err := h.service.CreateUser(req.Name, req.Email, req.Password, role, req.Address, req.Phone, req.Documents)
+	user, err := h.service.CreateUser(req.Name, req.Email, req.Password, models.RoleUser, req.Address, req.Phone, req.Documents)
 	if err != nil {
+		var policyErr *models.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyErrorResponse(c, policyErr)
+		}
+
 		if err.Error() == "email already in use" {
 			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 				"error": err.Error(),
@@ -84,6 +90,8 @@ func (h *userHandler) CreateUser(c *fiber.Ctx) error {
 	// Don't return the password, even though it's already marked as json:"-"
 	user.Password = ""
 
+	audit.Record(c, "user.create", "user", user.ID, nil, user)
+
 	return c.Status(fiber.StatusCreated).JSON(user)
 }
 
@@ -109,6 +117,20 @@ func (h *userHandler) GetUserByID(c *fiber.Ctx) error {
 		})
 	}
 
+	allowed, err := canReadUser(c, h.service, user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check permissions",
+		})
+	}
+
+	// Users the requester can't see are answered as if they didn't exist
+	if !allowed {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
 	// Don't return the password
 	user.Password = ""
 
@@ -137,6 +159,19 @@ func (h *userHandler) GetUserByEmail(c *fiber.Ctx) error {
 		})
 	}
 
+	inScope, err := isInShelterScope(c, h.service, user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if !inScope {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
 	// Don't return the password
 	user.Password = ""
 
@@ -159,7 +194,7 @@ func (h *userHandler) GetUsersByStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	users, err := h.service.GetUsersByStatus(status)
+	users, err := h.service.GetUsersByStatus(status, auth.ShelterScope(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -174,9 +209,9 @@ func (h *userHandler) GetUsersByStatus(c *fiber.Ctx) error {
 	return c.JSON(users)
 }
 
-// GetAllUsers handles getting all users
+// GetAllUsers handles getting all users. Staff only see the staff of their own shelter.
 func (h *userHandler) GetAllUsers(c *fiber.Ctx) error {
-	users, err := h.service.GetAllUsers()
+	users, err := h.service.GetAllUsers(auth.ShelterScope(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -215,6 +250,27 @@ func (h *userHandler) UpdateUser(c *fiber.Ctx) error {
 		})
 	}
 
+	allowed, err := canManageUser(c, h.service, id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check permissions",
+		})
+	}
+
+	if !allowed {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Insufficient permissions to update this user",
+		})
+	}
+
+	// Kept for the audit log
+	before, err := h.service.GetUserByID(id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	user, err := h.service.UpdateUser(id, req.Name, req.Email, req.Address, req.Phone, req.Documents)
 	if err != nil {
 		if err.Error() == "user not found" {
@@ -223,7 +279,7 @@ func (h *userHandler) UpdateUser(c *fiber.Ctx) error {
 			})
 		}
 
-		if err.Error() == "email already in use" {
+		if err.Error() == "email already in use" || err == models.ErrAccountDeleted {
 			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 				"error": err.Error(),
 			})
@@ -237,6 +293,8 @@ func (h *userHandler) UpdateUser(c *fiber.Ctx) error {
 	// Don't return the password
 	user.Password = ""
 
+	audit.Record(c, "user.update", "user", user.ID, before, user)
+
 	return c.JSON(user)
 }
 
@@ -250,7 +308,8 @@ func (h *userHandler) UpdateUserRole(c *fiber.Ctx) error {
 	}
 
 	type updateRoleRequest struct {
-		Role string `json:"role"`
+		Role      string `json:"role"`
+		ShelterID string `json:"shelter_id"` // Platform admins only, shelter admins give roles at their own shelter
 	}
 
 	var req updateRoleRequest
@@ -273,14 +332,40 @@ func (h *userHandler) UpdateUserRole(c *fiber.Ctx) error {
 		})
 	}
 
-	user, err := h.service.UpdateUserRole(id, role)
+	// Kept for the audit log
+	before, err := h.service.GetUserByID(id)
 	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Platform admins keep the user at their current shelter unless they pick another one
+	shelterID := auth.RecordShelter(c, req.ShelterID)
+	if auth.IsPlatformAdmin(c) && req.ShelterID == "" && before != nil {
+		shelterID = before.ShelterID
+	}
+
+	user, err := h.service.UpdateUserRole(id, role, shelterID)
+	if err != nil {
+		if err == models.ErrShelterNotFound {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
 		if err.Error() == "user not found" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": err.Error(),
 			})
 		}
 
+		if err == models.ErrAccountDeleted {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -289,6 +374,15 @@ func (h *userHandler) UpdateUserRole(c *fiber.Ctx) error {
 	// Don't return the password
 	user.Password = ""
 
+	audit.Record(c, "user.role_update", "user", user.ID, before, user)
+
+	// Tokens carry the role and shelter they were issued with, so the user must log in again
+	if err := auth.RevokeAllUserTokens(user.ID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke tokens",
+		})
+	}
+
 	return c.JSON(user)
 }
 
@@ -325,6 +419,14 @@ func (h *userHandler) UpdateUserStatus(c *fiber.Ctx) error {
 		})
 	}
 
+	// Kept for the audit log
+	before, err := h.service.GetUserByID(id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	user, err := h.service.UpdateUserStatus(id, status)
 	if err != nil {
 		if err.Error() == "user not found" {
@@ -333,6 +435,12 @@ func (h *userHandler) UpdateUserStatus(c *fiber.Ctx) error {
 			})
 		}
 
+		if err == models.ErrAccountDeleted {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -341,6 +449,17 @@ func (h *userHandler) UpdateUserStatus(c *fiber.Ctx) error {
 	// Don't return the password
 	user.Password = ""
 
+	audit.Record(c, "user.status_update", "user", user.ID, before, user)
+
+	// A user who is no longer active is logged out everywhere
+	if !status.IsEquals(models.StatusActive) {
+		if err := auth.RevokeAllUserTokens(user.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to revoke tokens",
+			})
+		}
+	}
+
 	return c.JSON(user)
 }
 
@@ -377,8 +496,26 @@ func (h *userHandler) ChangePassword(c *fiber.Ctx) error {
 		})
 	}
 
-	err := h.service.ChangePassword(id, req.OldPassword, req.NewPassword)
+	allowed, err := canManageUser(c, h.service, id)
 	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check permissions",
+		})
+	}
+
+	if !allowed {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Insufficient permissions to change the password of this user",
+		})
+	}
+
+	err = h.service.ChangePassword(id, req.OldPassword, req.NewPassword)
+	if err != nil {
+		var policyErr *models.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyErrorResponse(c, policyErr)
+		}
+
 		if err.Error() == "user not found" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": err.Error(),
@@ -396,13 +533,15 @@ func (h *userHandler) ChangePassword(c *fiber.Ctx) error {
 		})
 	}
 
+	audit.Record(c, "user.password_change", "user", id, nil, nil)
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Password changed successfully",
 	})
 }
 
-// DeleteUser handles deleting a user
-func (h *userHandler) DeleteUser(c *fiber.Ctx) error {
+// UnlockUser handles lifting the login lockout of a user's account
+func (h *userHandler) UnlockUser(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -410,14 +549,24 @@ func (h *userHandler) DeleteUser(c *fiber.Ctx) error {
 		})
 	}
 
-	err := h.service.DeleteUser(id)
+	err := h.service.UnlockUser(id)
 	if err != nil {
+		if err.Error() == "user not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	return c.Status(fiber.StatusNoContent).Send(nil)
+	audit.Record(c, "user.unlock", "user", id, nil, nil)
+
+	return c.JSON(fiber.Map{
+		"message": "User account unlocked successfully",
+	})
 }
 
 // Login handles user authentication
@@ -446,15 +595,56 @@ func (h *userHandler) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	user, err := h.service.Authenticate(req.Email, req.Password)
+	user, err := h.service.Authenticate(req.Email, req.Password, c.IP())
 	if err != nil {
+		var throttledErr *models.LoginThrottledError
+		if errors.As(err, &throttledErr) {
+			return loginThrottledResponse(c, throttledErr)
+		}
+
+		if err.Error() == "email address not verified" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+				"code":  "email_unverified",
+			})
+		}
+
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	// Users with two-factor authentication get a challenge to exchange for tokens with their second factor
+	mfaEnabled, err := h.mfaService.IsEnabled(user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if mfaEnabled {
+		mfaToken, err := auth.GenerateMFAChallengeToken(user, auth.AMRPassword)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to generate authentication token",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int64(auth.MFAChallengeExpiration.Seconds()),
+		})
+	}
+
+	if err := h.service.ForgetLoginFailures(user.Email); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Generate JWT token
-	tokenPair, err := auth.GenerateTokenPair(user)
+	tokenPair, err := auth.StartSession(user, auth.DeviceFromRequest(c), auth.AMRPassword)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate authentication token",
@@ -467,6 +657,8 @@ func (h *userHandler) Login(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"user":   user,
 		"tokens": tokenPair,
+		// Users whose role requires two-factor authentication must enroll before sensitive actions
+		"mfa_enrollment_required": auth.IsMFARequired(user.Role),
 	})
 }
 
@@ -480,6 +672,21 @@ func (h *userHandler) Logout(c *fiber.Ctx) error {
 		})
 	}
 
+	accessClaims, err := auth.ValidateAccessToken(accessToken)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid token",
+			"code":  "token_invalid",
+		})
+	}
+
+	// Revoke the access token until it expires
+	if err := auth.RevokeAccessToken(accessClaims); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke access token",
+		})
+	}
+
 	// Get refresh token from request body (optional)
 	type logoutRequest struct {
 		RefreshToken string `json:"refresh_token"`
@@ -487,22 +694,32 @@ func (h *userHandler) Logout(c *fiber.Ctx) error {
 
 	var req logoutRequest
 	if err := c.BodyParser(&req); err == nil && req.RefreshToken != "" {
-		// If refresh token is provided, revoke it
-		// Validate the token first to get its expiration
-		refreshClaims, err := auth.ValidateRefreshToken(req.RefreshToken)
-		if err == nil && refreshClaims != nil {
-			// Revoke the refresh token
+		// If refresh token is provided, revoke it along with the tokens rotated from it
+		err := auth.RevokeRefreshToken(req.RefreshToken, accessClaims.UserID)
+		if err == auth.ErrInvalidToken {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid refresh token",
+			})
+		} else if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to revoke refresh token",
+			})
 		}
 	}
 
-	// Get access token expiration from claims
-	accessClaims, err := auth.ValidateAccessToken(accessToken)
-	if err == nil && accessClaims != nil {
-
-		// Log the user ID that's being logged out
-		log.Printf("User logged out: %s", accessClaims.UserID)
+	// End the session of the access token, so its refresh tokens can't be used either
+	if accessClaims.SessionID != "" {
+		err := auth.EndSession(accessClaims.UserID, accessClaims.SessionID)
+		if err != nil && err != models.ErrSessionNotFound {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to end session",
+			})
+		}
 	}
 
+	// Log the user ID that's being logged out
+	log.Printf("User logged out: %s", accessClaims.UserID)
+
 	return c.JSON(fiber.Map{
 		"message": "Logged out successfully",
 	})
@@ -527,44 +744,37 @@ func (h *userHandler) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate the refresh token first
-	refreshClaims, err := auth.ValidateRefreshToken(req.RefreshToken)
+	// Rotate the refresh token, reloading the user's current role and status
+	tokenPair, err := auth.RefreshSession(req.RefreshToken, auth.DeviceFromRequest(c), h.service.GetUserByID)
 	if err != nil {
-		status := fiber.StatusUnauthorized
-		errorMsg := "Invalid refresh token"
-
-		if err == auth.ErrExpiredToken {
-			errorMsg = "Refresh token has expired"
+		switch err {
+		case auth.ErrExpiredToken:
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Refresh token has expired",
+				"code":  "refresh_token_invalid",
+			})
+		case auth.ErrTokenReused:
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Refresh token has already been used, please log in again",
+				"code":  "refresh_token_reused",
+			})
+		case auth.ErrUserNotFound:
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "User not found or account deleted",
+				"code":  "user_invalid",
+			})
+		case auth.ErrInactiveUser:
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "User account is inactive",
+				"code":  "user_inactive",
+			})
+		case auth.ErrInvalidToken, auth.ErrRevokedToken:
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid refresh token",
+				"code":  "refresh_token_invalid",
+			})
 		}
-		return c.Status(status).JSON(fiber.Map{
-			"error": errorMsg,
-			"code":  "refresh_token_invalid",
-		})
-	}
-
-	// Get the user from the database to ensure they still exist and are active
-	user, err := h.service.GetUserByID(refreshClaims.UserID)
-	if err != nil || user == nil {
-
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "User not found or account deleted",
-			"code":  "user_invalid",
-		})
-	}
 
-	// Check if user is active
-	if !user.Status.IsEquals(models.StatusActive) {
-		// Revoke the token if the user is inactive
-
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "User account is inactive",
-			"code":  "user_inactive",
-		})
-	}
-
-	// Generate new token pair
-	tokenPair, err := auth.GenerateTokenPair(user)
-	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate new tokens",
 		})
@@ -587,13 +797,15 @@ func (h *userHandler) RevokeUserTokens(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if the requesting user has permission (is admin or the same user)
-	requestingUserID := c.Locals("userID").(string)
-	requestingUserRole, _ := c.Locals("role").(models.Role)
-	isAdmin := requestingUserRole.IsEquals(models.RoleAdmin)
-	isSameUser := requestingUserID == id
+	// Check if the requesting user has permission (can manage users or is the same user)
+	allowed, err := canManageUser(c, h.service, id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check permissions",
+		})
+	}
 
-	if !isAdmin && !isSameUser {
+	if !allowed {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 			"error": "Insufficient permissions to revoke tokens for this user",
 		})
@@ -607,12 +819,284 @@ func (h *userHandler) RevokeUserTokens(c *fiber.Ctx) error {
 		})
 	}
 
-	// Revoke all tokens for this user
+	// Revoke all tokens issued to this user until now
+	if err := auth.RevokeAllUserTokens(id); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke tokens",
+		})
+	}
 
 	// Log the action
-	log.Printf("All tokens revoked for user %s by %s", id, requestingUserID)
+	log.Printf("All tokens revoked for user %s by %s", id, c.Locals("userID"))
+	audit.Record(c, "user.revoke_tokens", "user", id, nil, nil)
 
 	return c.JSON(fiber.Map{
 		"message": "All tokens revoked successfully",
 	})
 }
+
+// VerifyEmail handles activating an account from the link sent by email
+func (h *userHandler) VerifyEmail(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token query parameter is required",
+		})
+	}
+
+	user, err := h.service.VerifyEmail(token)
+	if err != nil {
+		switch err.Error() {
+		case "invalid verification token", "verification token has expired":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case "user account is not active":
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Don't return the password
+	user.Password = ""
+
+	audit.Record(c, "user.verify_email", "user", user.ID, map[string]any{"status": models.StatusPending}, map[string]any{"status": user.Status})
+
+	return c.JSON(fiber.Map{
+		"message": "Email verified successfully",
+		"user":    user,
+	})
+}
+
+// ResendVerificationEmail handles sending a new verification link to a pending account
+func (h *userHandler) ResendVerificationEmail(c *fiber.Ctx) error {
+	type resendRequest struct {
+		Email string `json:"email"`
+	}
+
+	var req resendRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Email is required",
+		})
+	}
+
+	err := h.service.ResendVerificationEmail(req.Email)
+	if err != nil {
+		var rateLimitErr *models.RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(rateLimitErr.RetryAfterSeconds()))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to send verification email",
+		})
+	}
+
+	// The same answer is given whether or not the address belongs to a pending account
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "If the account exists and is not verified yet, a new verification email has been sent",
+	})
+}
+
+// ForgotPassword handles emailing a password reset link
+func (h *userHandler) ForgotPassword(c *fiber.Ctx) error {
+	type forgotPasswordRequest struct {
+		Email string `json:"email"`
+	}
+
+	var req forgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Email is required",
+		})
+	}
+
+	if err := h.service.RequestPasswordReset(req.Email); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to request password reset",
+		})
+	}
+
+	// The same answer is given whether or not the address is registered
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "If an account exists for this email, a password reset link has been sent",
+	})
+}
+
+// ResetPassword handles setting a new password with a reset token
+func (h *userHandler) ResetPassword(c *fiber.Ctx) error {
+	type resetPasswordRequest struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	}
+
+	var req resetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token is required",
+		})
+	}
+
+	if req.NewPassword == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "New password is required",
+		})
+	}
+
+	user, err := h.service.ResetPassword(req.Token, req.NewPassword)
+	if err != nil {
+		var policyErr *models.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyErrorResponse(c, policyErr)
+		}
+
+		if err.Error() == "invalid or expired reset token" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to reset password",
+		})
+	}
+
+	audit.Record(c, "user.password_reset", "user", user.ID, nil, nil)
+
+	return c.JSON(fiber.Map{
+		"message": "Password reset successfully, please log in again",
+	})
+}
+
+// passwordPolicyErrorResponse answers with every rule a password breaks
+func passwordPolicyErrorResponse(c *fiber.Ctx, err *models.PasswordPolicyError) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error":      err.Error(),
+		"code":       "password_policy",
+		"violations": err.Violations,
+	})
+}
+
+// canManageUser checks if the requesting user is the given user, or has the users:manage
+// permission and can reach the user's shelter
+func canManageUser(c *fiber.Ctx, service ports.UserService, id string) (bool, error) {
+	if requestingUserID, _ := c.Locals("userID").(string); requestingUserID == id {
+		return true, nil
+	}
+
+	allowed, err := auth.Allowed(c, models.PermissionUsersManage)
+	if err != nil || !allowed {
+		return false, err
+	}
+
+	user, err := service.GetUserByID(id)
+	if err != nil {
+		return false, err
+	}
+
+	if user == nil {
+		return true, nil
+	}
+
+	return isInShelterScope(c, service, user)
+}
+
+// canReadUser checks if the requesting user is the given user, or has the users:read permission
+// and can reach the user's shelter
+func canReadUser(c *fiber.Ctx, service ports.UserService, user *models.User) (bool, error) {
+	if requestingUserID, _ := c.Locals("userID").(string); requestingUserID == user.ID {
+		return true, nil
+	}
+
+	allowed, err := auth.Allowed(c, models.PermissionUsersRead)
+	if err != nil || !allowed {
+		return false, err
+	}
+
+	return isInShelterScope(c, service, user)
+}
+
+// isInShelterScope checks if the request can administer a user: the staff of the shelters in its
+// scope, and the users who don't work at a shelter but adopted or donated at one of them. Platform
+// admins are only administered by platform admins.
+func isInShelterScope(c *fiber.Ctx, service ports.UserService, user *models.User) (bool, error) {
+	scope := auth.ShelterScope(c)
+	if user.ShelterID != "" || scope.IsAll() {
+		return scope.Allows(user.ShelterID), nil
+	}
+
+	return service.IsUserInScope(user.ID, scope)
+}
+
+// requireUserInScope is a middleware for the routes administering the user in the id parameter.
+// Users the request can't administer are answered as if they didn't exist.
+func requireUserInScope(service ports.UserService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, err := service.GetUserByID(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		if user == nil {
+			return c.Next()
+		}
+
+		inScope, err := isInShelterScope(c, service, user)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		if !inScope {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "User not found",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// loginThrottledResponse answers a login attempted while it must wait after failed attempts
+func loginThrottledResponse(c *fiber.Ctx, err *models.LoginThrottledError) error {
+	code := "login_throttled"
+	if err.Locked {
+		code = "account_locked"
+	}
+
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(err.RetryAfterSeconds()))
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error": err.Error(),
+		"code":  code,
+	})
+}
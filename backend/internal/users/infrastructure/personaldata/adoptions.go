@@ -0,0 +1,63 @@
+package personaldata
+
+import (
+	"strings"
+
+	adoptionPorts "github.com/solrac97gr/petparadise/internal/adoptions/domain/ports"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// AdoptionsSource implements the PersonalDataModule interface on top of the adoptions module
+type AdoptionsSource struct {
+	adoptions adoptionPorts.AdoptionService
+}
+
+// NewAdoptionsSource creates a new AdoptionsSource
+func NewAdoptionsSource(adoptions adoptionPorts.AdoptionService) *AdoptionsSource {
+	return &AdoptionsSource{
+		adoptions: adoptions,
+	}
+}
+
+// ExportPersonalData returns the adoptions of a user along with the documents they submitted
+func (s *AdoptionsSource) ExportPersonalData(userID string) ([]*models.ExportSection, error) {
+	adoptions, err := s.adoptions.GetAdoptionsByUserID(userID, tenant.AllShelters())
+	if err != nil {
+		return nil, err
+	}
+
+	section := &models.ExportSection{
+		Name:    "adoptions",
+		Records: adoptions,
+		Header:  []string{"id", "pet_id", "status", "documents", "created", "updated"},
+	}
+	for _, adoption := range adoptions {
+		section.Rows = append(section.Rows, []string{
+			adoption.ID, adoption.PetID, adoption.Status.String(), strings.Join(adoption.Documents, "; "), adoption.Created, adoption.Updated,
+		})
+	}
+
+	return []*models.ExportSection{section}, nil
+}
+
+// ErasePersonalData removes the documents a user submitted with their adoptions. The adoptions
+// themselves stay, linked to the erased account.
+func (s *AdoptionsSource) ErasePersonalData(userID string) error {
+	adoptions, err := s.adoptions.GetAdoptionsByUserID(userID, tenant.AllShelters())
+	if err != nil {
+		return err
+	}
+
+	for _, adoption := range adoptions {
+		if len(adoption.Documents) == 0 {
+			continue
+		}
+
+		if _, err := s.adoptions.UpdateAdoption(adoption.ID, adoption.Status, []string{}, tenant.AllShelters()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package personaldata
+
+import (
+	"time"
+
+	petModels "github.com/solrac97gr/petparadise/internal/pets/domain/models"
+	petPorts "github.com/solrac97gr/petparadise/internal/pets/domain/ports"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// AppointmentsSource implements the PersonalDataModule interface on top of the vet appointments
+// of the pets module
+type AppointmentsSource struct {
+	appointments petPorts.AppointmentService
+}
+
+// NewAppointmentsSource creates a new AppointmentsSource
+func NewAppointmentsSource(appointments petPorts.AppointmentService) *AppointmentsSource {
+	return &AppointmentsSource{
+		appointments: appointments,
+	}
+}
+
+// ExportPersonalData returns the appointments a user was assigned to as a vet, past and future
+func (s *AppointmentsSource) ExportPersonalData(userID string) ([]*models.ExportSection, error) {
+	appointments, err := s.appointments.GetAppointments(petModels.AppointmentFilter{
+		VetID: userID,
+		To:    time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC),
+	}, tenant.AllShelters())
+	if err != nil {
+		return nil, err
+	}
+
+	section := &models.ExportSection{
+		Name:    "appointments",
+		Records: appointments,
+		Header:  []string{"id", "pet_id", "procedure", "starts_at", "ends_at", "status", "notes", "created"},
+	}
+	for _, appointment := range appointments {
+		section.Rows = append(section.Rows, []string{
+			appointment.ID, appointment.PetID, appointment.Procedure.String(), formatTime(&appointment.StartsAt), formatTime(&appointment.EndsAt),
+			appointment.Status.String(), appointment.Notes, formatTime(&appointment.Created),
+		})
+	}
+
+	return []*models.ExportSection{section}, nil
+}
+
+// ErasePersonalData keeps the appointments of a vet whose account is erased, as they are the
+// medical history of the pets. They hold nothing about the vet but their ID.
+func (s *AppointmentsSource) ErasePersonalData(userID string) error {
+	return nil
+}
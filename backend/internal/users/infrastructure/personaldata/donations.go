@@ -0,0 +1,123 @@
+package personaldata
+
+import (
+	"strconv"
+
+	donationModels "github.com/solrac97gr/petparadise/internal/donations/domain/models"
+	donationPorts "github.com/solrac97gr/petparadise/internal/donations/domain/ports"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// DonationsSource implements the PersonalDataModule interface on top of the donations module
+type DonationsSource struct {
+	donations donationPorts.DonationService
+	refunds   donationPorts.RefundService
+	inKind    donationPorts.InKindService
+	donors    donationPorts.DonorService
+}
+
+// NewDonationsSource creates a new DonationsSource
+func NewDonationsSource(donations donationPorts.DonationService, refunds donationPorts.RefundService, inKind donationPorts.InKindService,
+	donors donationPorts.DonorService) *DonationsSource {
+	return &DonationsSource{
+		donations: donations,
+		refunds:   refunds,
+		inKind:    inKind,
+		donors:    donors,
+	}
+}
+
+// ExportPersonalData returns the donations of a user, the refunds of those donations, their
+// in-kind donations and how they appear on public donation pages
+func (s *DonationsSource) ExportPersonalData(userID string) ([]*models.ExportSection, error) {
+	donations, err := s.donations.GetDonationsByUserID(userID, tenant.AllShelters())
+	if err != nil {
+		return nil, err
+	}
+
+	donationSection := &models.ExportSection{
+		Name:    "donations",
+		Records: donations,
+		Header:  []string{"id", "amount", "net_amount", "status", "pet_id", "sponsorship", "campaign", "comment", "anonymous", "created", "updated"},
+	}
+
+	refunds := []*donationModels.Refund{}
+	for _, donation := range donations {
+		donationSection.Rows = append(donationSection.Rows, []string{
+			donation.ID, formatAmount(donation.Amount), formatAmount(donation.NetAmount), donation.Status.String(), donation.PetID,
+			string(donation.Sponsorship), donation.Campaign, donation.Comment, strconv.FormatBool(donation.Anonymous), donation.Created, donation.Updated,
+		})
+
+		found, err := s.refunds.GetRefundsByDonationID(donation.ID)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, found...)
+	}
+
+	refundSection := &models.ExportSection{
+		Name:    "refunds",
+		Records: refunds,
+		Header:  []string{"id", "donation_id", "amount", "reason", "status", "created", "updated"},
+	}
+	for _, refund := range refunds {
+		refundSection.Rows = append(refundSection.Rows, []string{
+			refund.ID, refund.DonationID, formatAmount(refund.Amount), refund.Reason, refund.Status.String(), refund.Created, refund.Updated,
+		})
+	}
+
+	inKindDonations, err := s.inKind.GetInKindDonationsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The items of in-kind donations get their own CSV file, and are nested in the JSON file
+	inKindSection := &models.ExportSection{
+		Name:    "in_kind_donations",
+		Records: inKindDonations,
+		Header:  []string{"id", "drop_off_date", "estimated_value", "comment", "acknowledged_at", "created", "updated"},
+	}
+	itemSection := &models.ExportSection{
+		Name:   "in_kind_donation_items",
+		Header: []string{"donation_id", "category", "name", "quantity", "unit", "estimated_value", "condition"},
+	}
+	for _, donation := range inKindDonations {
+		inKindSection.Rows = append(inKindSection.Rows, []string{
+			donation.ID, donation.DropOffDate, formatAmount(donation.EstimatedValue), donation.Comment, donation.AcknowledgedAt, donation.Created, donation.Updated,
+		})
+
+		for _, item := range donation.Items {
+			itemSection.Rows = append(itemSection.Rows, []string{
+				donation.ID, string(item.Category), item.Name, strconv.FormatFloat(item.Quantity, 'f', -1, 64), item.Unit.String(),
+				formatAmount(item.EstimatedValue), string(item.Condition),
+			})
+		}
+	}
+
+	profile, err := s.donors.GetDonorProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profileSection := &models.ExportSection{
+		Name:    "donor_profile",
+		Records: profile,
+		Header:  []string{"display_name", "hide_amounts", "updated"},
+		Rows:    [][]string{{profile.DisplayName, strconv.FormatBool(profile.HideAmounts), profile.Updated}},
+	}
+
+	return []*models.ExportSection{donationSection, refundSection, inKindSection, itemSection, profileSection}, nil
+}
+
+// ErasePersonalData resets the name a user chose for public donation pages. Their donations
+// are financial records and stay, linked to the erased account.
+func (s *DonationsSource) ErasePersonalData(userID string) error {
+	_, err := s.donors.UpdateDonorProfile(userID, "", false)
+	return err
+}
+
+// formatAmount formats an amount of money with two decimals
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
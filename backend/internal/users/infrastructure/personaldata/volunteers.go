@@ -0,0 +1,68 @@
+package personaldata
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	volunteerPorts "github.com/solrac97gr/petparadise/internal/volunteers/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// VolunteersSource implements the PersonalDataModule interface on top of the volunteers module
+type VolunteersSource struct {
+	shifts volunteerPorts.ShiftService
+}
+
+// NewVolunteersSource creates a new VolunteersSource
+func NewVolunteersSource(shifts volunteerPorts.ShiftService) *VolunteersSource {
+	return &VolunteersSource{
+		shifts: shifts,
+	}
+}
+
+// ExportPersonalData returns the shifts a user signed up for and the hours they worked
+func (s *VolunteersSource) ExportPersonalData(userID string) ([]*models.ExportSection, error) {
+	signUps, err := s.shifts.GetSignUpsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	section := &models.ExportSection{
+		Name:    "volunteer_shifts",
+		Records: signUps,
+		Header:  []string{"id", "shift_id", "task", "location", "starts_at", "status", "checked_in_at", "checked_out_at", "hours", "created"},
+	}
+	for _, signUp := range signUps {
+		shift, err := s.shifts.GetShiftByID(signUp.ShiftID, tenant.AllShelters())
+		if err != nil {
+			return nil, err
+		}
+
+		var task, location, startsAt string
+		if shift != nil {
+			task, location, startsAt = shift.Task, shift.Location, formatTime(&shift.StartsAt)
+		}
+
+		section.Rows = append(section.Rows, []string{
+			signUp.ID, signUp.ShiftID, task, location, startsAt, signUp.Status.String(), formatTime(signUp.CheckedInAt),
+			formatTime(signUp.CheckedOutAt), strconv.FormatFloat(signUp.Hours, 'f', 2, 64), formatTime(&signUp.Created),
+		})
+	}
+
+	return []*models.ExportSection{section}, nil
+}
+
+// ErasePersonalData keeps the shifts a user signed up for, so the hours they worked stay in the
+// reports. Sign-ups hold nothing about the user but their ID.
+func (s *VolunteersSource) ErasePersonalData(userID string) error {
+	return nil
+}
+
+// formatTime formats an optional time for the CSV files of the export
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// PostgresAccountDeletionRepository implements the AccountDeletionRepository interface.
+type PostgresAccountDeletionRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresAccountDeletionRepository creates a new PostgresAccountDeletionRepository
+func NewPostgresAccountDeletionRepository(db *sqlx.DB) *PostgresAccountDeletionRepository {
+	return &PostgresAccountDeletionRepository{
+		db: db,
+	}
+}
+
+// accountDeletionColumns are the columns scanned by scanAccountDeletion
+const accountDeletionColumns = `id, user_id, requested_by, requested_at, scheduled_for, cancelled_at, erased_at, completed_at`
+
+// Save saves an account deletion
+func (r *PostgresAccountDeletionRepository) Save(deletion *models.AccountDeletion) error {
+	query := `INSERT INTO account_deletions (id, user_id, requested_by, requested_at, scheduled_for)
+              VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := r.db.Exec(
+		query,
+		deletion.ID,
+		deletion.UserID,
+		deletion.RequestedBy,
+		deletion.RequestedAt.UTC(),
+		deletion.ScheduledFor.UTC(),
+	)
+
+	return err
+}
+
+// FindPendingByUserID finds the deletion of a user that has been neither cancelled nor carried out
+func (r *PostgresAccountDeletionRepository) FindPendingByUserID(userID string) (*models.AccountDeletion, error) {
+	query := `SELECT ` + accountDeletionColumns + ` FROM account_deletions
+              WHERE user_id = $1 AND cancelled_at IS NULL AND erased_at IS NULL`
+
+	return scanAccountDeletion(r.db.QueryRow(query, userID))
+}
+
+// FindDue finds the pending deletions whose grace period is over, and the erased accounts whose
+// data the other modules haven't finished erasing, oldest first
+func (r *PostgresAccountDeletionRepository) FindDue(now time.Time) ([]*models.AccountDeletion, error) {
+	query := `SELECT ` + accountDeletionColumns + ` FROM account_deletions
+              WHERE (cancelled_at IS NULL AND erased_at IS NULL AND scheduled_for <= $1)
+              OR (erased_at IS NOT NULL AND completed_at IS NULL)
+              ORDER BY scheduled_for`
+
+	rows, err := r.db.Query(query, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deletions := []*models.AccountDeletion{}
+	for rows.Next() {
+		deletion, err := scanAccountDeletion(rows)
+		if err != nil {
+			return nil, err
+		}
+		deletions = append(deletions, deletion)
+	}
+
+	return deletions, rows.Err()
+}
+
+// Cancel cancels a pending deletion, reporting whether it was pending
+func (r *PostgresAccountDeletionRepository) Cancel(id string, cancelledAt time.Time) (bool, error) {
+	query := `UPDATE account_deletions SET cancelled_at = $1
+              WHERE id = $2 AND cancelled_at IS NULL AND erased_at IS NULL`
+
+	result, err := r.db.Exec(query, cancelledAt.UTC(), id)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// Erase claims a pending deletion and erases the account in one transaction, reporting whether it
+// was pending, so it can no longer be cancelled. The user is kept as a tombstone without personal
+// data, and the sign-in methods, sessions and other records of the account are removed.
+func (r *PostgresAccountDeletionRepository) Erase(deletion *models.AccountDeletion, erasedAt time.Time) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE account_deletions SET erased_at = $1
+                            WHERE id = $2 AND cancelled_at IS NULL AND erased_at IS NULL`, erasedAt.UTC(), deletion.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if rows, err := result.RowsAffected(); err != nil || rows != 1 {
+		return false, err
+	}
+
+	var email string
+	if err := tx.QueryRow(`SELECT email FROM users WHERE id = $1 FOR UPDATE`, deletion.UserID).Scan(&email); err != nil {
+		return false, err
+	}
+
+	tombstoneEmail := models.TombstoneEmail(deletion.UserID)
+
+	// An empty password hash matches no password
+	_, err = tx.Exec(`UPDATE users SET name = $1, email = $2, password = '', status = $3, address = '', phone = '',
+                      documents = '[]', updated = $4 WHERE id = $5`,
+		models.DeletedUserName, tombstoneEmail, models.StatusDeleted.String(), erasedAt.UTC(), deletion.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, table := range []string{
+		"user_identities", "user_mfa", "mfa_recovery_codes", "password_history", "password_reset_tokens",
+		"data_exports", "sessions", "refresh_tokens",
+	} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE user_id = $1`, deletion.UserID); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM verification_emails WHERE email = $1`, email); err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(`DELETE FROM login_throttles WHERE kind = $1 AND subject = $2`, models.ThrottleAccount, strings.ToLower(email))
+	if err != nil {
+		return false, err
+	}
+
+	// Accepted invitations stay as a record of who invited whom
+	if _, err := tx.Exec(`UPDATE invitations SET email = $1 WHERE accepted_user_id = $2`, tombstoneEmail, deletion.UserID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Complete records that the other modules erased what they keep about the user of an erased account
+func (r *PostgresAccountDeletionRepository) Complete(id string, completedAt time.Time) error {
+	_, err := r.db.Exec(`UPDATE account_deletions SET completed_at = $1 WHERE id = $2 AND erased_at IS NOT NULL AND completed_at IS NULL`,
+		completedAt.UTC(), id)
+	return err
+}
+
+// scanAccountDeletion scans a row of accountDeletionColumns, returning nil if there is no row
+func scanAccountDeletion(row interface{ Scan(dest ...any) error }) (*models.AccountDeletion, error) {
+	var deletion models.AccountDeletion
+	var cancelledAt, erasedAt, completedAt sql.NullTime
+
+	err := row.Scan(
+		&deletion.ID,
+		&deletion.UserID,
+		&deletion.RequestedBy,
+		&deletion.RequestedAt,
+		&deletion.ScheduledFor,
+		&cancelledAt,
+		&erasedAt,
+		&completedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if cancelledAt.Valid {
+		deletion.CancelledAt = &cancelledAt.Time
+	}
+
+	if erasedAt.Valid {
+		deletion.ErasedAt = &erasedAt.Time
+	}
+
+	if completedAt.Valid {
+		deletion.CompletedAt = &completedAt.Time
+	}
+
+	deletion.Status = deletion.CurrentStatus()
+	return &deletion, nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// PostgresDataExportRepository implements the DataExportRepository interface. Archives are
+// stored with their export and only loaded for downloads.
+type PostgresDataExportRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresDataExportRepository creates a new PostgresDataExportRepository
+func NewPostgresDataExportRepository(db *sqlx.DB) *PostgresDataExportRepository {
+	return &PostgresDataExportRepository{
+		db: db,
+	}
+}
+
+// dataExportColumns are the columns scanned by scanDataExport
+const dataExportColumns = `id, user_id, status, created, completed_at, expires_at, size`
+
+// Save saves a data export
+func (r *PostgresDataExportRepository) Save(export *models.DataExport) error {
+	query := `INSERT INTO data_exports (id, user_id, status, created)
+              VALUES ($1, $2, $3, $4)`
+
+	_, err := r.db.Exec(query, export.ID, export.UserID, export.Status, export.Created.UTC())
+	return err
+}
+
+// FindByID finds a data export by its ID
+func (r *PostgresDataExportRepository) FindByID(id string) (*models.DataExport, error) {
+	return scanDataExport(r.db.QueryRow(`SELECT `+dataExportColumns+` FROM data_exports WHERE id = $1`, id))
+}
+
+// FindLatestByUserID finds the most recent data export of a user
+func (r *PostgresDataExportRepository) FindLatestByUserID(userID string) (*models.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE user_id = $1 ORDER BY created DESC LIMIT 1`
+	return scanDataExport(r.db.QueryRow(query, userID))
+}
+
+// FindArchive returns the archive of a data export, or nil if it has none
+func (r *PostgresDataExportRepository) FindArchive(id string) ([]byte, error) {
+	var archive []byte
+
+	err := r.db.QueryRow(`SELECT archive FROM data_exports WHERE id = $1`, id).Scan(&archive)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// Complete stores the archive of a data export and marks it as ready
+func (r *PostgresDataExportRepository) Complete(id string, archive []byte, completedAt, expiresAt time.Time) error {
+	query := `UPDATE data_exports SET status = $1, archive = $2, size = $3, completed_at = $4, expires_at = $5
+              WHERE id = $6`
+
+	_, err := r.db.Exec(query, models.DataExportReady, archive, len(archive), completedAt.UTC(), expiresAt.UTC(), id)
+	return err
+}
+
+// Fail marks a data export as failed
+func (r *PostgresDataExportRepository) Fail(id string, failedAt time.Time) error {
+	query := `UPDATE data_exports SET status = $1, completed_at = $2 WHERE id = $3`
+	_, err := r.db.Exec(query, models.DataExportFailed, failedAt.UTC(), id)
+	return err
+}
+
+// PurgeExpired removes the exports whose download link has expired, and the failed and stuck
+// ones older than DataExportTimeout
+func (r *PostgresDataExportRepository) PurgeExpired(now time.Time) (int64, error) {
+	query := `DELETE FROM data_exports WHERE expires_at <= $1 OR (status <> $2 AND created <= $3)`
+
+	result, err := r.db.Exec(query, now.UTC(), models.DataExportReady, now.Add(-models.DataExportTimeout).UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// scanDataExport scans a row of dataExportColumns, returning nil if there is no row
+func scanDataExport(row interface{ Scan(dest ...any) error }) (*models.DataExport, error) {
+	var export models.DataExport
+	var completedAt, expiresAt sql.NullTime
+
+	err := row.Scan(
+		&export.ID,
+		&export.UserID,
+		&export.Status,
+		&export.Created,
+		&completedAt,
+		&expiresAt,
+		&export.Size,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if completedAt.Valid {
+		export.CompletedAt = &completedAt.Time
+	}
+
+	if expiresAt.Valid {
+		export.ExpiresAt = &expiresAt.Time
+	}
+
+	return &export, nil
+}
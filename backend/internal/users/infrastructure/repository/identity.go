@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// PostgresIdentityRepository implements the IdentityRepository interface.
+type PostgresIdentityRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresIdentityRepository creates a new PostgresIdentityRepository
+func NewPostgresIdentityRepository(db *sqlx.DB) *PostgresIdentityRepository {
+	return &PostgresIdentityRepository{
+		db: db,
+	}
+}
+
+// Save links an external identity to a user
+func (r *PostgresIdentityRepository) Save(identity *models.ExternalIdentity) error {
+	query := `INSERT INTO user_identities (provider, subject, user_id, email, created)
+              VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := r.db.Exec(query, identity.Provider, identity.Subject, identity.UserID, identity.Email, identity.Created.UTC())
+	return err
+}
+
+// FindByProviderSubject finds the identity of an account at a provider
+func (r *PostgresIdentityRepository) FindByProviderSubject(provider, subject string) (*models.ExternalIdentity, error) {
+	var identity models.ExternalIdentity
+
+	query := `SELECT provider, subject, user_id, email, created
+              FROM user_identities WHERE provider = $1 AND subject = $2`
+
+	err := r.db.QueryRow(query, provider, subject).Scan(
+		&identity.Provider,
+		&identity.Subject,
+		&identity.UserID,
+		&identity.Email,
+		&identity.Created,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// FindByUserID finds the identities linked to a user, oldest first
+func (r *PostgresIdentityRepository) FindByUserID(userID string) ([]*models.ExternalIdentity, error) {
+	query := `SELECT provider, subject, user_id, email, created
+              FROM user_identities WHERE user_id = $1 ORDER BY created`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []*models.ExternalIdentity{}
+	for rows.Next() {
+		var identity models.ExternalIdentity
+		if err := rows.Scan(&identity.Provider, &identity.Subject, &identity.UserID, &identity.Email, &identity.Created); err != nil {
+			return nil, err
+		}
+		identities = append(identities, &identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// SaveLoginState saves the state of a login sent to a provider, purging the expired ones
+func (r *PostgresIdentityRepository) SaveLoginState(state *models.OIDCLoginState) error {
+	_, err := r.db.Exec(`DELETE FROM oidc_login_states WHERE expires_at <= $1`, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO oidc_login_states (state, provider, nonce, code_verifier, expires_at)
+              VALUES ($1, $2, $3, $4, $5)`
+
+	_, err = r.db.Exec(query, state.State, state.Provider, state.Nonce, state.CodeVerifier, state.ExpiresAt.UTC())
+	return err
+}
+
+// ConsumeLoginState removes and returns a login state that hasn't expired, so it can only be used once
+func (r *PostgresIdentityRepository) ConsumeLoginState(state string, now time.Time) (*models.OIDCLoginState, error) {
+	var loginState models.OIDCLoginState
+
+	query := `DELETE FROM oidc_login_states WHERE state = $1 AND expires_at > $2
+              RETURNING state, provider, nonce, code_verifier, expires_at`
+
+	err := r.db.QueryRow(query, state, now.UTC()).Scan(
+		&loginState.State,
+		&loginState.Provider,
+		&loginState.Nonce,
+		&loginState.CodeVerifier,
+		&loginState.ExpiresAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &loginState, nil
+}
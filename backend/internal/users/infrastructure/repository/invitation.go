@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// PostgresInvitationRepository implements the InvitationRepository interface.
+type PostgresInvitationRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresInvitationRepository creates a new PostgresInvitationRepository
+func NewPostgresInvitationRepository(db *sqlx.DB) *PostgresInvitationRepository {
+	return &PostgresInvitationRepository{
+		db: db,
+	}
+}
+
+// invitationColumns are the columns scanned by scanInvitation
+const invitationColumns = `id, email, role, COALESCE(shelter_id::text, ''), invited_by, created, expires_at, accepted_at,
+              accepted_user_id, revoked_at`
+
+// Save saves an invitation
+func (r *PostgresInvitationRepository) Save(invitation *models.Invitation) error {
+	query := `INSERT INTO invitations (id, email, role, shelter_id, invited_by, created, expires_at)
+              VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5, $6, $7)`
+
+	_, err := r.db.Exec(
+		query,
+		invitation.ID,
+		invitation.Email,
+		invitation.Role,
+		invitation.ShelterID,
+		invitation.InvitedBy,
+		invitation.Created.UTC(),
+		invitation.ExpiresAt.UTC(),
+	)
+
+	return shelterError(err, "invitations_shelter_id_fkey")
+}
+
+// FindByID finds an invitation by its ID
+func (r *PostgresInvitationRepository) FindByID(id string) (*models.Invitation, error) {
+	return scanInvitation(r.db.QueryRow(`SELECT `+invitationColumns+` FROM invitations WHERE id = $1`, id))
+}
+
+// FindAll returns every invitation to a shelter in scope, newest first
+func (r *PostgresInvitationRepository) FindAll(scope tenant.Scope) ([]*models.Invitation, error) {
+	all, shelterID := scope.Filter()
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE ` + tenant.Condition("shelter_id", 1) + ` ORDER BY created DESC`
+
+	rows, err := r.db.Query(query, all, shelterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*models.Invitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+// FindByUserID returns the invitations the user sent or accepted, newest first
+func (r *PostgresInvitationRepository) FindByUserID(userID string) ([]*models.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE invited_by = $1 OR accepted_user_id = $1 ORDER BY created DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*models.Invitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+// Accept marks a pending invitation as accepted by the given user, reporting whether it was pending
+func (r *PostgresInvitationRepository) Accept(id, userID string, acceptedAt time.Time) (bool, error) {
+	query := `UPDATE invitations SET accepted_at = $1, accepted_user_id = $2
+              WHERE id = $3 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $1`
+
+	return r.updateOne(query, acceptedAt.UTC(), userID, id)
+}
+
+// ReleaseAcceptance makes an accepted invitation pending again, when its account couldn't be created
+func (r *PostgresInvitationRepository) ReleaseAcceptance(id string) error {
+	_, err := r.db.Exec(`UPDATE invitations SET accepted_at = NULL, accepted_user_id = NULL WHERE id = $1`, id)
+	return err
+}
+
+// Revoke marks a pending invitation as revoked, reporting whether it was pending
+func (r *PostgresInvitationRepository) Revoke(id string, revokedAt time.Time) (bool, error) {
+	query := `UPDATE invitations SET revoked_at = $1
+              WHERE id = $2 AND accepted_at IS NULL AND revoked_at IS NULL`
+
+	return r.updateOne(query, revokedAt.UTC(), id)
+}
+
+func (r *PostgresInvitationRepository) updateOne(query string, args ...any) (bool, error) {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// scanInvitation scans a row of invitationColumns, returning nil if there is no row
+func scanInvitation(row interface{ Scan(dest ...any) error }) (*models.Invitation, error) {
+	var invitation models.Invitation
+	var acceptedAt, revokedAt sql.NullTime
+	var acceptedUserID sql.NullString
+
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.ShelterID,
+		&invitation.InvitedBy,
+		&invitation.Created,
+		&invitation.ExpiresAt,
+		&acceptedAt,
+		&acceptedUserID,
+		&revokedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if acceptedAt.Valid {
+		invitation.AcceptedAt = &acceptedAt.Time
+	}
+
+	if revokedAt.Valid {
+		invitation.RevokedAt = &revokedAt.Time
+	}
+
+	invitation.AcceptedUserID = acceptedUserID.String
+
+	return &invitation, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// PostgresLoginThrottleRepository implements the LoginThrottleRepository interface.
+type PostgresLoginThrottleRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresLoginThrottleRepository creates a new PostgresLoginThrottleRepository
+func NewPostgresLoginThrottleRepository(db *sqlx.DB) *PostgresLoginThrottleRepository {
+	return &PostgresLoginThrottleRepository{
+		db: db,
+	}
+}
+
+// Find finds the failed logins of an account or IP address
+func (r *PostgresLoginThrottleRepository) Find(kind models.ThrottleKind, subject string) (*models.LoginThrottle, error) {
+	query := `SELECT kind, subject, failures, last_failure, locked_until
+              FROM login_throttles WHERE kind = $1 AND subject = $2`
+
+	return r.scan(r.db.QueryRow(query, kind, subject))
+}
+
+// RecordFailure counts a failed login and returns the updated throttle. Failures older than the
+// policy window are forgotten, and throttles of the same kind that have run out are purged.
+func (r *PostgresLoginThrottleRepository) RecordFailure(kind models.ThrottleKind, subject string, at time.Time) (*models.LoginThrottle, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	windowStart := at.Add(-kind.Policy().Window).UTC()
+
+	_, err = tx.Exec(`DELETE FROM login_throttles WHERE kind = $1 AND last_failure < $2
+                      AND (locked_until IS NULL OR locked_until < $3)`, kind, windowStart, at.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	// Counted in a single statement so concurrent failures are all counted
+	query := `INSERT INTO login_throttles (kind, subject, failures, last_failure)
+              VALUES ($1, $2, 1, $3)
+              ON CONFLICT (kind, subject) DO UPDATE SET
+                  failures = CASE WHEN login_throttles.last_failure < $4 THEN 1 ELSE login_throttles.failures + 1 END,
+                  last_failure = $3
+              RETURNING kind, subject, failures, last_failure, locked_until`
+
+	throttle, err := r.scan(tx.QueryRow(query, kind, subject, at.UTC(), windowStart))
+	if err != nil {
+		return nil, err
+	}
+
+	return throttle, tx.Commit()
+}
+
+// Lock locks out the logins of an account or IP address until the given time
+func (r *PostgresLoginThrottleRepository) Lock(kind models.ThrottleKind, subject string, until time.Time) error {
+	query := `UPDATE login_throttles SET locked_until = $1 WHERE kind = $2 AND subject = $3`
+	_, err := r.db.Exec(query, until.UTC(), kind, subject)
+	return err
+}
+
+// Delete forgets the failed logins of an account or IP address, lifting any lock
+func (r *PostgresLoginThrottleRepository) Delete(kind models.ThrottleKind, subject string) error {
+	_, err := r.db.Exec(`DELETE FROM login_throttles WHERE kind = $1 AND subject = $2`, kind, subject)
+	return err
+}
+
+func (r *PostgresLoginThrottleRepository) scan(row *sql.Row) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	var lockedUntil sql.NullTime
+
+	err := row.Scan(
+		&throttle.Kind,
+		&throttle.Subject,
+		&throttle.Failures,
+		&throttle.LastFailure,
+		&lockedUntil,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if lockedUntil.Valid {
+		throttle.LockedUntil = &lockedUntil.Time
+	}
+
+	return &throttle, nil
+}
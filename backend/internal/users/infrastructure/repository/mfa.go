@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// PostgresMFARepository implements the MFARepository interface
+type PostgresMFARepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresMFARepository creates a new PostgresMFARepository
+func NewPostgresMFARepository(db *sqlx.DB) *PostgresMFARepository {
+	return &PostgresMFARepository{
+		db: db,
+	}
+}
+
+// Save creates or replaces the two-factor authentication settings of a user
+func (r *PostgresMFARepository) Save(mfa *models.MFA) error {
+	query := `INSERT INTO user_mfa (user_id, secret, enabled, last_used_step, failed_attempts, created, updated)
+              VALUES ($1, $2, $3, $4, $5, $6, $7)
+              ON CONFLICT (user_id) DO UPDATE SET secret = $2, enabled = $3, last_used_step = $4,
+              failed_attempts = $5, updated = $7`
+
+	_, err := r.db.Exec(
+		query,
+		mfa.UserID,
+		mfa.Secret,
+		mfa.Enabled,
+		mfa.LastUsedStep,
+		mfa.FailedAttempts,
+		mfa.Created,
+		mfa.Updated,
+	)
+
+	return err
+}
+
+// FindByUserID finds the two-factor authentication settings of a user
+func (r *PostgresMFARepository) FindByUserID(userID string) (*models.MFA, error) {
+	var mfa models.MFA
+
+	query := `SELECT user_id, secret, enabled, last_used_step, failed_attempts, created, updated
+              FROM user_mfa WHERE user_id = $1`
+
+	if err := r.db.Get(&mfa, query, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &mfa, nil
+}
+
+// Delete removes the two-factor authentication settings and recovery codes of a user
+func (r *PostgresMFARepository) Delete(userID string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseStep records the time step of an accepted code, reporting false if that step or a later one
+// was already used so two requests can't both use the same code
+func (r *PostgresMFARepository) UseStep(userID string, step int64) (bool, error) {
+	query := `UPDATE user_mfa SET last_used_step = $1, failed_attempts = 0
+              WHERE user_id = $2 AND last_used_step < $1`
+
+	result, err := r.db.Exec(query, step, userID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// RecordFailedAttempt counts an invalid code and returns the number of invalid codes in a row
+func (r *PostgresMFARepository) RecordFailedAttempt(userID string) (int, error) {
+	var attempts int
+
+	query := `UPDATE user_mfa SET failed_attempts = failed_attempts + 1 WHERE user_id = $1 RETURNING failed_attempts`
+	if err := r.db.QueryRow(query, userID).Scan(&attempts); err != nil {
+		return 0, err
+	}
+
+	return attempts, nil
+}
+
+// ResetFailedAttempts clears the count of invalid codes
+func (r *PostgresMFARepository) ResetFailedAttempts(userID string) error {
+	_, err := r.db.Exec(`UPDATE user_mfa SET failed_attempts = 0 WHERE user_id = $1`, userID)
+	return err
+}
+
+// ReplaceRecoveryCodes replaces the recovery codes of a user
+func (r *PostgresMFARepository) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		_, err := tx.Exec(`INSERT INTO mfa_recovery_codes (id, user_id, code_hash) VALUES ($1, $2, $3)`,
+			uuid.New().String(), userID, codeHash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode marks an unused recovery code as used, reporting whether it was found
+func (r *PostgresMFARepository) UseRecoveryCode(userID, codeHash, usedAt string) (bool, error) {
+	query := `UPDATE mfa_recovery_codes SET used_at = $1
+              WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL`
+
+	result, err := r.db.Exec(query, usedAt, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// CountRecoveryCodes counts the recovery codes of a user that haven't been used
+func (r *PostgresMFARepository) CountRecoveryCodes(userID string) (int, error) {
+	var count int
+
+	query := `SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	if err := r.db.Get(&count, query, userID); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,15 @@
+-- Access tokens revoked on logout, kept until they expire
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    token_id VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Add index for purging expired revocations
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+-- Per-user cutoff: every token issued to the user up to revoked_before is revoked
+CREATE TABLE IF NOT EXISTS user_token_cutoffs (
+    user_id UUID PRIMARY KEY,
+    revoked_before TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,9 @@
+-- Verification emails requested per address, used to rate limit resends
+CREATE TABLE IF NOT EXISTS verification_emails (
+    id UUID PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    sent_at TIMESTAMP NOT NULL
+);
+
+-- Add index for counting recent emails of an address
+CREATE INDEX IF NOT EXISTS idx_verification_emails_email_sent_at ON verification_emails(email, sent_at);
@@ -0,0 +1,12 @@
+-- Recent failed logins per email address and per IP address, used for backoff and lockout
+CREATE TABLE IF NOT EXISTS login_throttles (
+    kind VARCHAR(10) NOT NULL,
+    subject VARCHAR(100) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+    PRIMARY KEY (kind, subject)
+);
+
+-- Add index for purging old failures
+CREATE INDEX IF NOT EXISTS idx_login_throttles_last_failure ON login_throttles(kind, last_failure);
@@ -0,0 +1,8 @@
+-- Permissions granted to each role, edited at runtime by admins.
+-- Roles without a row keep their default permissions.
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(20) PRIMARY KEY,
+    permissions TEXT[] NOT NULL,
+    updated_by UUID NOT NULL,
+    updated TIMESTAMP NOT NULL
+);
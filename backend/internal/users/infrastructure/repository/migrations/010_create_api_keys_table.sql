@@ -0,0 +1,14 @@
+-- API keys for machine clients. Only the SHA-256 hash of each key is stored;
+-- the prefix is the visible start of the key and is used to look it up.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(20) UNIQUE NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    permissions TEXT[] NOT NULL,
+    created_by UUID NOT NULL,
+    created TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
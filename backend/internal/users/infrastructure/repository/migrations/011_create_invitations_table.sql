@@ -0,0 +1,16 @@
+-- Staff invitations. They are kept once accepted or revoked as a record of who invited whom,
+-- so the user columns have no foreign keys and outlive deleted accounts.
+CREATE TABLE IF NOT EXISTS invitations (
+    id UUID PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    invited_by UUID NOT NULL,
+    created TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    accepted_user_id UUID,
+    revoked_at TIMESTAMP
+);
+
+-- Add index for listing invitations
+CREATE INDEX IF NOT EXISTS idx_invitations_created ON invitations(created);
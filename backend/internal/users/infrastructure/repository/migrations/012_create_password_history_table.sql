@@ -0,0 +1,11 @@
+-- Previous password hashes of users, so recent passwords can't be reused
+CREATE TABLE IF NOT EXISTS password_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    password_hash VARCHAR(100) NOT NULL,
+    created TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Add index for finding the latest passwords of a user
+CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history(user_id, created);
@@ -0,0 +1,16 @@
+-- Personal data exports requested by users. The archive is kept until its download link expires.
+CREATE TABLE IF NOT EXISTS data_exports (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    size BIGINT NOT NULL DEFAULT 0,
+    archive BYTEA,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Add indexes for finding the latest export of a user and purging expired ones
+CREATE INDEX IF NOT EXISTS idx_data_exports_user_id ON data_exports(user_id, created);
+CREATE INDEX IF NOT EXISTS idx_data_exports_expires_at ON data_exports(expires_at);
@@ -0,0 +1,18 @@
+-- Requests to delete accounts. Once the grace period is over the user is kept as a tombstone
+-- without personal data, so their donations and adoptions remain.
+CREATE TABLE IF NOT EXISTS account_deletions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    requested_by UUID NOT NULL,
+    requested_at TIMESTAMP NOT NULL,
+    scheduled_for TIMESTAMP NOT NULL,
+    cancelled_at TIMESTAMP,
+    erased_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- A user has at most one deletion that hasn't been cancelled
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_deletions_user_id ON account_deletions(user_id) WHERE cancelled_at IS NULL;
+
+-- Add index for finding the deletions whose grace period is over
+CREATE INDEX IF NOT EXISTS idx_account_deletions_scheduled_for ON account_deletions(scheduled_for) WHERE cancelled_at IS NULL AND erased_at IS NULL;
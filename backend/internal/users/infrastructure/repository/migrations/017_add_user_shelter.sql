@@ -0,0 +1,11 @@
+-- Staff work at a shelter; admins without one are platform admins, working across shelters.
+-- Existing vets and volunteers are moved to the default shelter.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS shelter_id UUID REFERENCES shelters(id) ON DELETE RESTRICT;
+UPDATE users SET shelter_id = '00000000-0000-0000-0000-000000000001' WHERE shelter_id IS NULL AND role IN ('vet', 'volunteer');
+CREATE INDEX IF NOT EXISTS idx_users_shelter_id ON users(shelter_id);
+
+-- API keys without a shelter work across shelters
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS shelter_id UUID REFERENCES shelters(id) ON DELETE RESTRICT;
+
+-- Staff invited to a shelter join it when they accept
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS shelter_id UUID REFERENCES shelters(id) ON DELETE RESTRICT;
@@ -0,0 +1,3 @@
+-- Only keys created as platform-wide work across shelters, a key without a shelter alone doesn't.
+-- Existing keys without a shelter reach no shelter until they are recreated as platform-wide.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS platform_wide BOOLEAN NOT NULL DEFAULT FALSE;
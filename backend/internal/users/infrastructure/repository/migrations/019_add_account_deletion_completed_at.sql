@@ -0,0 +1,4 @@
+-- Accounts are erased before the other modules erase what they keep, which is recorded here once
+-- they all succeeded. Deletions erased before had the other modules go first.
+ALTER TABLE account_deletions ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+UPDATE account_deletions SET completed_at = erased_at WHERE erased_at IS NOT NULL AND completed_at IS NULL;
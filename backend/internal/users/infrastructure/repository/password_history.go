@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresPasswordHistoryRepository implements the PasswordHistoryRepository interface.
+type PostgresPasswordHistoryRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresPasswordHistoryRepository creates a new PostgresPasswordHistoryRepository
+func NewPostgresPasswordHistoryRepository(db *sqlx.DB) *PostgresPasswordHistoryRepository {
+	return &PostgresPasswordHistoryRepository{
+		db: db,
+	}
+}
+
+// Add records a previous password hash of a user
+func (r *PostgresPasswordHistoryRepository) Add(userID, passwordHash string, at time.Time) error {
+	query := `INSERT INTO password_history (user_id, password_hash, created) VALUES ($1, $2, $3)`
+	_, err := r.db.Exec(query, userID, passwordHash, at.UTC())
+	return err
+}
+
+// FindRecent finds the latest previous password hashes of a user, newest first
+func (r *PostgresPasswordHistoryRepository) FindRecent(userID string, limit int) ([]string, error) {
+	query := `SELECT password_hash FROM password_history
+              WHERE user_id = $1 ORDER BY created DESC, id DESC LIMIT $2`
+
+	var hashes []string
+	if err := r.db.Select(&hashes, query, userID, limit); err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// Prune deletes the previous password hashes of a user but the latest keep ones
+func (r *PostgresPasswordHistoryRepository) Prune(userID string, keep int) error {
+	query := `DELETE FROM password_history WHERE user_id = $1 AND id NOT IN (
+                  SELECT id FROM password_history WHERE user_id = $1 ORDER BY created DESC, id DESC LIMIT $2
+              )`
+
+	_, err := r.db.Exec(query, userID, keep)
+	return err
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// PostgresPasswordResetRepository implements the PasswordResetRepository interface.
+type PostgresPasswordResetRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresPasswordResetRepository creates a new PostgresPasswordResetRepository
+func NewPostgresPasswordResetRepository(db *sqlx.DB) *PostgresPasswordResetRepository {
+	return &PostgresPasswordResetRepository{
+		db: db,
+	}
+}
+
+// Save saves a password reset token
+func (r *PostgresPasswordResetRepository) Save(token *models.PasswordResetToken) error {
+	query := `INSERT INTO password_reset_tokens (token_hash, user_id, created, expires_at)
+              VALUES ($1, $2, $3, $4)`
+
+	_, err := r.db.Exec(query, token.TokenHash, token.UserID, token.Created.UTC(), token.ExpiresAt.UTC())
+	return err
+}
+
+// FindByHash finds a password reset token by its hash
+func (r *PostgresPasswordResetRepository) FindByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	query := `SELECT token_hash, user_id, created, expires_at, used_at
+              FROM password_reset_tokens WHERE token_hash = $1`
+
+	return r.findOne(query, tokenHash)
+}
+
+// FindLatestByUserID finds the most recent password reset token of a user
+func (r *PostgresPasswordResetRepository) FindLatestByUserID(userID string) (*models.PasswordResetToken, error) {
+	query := `SELECT token_hash, user_id, created, expires_at, used_at
+              FROM password_reset_tokens WHERE user_id = $1 ORDER BY created DESC LIMIT 1`
+
+	return r.findOne(query, userID)
+}
+
+// Consume marks a token as used if it is still usable, reporting whether it was
+func (r *PostgresPasswordResetRepository) Consume(tokenHash string, usedAt time.Time) (bool, error) {
+	query := `UPDATE password_reset_tokens SET used_at = $1
+              WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $1`
+
+	result, err := r.db.Exec(query, usedAt.UTC(), tokenHash)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// InvalidateByUserID marks every unused token of a user as used
+func (r *PostgresPasswordResetRepository) InvalidateByUserID(userID string, usedAt time.Time) error {
+	query := `UPDATE password_reset_tokens SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL`
+	_, err := r.db.Exec(query, usedAt.UTC(), userID)
+	return err
+}
+
+func (r *PostgresPasswordResetRepository) findOne(query string, arg string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	var usedAt sql.NullTime
+
+	err := r.db.QueryRow(query, arg).Scan(
+		&token.TokenHash,
+		&token.UserID,
+		&token.Created,
+		&token.ExpiresAt,
+		&usedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return &token, nil
+}
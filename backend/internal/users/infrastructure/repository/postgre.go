@@ -4,11 +4,25 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/database"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
 )
 
+// userScope returns the condition matching the users the shelters in scope can reach, whose Filter
+// values are passed as the arguments $n and $n+1: their staff, and the users who don't work at a
+// shelter but adopted or donated there. Admins without a shelter are platform admins, reached
+// only across shelters.
+func userScope(n int) string {
+	shelter := fmt.Sprintf("NULLIF($%d, '')::uuid", n+1)
+	return `(` + tenant.Condition("users.shelter_id", n) + ` OR (users.shelter_id IS NULL AND users.role <> 'admin' AND (
+              EXISTS (SELECT 1 FROM adoptions a WHERE a.user_id = users.id::text AND a.shelter_id = ` + shelter + `)
+              OR EXISTS (SELECT 1 FROM donations d WHERE d.user_id = users.id AND d.shelter_id = ` + shelter + `))))`
+}
+
 // PostgresRepository implements the UserRepository interface
 type PostgresRepository struct {
 	db *sqlx.DB
@@ -28,8 +42,8 @@ func (r *PostgresRepository) Save(user *models.User) error {
 		return err
 	}
 
-	query := `INSERT INTO users (id, name, email, password, status, created, updated, role, address, phone, documents) 
-              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	query := `INSERT INTO users (id, name, email, password, status, created, updated, role, address, phone, documents, shelter_id) 
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, '')::uuid)`
 
 	_, err = r.db.Exec(
 		query,
@@ -44,9 +58,10 @@ func (r *PostgresRepository) Save(user *models.User) error {
 		user.Address,
 		user.Phone,
 		documentsJSON,
+		user.ShelterID,
 	)
 
-	return err
+	return shelterError(err, "users_shelter_id_fkey")
 }
 
 // FindByID finds a user by their ID
@@ -55,7 +70,8 @@ func (r *PostgresRepository) FindByID(id string) (*models.User, error) {
 	var statusStr, roleStr string
 	var documentsJSON []byte
 
-	query := `SELECT id, name, email, password, status, created, updated, role, address, phone, documents 
+	query := `SELECT id, name, email, password, status, created, updated, role, address, phone, documents,
+              COALESCE(shelter_id::text, '')
               FROM users WHERE id = $1`
 
 	err := r.db.QueryRow(query, id).Scan(
@@ -70,6 +86,7 @@ func (r *PostgresRepository) FindByID(id string) (*models.User, error) {
 		&user.Address,
 		&user.Phone,
 		&documentsJSON,
+		&user.ShelterID,
 	)
 
 	if err != nil {
@@ -97,7 +114,8 @@ func (r *PostgresRepository) FindByEmail(email string) (*models.User, error) {
 	var statusStr, roleStr string
 	var documentsJSON []byte
 
-	query := `SELECT id, name, email, password, status, created, updated, role, address, phone, documents 
+	query := `SELECT id, name, email, password, status, created, updated, role, address, phone, documents,
+              COALESCE(shelter_id::text, '')
               FROM users WHERE email = $1`
 
 	err := r.db.QueryRow(query, email).Scan(
@@ -112,6 +130,7 @@ func (r *PostgresRepository) FindByEmail(email string) (*models.User, error) {
 		&user.Address,
 		&user.Phone,
 		&documentsJSON,
+		&user.ShelterID,
 	)
 
 	if err != nil {
@@ -133,12 +152,15 @@ func (r *PostgresRepository) FindByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
-// FindByStatus finds all users with a specific status
-func (r *PostgresRepository) FindByStatus(status models.Status) ([]*models.User, error) {
-	query := `SELECT id, name, email, password, status, created, updated, role, address, phone, documents 
-              FROM users WHERE status = $1`
+// FindByStatus finds all users with a specific status who work at a shelter in scope or don't
+// work at a shelter
+func (r *PostgresRepository) FindByStatus(status models.Status, scope tenant.Scope) ([]*models.User, error) {
+	query := `SELECT id, name, email, password, status, created, updated, role, address, phone, documents,
+              COALESCE(shelter_id::text, '')
+              FROM users WHERE status = $1 AND ` + userScope(2)
 
-	rows, err := r.db.Query(query, status.String())
+	all, shelterID := scope.Filter()
+	rows, err := r.db.Query(query, status.String(), all, shelterID)
 	if err != nil {
 		return nil, err
 	}
@@ -163,6 +185,7 @@ func (r *PostgresRepository) FindByStatus(status models.Status) ([]*models.User,
 			&user.Address,
 			&user.Phone,
 			&documentsJSON,
+			&user.ShelterID,
 		)
 
 		if err != nil {
@@ -188,12 +211,23 @@ func (r *PostgresRepository) FindByStatus(status models.Status) ([]*models.User,
 	return users, nil
 }
 
-// FindAll finds all users
-func (r *PostgresRepository) FindAll() ([]*models.User, error) {
-	query := `SELECT id, name, email, password, status, created, updated, role, address, phone, documents 
-              FROM users`
+// IsInScope checks if the shelters in scope can reach a user
+func (r *PostgresRepository) IsInScope(id string, scope tenant.Scope) (bool, error) {
+	all, shelterID := scope.Filter()
+
+	var reachable bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND `+userScope(2)+`)`, id, all, shelterID).Scan(&reachable)
+	return reachable, err
+}
 
-	rows, err := r.db.Query(query)
+// FindAll finds all users who work at a shelter in scope or don't work at a shelter
+func (r *PostgresRepository) FindAll(scope tenant.Scope) ([]*models.User, error) {
+	query := `SELECT id, name, email, password, status, created, updated, role, address, phone, documents,
+              COALESCE(shelter_id::text, '')
+              FROM users WHERE ` + userScope(1)
+
+	all, shelterID := scope.Filter()
+	rows, err := r.db.Query(query, all, shelterID)
 	if err != nil {
 		return nil, err
 	}
@@ -218,6 +252,7 @@ func (r *PostgresRepository) FindAll() ([]*models.User, error) {
 			&user.Address,
 			&user.Phone,
 			&documentsJSON,
+			&user.ShelterID,
 		)
 
 		if err != nil {
@@ -251,7 +286,7 @@ func (r *PostgresRepository) Update(user *models.User) error {
 	}
 
 	query := `UPDATE users SET name = $1, email = $2, password = $3, status = $4, updated = $5, 
-              role = $6, address = $7, phone = $8, documents = $9 WHERE id = $10`
+              role = $6, address = $7, phone = $8, documents = $9, shelter_id = NULLIF($10, '')::uuid WHERE id = $11`
 
 	_, err = r.db.Exec(
 		query,
@@ -264,15 +299,18 @@ func (r *PostgresRepository) Update(user *models.User) error {
 		user.Address,
 		user.Phone,
 		documentsJSON,
+		user.ShelterID,
 		user.ID,
 	)
 
-	return err
+	return shelterError(err, "users_shelter_id_fkey")
 }
 
-// Delete deletes a user
-func (r *PostgresRepository) Delete(id string) error {
-	query := `DELETE FROM users WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+// shelterError maps the violation of a foreign key to the shelters table to ErrShelterNotFound
+func shelterError(err error, constraint string) error {
+	if database.IsForeignKeyViolation(err, constraint) {
+		return models.ErrShelterNotFound
+	}
+
 	return err
 }
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// PostgresVerificationRepository implements the VerificationRepository interface
+type PostgresVerificationRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresVerificationRepository creates a new PostgresVerificationRepository
+func NewPostgresVerificationRepository(db *sqlx.DB) *PostgresVerificationRepository {
+	return &PostgresVerificationRepository{
+		db: db,
+	}
+}
+
+// RecordVerificationEmail records that a verification email was requested for an address,
+// dropping the address' records that no longer count towards the rate limit
+func (r *PostgresVerificationRepository) RecordVerificationEmail(email string, sentAt time.Time) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	email = strings.ToLower(email)
+
+	_, err = tx.Exec(`DELETE FROM verification_emails WHERE email = $1 AND sent_at < $2`,
+		email, sentAt.Add(-models.VerificationEmailWindow).UTC())
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO verification_emails (id, email, sent_at) VALUES ($1, $2, $3)`,
+		uuid.New().String(), email, sentAt.UTC())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindVerificationEmailsSince returns when verification emails were requested for an address, oldest first
+func (r *PostgresVerificationRepository) FindVerificationEmailsSince(email string, since time.Time) ([]time.Time, error) {
+	var sentAt []time.Time
+
+	query := `SELECT sent_at FROM verification_emails WHERE email = $1 AND sent_at >= $2 ORDER BY sent_at`
+	if err := r.db.Select(&sentAt, query, strings.ToLower(email), since.UTC()); err != nil {
+		return nil, err
+	}
+
+	return sentAt, nil
+}
@@ -0,0 +1,253 @@
+package aplication
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/volunteers/domain/models"
+	"github.com/solrac97gr/petparadise/internal/volunteers/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// ShiftService implements the ShiftService interface
+type ShiftService struct {
+	shifts  ports.ShiftRepository
+	signUps ports.SignUpRepository
+}
+
+// NewShiftService creates a new ShiftService instance
+func NewShiftService(shifts ports.ShiftRepository, signUps ports.SignUpRepository) *ShiftService {
+	return &ShiftService{
+		shifts:  shifts,
+		signUps: signUps,
+	}
+}
+
+// CreateShift schedules a new shift at a shelter
+func (s *ShiftService) CreateShift(shelterID, task, location string, capacity int, startsAt, endsAt time.Time, createdBy string) (*models.Shift, error) {
+	shift, err := models.NewShift(uuid.New().String(), shelterID, task, location, capacity, startsAt, endsAt, createdBy, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.shifts.Save(shift); err != nil {
+		return nil, err
+	}
+
+	return shift, nil
+}
+
+// GetShiftByID returns a shift of the shelters in scope by its ID
+func (s *ShiftService) GetShiftByID(id string, scope tenant.Scope) (*models.Shift, error) {
+	return s.shifts.FindByID(id, scope)
+}
+
+// GetShifts returns the shifts of the shelters in scope starting in [from, to), soonest first
+func (s *ShiftService) GetShifts(from, to time.Time, scope tenant.Scope) ([]*models.Shift, error) {
+	return s.shifts.FindBetween(from, to, scope)
+}
+
+// UpdateShift changes the task, location, capacity and time of a shift of the shelters in scope
+func (s *ShiftService) UpdateShift(id, task, location string, capacity int, startsAt, endsAt time.Time, scope tenant.Scope) (*models.Shift, error) {
+	shift, err := s.findShift(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := shift.Update(task, location, capacity, startsAt, endsAt, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.shifts.Update(shift); err != nil {
+		return nil, err
+	}
+
+	return shift, nil
+}
+
+// DeleteShift deletes a shift of the shelters in scope along with its sign-ups, unless hours were
+// recorded on it
+func (s *ShiftService) DeleteShift(id string, scope tenant.Scope) error {
+	if _, err := s.findShift(id, scope); err != nil {
+		return err
+	}
+
+	deleted, err := s.shifts.Delete(id, scope)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return models.ErrShiftHasHours
+	}
+
+	return nil
+}
+
+// SignUp signs a volunteer up for a shift of the shelters in scope that hasn't started and has
+// room left. A volunteer can't be on two shifts at the same time.
+func (s *ShiftService) SignUp(shiftID, userID string, scope tenant.Scope) (*models.SignUp, error) {
+	shift, err := s.findShift(shiftID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.signUps.FindActive(shiftID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, models.ErrAlreadySignedUp
+	}
+
+	overlapping, err := s.signUps.HasOverlapping(userID, shift.StartsAt, shift.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if overlapping {
+		return nil, models.ErrOverlappingShift
+	}
+
+	signUp, err := models.NewSignUp(uuid.New().String(), shift, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// The shift may have filled up since it was loaded
+	saved, err := s.signUps.Save(signUp)
+	if err != nil {
+		return nil, err
+	}
+
+	if !saved {
+		return nil, models.ErrShiftFull
+	}
+
+	return signUp, nil
+}
+
+// CancelSignUp cancels a volunteer's sign-up for a shift of the shelters in scope, freeing their place
+func (s *ShiftService) CancelSignUp(shiftID, userID string, scope tenant.Scope) (*models.SignUp, error) {
+	shift, err := s.findShift(shiftID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	signUp, err := s.signUps.FindActive(shiftID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if signUp == nil {
+		return nil, models.ErrSignUpNotFound
+	}
+
+	if err := signUp.Cancel(shift, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.signUps.Update(signUp); err != nil {
+		return nil, err
+	}
+
+	return signUp, nil
+}
+
+// GetSignUpByID returns a sign-up for a shift of the shelters in scope by its ID
+func (s *ShiftService) GetSignUpByID(id string, scope tenant.Scope) (*models.SignUp, error) {
+	return s.signUps.FindByID(id, scope)
+}
+
+// GetSignUpsByShiftID returns the sign-ups of a shift of the shelters in scope, including cancelled ones
+func (s *ShiftService) GetSignUpsByShiftID(shiftID string, scope tenant.Scope) ([]*models.SignUp, error) {
+	if _, err := s.findShift(shiftID, scope); err != nil {
+		return nil, err
+	}
+
+	return s.signUps.FindByShiftID(shiftID)
+}
+
+// GetSignUpsByUserID returns the sign-ups of a volunteer, latest shift first
+func (s *ShiftService) GetSignUpsByUserID(userID string) ([]*models.SignUp, error) {
+	return s.signUps.FindByUserID(userID)
+}
+
+// CheckIn records a volunteer's arrival for their shift
+func (s *ShiftService) CheckIn(signUpID string) (*models.SignUp, error) {
+	signUp, shift, err := s.findSignUpWithShift(signUpID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := signUp.CheckIn(shift, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.signUps.Update(signUp); err != nil {
+		return nil, err
+	}
+
+	return signUp, nil
+}
+
+// CheckOut records when a volunteer left their shift: at, or now if at is nil
+func (s *ShiftService) CheckOut(signUpID string, at *time.Time) (*models.SignUp, error) {
+	signUp, err := s.signUps.FindByID(signUpID, tenant.AllShelters())
+	if err != nil {
+		return nil, err
+	}
+
+	if signUp == nil {
+		return nil, models.ErrSignUpNotFound
+	}
+
+	now := time.Now()
+	if at == nil {
+		at = &now
+	}
+
+	if err := signUp.CheckOut(*at, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.signUps.Update(signUp); err != nil {
+		return nil, err
+	}
+
+	return signUp, nil
+}
+
+// findShift returns a shift of the shelters in scope that exists
+func (s *ShiftService) findShift(id string, scope tenant.Scope) (*models.Shift, error) {
+	shift, err := s.shifts.FindByID(id, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if shift == nil {
+		return nil, models.ErrShiftNotFound
+	}
+
+	return shift, nil
+}
+
+// findSignUpWithShift returns a sign-up that exists and its shift
+func (s *ShiftService) findSignUpWithShift(id string) (*models.SignUp, *models.Shift, error) {
+	signUp, err := s.signUps.FindByID(id, tenant.AllShelters())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if signUp == nil {
+		return nil, nil, models.ErrSignUpNotFound
+	}
+
+	shift, err := s.findShift(signUp.ShiftID, tenant.AllShelters())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return signUp, shift, nil
+}
@@ -0,0 +1,189 @@
+package aplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/volunteers/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// fakeShiftRepository keeps shifts in memory, counting their places taken from the sign-ups
+type fakeShiftRepository struct {
+	shifts  map[string]*models.Shift
+	signUps *fakeSignUpRepository
+}
+
+func (r *fakeShiftRepository) Save(shift *models.Shift) error {
+	r.shifts[shift.ID] = shift
+	return nil
+}
+
+func (r *fakeShiftRepository) FindByID(id string, scope tenant.Scope) (*models.Shift, error) {
+	shift, ok := r.shifts[id]
+	if !ok || !scope.Allows(shift.ShelterID) {
+		return nil, nil
+	}
+	copied := *shift
+	copied.SignedUp = r.signUps.countActive(id)
+	return &copied, nil
+}
+
+func (r *fakeShiftRepository) FindBetween(from, to time.Time, scope tenant.Scope) ([]*models.Shift, error) {
+	return nil, nil
+}
+
+func (r *fakeShiftRepository) Update(shift *models.Shift) error {
+	r.shifts[shift.ID] = shift
+	return nil
+}
+
+func (r *fakeShiftRepository) Delete(id string, scope tenant.Scope) (bool, error) {
+	delete(r.shifts, id)
+	return true, nil
+}
+
+// fakeSignUpRepository keeps sign-ups in memory
+type fakeSignUpRepository struct {
+	shifts      *fakeShiftRepository
+	signUps     map[string]*models.SignUp
+	overlapping bool
+}
+
+func (r *fakeSignUpRepository) Save(signUp *models.SignUp) (bool, error) {
+	if r.countActive(signUp.ShiftID) >= r.shifts.shifts[signUp.ShiftID].Capacity {
+		return false, nil
+	}
+
+	r.signUps[signUp.ID] = signUp
+	return true, nil
+}
+
+// countActive counts the sign-ups of a shift that weren't cancelled
+func (r *fakeSignUpRepository) countActive(shiftID string) int {
+	count := 0
+	for _, signUp := range r.signUps {
+		if signUp.ShiftID == shiftID && signUp.CancelledAt == nil {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *fakeSignUpRepository) FindByID(id string, scope tenant.Scope) (*models.SignUp, error) {
+	return r.signUps[id], nil
+}
+
+func (r *fakeSignUpRepository) FindActive(shiftID, userID string) (*models.SignUp, error) {
+	for _, signUp := range r.signUps {
+		if signUp.ShiftID == shiftID && signUp.UserID == userID && signUp.CancelledAt == nil {
+			return signUp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeSignUpRepository) FindByShiftID(shiftID string) ([]*models.SignUp, error) {
+	return nil, nil
+}
+
+func (r *fakeSignUpRepository) FindByUserID(userID string) ([]*models.SignUp, error) {
+	return nil, nil
+}
+
+func (r *fakeSignUpRepository) HasOverlapping(userID string, startsAt, endsAt time.Time) (bool, error) {
+	return r.overlapping, nil
+}
+
+func (r *fakeSignUpRepository) Update(signUp *models.SignUp) error {
+	r.signUps[signUp.ID] = signUp
+	return nil
+}
+
+func newTestShiftService(capacity int) (*ShiftService, *fakeSignUpRepository) {
+	startsAt := time.Now().Add(time.Hour * 24)
+	shifts := &fakeShiftRepository{shifts: map[string]*models.Shift{
+		"shift-1": {ID: "shift-1", ShelterID: "shelter-1", Capacity: capacity, StartsAt: startsAt, EndsAt: startsAt.Add(time.Hour * 2)},
+	}}
+	signUps := &fakeSignUpRepository{shifts: shifts, signUps: map[string]*models.SignUp{}}
+	shifts.signUps = signUps
+
+	return NewShiftService(shifts, signUps), signUps
+}
+
+func TestSignUpFillsTheShift(t *testing.T) {
+	service, _ := newTestShiftService(1)
+	scope := tenant.Shelter("shelter-1")
+
+	if _, err := service.SignUp("shift-1", "volunteer-1", scope); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	if _, err := service.SignUp("shift-1", "volunteer-1", scope); err != models.ErrAlreadySignedUp {
+		t.Errorf("SignUp() twice error = %v, want %v", err, models.ErrAlreadySignedUp)
+	}
+
+	if _, err := service.SignUp("shift-1", "volunteer-2", scope); err != models.ErrShiftFull {
+		t.Errorf("SignUp() to a full shift error = %v, want %v", err, models.ErrShiftFull)
+	}
+
+	if _, err := service.SignUp("shift-1", "volunteer-2", tenant.Shelter("shelter-2")); err != models.ErrShiftNotFound {
+		t.Errorf("SignUp() to another shelter's shift error = %v, want %v", err, models.ErrShiftNotFound)
+	}
+}
+
+func TestSignUpRefusesOverlappingShifts(t *testing.T) {
+	service, signUps := newTestShiftService(3)
+	signUps.overlapping = true
+
+	if _, err := service.SignUp("shift-1", "volunteer-1", tenant.Shelter("shelter-1")); err != models.ErrOverlappingShift {
+		t.Errorf("SignUp() error = %v, want %v", err, models.ErrOverlappingShift)
+	}
+}
+
+func TestCancelSignUpFreesThePlace(t *testing.T) {
+	service, _ := newTestShiftService(1)
+	scope := tenant.Shelter("shelter-1")
+
+	if _, err := service.CancelSignUp("shift-1", "volunteer-1", scope); err != models.ErrSignUpNotFound {
+		t.Fatalf("CancelSignUp() without a sign-up error = %v, want %v", err, models.ErrSignUpNotFound)
+	}
+
+	if _, err := service.SignUp("shift-1", "volunteer-1", scope); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	signUp, err := service.CancelSignUp("shift-1", "volunteer-1", scope)
+	if err != nil {
+		t.Fatalf("CancelSignUp() error = %v", err)
+	}
+
+	if signUp.Status != models.SignUpCancelled {
+		t.Errorf("CancelSignUp() status = %q, want %q", signUp.Status, models.SignUpCancelled)
+	}
+
+	if _, err := service.SignUp("shift-1", "volunteer-1", scope); err != nil {
+		t.Errorf("SignUp() after cancelling error = %v, want the volunteer signed up again", err)
+	}
+}
+
+func TestCheckOutAtAGivenTime(t *testing.T) {
+	service, signUps := newTestShiftService(1)
+
+	checkedIn := time.Now().Add(-time.Hour * 3)
+	signUps.signUps["sign-up-1"] = &models.SignUp{ID: "sign-up-1", ShiftID: "shift-1", UserID: "volunteer-1", CheckedInAt: &checkedIn}
+
+	at := checkedIn.Add(time.Hour * 2)
+	signUp, err := service.CheckOut("sign-up-1", &at)
+	if err != nil {
+		t.Fatalf("CheckOut() error = %v", err)
+	}
+
+	if signUp.Hours != 2 || signUp.Status != models.SignUpCompleted {
+		t.Errorf("CheckOut() = %q with %v hours, want %q with 2 hours", signUp.Status, signUp.Hours, models.SignUpCompleted)
+	}
+
+	if _, err := service.CheckOut("sign-up-2", nil); err != models.ErrSignUpNotFound {
+		t.Errorf("CheckOut() of an unknown sign-up error = %v, want %v", err, models.ErrSignUpNotFound)
+	}
+}
@@ -0,0 +1,35 @@
+package aplication
+
+import (
+	"github.com/solrac97gr/petparadise/internal/volunteers/domain/models"
+	"github.com/solrac97gr/petparadise/internal/volunteers/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// ReportService implements the ReportService interface
+type ReportService struct {
+	repository ports.ReportRepository
+}
+
+// NewReportService creates a new ReportService instance
+func NewReportService(repository ports.ReportRepository) *ReportService {
+	return &ReportService{
+		repository: repository,
+	}
+}
+
+// GetHours returns the hours each volunteer worked at the shelters in scope in the range by day,
+// week or month
+func (s *ReportService) GetHours(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.VolunteerHours, error) {
+	if !interval.IsValid() {
+		return nil, models.ErrInvalidInterval
+	}
+
+	return s.repository.HoursByInterval(reportRange, interval, scope)
+}
+
+// GetVolunteerTotals returns the hours each volunteer worked at the shelters in scope over the
+// whole range
+func (s *ReportService) GetVolunteerTotals(reportRange *models.ReportRange, scope tenant.Scope) ([]*models.VolunteerTotal, error) {
+	return s.repository.HoursByVolunteer(reportRange, scope)
+}
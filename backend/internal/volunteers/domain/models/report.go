@@ -0,0 +1,94 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type Interval string
+
+var (
+	ErrInvalidInterval    = errors.New("invalid interval")
+	ErrInvalidReportRange = errors.New("report start date must be before its end date")
+)
+
+const (
+	IntervalDay   Interval = "day"
+	IntervalWeek  Interval = "week"
+	IntervalMonth Interval = "month"
+)
+
+var (
+	validIntervals = map[Interval]struct{}{
+		IntervalDay:   {},
+		IntervalWeek:  {},
+		IntervalMonth: {},
+	}
+)
+
+// ReportDateLayout is the layout of the dates used by reports
+const ReportDateLayout = "2006-01-02"
+
+// String converts the Interval to a string
+func (i Interval) String() string {
+	return string(i)
+}
+
+// IsValid checks if the interval is valid
+func (i Interval) IsValid() bool {
+	_, ok := validIntervals[i]
+	return ok
+}
+
+// ReportRange is the half-open time range [From, To) covered by a report
+type ReportRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// NewReportRange creates a new ReportRange instance
+func NewReportRange(from, to time.Time) (*ReportRange, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidReportRange
+	}
+
+	return &ReportRange{
+		From: from,
+		To:   to,
+	}, nil
+}
+
+// VolunteerHours totals the hours a volunteer worked in a day, week or month. Hours count
+// towards the period the volunteer checked in.
+type VolunteerHours struct {
+	PeriodStart string  `json:"period_start"`
+	UserID      string  `json:"user_id"`
+	Name        string  `json:"name"`
+	Shifts      int     `json:"shifts"`
+	Hours       float64 `json:"hours"`
+}
+
+// CSVRecord converts the volunteer hours into a CSV record
+func (h *VolunteerHours) CSVRecord() []string {
+	return []string{h.PeriodStart, h.UserID, h.Name, strconv.Itoa(h.Shifts), formatHours(h.Hours)}
+}
+
+// VolunteerTotal totals the hours a volunteer worked over a whole report range
+type VolunteerTotal struct {
+	UserID string  `json:"user_id"`
+	Name   string  `json:"name"`
+	Shifts int     `json:"shifts"`
+	Hours  float64 `json:"hours"`
+}
+
+// CSVRecord converts the volunteer total into a CSV record
+func (t *VolunteerTotal) CSVRecord() []string {
+	return []string{t.UserID, t.Name, strconv.Itoa(t.Shifts), formatHours(t.Hours)}
+}
+
+// formatHours formats hours with two decimals
+func formatHours(hours float64) string {
+	return fmt.Sprintf("%.2f", hours)
+}
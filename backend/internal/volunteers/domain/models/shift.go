@@ -0,0 +1,102 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrShiftNotFound        = errors.New("shift not found")
+	ErrInvalidTask          = errors.New("task is required and must be at most 100 characters")
+	ErrInvalidLocation      = errors.New("location is required and must be at most 100 characters")
+	ErrInvalidCapacity      = errors.New("capacity must be at least 1")
+	ErrInvalidShiftTime     = errors.New("a shift must end after it starts and last at most 24 hours")
+	ErrCapacityBelowSignUps = errors.New("capacity cannot be lower than the number of volunteers signed up")
+	ErrShiftHasHours        = errors.New("a shift with recorded hours cannot be deleted")
+	ErrShelterRequired      = errors.New("shelter ID is required")
+	ErrShelterNotFound      = errors.New("shelter not found")
+)
+
+const (
+	// MaxShiftLength is the longest a shift can last
+	MaxShiftLength = time.Hour * 24
+	maxTextLength  = 100
+)
+
+// Shift is a stretch of time volunteers can sign up for, to do a task at a location of a shelter.
+// Capacity is how many volunteers the shift needs.
+type Shift struct {
+	ID        string    `json:"id"`
+	ShelterID string    `json:"shelter_id"`
+	Task      string    `json:"task"`
+	Location  string    `json:"location"`
+	Capacity  int       `json:"capacity"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	SignedUp  int       `json:"signed_up"` // Not stored, counted from the sign-ups that weren't cancelled
+	CreatedBy string    `json:"created_by"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+}
+
+// NewShift creates a new Shift instance
+func NewShift(id, shelterID, task, location string, capacity int, startsAt, endsAt time.Time, createdBy string, now time.Time) (*Shift, error) {
+	if shelterID == "" {
+		return nil, ErrShelterRequired
+	}
+
+	shift := &Shift{
+		ID:        id,
+		ShelterID: shelterID,
+		CreatedBy: createdBy,
+		Created:   now,
+	}
+
+	if err := shift.Update(task, location, capacity, startsAt, endsAt, now); err != nil {
+		return nil, err
+	}
+
+	return shift, nil
+}
+
+// Update changes the task, location, capacity and time of the shift. The capacity can't drop
+// below the number of volunteers already signed up.
+func (s *Shift) Update(task, location string, capacity int, startsAt, endsAt time.Time, now time.Time) error {
+	task = strings.TrimSpace(task)
+	if task == "" || utf8.RuneCountInString(task) > maxTextLength {
+		return ErrInvalidTask
+	}
+
+	location = strings.TrimSpace(location)
+	if location == "" || utf8.RuneCountInString(location) > maxTextLength {
+		return ErrInvalidLocation
+	}
+
+	if capacity < 1 {
+		return ErrInvalidCapacity
+	}
+
+	if capacity < s.SignedUp {
+		return ErrCapacityBelowSignUps
+	}
+
+	if !endsAt.After(startsAt) || endsAt.Sub(startsAt) > MaxShiftLength {
+		return ErrInvalidShiftTime
+	}
+
+	s.Task = task
+	s.Location = location
+	s.Capacity = capacity
+	s.StartsAt = startsAt
+	s.EndsAt = endsAt
+	s.Updated = now
+
+	return nil
+}
+
+// Available returns how many more volunteers can sign up
+func (s *Shift) Available() int {
+	return max(s.Capacity-s.SignedUp, 0)
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewShift(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	startsAt := now.Add(time.Hour * 24)
+
+	tests := []struct {
+		name      string
+		shelterID string
+		task      string
+		location  string
+		capacity  int
+		endsAt    time.Time
+		wantErr   error
+	}{
+		{name: "Valid", shelterID: "shelter-1", task: " Dog walking ", location: "Park", capacity: 3, endsAt: startsAt.Add(time.Hour * 2)},
+		{name: "A whole day", shelterID: "shelter-1", task: "Open day", location: "Shelter", capacity: 10, endsAt: startsAt.Add(MaxShiftLength)},
+		{name: "No shelter", task: "Dog walking", location: "Park", capacity: 3, endsAt: startsAt.Add(time.Hour), wantErr: ErrShelterRequired},
+		{name: "No task", shelterID: "shelter-1", task: " ", location: "Park", capacity: 3, endsAt: startsAt.Add(time.Hour), wantErr: ErrInvalidTask},
+		{name: "Long location", shelterID: "shelter-1", task: "Dog walking", location: strings.Repeat("a", 101), capacity: 3, endsAt: startsAt.Add(time.Hour), wantErr: ErrInvalidLocation},
+		{name: "No capacity", shelterID: "shelter-1", task: "Dog walking", location: "Park", capacity: 0, endsAt: startsAt.Add(time.Hour), wantErr: ErrInvalidCapacity},
+		{name: "Ends when it starts", shelterID: "shelter-1", task: "Dog walking", location: "Park", capacity: 3, endsAt: startsAt, wantErr: ErrInvalidShiftTime},
+		{name: "Longer than a day", shelterID: "shelter-1", task: "Dog walking", location: "Park", capacity: 3, endsAt: startsAt.Add(MaxShiftLength + time.Minute), wantErr: ErrInvalidShiftTime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shift, err := NewShift("shift-1", tt.shelterID, tt.task, tt.location, tt.capacity, startsAt, tt.endsAt, "coordinator-1", now)
+			if err != tt.wantErr {
+				t.Fatalf("NewShift() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && shift.Task != strings.TrimSpace(tt.task) {
+				t.Errorf("NewShift() task = %q, want it trimmed", shift.Task)
+			}
+		})
+	}
+}
+
+func TestShiftCapacity(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	shift, err := NewShift("shift-1", "shelter-1", "Feeding", "Kennels", 3, now.Add(time.Hour), now.Add(time.Hour*3), "coordinator-1", now)
+	if err != nil {
+		t.Fatalf("NewShift() error = %v", err)
+	}
+
+	shift.SignedUp = 2
+	if got := shift.Available(); got != 1 {
+		t.Errorf("Available() = %d, want 1", got)
+	}
+
+	if err := shift.Update(shift.Task, shift.Location, 1, shift.StartsAt, shift.EndsAt, now); err != ErrCapacityBelowSignUps {
+		t.Errorf("Update() below the sign-ups error = %v, want %v", err, ErrCapacityBelowSignUps)
+	}
+
+	if err := shift.Update(shift.Task, shift.Location, 2, shift.StartsAt, shift.EndsAt, now); err != nil {
+		t.Fatalf("Update() to the sign-ups error = %v", err)
+	}
+
+	if got := shift.Available(); got != 0 {
+		t.Errorf("Available() of a full shift = %d, want 0", got)
+	}
+
+	shift.SignedUp = 5
+	if got := shift.Available(); got != 0 {
+		t.Errorf("Available() of an overbooked shift = %d, want 0", got)
+	}
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	ErrSignUpNotFound      = errors.New("sign-up not found")
+	ErrNotVolunteer        = errors.New("only volunteers can sign up for shifts")
+	ErrShiftFull           = errors.New("shift is full")
+	ErrShiftStarted        = errors.New("shift has already started")
+	ErrAlreadySignedUp     = errors.New("already signed up for this shift")
+	ErrOverlappingShift    = errors.New("already signed up for another shift at that time")
+	ErrSignUpCancelled     = errors.New("sign-up has been cancelled")
+	ErrCheckInNotOpen      = errors.New("check-in opens 30 minutes before the shift starts and closes when it ends")
+	ErrAlreadyCheckedIn    = errors.New("already checked in")
+	ErrNotCheckedIn        = errors.New("not checked in")
+	ErrAlreadyCheckedOut   = errors.New("already checked out")
+	ErrInvalidCheckOutTime = errors.New("check-out time must be after the check-in time and not in the future")
+)
+
+// CheckInWindow is how long before a shift starts volunteers can check in
+const CheckInWindow = time.Minute * 30
+
+// SignUpStatus is where a sign-up stands
+type SignUpStatus string
+
+const (
+	SignUpConfirmed SignUpStatus = "signed_up"
+	SignUpCancelled SignUpStatus = "cancelled"
+	SignUpCheckedIn SignUpStatus = "checked_in"
+	SignUpCompleted SignUpStatus = "completed"
+)
+
+// String converts the SignUpStatus to a string
+func (s SignUpStatus) String() string {
+	return string(s)
+}
+
+// SignUp is a volunteer's place on a shift. Checking in and out records the hours they worked.
+type SignUp struct {
+	ID           string       `json:"id"`
+	ShiftID      string       `json:"shift_id"`
+	UserID       string       `json:"user_id"`
+	Created      time.Time    `json:"created"`
+	CancelledAt  *time.Time   `json:"cancelled_at,omitempty"`
+	CheckedInAt  *time.Time   `json:"checked_in_at,omitempty"`
+	CheckedOutAt *time.Time   `json:"checked_out_at,omitempty"`
+	Status       SignUpStatus `json:"status"` // Not stored, set by Refresh when the sign-up is loaded
+	Hours        float64      `json:"hours"`  // Not stored, set by Refresh from the check-in and check-out times
+}
+
+// NewSignUp creates a new SignUp on a shift that hasn't started and has room left
+func NewSignUp(id string, shift *Shift, userID string, now time.Time) (*SignUp, error) {
+	if !now.Before(shift.StartsAt) {
+		return nil, ErrShiftStarted
+	}
+
+	if shift.Available() == 0 {
+		return nil, ErrShiftFull
+	}
+
+	signUp := &SignUp{
+		ID:      id,
+		ShiftID: shift.ID,
+		UserID:  userID,
+		Created: now,
+	}
+	signUp.Refresh()
+
+	return signUp, nil
+}
+
+// Cancel cancels the sign-up, which is only possible before the shift starts
+func (s *SignUp) Cancel(shift *Shift, now time.Time) error {
+	if s.CancelledAt != nil {
+		return ErrSignUpCancelled
+	}
+
+	if s.CheckedInAt != nil {
+		return ErrAlreadyCheckedIn
+	}
+
+	if !now.Before(shift.StartsAt) {
+		return ErrShiftStarted
+	}
+
+	s.CancelledAt = &now
+	s.Refresh()
+
+	return nil
+}
+
+// CheckIn records the volunteer's arrival, from CheckInWindow before the shift starts until it ends
+func (s *SignUp) CheckIn(shift *Shift, now time.Time) error {
+	if s.CancelledAt != nil {
+		return ErrSignUpCancelled
+	}
+
+	if s.CheckedInAt != nil {
+		return ErrAlreadyCheckedIn
+	}
+
+	if now.Before(shift.StartsAt.Add(-CheckInWindow)) || !now.Before(shift.EndsAt) {
+		return ErrCheckInNotOpen
+	}
+
+	s.CheckedInAt = &now
+	s.Refresh()
+
+	return nil
+}
+
+// CheckOut records when the volunteer left, which is now unless a coordinator records it for a
+// volunteer who forgot to check out
+func (s *SignUp) CheckOut(at, now time.Time) error {
+	if s.CancelledAt != nil {
+		return ErrSignUpCancelled
+	}
+
+	if s.CheckedInAt == nil {
+		return ErrNotCheckedIn
+	}
+
+	if s.CheckedOutAt != nil {
+		return ErrAlreadyCheckedOut
+	}
+
+	if !at.After(*s.CheckedInAt) || at.After(now) {
+		return ErrInvalidCheckOutTime
+	}
+
+	s.CheckedOutAt = &at
+	s.Refresh()
+
+	return nil
+}
+
+// Refresh sets the status and the hours worked from the recorded times
+func (s *SignUp) Refresh() {
+	s.Hours = 0
+
+	switch {
+	case s.CancelledAt != nil:
+		s.Status = SignUpCancelled
+	case s.CheckedOutAt != nil:
+		s.Status = SignUpCompleted
+		s.Hours = roundHours(s.CheckedOutAt.Sub(*s.CheckedInAt))
+	case s.CheckedInAt != nil:
+		s.Status = SignUpCheckedIn
+	default:
+		s.Status = SignUpConfirmed
+	}
+}
+
+// roundHours converts a duration to hours, rounded to two decimals
+func roundHours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// testShift returns a shift from 10:00 to 12:00 with room for capacity volunteers
+func testShift(capacity, signedUp int) *Shift {
+	startsAt := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	return &Shift{
+		ID:       "shift-1",
+		Capacity: capacity,
+		SignedUp: signedUp,
+		StartsAt: startsAt,
+		EndsAt:   startsAt.Add(time.Hour * 2),
+	}
+}
+
+func TestNewSignUp(t *testing.T) {
+	shift := testShift(2, 1)
+
+	tests := []struct {
+		name    string
+		shift   *Shift
+		now     time.Time
+		wantErr error
+	}{
+		{name: "Room left", shift: shift, now: shift.StartsAt.Add(-time.Minute)},
+		{name: "Full", shift: testShift(2, 2), now: shift.StartsAt.Add(-time.Hour), wantErr: ErrShiftFull},
+		{name: "Started", shift: shift, now: shift.StartsAt, wantErr: ErrShiftStarted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signUp, err := NewSignUp("sign-up-1", tt.shift, "volunteer-1", tt.now)
+			if err != tt.wantErr {
+				t.Fatalf("NewSignUp() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && signUp.Status != SignUpConfirmed {
+				t.Errorf("NewSignUp() status = %q, want %q", signUp.Status, SignUpConfirmed)
+			}
+		})
+	}
+}
+
+func TestSignUpCheckInWindow(t *testing.T) {
+	shift := testShift(1, 0)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantErr error
+	}{
+		{name: "Too early", now: shift.StartsAt.Add(-CheckInWindow - time.Second), wantErr: ErrCheckInNotOpen},
+		{name: "When check-in opens", now: shift.StartsAt.Add(-CheckInWindow)},
+		{name: "Late", now: shift.EndsAt.Add(-time.Minute)},
+		{name: "After the shift", now: shift.EndsAt, wantErr: ErrCheckInNotOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signUp := &SignUp{ID: "sign-up-1", ShiftID: shift.ID}
+
+			if err := signUp.CheckIn(shift, tt.now); err != tt.wantErr {
+				t.Fatalf("CheckIn() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && signUp.Status != SignUpCheckedIn {
+				t.Errorf("CheckIn() status = %q, want %q", signUp.Status, SignUpCheckedIn)
+			}
+		})
+	}
+}
+
+func TestSignUpHours(t *testing.T) {
+	shift := testShift(1, 0)
+	signUp := &SignUp{ID: "sign-up-1", ShiftID: shift.ID}
+
+	if err := signUp.CheckOut(shift.EndsAt, shift.EndsAt); err != ErrNotCheckedIn {
+		t.Fatalf("CheckOut() before checking in error = %v, want %v", err, ErrNotCheckedIn)
+	}
+
+	checkedIn := shift.StartsAt.Add(-time.Minute * 5)
+	if err := signUp.CheckIn(shift, checkedIn); err != nil {
+		t.Fatalf("CheckIn() error = %v", err)
+	}
+
+	if err := signUp.Cancel(shift, checkedIn); err != ErrAlreadyCheckedIn {
+		t.Errorf("Cancel() after checking in error = %v, want %v", err, ErrAlreadyCheckedIn)
+	}
+
+	if err := signUp.CheckOut(checkedIn, shift.EndsAt); err != ErrInvalidCheckOutTime {
+		t.Errorf("CheckOut() at the check-in time error = %v, want %v", err, ErrInvalidCheckOutTime)
+	}
+
+	checkedOut := checkedIn.Add(time.Hour*2 + time.Minute*20)
+	if err := signUp.CheckOut(checkedOut, checkedOut.Add(-time.Minute)); err != ErrInvalidCheckOutTime {
+		t.Errorf("CheckOut() in the future error = %v, want %v", err, ErrInvalidCheckOutTime)
+	}
+
+	if err := signUp.CheckOut(checkedOut, checkedOut); err != nil {
+		t.Fatalf("CheckOut() error = %v", err)
+	}
+
+	if signUp.Status != SignUpCompleted || signUp.Hours != 2.33 {
+		t.Errorf("CheckOut() = %q with %v hours, want %q with 2.33 hours", signUp.Status, signUp.Hours, SignUpCompleted)
+	}
+
+	if err := signUp.CheckOut(checkedOut, checkedOut); err != ErrAlreadyCheckedOut {
+		t.Errorf("CheckOut() twice error = %v, want %v", err, ErrAlreadyCheckedOut)
+	}
+}
+
+func TestSignUpCancel(t *testing.T) {
+	shift := testShift(1, 1)
+	signUp := &SignUp{ID: "sign-up-1", ShiftID: shift.ID}
+
+	if err := signUp.Cancel(shift, shift.StartsAt); err != ErrShiftStarted {
+		t.Fatalf("Cancel() once the shift started error = %v, want %v", err, ErrShiftStarted)
+	}
+
+	if err := signUp.Cancel(shift, shift.StartsAt.Add(-time.Hour)); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+
+	if signUp.Status != SignUpCancelled {
+		t.Errorf("Cancel() status = %q, want %q", signUp.Status, SignUpCancelled)
+	}
+
+	if err := signUp.CheckIn(shift, shift.StartsAt); err != ErrSignUpCancelled {
+		t.Errorf("CheckIn() of a cancelled sign-up error = %v, want %v", err, ErrSignUpCancelled)
+	}
+}
@@ -0,0 +1,52 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/volunteers/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+type ShiftRepository interface {
+	Save(shift *models.Shift) error
+	FindByID(id string, scope tenant.Scope) (*models.Shift, error)
+	FindBetween(from, to time.Time, scope tenant.Scope) ([]*models.Shift, error)
+	Update(shift *models.Shift) error
+	Delete(id string, scope tenant.Scope) (bool, error)
+}
+
+type SignUpRepository interface {
+	// Save saves a sign-up if the shift still has room, reporting whether it had
+	Save(signUp *models.SignUp) (bool, error)
+	FindByID(id string, scope tenant.Scope) (*models.SignUp, error)
+	FindActive(shiftID, userID string) (*models.SignUp, error)
+	FindByShiftID(shiftID string) ([]*models.SignUp, error)
+	FindByUserID(userID string) ([]*models.SignUp, error)
+	HasOverlapping(userID string, startsAt, endsAt time.Time) (bool, error)
+	Update(signUp *models.SignUp) error
+}
+
+type ReportRepository interface {
+	HoursByInterval(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.VolunteerHours, error)
+	HoursByVolunteer(reportRange *models.ReportRange, scope tenant.Scope) ([]*models.VolunteerTotal, error)
+}
+
+type ShiftService interface {
+	CreateShift(shelterID, task, location string, capacity int, startsAt, endsAt time.Time, createdBy string) (*models.Shift, error)
+	GetShiftByID(id string, scope tenant.Scope) (*models.Shift, error)
+	GetShifts(from, to time.Time, scope tenant.Scope) ([]*models.Shift, error)
+	UpdateShift(id, task, location string, capacity int, startsAt, endsAt time.Time, scope tenant.Scope) (*models.Shift, error)
+	DeleteShift(id string, scope tenant.Scope) error
+	SignUp(shiftID, userID string, scope tenant.Scope) (*models.SignUp, error)
+	CancelSignUp(shiftID, userID string, scope tenant.Scope) (*models.SignUp, error)
+	GetSignUpByID(id string, scope tenant.Scope) (*models.SignUp, error)
+	GetSignUpsByShiftID(shiftID string, scope tenant.Scope) ([]*models.SignUp, error)
+	GetSignUpsByUserID(userID string) ([]*models.SignUp, error)
+	CheckIn(signUpID string) (*models.SignUp, error)
+	CheckOut(signUpID string, at *time.Time) (*models.SignUp, error)
+}
+
+type ReportService interface {
+	GetHours(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.VolunteerHours, error)
+	GetVolunteerTotals(reportRange *models.ReportRange, scope tenant.Scope) ([]*models.VolunteerTotal, error)
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// ShiftHandler interface defines methods for volunteer shift and sign-up HTTP handlers
+type ShiftHandler interface {
+	CreateShift(c *fiber.Ctx) error
+	GetShifts(c *fiber.Ctx) error
+	GetShiftByID(c *fiber.Ctx) error
+	UpdateShift(c *fiber.Ctx) error
+	DeleteShift(c *fiber.Ctx) error
+	GetShiftSignUps(c *fiber.Ctx) error
+	SignUp(c *fiber.Ctx) error
+	CancelSignUp(c *fiber.Ctx) error
+	GetMySignUps(c *fiber.Ctx) error
+	CheckIn(c *fiber.Ctx) error
+	CheckOut(c *fiber.Ctx) error
+}
+
+// ReportHandler interface defines methods for volunteer hours report HTTP handlers
+type ReportHandler interface {
+	GetHours(c *fiber.Ctx) error
+	GetVolunteerTotals(c *fiber.Ctx) error
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/volunteers/domain/models"
+	"github.com/solrac97gr/petparadise/internal/volunteers/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/daterange"
+)
+
+// CSV headers of the report exports
+var (
+	volunteerHoursHeader = []string{"period_start", "user_id", "name", "shifts", "hours"}
+	volunteerTotalHeader = []string{"user_id", "name", "shifts", "hours"}
+)
+
+// csvRecorder is implemented by the report rows that can be exported as CSV
+type csvRecorder interface {
+	CSVRecord() []string
+}
+
+type reportHandler struct {
+	service ports.ReportService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(service ports.ReportService) ReportHandler {
+	return &reportHandler{
+		service: service,
+	}
+}
+
+// GetHours handles getting the hours each volunteer worked in a date range by day, week or month
+func (h *reportHandler) GetHours(c *fiber.Ctx) error {
+	reportRange, err := parseReportRange(c)
+	if err != nil {
+		return reportErrorResponse(c, err)
+	}
+
+	interval := models.Interval(c.Query("interval", models.IntervalMonth.String()))
+
+	hours, err := h.service.GetHours(reportRange, interval, auth.ShelterScope(c))
+	if err != nil {
+		return reportErrorResponse(c, err)
+	}
+
+	if isCSVRequested(c) {
+		return sendCSV(c, "volunteer-hours", volunteerHoursHeader, hours)
+	}
+
+	return c.JSON(hours)
+}
+
+// GetVolunteerTotals handles getting the hours each volunteer worked over a whole date range
+func (h *reportHandler) GetVolunteerTotals(c *fiber.Ctx) error {
+	reportRange, err := parseReportRange(c)
+	if err != nil {
+		return reportErrorResponse(c, err)
+	}
+
+	totals, err := h.service.GetVolunteerTotals(reportRange, auth.ShelterScope(c))
+	if err != nil {
+		return reportErrorResponse(c, err)
+	}
+
+	if isCSVRequested(c) {
+		return sendCSV(c, "volunteer-totals", volunteerTotalHeader, totals)
+	}
+
+	return c.JSON(totals)
+}
+
+// parseReportRange reads the inclusive from and to dates (YYYY-MM-DD) of a report.
+// The range defaults to the year ending today.
+func parseReportRange(c *fiber.Ctx) (*models.ReportRange, error) {
+	today := daterange.Today()
+	from, to, err := daterange.Parse(c.Query("from"), c.Query("to"), today.AddDate(-1, 0, 1), today)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", models.ErrInvalidReportRange, err)
+	}
+
+	return models.NewReportRange(from, to)
+}
+
+// isCSVRequested checks if the client asked for a CSV export
+func isCSVRequested(c *fiber.Ctx) bool {
+	return c.Query("format") == "csv"
+}
+
+// sendCSV writes the report rows as a CSV attachment
+func sendCSV[T csvRecorder](c *fiber.Ctx, name string, header []string, rows []T) error {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if err := writer.Write(row.CSVRecord()); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+
+	return c.Send(buf.Bytes())
+}
+
+// reportErrorResponse maps report errors to HTTP responses
+func reportErrorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, models.ErrInvalidReportRange) || errors.Is(err, models.ErrInvalidInterval) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
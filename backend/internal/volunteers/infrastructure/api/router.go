@@ -0,0 +1,50 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/volunteers/aplication"
+	"github.com/solrac97gr/petparadise/internal/volunteers/infrastructure/repository"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+)
+
+// SetupVolunteerRoutes sets up all volunteer shift and hours routes
+func SetupVolunteerRoutes(router fiber.Router, db *sqlx.DB) {
+	// Initialize repositories
+	shiftRepo := repository.NewPostgresShiftRepository(db)
+	signUpRepo := repository.NewPostgresSignUpRepository(db)
+	reportRepo := repository.NewPostgresReportRepository(db)
+
+	// Initialize services
+	shiftService := aplication.NewShiftService(shiftRepo, signUpRepo)
+	reportService := aplication.NewReportService(reportRepo)
+
+	// Initialize handlers
+	shiftHandler := NewShiftHandler(shiftService)
+	reportHandler := NewReportHandler(reportService)
+
+	// All volunteer routes require authentication
+	protected := router.Use(auth.Protected())
+
+	// Report routes - add format=csv to export
+	canManage := auth.RequirePermission(models.PermissionVolunteersManage)
+	protected.Get("/reports/hours", canManage, reportHandler.GetHours)
+	protected.Get("/reports/volunteers", canManage, reportHandler.GetVolunteerTotals)
+
+	// Sign-up routes - volunteers check in and out of their own shifts, coordinators of anyone's
+	protected.Get("/sign-ups/mine", shiftHandler.GetMySignUps)
+	protected.Post("/sign-ups/:signUpId/check-in", shiftHandler.CheckIn)
+	protected.Post("/sign-ups/:signUpId/check-out", shiftHandler.CheckOut)
+
+	// Shift routes - volunteers see and sign up for the shifts of their own shelter, and
+	// coordinators schedule them
+	protected.Get("/shifts", shiftHandler.GetShifts)
+	protected.Get("/shifts/:shiftId", shiftHandler.GetShiftByID)
+	protected.Post("/shifts/:shiftId/sign-up", shiftHandler.SignUp)
+	protected.Delete("/shifts/:shiftId/sign-up", shiftHandler.CancelSignUp)
+	protected.Post("/shifts", canManage, shiftHandler.CreateShift)
+	protected.Put("/shifts/:shiftId", canManage, shiftHandler.UpdateShift)
+	protected.Delete("/shifts/:shiftId", canManage, shiftHandler.DeleteShift)
+	protected.Get("/shifts/:shiftId/sign-ups", canManage, shiftHandler.GetShiftSignUps)
+}
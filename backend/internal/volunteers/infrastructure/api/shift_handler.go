@@ -0,0 +1,365 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	userModels "github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/internal/volunteers/domain/models"
+	"github.com/solrac97gr/petparadise/internal/volunteers/domain/ports"
+	"github.com/solrac97gr/petparadise/pkg/audit"
+	"github.com/solrac97gr/petparadise/pkg/auth"
+	"github.com/solrac97gr/petparadise/pkg/daterange"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// defaultShiftListDays is how many days of shifts are listed when no range is given
+const defaultShiftListDays = 28
+
+// shiftRequest is the body of the requests creating and updating shifts
+type shiftRequest struct {
+	ShelterID string    `json:"shelter_id"` // Only read on creation, a shift can't move to another shelter
+	Task      string    `json:"task"`
+	Location  string    `json:"location"`
+	Capacity  int       `json:"capacity"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+}
+
+type shiftHandler struct {
+	service ports.ShiftService
+}
+
+// NewShiftHandler creates a new shift handler
+func NewShiftHandler(service ports.ShiftService) ShiftHandler {
+	return &shiftHandler{
+		service: service,
+	}
+}
+
+// CreateShift handles scheduling a new shift
+func (h *shiftHandler) CreateShift(c *fiber.Ctx) error {
+	var req shiftRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Coordinators schedule shifts at their own shelter, platform admins at the one they pick
+	shelterID := auth.RecordShelter(c, req.ShelterID)
+	if shelterID == "" {
+		return shiftErrorResponse(c, models.ErrShelterRequired)
+	}
+
+	createdBy, _ := c.Locals("userID").(string)
+
+	shift, err := h.service.CreateShift(shelterID, req.Task, req.Location, req.Capacity, req.StartsAt, req.EndsAt, createdBy)
+	if err != nil {
+		return shiftErrorResponse(c, err)
+	}
+
+	audit.Record(c, "shift.create", "shift", shift.ID, nil, shift)
+
+	return c.Status(fiber.StatusCreated).JSON(shift)
+}
+
+// GetShifts handles listing the shifts starting between the from and to dates (YYYY-MM-DD,
+// inclusive), soonest first. The range defaults to the next four weeks.
+func (h *shiftHandler) GetShifts(c *fiber.Ctx) error {
+	today := daterange.Today()
+	from, to, err := daterange.Parse(c.Query("from"), c.Query("to"), today, today.AddDate(0, 0, defaultShiftListDays-1))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	shifts, err := h.service.GetShifts(from, to, auth.ShelterScope(c))
+	if err != nil {
+		return shiftErrorResponse(c, err)
+	}
+
+	return c.JSON(shifts)
+}
+
+// GetShiftByID handles getting a single shift by ID
+func (h *shiftHandler) GetShiftByID(c *fiber.Ctx) error {
+	id := c.Params("shiftId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	shift, err := h.service.GetShiftByID(id, auth.ShelterScope(c))
+	if err != nil {
+		return shiftErrorResponse(c, err)
+	}
+
+	if shift == nil {
+		return shiftErrorResponse(c, models.ErrShiftNotFound)
+	}
+
+	return c.JSON(shift)
+}
+
+// UpdateShift handles changing the task, location, capacity and time of a shift
+func (h *shiftHandler) UpdateShift(c *fiber.Ctx) error {
+	id := c.Params("shiftId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	var req shiftRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	scope := auth.ShelterScope(c)
+
+	// Kept for the audit log
+	before, err := h.service.GetShiftByID(id, scope)
+	if err != nil {
+		return shiftErrorResponse(c, err)
+	}
+
+	shift, err := h.service.UpdateShift(id, req.Task, req.Location, req.Capacity, req.StartsAt, req.EndsAt, scope)
+	if err != nil {
+		return shiftErrorResponse(c, err)
+	}
+
+	audit.Record(c, "shift.update", "shift", shift.ID, before, shift)
+
+	return c.JSON(shift)
+}
+
+// DeleteShift handles deleting a shift that no volunteer has checked in to
+func (h *shiftHandler) DeleteShift(c *fiber.Ctx) error {
+	id := c.Params("shiftId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	scope := auth.ShelterScope(c)
+
+	// Kept for the audit log
+	before, err := h.service.GetShiftByID(id, scope)
+	if err != nil {
+		return shiftErrorResponse(c, err)
+	}
+
+	if err := h.service.DeleteShift(id, scope); err != nil {
+		return shiftErrorResponse(c, err)
+	}
+
+	audit.Record(c, "shift.delete", "shift", id, before, nil)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetShiftSignUps handles listing the sign-ups of a shift
+func (h *shiftHandler) GetShiftSignUps(c *fiber.Ctx) error {
+	id := c.Params("shiftId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	signUps, err := h.service.GetSignUpsByShiftID(id, auth.ShelterScope(c))
+	if err != nil {
+		return shiftErrorResponse(c, err)
+	}
+
+	return c.JSON(signUps)
+}
+
+// SignUp handles a volunteer signing up for a shift
+func (h *shiftHandler) SignUp(c *fiber.Ctx) error {
+	id := c.Params("shiftId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	if role, _ := c.Locals("role").(userModels.Role); !role.IsEquals(userModels.RoleVolunteer) {
+		return shiftErrorResponse(c, models.ErrNotVolunteer)
+	}
+
+	signUp, err := h.service.SignUp(id, c.Locals("userID").(string), auth.ShelterScope(c))
+	if err != nil {
+		return shiftErrorResponse(c, err)
+	}
+
+	audit.Record(c, "sign_up.create", "sign_up", signUp.ID, nil, signUp)
+
+	return c.Status(fiber.StatusCreated).JSON(signUp)
+}
+
+// CancelSignUp handles a volunteer cancelling their sign-up for a shift
+func (h *shiftHandler) CancelSignUp(c *fiber.Ctx) error {
+	id := c.Params("shiftId")
+	if id == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	// Volunteers cancel their own sign-ups, whichever shelter the shift is at
+	userID := c.Locals("userID").(string)
+	signUp, err := h.service.CancelSignUp(id, userID, auth.OwnerShelterScope(c, userID))
+	if err != nil {
+		return shiftErrorResponse(c, err)
+	}
+
+	audit.Record(c, "sign_up.cancel", "sign_up", signUp.ID,
+		map[string]any{"status": models.SignUpConfirmed}, map[string]any{"status": signUp.Status, "cancelled_at": signUp.CancelledAt})
+
+	return c.JSON(signUp)
+}
+
+// GetMySignUps handles getting the authenticated volunteer's sign-ups with the hours they worked
+func (h *shiftHandler) GetMySignUps(c *fiber.Ctx) error {
+	signUps, err := h.service.GetSignUpsByUserID(c.Locals("userID").(string))
+	if err != nil {
+		return shiftErrorResponse(c, err)
+	}
+
+	return c.JSON(signUps)
+}
+
+// CheckIn handles recording a volunteer's arrival, by the volunteer or a coordinator
+func (h *shiftHandler) CheckIn(c *fiber.Ctx) error {
+	before, allowed, err := h.findAccessibleSignUp(c)
+	if !allowed {
+		return err
+	}
+
+	signUp, err := h.service.CheckIn(before.ID)
+	if err != nil {
+		return shiftErrorResponse(c, err)
+	}
+
+	audit.Record(c, "sign_up.check_in", "sign_up", signUp.ID, before, signUp)
+
+	return c.JSON(signUp)
+}
+
+// CheckOut handles recording when a volunteer left, by the volunteer or a coordinator. Coordinators
+// can give the time a volunteer who forgot to check out left.
+func (h *shiftHandler) CheckOut(c *fiber.Ctx) error {
+	type checkOutRequest struct {
+		CheckedOutAt *time.Time `json:"checked_out_at"` // Leave empty for now
+	}
+
+	var req checkOutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	before, allowed, err := h.findAccessibleSignUp(c)
+	if !allowed {
+		return err
+	}
+
+	if req.CheckedOutAt != nil {
+		if canManage, err := auth.Allowed(c, userModels.PermissionVolunteersManage); err != nil || !canManage {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Only coordinators can set the check-out time",
+			})
+		}
+	}
+
+	signUp, err := h.service.CheckOut(before.ID, req.CheckedOutAt)
+	if err != nil {
+		return shiftErrorResponse(c, err)
+	}
+
+	audit.Record(c, "sign_up.check_out", "sign_up", signUp.ID, before, signUp)
+
+	return c.JSON(signUp)
+}
+
+// findAccessibleSignUp finds the sign-up of the request, which must belong to the requesting
+// volunteer unless they coordinate the volunteers of the shift's shelter. When it returns false,
+// the error response has been sent.
+func (h *shiftHandler) findAccessibleSignUp(c *fiber.Ctx) (*models.SignUp, bool, error) {
+	id := c.Params("signUpId")
+	if id == "" {
+		return nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID is required",
+		})
+	}
+
+	signUp, err := h.service.GetSignUpByID(id, tenant.AllShelters())
+	if err != nil {
+		return nil, false, shiftErrorResponse(c, err)
+	}
+
+	if signUp == nil {
+		return nil, false, shiftErrorResponse(c, models.ErrSignUpNotFound)
+	}
+
+	if requestingUserID, _ := c.Locals("userID").(string); requestingUserID == signUp.UserID {
+		return signUp, true, nil
+	}
+
+	if canManage, err := auth.Allowed(c, userModels.PermissionVolunteersManage); err != nil || !canManage {
+		return nil, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Insufficient permissions to access this sign-up",
+		})
+	}
+
+	// Sign-ups for the shifts of other shelters are hidden from coordinators
+	signUp, err = h.service.GetSignUpByID(id, auth.ShelterScope(c))
+	if err != nil {
+		return nil, false, shiftErrorResponse(c, err)
+	}
+
+	if signUp == nil {
+		return nil, false, shiftErrorResponse(c, models.ErrSignUpNotFound)
+	}
+
+	return signUp, true, nil
+}
+
+// shiftErrorResponse maps shift and sign-up errors to HTTP responses
+func shiftErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case models.ErrInvalidTask, models.ErrInvalidLocation, models.ErrInvalidCapacity, models.ErrInvalidShiftTime,
+		models.ErrInvalidCheckOutTime, models.ErrShelterRequired, models.ErrShelterNotFound:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrNotVolunteer:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrShiftNotFound, models.ErrSignUpNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case models.ErrCapacityBelowSignUps, models.ErrShiftHasHours, models.ErrShiftFull, models.ErrShiftStarted,
+		models.ErrAlreadySignedUp, models.ErrOverlappingShift, models.ErrSignUpCancelled, models.ErrCheckInNotOpen,
+		models.ErrAlreadyCheckedIn, models.ErrNotCheckedIn, models.ErrAlreadyCheckedOut:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,33 @@
+-- Volunteer shifts and the volunteers signed up for them. Hours are recorded by checking in
+-- and out, and are kept for grant reports, so volunteers can't be removed along with them.
+CREATE TABLE IF NOT EXISTS shifts (
+    id UUID PRIMARY KEY,
+    task VARCHAR(100) NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    capacity INT NOT NULL CHECK (capacity > 0),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    created_by UUID NOT NULL,
+    created TIMESTAMP NOT NULL,
+    updated TIMESTAMP NOT NULL,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shifts_starts_at ON shifts(starts_at);
+
+CREATE TABLE IF NOT EXISTS shift_sign_ups (
+    id UUID PRIMARY KEY,
+    shift_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created TIMESTAMP NOT NULL,
+    cancelled_at TIMESTAMP,
+    checked_in_at TIMESTAMP,
+    checked_out_at TIMESTAMP,
+    FOREIGN KEY (shift_id) REFERENCES shifts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT
+);
+
+-- A volunteer has at most one sign-up per shift that isn't cancelled
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shift_sign_ups_active ON shift_sign_ups(shift_id, user_id) WHERE cancelled_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_shift_sign_ups_user_id ON shift_sign_ups(user_id);
+CREATE INDEX IF NOT EXISTS idx_shift_sign_ups_checked_in_at ON shift_sign_ups(checked_in_at);
@@ -0,0 +1,6 @@
+-- Every shift belongs to a shelter; existing shifts belong to the default shelter
+ALTER TABLE shifts ADD COLUMN IF NOT EXISTS shelter_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+    REFERENCES shelters(id) ON DELETE RESTRICT;
+ALTER TABLE shifts ALTER COLUMN shelter_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_shifts_shelter_id_starts_at ON shifts(shelter_id, starts_at);
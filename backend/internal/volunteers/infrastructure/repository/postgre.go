@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/volunteers/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/database"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// shiftColumns are the columns scanned by scanShift. Only sign-ups that weren't cancelled take a place.
+const shiftColumns = `s.id, s.shelter_id, s.task, s.location, s.capacity, s.starts_at, s.ends_at, s.created_by, s.created, s.updated,
+              (SELECT COUNT(*) FROM shift_sign_ups u WHERE u.shift_id = s.id AND u.cancelled_at IS NULL)`
+
+// signUpColumns are the columns scanned by scanSignUp
+const signUpColumns = `u.id, u.shift_id, u.user_id, u.created, u.cancelled_at, u.checked_in_at, u.checked_out_at`
+
+// PostgresShiftRepository implements the ShiftRepository interface.
+type PostgresShiftRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresShiftRepository creates a new PostgresShiftRepository
+func NewPostgresShiftRepository(db *sqlx.DB) *PostgresShiftRepository {
+	return &PostgresShiftRepository{
+		db: db,
+	}
+}
+
+// Save saves a shift
+func (r *PostgresShiftRepository) Save(shift *models.Shift) error {
+	query := `INSERT INTO shifts (id, shelter_id, task, location, capacity, starts_at, ends_at, created_by, created, updated)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := r.db.Exec(
+		query,
+		shift.ID,
+		shift.ShelterID,
+		shift.Task,
+		shift.Location,
+		shift.Capacity,
+		shift.StartsAt.UTC(),
+		shift.EndsAt.UTC(),
+		shift.CreatedBy,
+		shift.Created.UTC(),
+		shift.Updated.UTC(),
+	)
+
+	if database.IsForeignKeyViolation(err, "shifts_shelter_id_fkey") {
+		return models.ErrShelterNotFound
+	}
+
+	return err
+}
+
+// FindByID finds a shift of the shelters in scope by its ID
+func (r *PostgresShiftRepository) FindByID(id string, scope tenant.Scope) (*models.Shift, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + shiftColumns + ` FROM shifts s WHERE s.id = $1 AND ` + tenant.Condition("s.shelter_id", 2)
+
+	return scanShift(r.db.QueryRow(query, id, all, shelterID))
+}
+
+// FindBetween finds the shifts of the shelters in scope starting in [from, to), soonest first
+func (r *PostgresShiftRepository) FindBetween(from, to time.Time, scope tenant.Scope) ([]*models.Shift, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + shiftColumns + ` FROM shifts s
+              WHERE s.starts_at >= $1 AND s.starts_at < $2 AND ` + tenant.Condition("s.shelter_id", 3) + `
+              ORDER BY s.starts_at, s.location`
+
+	rows, err := r.db.Query(query, from.UTC(), to.UTC(), all, shelterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := []*models.Shift{}
+	for rows.Next() {
+		shift, err := scanShift(rows)
+		if err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, shift)
+	}
+
+	return shifts, rows.Err()
+}
+
+// Update updates the task, location, capacity and time of a shift. Its shelter can't be changed.
+func (r *PostgresShiftRepository) Update(shift *models.Shift) error {
+	query := `UPDATE shifts SET task = $1, location = $2, capacity = $3, starts_at = $4, ends_at = $5, updated = $6
+              WHERE id = $7`
+
+	_, err := r.db.Exec(
+		query,
+		shift.Task,
+		shift.Location,
+		shift.Capacity,
+		shift.StartsAt.UTC(),
+		shift.EndsAt.UTC(),
+		shift.Updated.UTC(),
+		shift.ID,
+	)
+
+	return err
+}
+
+// Delete deletes a shift of the shelters in scope and its sign-ups unless a volunteer checked in,
+// reporting whether it was deleted
+func (r *PostgresShiftRepository) Delete(id string, scope tenant.Scope) (bool, error) {
+	all, shelterID := scope.Filter()
+
+	query := `DELETE FROM shifts WHERE id = $1 AND ` + tenant.Condition("shelter_id", 2) + `
+              AND NOT EXISTS (SELECT 1 FROM shift_sign_ups WHERE shift_id = $1 AND checked_in_at IS NOT NULL)`
+
+	result, err := r.db.Exec(query, id, all, shelterID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// PostgresSignUpRepository implements the SignUpRepository interface.
+type PostgresSignUpRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresSignUpRepository creates a new PostgresSignUpRepository
+func NewPostgresSignUpRepository(db *sqlx.DB) *PostgresSignUpRepository {
+	return &PostgresSignUpRepository{
+		db: db,
+	}
+}
+
+// Save saves a sign-up if its shift still has room. The shift is locked until the sign-up is
+// stored, so concurrent sign-ups can't take more places than the shift has.
+func (r *PostgresSignUpRepository) Save(signUp *models.SignUp) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var capacity, signedUp int
+	if err := tx.QueryRow(`SELECT capacity FROM shifts WHERE id = $1 FOR UPDATE`, signUp.ShiftID).Scan(&capacity); err != nil {
+		return false, err
+	}
+
+	query := `SELECT COUNT(*) FROM shift_sign_ups WHERE shift_id = $1 AND cancelled_at IS NULL`
+	if err := tx.QueryRow(query, signUp.ShiftID).Scan(&signedUp); err != nil {
+		return false, err
+	}
+
+	if signedUp >= capacity {
+		return false, nil
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO shift_sign_ups (id, shift_id, user_id, created) VALUES ($1, $2, $3, $4)`,
+		signUp.ID,
+		signUp.ShiftID,
+		signUp.UserID,
+		signUp.Created.UTC(),
+	)
+	if err != nil {
+		// Signed up by a concurrent request
+		if database.IsUniqueViolation(err, "idx_shift_sign_ups_active") {
+			return false, models.ErrAlreadySignedUp
+		}
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// FindByID finds a sign-up for a shift of the shelters in scope by its ID
+func (r *PostgresSignUpRepository) FindByID(id string, scope tenant.Scope) (*models.SignUp, error) {
+	all, shelterID := scope.Filter()
+
+	query := `SELECT ` + signUpColumns + ` FROM shift_sign_ups u
+              WHERE u.id = $1 AND u.shift_id IN (SELECT id FROM shifts WHERE ` + tenant.Condition("shelter_id", 2) + `)`
+
+	return scanSignUp(r.db.QueryRow(query, id, all, shelterID))
+}
+
+// FindActive finds the sign-up of a volunteer for a shift that wasn't cancelled
+func (r *PostgresSignUpRepository) FindActive(shiftID, userID string) (*models.SignUp, error) {
+	query := `SELECT ` + signUpColumns + ` FROM shift_sign_ups u
+              WHERE u.shift_id = $1 AND u.user_id = $2 AND u.cancelled_at IS NULL`
+
+	return scanSignUp(r.db.QueryRow(query, shiftID, userID))
+}
+
+// FindByShiftID finds the sign-ups of a shift, in the order they were made
+func (r *PostgresSignUpRepository) FindByShiftID(shiftID string) ([]*models.SignUp, error) {
+	return r.findMany(`SELECT `+signUpColumns+` FROM shift_sign_ups u WHERE u.shift_id = $1 ORDER BY u.created`, shiftID)
+}
+
+// FindByUserID finds the sign-ups of a volunteer, latest shift first
+func (r *PostgresSignUpRepository) FindByUserID(userID string) ([]*models.SignUp, error) {
+	query := `SELECT ` + signUpColumns + ` FROM shift_sign_ups u
+              JOIN shifts s ON s.id = u.shift_id
+              WHERE u.user_id = $1
+              ORDER BY s.starts_at DESC, u.created DESC`
+
+	return r.findMany(query, userID)
+}
+
+// HasOverlapping checks if a volunteer is signed up for a shift overlapping [startsAt, endsAt)
+func (r *PostgresSignUpRepository) HasOverlapping(userID string, startsAt, endsAt time.Time) (bool, error) {
+	query := `SELECT EXISTS (
+                  SELECT 1 FROM shift_sign_ups u
+                  JOIN shifts s ON s.id = u.shift_id
+                  WHERE u.user_id = $1 AND u.cancelled_at IS NULL AND s.starts_at < $3 AND s.ends_at > $2
+              )`
+
+	var overlapping bool
+	err := r.db.QueryRow(query, userID, startsAt.UTC(), endsAt.UTC()).Scan(&overlapping)
+	return overlapping, err
+}
+
+// Update updates the cancellation, check-in and check-out times of a sign-up
+func (r *PostgresSignUpRepository) Update(signUp *models.SignUp) error {
+	query := `UPDATE shift_sign_ups SET cancelled_at = $1, checked_in_at = $2, checked_out_at = $3 WHERE id = $4`
+
+	_, err := r.db.Exec(
+		query,
+		nullTime(signUp.CancelledAt),
+		nullTime(signUp.CheckedInAt),
+		nullTime(signUp.CheckedOutAt),
+		signUp.ID,
+	)
+
+	return err
+}
+
+// findMany runs a query returning rows of signUpColumns
+func (r *PostgresSignUpRepository) findMany(query string, args ...any) ([]*models.SignUp, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	signUps := []*models.SignUp{}
+	for rows.Next() {
+		signUp, err := scanSignUp(rows)
+		if err != nil {
+			return nil, err
+		}
+		signUps = append(signUps, signUp)
+	}
+
+	return signUps, rows.Err()
+}
+
+// scanShift scans a row of shiftColumns, returning nil if there is no row
+func scanShift(row interface{ Scan(dest ...any) error }) (*models.Shift, error) {
+	var shift models.Shift
+
+	err := row.Scan(
+		&shift.ID,
+		&shift.ShelterID,
+		&shift.Task,
+		&shift.Location,
+		&shift.Capacity,
+		&shift.StartsAt,
+		&shift.EndsAt,
+		&shift.CreatedBy,
+		&shift.Created,
+		&shift.Updated,
+		&shift.SignedUp,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &shift, nil
+}
+
+// scanSignUp scans a row of signUpColumns, returning nil if there is no row
+func scanSignUp(row interface{ Scan(dest ...any) error }) (*models.SignUp, error) {
+	var signUp models.SignUp
+	var cancelledAt, checkedInAt, checkedOutAt sql.NullTime
+
+	err := row.Scan(
+		&signUp.ID,
+		&signUp.ShiftID,
+		&signUp.UserID,
+		&signUp.Created,
+		&cancelledAt,
+		&checkedInAt,
+		&checkedOutAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if cancelledAt.Valid {
+		signUp.CancelledAt = &cancelledAt.Time
+	}
+
+	if checkedInAt.Valid {
+		signUp.CheckedInAt = &checkedInAt.Time
+	}
+
+	if checkedOutAt.Valid {
+		signUp.CheckedOutAt = &checkedOutAt.Time
+	}
+
+	signUp.Refresh()
+
+	return &signUp, nil
+}
+
+// nullTime converts an optional time to a UTC column value
+func nullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/internal/volunteers/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// reportShifts selects the completed sign-ups for the shifts of the shelters in scope ($3, $4)
+// checked in during [$1, $2) with the hours worked. The range filter on checked_in_at is served
+// by idx_shift_sign_ups_checked_in_at. Times are stored in UTC, so periods are truncated in UTC
+// whatever the time zone of the session.
+var reportShifts = `worked AS (
+                  SELECT u.user_id, u.checked_in_at,
+                         EXTRACT(EPOCH FROM (u.checked_out_at - u.checked_in_at)) / 3600 AS hours
+                  FROM shift_sign_ups u
+                  JOIN shifts s ON s.id = u.shift_id
+                  WHERE u.checked_out_at IS NOT NULL AND u.checked_in_at >= $1 AND u.checked_in_at < $2
+                        AND ` + tenant.Condition("s.shelter_id", 3) + `
+              )`
+
+// PostgresReportRepository implements the ReportRepository interface
+type PostgresReportRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresReportRepository creates a new PostgresReportRepository
+func NewPostgresReportRepository(db *sqlx.DB) *PostgresReportRepository {
+	return &PostgresReportRepository{
+		db: db,
+	}
+}
+
+// HoursByInterval totals the hours of each volunteer at the shelters in scope in the range by
+// day, week or month
+func (r *PostgresReportRepository) HoursByInterval(reportRange *models.ReportRange, interval models.Interval, scope tenant.Scope) ([]*models.VolunteerHours, error) {
+	all, shelterID := scope.Filter()
+
+	query := `WITH ` + reportShifts + `
+              SELECT date_trunc($5, w.checked_in_at AT TIME ZONE 'UTC', 'UTC') AS period_start, w.user_id, users.name,
+                     COUNT(*), ROUND(SUM(w.hours)::numeric, 2)
+              FROM worked w
+              JOIN users ON users.id = w.user_id
+              GROUP BY period_start, w.user_id, users.name
+              ORDER BY period_start, users.name`
+
+	rows, err := r.db.Query(query, reportRange.From, reportRange.To, all, shelterID, interval.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := []*models.VolunteerHours{}
+
+	for rows.Next() {
+		var row models.VolunteerHours
+		var periodStart time.Time
+
+		err := rows.Scan(
+			&periodStart,
+			&row.UserID,
+			&row.Name,
+			&row.Shifts,
+			&row.Hours,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		row.PeriodStart = periodStart.UTC().Format(models.ReportDateLayout)
+		hours = append(hours, &row)
+	}
+
+	return hours, rows.Err()
+}
+
+// HoursByVolunteer totals the hours of each volunteer at the shelters in scope over the whole
+// range, most hours first
+func (r *PostgresReportRepository) HoursByVolunteer(reportRange *models.ReportRange, scope tenant.Scope) ([]*models.VolunteerTotal, error) {
+	all, shelterID := scope.Filter()
+
+	query := `WITH ` + reportShifts + `
+              SELECT w.user_id, users.name, COUNT(*), ROUND(SUM(w.hours)::numeric, 2) AS total_hours
+              FROM worked w
+              JOIN users ON users.id = w.user_id
+              GROUP BY w.user_id, users.name
+              ORDER BY total_hours DESC, users.name`
+
+	rows, err := r.db.Query(query, reportRange.From, reportRange.To, all, shelterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []*models.VolunteerTotal{}
+
+	for rows.Next() {
+		var total models.VolunteerTotal
+
+		err := rows.Scan(
+			&total.UserID,
+			&total.Name,
+			&total.Shifts,
+			&total.Hours,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		totals = append(totals, &total)
+	}
+
+	return totals, rows.Err()
+}
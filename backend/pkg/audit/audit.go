@@ -0,0 +1,269 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+var ErrInvalidFilter = errors.New("invalid audit log filter")
+
+const (
+	SystemActor    = "system"    // Actor of changes made by background jobs
+	AnonymousActor = "anonymous" // Actor of changes made through public routes, such as registering
+)
+
+// Redacted replaces the values of personal fields in the audit log
+const Redacted = "[redacted]"
+
+// personalFields are the fields of each entity type whose values are personal data. The audit
+// log can't be changed, so it only records that they changed, and erasing an account leaves
+// nothing behind in it.
+var personalFields = map[string][]string{}
+
+// SetPersonalFields sets the fields of an entity type whose values are personal data
+func SetPersonalFields(entityType string, fields ...string) {
+	personalFields[entityType] = fields
+}
+
+// Entry is a change recorded in the audit log. Entries form a hash chain: each one holds the
+// hash of the previous entry, so changing or removing an entry breaks every hash after it.
+type Entry struct {
+	Sequence   int64           `json:"sequence"`
+	ID         string          `json:"id"`
+	Actor      string          `json:"actor"`  // ID of the user, api-key:<ID> for API keys, or SystemActor/AnonymousActor
+	Action     string          `json:"action"` // What was done, written as entity.verb, such as pet.update
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Changes    json.RawMessage `json:"changes"` // Fields that changed, each with its value before and after
+	IPAddress  string          `json:"ip_address,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Created    time.Time       `json:"created"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+// Change is the value of a field before and after a change. Before is null for created
+// entities, after is null for deleted ones.
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Filter selects audit log entries. Empty fields match every entry.
+type Filter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+// Verification is the result of checking the hash chain of the audit log
+type Verification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int64  `json:"entries"`             // Entries found valid, up to the first broken one
+	BrokenAt *int64 `json:"broken_at,omitempty"` // Sequence of the first entry whose hash doesn't match
+}
+
+// NewEntry creates a new Entry with the changes between before and after. Its place in the
+// chain is set by the store when it is appended.
+func NewEntry(actor, action, entityType, entityID string, before, after any, now time.Time) (*Entry, error) {
+	changes, err := Diff(before, after, personalFields[entityType]...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Entry{
+		ID:         uuid.New().String(),
+		Actor:      actor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    changes,
+		Created:    now.UTC().Truncate(time.Microsecond), // The precision the database keeps, so the hash can be checked
+	}, nil
+}
+
+// ComputeHash returns the hash of the entry, covering its content and the hash of the previous entry
+func (e *Entry) ComputeHash() string {
+	content, _ := json.Marshal(struct {
+		Sequence   int64           `json:"sequence"`
+		ID         string          `json:"id"`
+		Actor      string          `json:"actor"`
+		Action     string          `json:"action"`
+		EntityType string          `json:"entity_type"`
+		EntityID   string          `json:"entity_id"`
+		Changes    json.RawMessage `json:"changes"`
+		IPAddress  string          `json:"ip_address"`
+		RequestID  string          `json:"request_id"`
+		Created    string          `json:"created"`
+		PrevHash   string          `json:"prev_hash"`
+	}{
+		e.Sequence, e.ID, e.Actor, e.Action, e.EntityType, e.EntityID, e.Changes, e.IPAddress, e.RequestID,
+		e.Created.UTC().Format(time.RFC3339Nano), e.PrevHash,
+	})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Chain places the entry after the previous one, or first in the log if previous is nil, and sets its hash
+func (e *Entry) Chain(previous *Entry) {
+	e.Sequence = 1
+	e.PrevHash = ""
+	if previous != nil {
+		e.Sequence = previous.Sequence + 1
+		e.PrevHash = previous.Hash
+	}
+	e.Hash = e.ComputeHash()
+}
+
+// Diff returns the fields whose JSON value differs between before and after, as a JSON object
+// of Changes. Either can be nil, for created and deleted entities. The values of the redacted
+// fields are replaced with Redacted, and fields left out of the JSON of a model, such as
+// password hashes, are never recorded.
+func Diff(before, after any, redacted ...string) (json.RawMessage, error) {
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(beforeFields)+len(afterFields))
+	for name := range beforeFields {
+		names = append(names, name)
+	}
+	for name := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make(map[string]Change)
+	for _, name := range names {
+		if reflect.DeepEqual(beforeFields[name], afterFields[name]) {
+			continue
+		}
+
+		change := Change{Before: beforeFields[name], After: afterFields[name]}
+		if slices.Contains(redacted, name) {
+			change = Change{Before: redact(change.Before), After: redact(change.After)}
+		}
+		changes[name] = change
+	}
+
+	return json.Marshal(changes)
+}
+
+// redact hides a value, keeping whether it was set
+func redact(value any) any {
+	if value == nil {
+		return nil
+	}
+	return Redacted
+}
+
+// fields returns the JSON fields of a value. Values that aren't JSON objects are kept under "value".
+func fields(value any) (map[string]any, error) {
+	if value == nil || reflect.ValueOf(value).Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil() {
+		return map[string]any{}, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	if object, ok := decoded.(map[string]any); ok {
+		return object, nil
+	}
+
+	return map[string]any{"value": decoded}, nil
+}
+
+// Verify checks the hash chain of entries given in sequence order, starting after previous
+// (nil for the start of the log). It returns the sequence of the first broken entry, if any.
+func Verify(previous *Entry, entries []*Entry) *int64 {
+	for _, entry := range entries {
+		expectedSequence, expectedPrevHash := int64(1), ""
+		if previous != nil {
+			expectedSequence, expectedPrevHash = previous.Sequence+1, previous.Hash
+		}
+
+		if entry.Sequence != expectedSequence || entry.PrevHash != expectedPrevHash || entry.Hash != entry.ComputeHash() {
+			sequence := entry.Sequence
+			return &sequence
+		}
+
+		previous = entry
+	}
+
+	return nil
+}
+
+// Record records a change made by the request: who made it, from which IP address and with
+// which request ID. before and after are the entity before and after the change, nil for
+// created and deleted entities. The change has already been made, so failures are only logged.
+func Record(c *fiber.Ctx, action, entityType, entityID string, before, after any) {
+	entry, err := NewEntry(actor(c), action, entityType, entityID, before, after, time.Now())
+	if err != nil {
+		log.Printf("Failed to record %s of %s %s in the audit log: %v", action, entityType, entityID, err)
+		return
+	}
+
+	entry.IPAddress = c.IP()
+	entry.RequestID, _ = c.Locals("requestid").(string)
+
+	if err := store.Append(entry); err != nil {
+		log.Printf("Failed to record %s of %s %s in the audit log: %v", action, entityType, entityID, err)
+	}
+}
+
+// RecordSystem records a change made by a background job rather than a request
+func RecordSystem(action, entityType, entityID string, before, after any) {
+	entry, err := NewEntry(SystemActor, action, entityType, entityID, before, after, time.Now())
+	if err == nil {
+		err = store.Append(entry)
+	}
+
+	if err != nil {
+		log.Printf("Failed to record %s of %s %s in the audit log: %v", action, entityType, entityID, err)
+	}
+}
+
+// actor returns who made the request, set by the auth.Protected middleware on protected routes
+func actor(c *fiber.Ctx) string {
+	if key, ok := c.Locals("apiKey").(*models.APIKey); ok {
+		return "api-key:" + key.ID
+	}
+
+	if userID, ok := c.Locals("userID").(string); ok && userID != "" {
+		return userID
+	}
+
+	return AnonymousActor
+}
@@ -0,0 +1,258 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// useMemoryStore sets a fresh in-memory store for the test
+func useMemoryStore(t *testing.T) *MemoryStore {
+	t.Helper()
+
+	memory := NewMemoryStore()
+	SetStore(memory)
+	t.Cleanup(func() { SetStore(NewMemoryStore()) })
+
+	return memory
+}
+
+func TestDiff(t *testing.T) {
+	type pet struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+		Secret string `json:"-"`
+	}
+
+	tests := []struct {
+		name     string
+		before   any
+		after    any
+		redacted []string
+		want     map[string]Change
+	}{
+		{
+			name:   "Changed fields only",
+			before: pet{Name: "Rex", Status: "available", Secret: "a"},
+			after:  pet{Name: "Rex", Status: "adopted", Secret: "b"},
+			want:   map[string]Change{"status": {Before: "available", After: "adopted"}},
+		},
+		{
+			name:  "Created entity",
+			after: &pet{Name: "Rex", Status: "available"},
+			want: map[string]Change{
+				"name":   {Before: nil, After: "Rex"},
+				"status": {Before: nil, After: "available"},
+			},
+		},
+		{
+			name:   "Deleted entity",
+			before: &pet{Name: "Rex", Status: "available"},
+			after:  (*pet)(nil),
+			want: map[string]Change{
+				"name":   {Before: "Rex", After: nil},
+				"status": {Before: "available", After: nil},
+			},
+		},
+		{
+			name:     "Redacted fields",
+			before:   pet{Name: "Rex", Status: "available"},
+			after:    pet{Name: "Max", Status: "available"},
+			redacted: []string{"name"},
+			want:     map[string]Change{"name": {Before: Redacted, After: Redacted}},
+		},
+		{
+			name:     "Redacted field that was set",
+			after:    pet{Name: "Rex"},
+			redacted: []string{"name"},
+			want: map[string]Change{
+				"name":   {Before: nil, After: Redacted},
+				"status": {Before: nil, After: ""},
+			},
+		},
+		{
+			name:   "No changes",
+			before: pet{Name: "Rex"},
+			after:  pet{Name: "Rex"},
+			want:   map[string]Change{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := Diff(tt.before, tt.after, tt.redacted...)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+
+			var got map[string]Change
+			if err := json.Unmarshal(changes, &got); err != nil {
+				t.Fatalf("Diff() returned invalid JSON %s: %v", changes, err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Diff() = %s, want %v", changes, tt.want)
+			}
+			for name, change := range tt.want {
+				if got[name] != change {
+					t.Errorf("Diff()[%q] = %v, want %v", name, got[name], change)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	memory := useMemoryStore(t)
+
+	for _, status := range []string{"available", "adopted", "returned"} {
+		RecordSystem("pet.status_update", "pet", "pet-1", nil, map[string]string{"status": status})
+	}
+
+	verification, err := VerifyChain()
+	if err != nil {
+		t.Fatalf("VerifyChain() error = %v", err)
+	}
+	if !verification.Valid || verification.Entries != 3 {
+		t.Fatalf("VerifyChain() = %+v, want a valid chain of 3 entries", verification)
+	}
+
+	// Changing an entry breaks the chain from there
+	memory.entries[1].Actor = "someone-else"
+
+	verification, err = VerifyChain()
+	if err != nil {
+		t.Fatalf("VerifyChain() error = %v", err)
+	}
+	if verification.Valid || verification.BrokenAt == nil || *verification.BrokenAt != 2 || verification.Entries != 1 {
+		t.Errorf("VerifyChain() after tampering = %+v, want broken at 2 after 1 entry", verification)
+	}
+
+	// So does removing one
+	memory.entries[1].Actor = SystemActor
+	memory.entries = append(memory.entries[:1], memory.entries[2:]...)
+
+	verification, err = VerifyChain()
+	if err != nil {
+		t.Fatalf("VerifyChain() error = %v", err)
+	}
+	if verification.Valid || verification.BrokenAt == nil || *verification.BrokenAt != 3 {
+		t.Errorf("VerifyChain() after removing an entry = %+v, want broken at 3", verification)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	useMemoryStore(t)
+	SetPersonalFields("test_user", "email")
+	defer delete(personalFields, "test_user")
+
+	app := fiber.New()
+	app.Use(requestid.New())
+	app.Put("/", func(c *fiber.Ctx) error {
+		c.Locals("userID", "admin-1")
+		Record(c, "user.update", "test_user", "user-1",
+			map[string]string{"email": "old@example.com", "role": "user"},
+			map[string]string{"email": "new@example.com", "role": "staff"})
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Post("/", func(c *fiber.Ctx) error {
+		Record(c, "user.create", "test_user", "user-2", nil, map[string]string{"role": "user"})
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	req := httptest.NewRequest("PUT", "/", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "request-1")
+	if _, err := app.Test(req); err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+	if _, err := app.Test(httptest.NewRequest("POST", "/", nil)); err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+
+	entries, err := Find(Filter{EntityType: "test_user", Limit: 10})
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Find() returned %d entries, want 2", len(entries))
+	}
+
+	created, updated := entries[0], entries[1]
+	if updated.Actor != "admin-1" || updated.RequestID != "request-1" || updated.IPAddress == "" {
+		t.Errorf("Record() = %+v, want actor admin-1, request ID request-1 and an IP address", updated)
+	}
+	if created.Actor != AnonymousActor {
+		t.Errorf("Record() actor = %q, want %q", created.Actor, AnonymousActor)
+	}
+
+	var changes map[string]Change
+	if err := json.Unmarshal(updated.Changes, &changes); err != nil {
+		t.Fatalf("invalid changes %s: %v", updated.Changes, err)
+	}
+	if changes["email"] != (Change{Before: Redacted, After: Redacted}) {
+		t.Errorf("Record() email change = %v, want it redacted", changes["email"])
+	}
+	if changes["role"] != (Change{Before: "user", After: "staff"}) {
+		t.Errorf("Record() role change = %v, want user to staff", changes["role"])
+	}
+}
+
+func TestFind(t *testing.T) {
+	useMemoryStore(t)
+
+	for _, id := range []string{"pet-1", "pet-2", "pet-3"} {
+		RecordSystem("pet.create", "pet", id, nil, map[string]string{"name": id})
+	}
+	RecordSystem("adoption.create", "adoption", "adoption-1", nil, map[string]string{"pet_id": "pet-1"})
+
+	now := time.Now()
+	hourAgo := now.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		want    []string
+		wantErr error
+	}{
+		{name: "Newest first", filter: Filter{Limit: 10}, want: []string{"adoption-1", "pet-3", "pet-2", "pet-1"}},
+		{name: "By entity type", filter: Filter{EntityType: "pet", Limit: 10}, want: []string{"pet-3", "pet-2", "pet-1"}},
+		{name: "By entity", filter: Filter{EntityType: "pet", EntityID: "pet-2", Limit: 10}, want: []string{"pet-2"}},
+		{name: "Paged", filter: Filter{Limit: 2, Offset: 1}, want: []string{"pet-3", "pet-2"}},
+		{name: "Past the end", filter: Filter{Limit: 2, Offset: 10}, want: []string{}},
+		{name: "Before the entries", filter: Filter{To: &hourAgo, Limit: 10}, want: []string{}},
+		{name: "No limit", filter: Filter{}, wantErr: ErrInvalidFilter},
+		{name: "Negative offset", filter: Filter{Limit: 10, Offset: -1}, wantErr: ErrInvalidFilter},
+		{name: "Reversed range", filter: Filter{From: &now, To: &hourAgo, Limit: 10}, wantErr: ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := Find(tt.filter)
+			if err != tt.wantErr {
+				t.Fatalf("Find() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			got := []string{}
+			for _, entry := range entries {
+				got = append(got, entry.EntityID)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Find() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Find() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Page size limits of the audit log
+const (
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+// ListHandler returns the entries of the audit log, newest first. They can be filtered by
+// actor, action, entity_type and entity_id, and by time with from and to (RFC 3339).
+func ListHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		filter := Filter{
+			Actor:      c.Query("actor"),
+			Action:     c.Query("action"),
+			EntityType: c.Query("entity_type"),
+			EntityID:   c.Query("entity_id"),
+			Limit:      min(c.QueryInt("limit", defaultLimit), maxLimit),
+			Offset:     c.QueryInt("offset", 0),
+		}
+
+		for param, bound := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+			value := c.Query(param)
+			if value == "" {
+				continue
+			}
+
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid " + param + " time, use RFC 3339",
+				})
+			}
+			*bound = &parsed
+		}
+
+		entries, err := Find(filter)
+		if err != nil {
+			if err == ErrInvalidFilter {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.JSON(entries)
+	}
+}
+
+// VerifyHandler checks the hash chain of the audit log, reporting the first entry that was
+// changed or follows a removed one
+func VerifyHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		verification, err := VerifyChain()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.JSON(verification)
+	}
+}
@@ -0,0 +1,170 @@
+package audit
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresStore implements the Store interface. A trigger keeps the audit_log table
+// append-only.
+type PostgresStore struct {
+	db *sqlx.DB
+}
+
+// NewPostgresStore creates a new PostgresStore
+func NewPostgresStore(db *sqlx.DB) *PostgresStore {
+	return &PostgresStore{
+		db: db,
+	}
+}
+
+// entryColumns are the columns scanned by scanEntry
+const entryColumns = `sequence, id, actor, action, entity_type, entity_id, changes, ip_address, request_id, created, prev_hash, hash`
+
+// Append chains the entry after the last one and stores it. The table is locked against other
+// appends until the entry is stored, so no two entries are chained to the same one.
+func (s *PostgresStore) Append(entry *Entry) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`LOCK TABLE audit_log IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return err
+	}
+
+	previous, err := scanEntry(tx.QueryRow(`SELECT ` + entryColumns + ` FROM audit_log ORDER BY sequence DESC LIMIT 1`))
+	if err != nil {
+		return err
+	}
+
+	entry.Chain(previous)
+
+	query := `INSERT INTO audit_log (` + entryColumns + `)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	_, err = tx.Exec(
+		query,
+		entry.Sequence,
+		entry.ID,
+		entry.Actor,
+		entry.Action,
+		entry.EntityType,
+		entry.EntityID,
+		string(entry.Changes),
+		entry.IPAddress,
+		entry.RequestID,
+		entry.Created.UTC(),
+		entry.PrevHash,
+		entry.Hash,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Find finds the entries matching the filter, newest first
+func (s *PostgresStore) Find(filter Filter) ([]*Entry, error) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.Replace(condition, "?", "$"+strconv.Itoa(len(args)), 1))
+	}
+
+	if filter.Actor != "" {
+		addCondition("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		addCondition("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		addCondition("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		addCondition("entity_id = ?", filter.EntityID)
+	}
+	if filter.From != nil {
+		addCondition("created >= ?", filter.From.UTC())
+	}
+	if filter.To != nil {
+		addCondition("created < ?", filter.To.UTC())
+	}
+
+	query := `SELECT ` + entryColumns + ` FROM audit_log`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query += ` ORDER BY sequence DESC LIMIT $` + strconv.Itoa(len(args)-1) + ` OFFSET $` + strconv.Itoa(len(args))
+
+	return s.query(query, args...)
+}
+
+// FindChain finds up to limit entries after the given sequence, in sequence order
+func (s *PostgresStore) FindChain(afterSequence int64, limit int) ([]*Entry, error) {
+	query := `SELECT ` + entryColumns + ` FROM audit_log WHERE sequence > $1 ORDER BY sequence LIMIT $2`
+
+	return s.query(query, afterSequence, limit)
+}
+
+// query runs a query returning rows of entryColumns
+func (s *PostgresStore) query(query string, args ...any) ([]*Entry, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*Entry{}
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// scanEntry scans a row of entryColumns, returning nil if there is no row
+func scanEntry(row interface{ Scan(dest ...any) error }) (*Entry, error) {
+	var entry Entry
+	var changes string
+
+	err := row.Scan(
+		&entry.Sequence,
+		&entry.ID,
+		&entry.Actor,
+		&entry.Action,
+		&entry.EntityType,
+		&entry.EntityID,
+		&changes,
+		&entry.IPAddress,
+		&entry.RequestID,
+		&entry.Created,
+		&entry.PrevHash,
+		&entry.Hash,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// The json column keeps the changes as they were hashed
+	entry.Changes = []byte(changes)
+	return &entry, nil
+}
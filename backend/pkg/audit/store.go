@@ -0,0 +1,151 @@
+package audit
+
+import (
+	"slices"
+	"sync"
+)
+
+// store keeps the audit log. It defaults to an in-memory store until a persistent one is
+// set with SetStore.
+var store Store = NewMemoryStore()
+
+// SetStore sets the store the audit log is kept in
+func SetStore(s Store) {
+	store = s
+}
+
+// Store keeps the audit log. Entries can only be appended.
+type Store interface {
+	// Append chains the entry after the last one and stores it. Appends are serialized, so
+	// every entry is chained to the one stored before it.
+	Append(entry *Entry) error
+	// Find finds the entries matching the filter, newest first
+	Find(filter Filter) ([]*Entry, error)
+	// FindChain finds up to limit entries after the given sequence, in sequence order
+	FindChain(afterSequence int64, limit int) ([]*Entry, error)
+}
+
+// Find finds the audit log entries matching the filter, newest first
+func Find(filter Filter) ([]*Entry, error) {
+	if filter.Limit <= 0 || filter.Offset < 0 || filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, ErrInvalidFilter
+	}
+
+	return store.Find(filter)
+}
+
+// verifyBatchSize is how many entries are loaded at a time to verify the chain
+const verifyBatchSize = 1000
+
+// VerifyChain checks the hash chain of the whole audit log
+func VerifyChain() (*Verification, error) {
+	verification := &Verification{Valid: true}
+
+	var previous *Entry
+	for {
+		var after int64
+		if previous != nil {
+			after = previous.Sequence
+		}
+
+		entries, err := store.FindChain(after, verifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		if brokenAt := Verify(previous, entries); brokenAt != nil {
+			for _, entry := range entries {
+				if entry.Sequence == *brokenAt {
+					break
+				}
+				verification.Entries++
+			}
+
+			verification.Valid = false
+			verification.BrokenAt = brokenAt
+			return verification, nil
+		}
+
+		verification.Entries += int64(len(entries))
+
+		if len(entries) < verifyBatchSize {
+			return verification, nil
+		}
+		previous = entries[len(entries)-1]
+	}
+}
+
+// MemoryStore is an in-memory Store, used when no database store is configured
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewMemoryStore creates a new MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Append chains the entry after the last one and stores it
+func (s *MemoryStore) Append(entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var previous *Entry
+	if len(s.entries) > 0 {
+		previous = &s.entries[len(s.entries)-1]
+	}
+
+	entry.Chain(previous)
+	s.entries = append(s.entries, *entry)
+	return nil
+}
+
+// Find finds the entries matching the filter, newest first
+func (s *MemoryStore) Find(filter Filter) ([]*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := []*Entry{}
+	for _, entry := range slices.Backward(s.entries) {
+		if matches(&entry, filter) {
+			entries = append(entries, &entry)
+		}
+	}
+
+	if filter.Offset >= len(entries) {
+		return []*Entry{}, nil
+	}
+
+	entries = entries[filter.Offset:]
+	if len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+
+	return entries, nil
+}
+
+// FindChain finds up to limit entries after the given sequence, in sequence order
+func (s *MemoryStore) FindChain(afterSequence int64, limit int) ([]*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := []*Entry{}
+	for _, entry := range s.entries {
+		if entry.Sequence > afterSequence && len(entries) < limit {
+			entries = append(entries, &entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// matches checks if an entry matches the filter
+func matches(entry *Entry, filter Filter) bool {
+	return (filter.Actor == "" || entry.Actor == filter.Actor) &&
+		(filter.Action == "" || entry.Action == filter.Action) &&
+		(filter.EntityType == "" || entry.EntityType == filter.EntityType) &&
+		(filter.EntityID == "" || entry.EntityID == filter.EntityID) &&
+		(filter.From == nil || !entry.Created.Before(*filter.From)) &&
+		(filter.To == nil || entry.Created.Before(*filter.To))
+}
@@ -0,0 +1,301 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// APIKeyHeader is the header machine clients send their API key in
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyScheme starts every API key, so leaked keys are easy to recognize
+const apiKeyScheme = "pp"
+
+var (
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	ErrExpiredAPIKey = errors.New("API key has expired")
+	ErrRevokedAPIKey = errors.New("API key has been revoked")
+)
+
+// apiKeys stores the API keys. It defaults to an in-memory store until a persistent one is
+// set with SetAPIKeyStore.
+var apiKeys APIKeyStore = NewMemoryAPIKeyStore()
+
+// SetAPIKeyStore sets the store used to keep API keys
+func SetAPIKeyStore(store APIKeyStore) {
+	apiKeys = store
+}
+
+// APIKeyStore keeps API keys, looked up by their prefix when used
+type APIKeyStore interface {
+	Save(key *models.APIKey) error
+	FindByID(id string) (*models.APIKey, error)
+	FindByPrefix(prefix string) (*models.APIKey, error)
+	FindAll(scope tenant.Scope) ([]*models.APIKey, error)
+	Revoke(id string, at time.Time) error
+	MarkUsed(id string, at time.Time) error
+}
+
+// CreateAPIKey creates an API key and returns it along with the key itself, which isn't stored
+// and can't be shown again. Only platform-wide keys work across shelters.
+func CreateAPIKey(name string, permissions []models.Permission, createdBy, shelterID string, platformWide bool, expiresAt *time.Time) (*models.APIKey, string, error) {
+	prefix, secret, err := newAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	rawKey := prefix + "_" + secret
+	key := &models.APIKey{
+		ID:           uuid.New().String(),
+		Name:         name,
+		Prefix:       prefix,
+		KeyHash:      hashAPIKey(rawKey),
+		Permissions:  permissions,
+		CreatedBy:    createdBy,
+		ShelterID:    shelterID,
+		PlatformWide: platformWide,
+		Created:      time.Now(),
+		ExpiresAt:    expiresAt,
+	}
+
+	if err := apiKeys.Save(key); err != nil {
+		return nil, "", err
+	}
+
+	return key, rawKey, nil
+}
+
+// GetAPIKeys returns every API key of the shelters in scope, revoked and expired ones included
+func GetAPIKeys(scope tenant.Scope) ([]*models.APIKey, error) {
+	return apiKeys.FindAll(scope)
+}
+
+// RevokeAPIKey revokes an API key of a shelter in scope; requests using it are refused from then on.
+// Revoking a key that is already revoked keeps its original revocation time.
+func RevokeAPIKey(id string, scope tenant.Scope) (*models.APIKey, error) {
+	key, err := apiKeys.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil || !scope.Allows(key.ShelterID) {
+		return nil, models.ErrAPIKeyNotFound
+	}
+
+	if key.IsRevoked() {
+		return key, nil
+	}
+
+	now := time.Now()
+	if err := apiKeys.Revoke(id, now); err != nil {
+		return nil, err
+	}
+
+	key.RevokedAt = &now
+	return key, nil
+}
+
+// ValidateAPIKey checks an API key and records that it was used
+func ValidateAPIKey(rawKey string) (*models.APIKey, error) {
+	prefix, ok := parseAPIKeyPrefix(rawKey)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := apiKeys.FindByPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil || subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashAPIKey(rawKey))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if key.IsRevoked() {
+		return nil, ErrRevokedAPIKey
+	}
+
+	now := time.Now()
+	if key.IsExpired(now) {
+		return nil, ErrExpiredAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= models.APIKeyLastUsedInterval {
+		if err := apiKeys.MarkUsed(key.ID, now); err != nil {
+			return nil, err
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
+
+// authenticateAPIKey authenticates a request made with an API key. The key takes the place of
+// the user: it has no role, only the permissions it was granted, and the shelter it was made for.
+func authenticateAPIKey(c *fiber.Ctx, rawKey string) error {
+	key, err := ValidateAPIKey(rawKey)
+	if err != nil {
+		switch err {
+		case ErrInvalidAPIKey, ErrExpiredAPIKey, ErrRevokedAPIKey:
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": err.Error(),
+				"code":  "api_key_invalid",
+			})
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check API key",
+		})
+	}
+
+	c.Locals("userID", key.ID)
+	c.Locals("email", "")
+	c.Locals("role", models.Role(""))
+	c.Locals("shelterID", key.ShelterID)
+	c.Locals("apiKey", key)
+
+	return c.Next()
+}
+
+// IsAPIKeyRequest checks if the request was authenticated with an API key rather than a user's token
+func IsAPIKeyRequest(c *fiber.Ctx) bool {
+	_, ok := c.Locals("apiKey").(*models.APIKey)
+	return ok
+}
+
+// newAPIKey generates the prefix and the secret part of an API key, written as pp_<prefix>_<secret>
+func newAPIKey() (string, string, error) {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	return apiKeyScheme + "_" + hex.EncodeToString(id), base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// parseAPIKeyPrefix returns the prefix of an API key. The prefix is hex, so the first two
+// underscores separate it even though the secret can contain underscores too.
+func parseAPIKeyPrefix(rawKey string) (string, bool) {
+	parts := strings.SplitN(rawKey, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyScheme || len(parts[1]) != 12 || parts[2] == "" {
+		return "", false
+	}
+
+	return parts[0] + "_" + parts[1], true
+}
+
+// hashAPIKey hashes an API key; the keys are random enough not to need a slow hash
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryAPIKeyStore is an in-memory APIKeyStore, used when no database store is configured and in tests
+type MemoryAPIKeyStore struct {
+	mu   sync.Mutex
+	keys map[string]*models.APIKey
+}
+
+// NewMemoryAPIKeyStore creates a new MemoryAPIKeyStore
+func NewMemoryAPIKeyStore() *MemoryAPIKeyStore {
+	return &MemoryAPIKeyStore{
+		keys: make(map[string]*models.APIKey),
+	}
+}
+
+// Save stores an API key
+func (s *MemoryAPIKeyStore) Save(key *models.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *key
+	s.keys[key.ID] = &stored
+	return nil
+}
+
+// FindByID finds an API key by its ID
+func (s *MemoryAPIKeyStore) FindByID(id string) (*models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[id]; ok {
+		found := *key
+		return &found, nil
+	}
+	return nil, nil
+}
+
+// FindByPrefix finds an API key by its prefix
+func (s *MemoryAPIKeyStore) FindByPrefix(prefix string) (*models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.keys {
+		if key.Prefix == prefix {
+			found := *key
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// FindAll returns every API key of the shelters in scope, newest first
+func (s *MemoryAPIKeyStore) FindAll(scope tenant.Scope) ([]*models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]*models.APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		if !scope.Allows(key.ShelterID) {
+			continue
+		}
+		found := *key
+		keys = append(keys, &found)
+	}
+
+	slices.SortFunc(keys, func(a, b *models.APIKey) int {
+		return b.Created.Compare(a.Created)
+	})
+
+	return keys, nil
+}
+
+// Revoke marks an API key as revoked
+func (s *MemoryAPIKeyStore) Revoke(id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[id]; ok {
+		key.RevokedAt = &at
+	}
+	return nil
+}
+
+// MarkUsed records when an API key was last used
+func (s *MemoryAPIKeyStore) MarkUsed(id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[id]; ok {
+		key.LastUsedAt = &at
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// apiKeyStatus calls a route that needs the pets:write permission with the given API key
+func apiKeyStatus(t *testing.T, rawKey string) int {
+	t.Helper()
+
+	app := fiber.New()
+	app.Post("/", Protected(), RequirePermission(models.PermissionPetsWrite), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set(APIKeyHeader, rawKey)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+
+	return resp.StatusCode
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	store := NewMemoryAPIKeyStore()
+	SetAPIKeyStore(store)
+	defer SetAPIKeyStore(NewMemoryAPIKeyStore())
+
+	past := time.Now().Add(-time.Hour)
+
+	writer, writerKey, err := CreateAPIKey("clinic", []models.Permission{models.PermissionPetsWrite}, "admin-1", "", false, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+
+	_, readerKey, err := CreateAPIKey("website", []models.Permission{models.PermissionAdoptionsRead}, "admin-1", "", false, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+
+	_, expiredKey, err := CreateAPIKey("old", []models.Permission{models.PermissionPetsWrite}, "admin-1", "", false, &past)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+
+	revoked, revokedKey, err := CreateAPIKey("revoked", []models.Permission{models.PermissionPetsWrite}, "admin-1", "", false, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+
+	if _, err := RevokeAPIKey(revoked.ID, tenant.AllShelters()); err != nil {
+		t.Fatalf("RevokeAPIKey() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		want int
+	}{
+		{"key with the permission", writerKey, fiber.StatusCreated},
+		{"key without the permission", readerKey, fiber.StatusForbidden},
+		{"expired key", expiredKey, fiber.StatusUnauthorized},
+		{"revoked key", revokedKey, fiber.StatusUnauthorized},
+		{"wrong secret", writer.Prefix + "_not-the-secret", fiber.StatusUnauthorized},
+		{"malformed key", "not-an-api-key", fiber.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		if status := apiKeyStatus(t, tt.key); status != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, status, tt.want)
+		}
+	}
+
+	stored, err := store.FindByID(writer.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+
+	if stored.LastUsedAt == nil {
+		t.Error("last use of the key was not recorded")
+	}
+
+	if stored.KeyHash == writerKey {
+		t.Error("the key was stored instead of its hash")
+	}
+}
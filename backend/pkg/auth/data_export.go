@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// DataExportClaims represents the JWT claims of a data export download link
+type DataExportClaims struct {
+	ExportID string `json:"export_id"`
+	UserID   string `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+// GenerateDataExportToken creates the token of the download link of a ready data export, valid
+// until its archive expires
+func GenerateDataExportToken(export *models.DataExport) (string, error) {
+	if export.ExpiresAt == nil {
+		return "", ErrInvalidToken
+	}
+
+	return signPurposeToken(purposeDataExport, &DataExportClaims{
+		ExportID: export.ID,
+		UserID:   export.UserID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(*export.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(export.Created),
+			Subject:   export.UserID,
+		},
+	})
+}
+
+// ValidateDataExportToken validates a data export download token and returns its claims.
+// Whether the archive is still there is up to the caller.
+func ValidateDataExportToken(tokenString string) (*DataExportClaims, error) {
+	claims := &DataExportClaims{}
+	if err := parsePurposeToken(purposeDataExport, tokenString, claims); err != nil {
+		return nil, err
+	}
+
+	if claims.ExportID == "" || claims.UserID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
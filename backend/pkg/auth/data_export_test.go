@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+func TestDataExportTokenIsOnlyValidForDownloads(t *testing.T) {
+	user := &models.User{ID: "user-1", Email: "jane@example.com", Role: models.RoleUser, Status: models.StatusActive}
+	setupRefreshTest(map[string]*models.User{user.ID: user})
+
+	now := time.Now()
+	expiresAt := now.Add(models.DataExportExpiration)
+	export := models.NewDataExport("export-1", user.ID, now)
+
+	// Only ready exports, which have an expiry, get a download link
+	if _, err := GenerateDataExportToken(export); err != ErrInvalidToken {
+		t.Errorf("GenerateDataExportToken(pending export) error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	export.Status = models.DataExportReady
+	export.ExpiresAt = &expiresAt
+
+	token, err := GenerateDataExportToken(export)
+	if err != nil {
+		t.Fatalf("GenerateDataExportToken() error = %v", err)
+	}
+
+	claims, err := ValidateDataExportToken(token)
+	if err != nil {
+		t.Fatalf("ValidateDataExportToken() error = %v", err)
+	}
+
+	if claims.ExportID != export.ID || claims.UserID != user.ID {
+		t.Errorf("claims = %+v, want export %s of user %s", claims, export.ID, user.ID)
+	}
+
+	if _, err := ValidateEmailVerificationToken(token); err != ErrInvalidToken {
+		t.Errorf("ValidateEmailVerificationToken(data export token) error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	expired := now.Add(-time.Minute)
+	export.ExpiresAt = &expired
+
+	expiredToken, err := GenerateDataExportToken(export)
+	if err != nil {
+		t.Fatalf("GenerateDataExportToken() error = %v", err)
+	}
+
+	if _, err := ValidateDataExportToken(expiredToken); err != ErrExpiredToken {
+		t.Errorf("ValidateDataExportToken(expired token) error = %v, want %v", err, ErrExpiredToken)
+	}
+
+	if status := export.StatusAt(now); status != models.DataExportExpired {
+		t.Errorf("StatusAt() = %q, want %q", status, models.DataExportExpired)
+	}
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// InvitationClaims represents the JWT claims of a staff invitation token
+type InvitationClaims struct {
+	InvitationID string      `json:"invitation_id"`
+	Email        string      `json:"email"`
+	Role         models.Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// GenerateInvitationToken creates the token sent to an invited staff member, valid until the invitation expires
+func GenerateInvitationToken(invitation *models.Invitation) (string, error) {
+	return signPurposeToken(purposeInvitation, &InvitationClaims{
+		InvitationID: invitation.ID,
+		Email:        invitation.Email,
+		Role:         invitation.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(invitation.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(invitation.Created),
+			Subject:   invitation.ID,
+		},
+	})
+}
+
+// ValidateInvitationToken validates an invitation token and returns its claims. Whether the
+// invitation is still open is up to the caller.
+func ValidateInvitationToken(tokenString string) (*InvitationClaims, error) {
+	claims := &InvitationClaims{}
+	if err := parsePurposeToken(purposeInvitation, tokenString, claims); err != nil {
+		return nil, err
+	}
+
+	if claims.InvitationID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+func TestInvitationTokenIsOnlyValidForInvitations(t *testing.T) {
+	user := &models.User{ID: "user-1", Email: "jane@example.com", Role: models.RoleUser, Status: models.StatusPending}
+	setupRefreshTest(map[string]*models.User{user.ID: user})
+
+	invitation, err := models.NewInvitation("invitation-1", "vet@example.com", models.RoleVet, "", "admin-1", time.Now())
+	if err != nil {
+		t.Fatalf("NewInvitation() error = %v", err)
+	}
+
+	token, err := GenerateInvitationToken(invitation)
+	if err != nil {
+		t.Fatalf("GenerateInvitationToken() error = %v", err)
+	}
+
+	claims, err := ValidateInvitationToken(token)
+	if err != nil {
+		t.Fatalf("ValidateInvitationToken() error = %v", err)
+	}
+
+	if claims.InvitationID != invitation.ID || claims.Email != invitation.Email || claims.Role != models.RoleVet {
+		t.Errorf("claims = %+v, want invitation %s for %s as %s", claims, invitation.ID, invitation.Email, models.RoleVet)
+	}
+
+	if _, err := ValidateEmailVerificationToken(token); err != ErrInvalidToken {
+		t.Errorf("ValidateEmailVerificationToken(invitation token) error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	verificationToken, err := GenerateEmailVerificationToken(user)
+	if err != nil {
+		t.Fatalf("GenerateEmailVerificationToken() error = %v", err)
+	}
+
+	if _, err := ValidateInvitationToken(verificationToken); err != ErrInvalidToken {
+		t.Errorf("ValidateInvitationToken(verification token) error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	expired, _ := models.NewInvitation("invitation-2", "vet@example.com", models.RoleVet, "", "admin-1", time.Now().Add(-models.InvitationExpiration-time.Minute))
+	expiredToken, err := GenerateInvitationToken(expired)
+	if err != nil {
+		t.Fatalf("GenerateInvitationToken() error = %v", err)
+	}
+
+	if _, err := ValidateInvitationToken(expiredToken); err != ErrExpiredToken {
+		t.Errorf("ValidateInvitationToken(expired token) error = %v, want %v", err, ErrExpiredToken)
+	}
+
+	if _, err := models.NewInvitation("invitation-3", "jane@example.com", models.RoleUser, "", "admin-1", time.Now()); err != models.ErrInvalidInvitationRole {
+		t.Errorf("NewInvitation(user role) error = %v, want %v", err, models.ErrInvalidInvitationRole)
+	}
+}
@@ -2,11 +2,11 @@ package auth
 
 import (
 	"errors"
-	"fmt"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/solrac97gr/petparadise/internal/users/domain/models"
 	"github.com/solrac97gr/petparadise/pkg/config"
 )
@@ -15,35 +15,64 @@ import (
 const (
 	AccessTokenExpiration  = time.Minute * 15   // 15 minutes
 	RefreshTokenExpiration = time.Hour * 24 * 7 // 7 days
+	SweepInterval          = time.Minute * 15   // How often expired tokens and revocations are purged
+)
+
+// Authentication methods recorded in the amr claim (RFC 8176)
+const (
+	AMRPassword = "pwd"
+	AMROTP      = "otp" // One-time password from an authenticator app
+	AMRMFA      = "mfa" // A second factor was used
+	AMRExternal = "ext" // Signed in through an external identity provider (not an RFC 8176 value)
 )
 
 var (
 	ErrInvalidToken = errors.New("invalid token")
 	ErrExpiredToken = errors.New("token has expired")
+	ErrRevokedToken = errors.New("token has been revoked")
+	ErrTokenReused  = errors.New("refresh token has already been used")
+	ErrUserNotFound = errors.New("user not found")
+	ErrInactiveUser = errors.New("user account is inactive")
 )
 
 // JWT secret key - will be set from configuration
 var jwtSecret []byte
 
+// refreshTokens stores the issued refresh tokens. It defaults to an in-memory store
+// until a persistent one is set with SetRefreshTokenStore.
+var refreshTokens RefreshTokenStore = NewMemoryRefreshTokenStore()
+
+// UserLoader loads the current state of a user, returning nil when the user does not exist
+type UserLoader func(id string) (*models.User, error)
+
 // InitJWTSecret initializes the JWT secret from config
 func InitJWTSecret(cfg *config.Config) {
 	jwtSecret = []byte(cfg.JWTSecret)
 
-	// Start the cleanup ticker to periodically remove expired tokens
+	// Issue times need sub-second precision to tell tokens issued right after a revoke-all apart
+	jwt.TimePrecision = time.Millisecond
+}
+
+// SetRefreshTokenStore sets the store used to keep track of issued refresh tokens
+func SetRefreshTokenStore(store RefreshTokenStore) {
+	refreshTokens = store
 }
 
 // AccessClaims represents the JWT claims for access tokens
 type AccessClaims struct {
-	UserID string      `json:"user_id"`
-	Email  string      `json:"email"`
-	Role   models.Role `json:"role"`
+	UserID    string      `json:"user_id"`
+	Email     string      `json:"email"`
+	Role      models.Role `json:"role"`
+	AMR       []string    `json:"amr,omitempty"`        // How the user authenticated
+	SessionID string      `json:"sid,omitempty"`        // Session the token was issued for
+	ShelterID string      `json:"shelter_id,omitempty"` // Shelter the user works at, empty for platform admins and the public
 	jwt.RegisteredClaims
 }
 
 // RefreshClaims represents the JWT claims for refresh tokens
 type RefreshClaims struct {
 	UserID  string `json:"user_id"`
-	TokenID string `json:"token_id"` // Unique ID for this refresh token
+	TokenID string `json:"token_id"` // Unique ID for this refresh token, used to look it up in the store
 	jwt.RegisteredClaims
 }
 
@@ -54,19 +83,36 @@ type TokenPair struct {
 	ExpiresIn    int64  `json:"expires_in"` // Seconds until access token expires
 }
 
-// GenerateTokenPair creates a new pair of access and refresh tokens for a user
-func GenerateTokenPair(user *models.User) (*TokenPair, error) {
-	// Generate unique token ID for the refresh token
-	tokenID := fmt.Sprintf("%s-%d", user.ID, time.Now().UnixNano())
+// GenerateTokenPair creates a new pair of access and refresh tokens for a user, starting a new
+// session without device details. amr lists the methods the user authenticated with; tokens
+// rotated from the pair keep them. Logins use StartSession to record the device.
+func GenerateTokenPair(user *models.User, amr ...string) (*TokenPair, error) {
+	return StartSession(user, Device{}, amr...)
+}
 
+// generateTokenPair creates a new pair of access and refresh tokens and stores the refresh token
+func generateTokenPair(user *models.User, tokenID, familyID string, amr []string) (*TokenPair, error) {
 	// Create access token
-	accessToken, err := generateAccessToken(user)
+	accessToken, err := generateAccessToken(user, familyID, amr)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create refresh token
-	refreshToken, err := generateRefreshToken(user.ID, tokenID)
+	now := time.Now()
+	refreshToken, err := generateRefreshToken(user.ID, tokenID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = refreshTokens.Save(&StoredRefreshToken{
+		TokenID:   tokenID,
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(RefreshTokenExpiration),
+		AMR:       amr,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -78,67 +124,46 @@ func GenerateTokenPair(user *models.User) (*TokenPair, error) {
 	}, nil
 }
 
-// generateAccessToken creates a new access token for a user
-func generateAccessToken(user *models.User) (string, error) {
+// generateAccessToken creates a new access token for a session of a user
+func generateAccessToken(user *models.User, sessionID string, amr []string) (string, error) {
 	// Set claims
 	claims := &AccessClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		AMR:       amr,
+		SessionID: sessionID,
+		ShelterID: user.ShelterID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenExpiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Subject:   user.ID,
+			ID:        uuid.New().String(), // Lets the token be revoked on its own
 		},
 	}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign the token
-	tokenString, err := token.SignedString(jwtSecret)
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
+	// Sign the token with the signing key, or the JWT secret when none is set
+	return signAccessToken(claims)
 }
 
-// generateRefreshToken creates a new refresh token for a user
-func generateRefreshToken(userID, tokenID string) (string, error) {
-	// Set claims
-	claims := &RefreshClaims{
+// generateRefreshToken creates a new refresh token for a user. It is signed with the key of its
+// purpose, so it can't be passed off as an access token.
+func generateRefreshToken(userID, tokenID string, issuedAt time.Time) (string, error) {
+	return signPurposeToken(purposeRefreshToken, &RefreshClaims{
 		UserID:  userID,
 		TokenID: tokenID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RefreshTokenExpiration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(RefreshTokenExpiration)),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
 			Subject:   userID,
 		},
-	}
-
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign the token
-	tokenString, err := token.SignedString(jwtSecret)
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
+	})
 }
 
 // ValidateAccessToken validates a JWT access token and returns the claims
 func ValidateAccessToken(tokenString string) (*AccessClaims, error) {
 	// Parse the token
-	token, err := jwt.ParseWithClaims(tokenString, &AccessClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &AccessClaims{}, accessTokenKey)
 
 	// Check for token expiration error specifically
 	if err != nil && strings.Contains(err.Error(), "token is expired") {
@@ -153,6 +178,12 @@ func ValidateAccessToken(tokenString string) (*AccessClaims, error) {
 		if claims.ExpiresAt.Time.Before(time.Now()) {
 			return nil, ErrExpiredToken
 		}
+
+		// Access tokens always have an ID, so they can be revoked. Other tokens signed with the
+		// JWT secret, like refresh tokens issued before they got their own key, don't.
+		if claims.ID == "" || claims.UserID == "" {
+			return nil, ErrInvalidToken
+		}
 		return claims, nil
 	}
 
@@ -161,56 +192,102 @@ func ValidateAccessToken(tokenString string) (*AccessClaims, error) {
 
 // ValidateRefreshToken validates a JWT refresh token and returns the claims
 func ValidateRefreshToken(tokenString string) (*RefreshClaims, error) {
+	claims := &RefreshClaims{}
+	if err := parsePurposeToken(purposeRefreshToken, tokenString, claims); err != nil {
+		return nil, err
+	}
 
-	// Parse the token
-	token, err := jwt.ParseWithClaims(tokenString, &RefreshClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtSecret, nil
-	})
+	return claims, nil
+}
 
-	// Check for token expiration error specifically
-	if err != nil && strings.Contains(err.Error(), "token is expired") {
-		return nil, ErrExpiredToken
+// RefreshTokens exchanges a valid refresh token for a new token pair. Each refresh token can only
+// be used once: the new pair belongs to the same family, and replaying a token that was already
+// used revokes the whole family, since either the legitimate user or an attacker holds a stolen copy.
+// The user is reloaded so the new tokens carry their current role and shelter, and inactive users are refused.
+func RefreshTokens(refreshToken string, loadUser UserLoader) (*TokenPair, error) {
+	return RefreshSession(refreshToken, Device{}, loadUser)
+}
+
+// RefreshSession is RefreshTokens for a request from the device, which is recorded as the
+// session's latest address and user agent
+func RefreshSession(refreshToken string, device Device, loadUser UserLoader) (*TokenPair, error) {
+	// Validate the refresh token
+	claims, err := ValidateRefreshToken(refreshToken)
+	if err == ErrExpiredToken {
+		return nil, err
 	} else if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	stored, err := refreshTokens.FindByID(claims.TokenID)
+	if err != nil {
 		return nil, err
 	}
 
-	// Validate token and extract claims
-	if claims, ok := token.Claims.(*RefreshClaims); ok && token.Valid {
-		// Double check if token has expired
-		if claims.ExpiresAt.Time.Before(time.Now()) {
-			return nil, ErrExpiredToken
+	if stored == nil || stored.UserID != claims.UserID {
+		return nil, ErrInvalidToken
+	}
+
+	if stored.RevokedAt != nil {
+		return nil, ErrRevokedToken
+	}
+
+	revoked, err := isIssuedBeforeCutoff(stored.UserID, stored.IssuedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if revoked {
+		return nil, ErrRevokedToken
+	}
+
+	now := time.Now()
+	if stored.UsedAt != nil {
+		if err := revokeSession(stored.FamilyID, now); err != nil {
+			return nil, err
 		}
-		return claims, nil
+		return nil, ErrTokenReused
 	}
 
-	return nil, ErrInvalidToken
-}
+	// Get the current role and status of the user
+	user, err := loadUser(stored.UserID)
+	if err != nil {
+		return nil, err
+	}
 
-// RefreshTokens uses a valid refresh token to generate a new token pair
-func RefreshTokens(refreshToken string) (*TokenPair, error) {
-	// Validate the refresh token
-	claims, err := ValidateRefreshToken(refreshToken)
+	if user == nil || !user.Status.IsEquals(models.StatusActive) {
+		if err := revokeSession(stored.FamilyID, now); err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInactiveUser
+	}
+
+	// Rotate the refresh token; losing the race to a concurrent refresh counts as reuse
+	newTokenID := uuid.New().String()
+	marked, err := refreshTokens.MarkUsed(stored.TokenID, newTokenID, now)
 	if err != nil {
 		return nil, err
 	}
 
-	// In a real application, you would verify the user exists and is still active
-	// For simplicity, we'll create a minimal user object with the ID from the claims
-	user := &models.User{
-		ID: claims.UserID,
-		// In a real implementation, you would fetch other user data from the database
-		Role: models.RoleUser, // Default role - in real implementation, get from DB
+	if !marked {
+		if err := revokeSession(stored.FamilyID, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrTokenReused
 	}
 
 	// Generate new token pair
-	tokenPair, err := GenerateTokenPair(user)
+	pair, err := generateTokenPair(user, newTokenID, stored.FamilyID, stored.AMR)
 	if err != nil {
 		return nil, err
 	}
 
-	return tokenPair, nil
+	if err := sessions.Touch(stored.FamilyID, device, now, now.Add(RefreshTokenExpiration)); err != nil {
+		return nil, err
+	}
+
+	return pair, nil
 }
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/config"
+)
+
+// setupRefreshTest initializes the package with a test secret, an empty in-memory store
+// and a user loader backed by the given users
+func setupRefreshTest(users map[string]*models.User) UserLoader {
+	InitJWTSecret(&config.Config{JWTSecret: "test-secret"})
+	SetRefreshTokenStore(NewMemoryRefreshTokenStore())
+
+	return func(id string) (*models.User, error) {
+		return users[id], nil
+	}
+}
+
+func TestRefreshTokensRotatesAndReloadsRole(t *testing.T) {
+	user := &models.User{ID: "user-1", Email: "admin@example.com", Role: models.RoleAdmin, Status: models.StatusActive}
+	loadUser := setupRefreshTest(map[string]*models.User{user.ID: user})
+
+	pair, err := GenerateTokenPair(user)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	refreshed, err := RefreshTokens(pair.RefreshToken, loadUser)
+	if err != nil {
+		t.Fatalf("RefreshTokens() error = %v", err)
+	}
+
+	if refreshed.RefreshToken == pair.RefreshToken {
+		t.Fatal("RefreshTokens() did not rotate the refresh token")
+	}
+
+	claims, err := ValidateAccessToken(refreshed.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+
+	if !claims.Role.IsEquals(models.RoleAdmin) {
+		t.Errorf("refreshed role = %q, want %q", claims.Role, models.RoleAdmin)
+	}
+}
+
+func TestRefreshTokensReuseRevokesFamily(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	loadUser := setupRefreshTest(map[string]*models.User{user.ID: user})
+
+	pair, err := GenerateTokenPair(user)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	refreshed, err := RefreshTokens(pair.RefreshToken, loadUser)
+	if err != nil {
+		t.Fatalf("RefreshTokens() error = %v", err)
+	}
+
+	if _, err := RefreshTokens(pair.RefreshToken, loadUser); err != ErrTokenReused {
+		t.Fatalf("replaying a used token: error = %v, want %v", err, ErrTokenReused)
+	}
+
+	if _, err := RefreshTokens(refreshed.RefreshToken, loadUser); err != ErrRevokedToken {
+		t.Errorf("using the rotated token after reuse: error = %v, want %v", err, ErrRevokedToken)
+	}
+}
+
+func TestRefreshTokensRejectsInactiveAndUnknownUsers(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	users := map[string]*models.User{user.ID: user}
+	loadUser := setupRefreshTest(users)
+
+	pair, err := GenerateTokenPair(user)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	users[user.ID] = &models.User{ID: user.ID, Role: models.RoleUser, Status: models.StatusSuspended}
+	if _, err := RefreshTokens(pair.RefreshToken, loadUser); err != ErrInactiveUser {
+		t.Errorf("suspended user: error = %v, want %v", err, ErrInactiveUser)
+	}
+
+	pair, err = GenerateTokenPair(user)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	delete(users, user.ID)
+	if _, err := RefreshTokens(pair.RefreshToken, loadUser); err != ErrUserNotFound {
+		t.Errorf("deleted user: error = %v, want %v", err, ErrUserNotFound)
+	}
+}
+
+func TestRefreshTokensRejectsUnknownTokens(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	loadUser := setupRefreshTest(map[string]*models.User{user.ID: user})
+
+	// A correctly signed token that was never stored, e.g. issued before a store was configured
+	token, err := generateRefreshToken(user.ID, "unknown-token", time.Now())
+	if err != nil {
+		t.Fatalf("generateRefreshToken() error = %v", err)
+	}
+
+	if _, err := RefreshTokens(token, loadUser); err != ErrInvalidToken {
+		t.Errorf("unknown token: error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	if _, err := RefreshTokens("not.a.token", loadUser); err != ErrInvalidToken {
+		t.Errorf("malformed token: error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestEmailVerificationTokenCannotBeUsedAsAccessToken(t *testing.T) {
+	user := &models.User{ID: "user-1", Email: "jane@example.com", Role: models.RoleUser, Status: models.StatusPending}
+	setupRefreshTest(map[string]*models.User{user.ID: user})
+
+	token, err := GenerateEmailVerificationToken(user)
+	if err != nil {
+		t.Fatalf("GenerateEmailVerificationToken() error = %v", err)
+	}
+
+	claims, err := ValidateEmailVerificationToken(token)
+	if err != nil {
+		t.Fatalf("ValidateEmailVerificationToken() error = %v", err)
+	}
+
+	if claims.UserID != user.ID || claims.Email != user.Email {
+		t.Errorf("claims = %+v, want user %s with email %s", claims, user.ID, user.Email)
+	}
+
+	if _, err := ValidateAccessToken(token); err == nil {
+		t.Error("ValidateAccessToken() accepted an email verification token")
+	}
+
+	pair, err := GenerateTokenPair(user)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	if _, err := ValidateEmailVerificationToken(pair.AccessToken); err != ErrInvalidToken {
+		t.Errorf("ValidateEmailVerificationToken(access token) error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestRefreshTokenCannotBeUsedAsAccessToken(t *testing.T) {
+	user := &models.User{ID: "user-1", Email: "jane@example.com", Role: models.RoleUser, Status: models.StatusActive}
+	loadUser := setupRefreshTest(map[string]*models.User{user.ID: user})
+
+	pair, err := GenerateTokenPair(user)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	if _, err := ValidateAccessToken(pair.RefreshToken); err == nil {
+		t.Error("ValidateAccessToken() accepted a refresh token")
+	}
+
+	if _, err := ValidateRefreshToken(pair.AccessToken); err != ErrInvalidToken {
+		t.Errorf("ValidateRefreshToken(access token) error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	// Refresh tokens issued before they got their own key were signed with the JWT secret
+	legacy, err := signAccessToken(&RefreshClaims{
+		UserID:  user.ID,
+		TokenID: "legacy-token",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RefreshTokenExpiration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   user.ID,
+		},
+	})
+	if err != nil {
+		t.Fatalf("signAccessToken() error = %v", err)
+	}
+
+	if _, err := ValidateAccessToken(legacy); err != ErrInvalidToken {
+		t.Errorf("ValidateAccessToken(legacy refresh token) error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	if _, err := RefreshTokens(pair.RefreshToken, loadUser); err != nil {
+		t.Errorf("RefreshTokens() error = %v", err)
+	}
+}
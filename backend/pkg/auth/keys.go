@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeyBits is the smallest RSA key accepted for signing tokens
+const minRSAKeyBits = 2048
+
+var (
+	ErrUnsupportedKey = errors.New("unsupported key, use an RSA key of at least 2048 bits or an Ed25519 key")
+	ErrNotPrivateKey  = errors.New("signing key must be a private key")
+	ErrUnknownKeyID   = errors.New("token is signed with an unknown key")
+)
+
+// SigningKey is an asymmetric key access tokens are signed or verified with. Keys are identified
+// by their JWK thumbprint (RFC 7638), sent as the kid header of the tokens they sign.
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod // RS256 for RSA keys, EdDSA for Ed25519 keys
+	private crypto.Signer     // Nil for keys only used to verify tokens
+	public  crypto.PublicKey
+}
+
+// accessTokenKeys holds the asymmetric keys of access tokens. While it is nil, access tokens are
+// signed with the JWT secret (HS256) and no keys are published.
+var accessTokenKeys *keySet
+
+// keySet is the key new access tokens are signed with, and every key they are verified with
+type keySet struct {
+	signing      *SigningKey
+	verification []*SigningKey // The signing key first
+}
+
+// find finds a verification key by its ID
+func (s *keySet) find(id string) (*SigningKey, bool) {
+	for _, key := range s.verification {
+		if key.ID == id {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// SetSigningKeys sets the key access tokens are signed with. The other keys are still accepted to
+// verify tokens, so tokens signed with a previous key stay valid until they expire, and a key can
+// be published before it starts signing. Without a signing key, tokens are signed with the JWT secret.
+func SetSigningKeys(signing *SigningKey, verification ...*SigningKey) error {
+	if signing == nil {
+		accessTokenKeys = nil
+		return nil
+	}
+
+	if signing.private == nil {
+		return ErrNotPrivateKey
+	}
+
+	keys := &keySet{
+		signing:      signing,
+		verification: []*SigningKey{signing},
+	}
+	for _, key := range verification {
+		if _, ok := keys.find(key.ID); !ok {
+			keys.verification = append(keys.verification, key)
+		}
+	}
+
+	accessTokenKeys = keys
+	return nil
+}
+
+// LoadSigningKeys reads the signing key and the verification keys of access tokens from PEM files
+// and sets them with SetSigningKeys
+func LoadSigningKeys(signingKeyFile string, verificationKeyFiles []string) error {
+	data, err := os.ReadFile(signingKeyFile)
+	if err != nil {
+		return err
+	}
+
+	signing, err := ParsePrivateKeyPEM(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", signingKeyFile, err)
+	}
+
+	var verification []*SigningKey
+	for _, file := range verificationKeyFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		key, err := ParsePublicKeyPEM(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		verification = append(verification, key)
+	}
+
+	return SetSigningKeys(signing, verification...)
+}
+
+// ParsePrivateKeyPEM parses an RSA or Ed25519 private key, in PKCS #8 or PKCS #1 (RSA only) form
+func ParsePrivateKeyPEM(data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, ErrNotPrivateKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	key, err := newSigningKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	key.private = signer
+	return key, nil
+}
+
+// ParsePublicKeyPEM parses an RSA or Ed25519 public key in PKIX form. Private keys are accepted
+// too, and only their public part is kept.
+func ParsePublicKeyPEM(data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	if block.Type != "PUBLIC KEY" {
+		key, err := ParsePrivateKeyPEM(data)
+		if err != nil {
+			return nil, err
+		}
+
+		key.private = nil
+		return key, nil
+	}
+
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSigningKey(public)
+}
+
+// newSigningKey picks the signing method of a public key and computes its ID
+func newSigningKey(public crypto.PublicKey) (*SigningKey, error) {
+	key := &SigningKey{public: public}
+
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSAKeyBits {
+			return nil, ErrUnsupportedKey
+		}
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	// The thumbprint hashes the required members of the JWK, in lexicographic order
+	jwk := key.JWK()
+	var thumbprintInput []byte
+	if jwk.Kty == "RSA" {
+		thumbprintInput, _ = json.Marshal(struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N})
+	} else {
+		thumbprintInput, _ = json.Marshal(struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X})
+	}
+
+	sum := sha256.Sum256(thumbprintInput)
+	key.ID = base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return key, nil
+}
+
+// JWK is a public key in JSON Web Key form (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // Curve of OKP keys
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKSet is a JSON Web Key Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public part of the key as a JSON Web Key
+func (k *SigningKey) JWK() JWK {
+	jwk := JWK{Use: "sig", Alg: k.Method.Alg(), Kid: k.ID}
+
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+
+	return jwk
+}
+
+// PublicKeys returns every key access tokens are verified with, the signing key first.
+// It is empty while access tokens are signed with the JWT secret.
+func PublicKeys() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	if accessTokenKeys == nil {
+		return set
+	}
+
+	for _, key := range accessTokenKeys.verification {
+		set.Keys = append(set.Keys, key.JWK())
+	}
+
+	return set
+}
+
+// JWKSHandler serves the keys access tokens are verified with, so other services can verify them
+func JWKSHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(PublicKeys())
+	}
+}
+
+// signAccessToken signs the claims of an access token with the signing key, or the JWT secret
+func signAccessToken(claims jwt.Claims) (string, error) {
+	if accessTokenKeys == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+	}
+
+	key := accessTokenKeys.signing
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	token.Header["typ"] = "at+jwt" // Tells access tokens apart from other JWTs (RFC 9068)
+
+	return token.SignedString(key.private)
+}
+
+// accessTokenKey returns the key an access token is verified with. Once signing keys are set,
+// tokens signed with the JWT secret are refused.
+func accessTokenKey(token *jwt.Token) (any, error) {
+	if accessTokenKeys == nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return jwtSecret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := accessTokenKeys.find(kid)
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.public, nil
+}
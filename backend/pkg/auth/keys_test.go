@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// newTestKey generates a private key and parses it back from its PKCS #8 PEM form
+func newTestKey(t *testing.T, private any) (*SigningKey, []byte) {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	key, err := ParsePrivateKeyPEM(data)
+	if err != nil {
+		t.Fatalf("ParsePrivateKeyPEM() error = %v", err)
+	}
+
+	return key, data
+}
+
+func newTestRSAKey(t *testing.T) (*SigningKey, []byte) {
+	t.Helper()
+
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+
+	return newTestKey(t, private)
+}
+
+func newTestEd25519Key(t *testing.T) (*SigningKey, []byte) {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() error = %v", err)
+	}
+
+	return newTestKey(t, private)
+}
+
+func TestAccessTokensSignedWithAsymmetricKeys(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	setupRefreshTest(map[string]*models.User{user.ID: user})
+	defer SetSigningKeys(nil)
+
+	rsaKey, _ := newTestRSAKey(t)
+	edKey, _ := newTestEd25519Key(t)
+
+	for _, key := range []*SigningKey{rsaKey, edKey} {
+		if err := SetSigningKeys(key); err != nil {
+			t.Fatalf("SetSigningKeys() error = %v", err)
+		}
+
+		pair, err := GenerateTokenPair(user, AMRPassword)
+		if err != nil {
+			t.Fatalf("GenerateTokenPair() error = %v", err)
+		}
+
+		token, _, err := jwt.NewParser().ParseUnverified(pair.AccessToken, &AccessClaims{})
+		if err != nil {
+			t.Fatalf("ParseUnverified() error = %v", err)
+		}
+
+		if token.Header["kid"] != key.ID || token.Header["alg"] != key.Method.Alg() {
+			t.Errorf("header = %v, want kid %s and alg %s", token.Header, key.ID, key.Method.Alg())
+		}
+
+		if _, err := ValidateAccessToken(pair.AccessToken); err != nil {
+			t.Errorf("%s: ValidateAccessToken() error = %v", key.Method.Alg(), err)
+		}
+
+		// Refresh tokens aren't published to other services and stay signed with the secret
+		if _, err := ValidateRefreshToken(pair.RefreshToken); err != nil {
+			t.Errorf("%s: ValidateRefreshToken() error = %v", key.Method.Alg(), err)
+		}
+	}
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	setupRefreshTest(map[string]*models.User{user.ID: user})
+	defer SetSigningKeys(nil)
+
+	secretPair, err := GenerateTokenPair(user)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	oldKey, _ := newTestRSAKey(t)
+	newKey, _ := newTestEd25519Key(t)
+
+	if err := SetSigningKeys(oldKey); err != nil {
+		t.Fatalf("SetSigningKeys() error = %v", err)
+	}
+
+	oldPair, err := GenerateTokenPair(user)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	// The new key signs, the old one is still accepted
+	if err := SetSigningKeys(newKey, oldKey); err != nil {
+		t.Fatalf("SetSigningKeys() error = %v", err)
+	}
+
+	if _, err := ValidateAccessToken(oldPair.AccessToken); err != nil {
+		t.Errorf("token of the previous key: ValidateAccessToken() error = %v", err)
+	}
+
+	if _, err := ValidateAccessToken(secretPair.AccessToken); err == nil {
+		t.Error("ValidateAccessToken() accepted a token signed with the secret once signing keys are set")
+	}
+
+	// Once the old key is dropped, its tokens are refused
+	if err := SetSigningKeys(newKey); err != nil {
+		t.Fatalf("SetSigningKeys() error = %v", err)
+	}
+
+	if _, err := ValidateAccessToken(oldPair.AccessToken); err == nil {
+		t.Error("ValidateAccessToken() accepted a token of a dropped key")
+	}
+}
+
+func TestLoadSigningKeysAndJWKS(t *testing.T) {
+	defer SetSigningKeys(nil)
+
+	signing, signingPEM := newTestEd25519Key(t)
+	previous, _ := newTestRSAKey(t)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(previous.public)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	signingFile := filepath.Join(dir, "signing.pem")
+	previousFile := filepath.Join(dir, "previous.pem")
+	if err := os.WriteFile(signingFile, signingPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(previousFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadSigningKeys(previousFile, nil); !errors.Is(err, ErrNotPrivateKey) {
+		t.Errorf("LoadSigningKeys() with a public key error = %v, want %v", err, ErrNotPrivateKey)
+	}
+
+	if err := LoadSigningKeys(signingFile, []string{previousFile}); err != nil {
+		t.Fatalf("LoadSigningKeys() error = %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/.well-known/jwks.json", JWKSHandler())
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+
+	var set JWKSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		t.Fatalf("decoding the key set: %v", err)
+	}
+
+	if len(set.Keys) != 2 {
+		t.Fatalf("published %d keys, want 2", len(set.Keys))
+	}
+
+	if got := set.Keys[0]; got.Kid != signing.ID || got.Kty != "OKP" || got.Crv != "Ed25519" || got.Alg != "EdDSA" {
+		t.Errorf("signing key = %+v", got)
+	}
+
+	if got := set.Keys[1]; got.Kid != previous.ID || got.Kty != "RSA" || got.N == "" || got.E != "AQAB" || got.Alg != "RS256" {
+		t.Errorf("previous key = %+v", got)
+	}
+}
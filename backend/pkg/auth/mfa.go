@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// MFAChallengeExpiration is how long a user has to enter their second factor after their password
+const MFAChallengeExpiration = time.Minute * 5
+
+// MFAChallengeClaims represents the JWT claims of the token handed out between the two login steps.
+// It proves the first factor was checked, and nothing else.
+type MFAChallengeClaims struct {
+	UserID string   `json:"user_id"`
+	AMR    []string `json:"amr,omitempty"` // How the user passed the first step
+	jwt.RegisteredClaims
+}
+
+// GenerateMFAChallengeToken creates the token a user exchanges for a token pair with their second factor.
+// amr lists the methods of the first step, which the final tokens carry along with the second factor.
+func GenerateMFAChallengeToken(user *models.User, amr ...string) (string, error) {
+	now := time.Now()
+	return signPurposeToken(purposeMFAChallenge, &MFAChallengeClaims{
+		UserID: user.ID,
+		AMR:    amr,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(MFAChallengeExpiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Subject:   user.ID,
+			ID:        uuid.New().String(), // Lets the challenge be used only once
+		},
+	})
+}
+
+// ValidateMFAChallengeToken validates an MFA challenge token that hasn't been used yet and returns its claims
+func ValidateMFAChallengeToken(tokenString string) (*MFAChallengeClaims, error) {
+	claims := &MFAChallengeClaims{}
+	if err := parsePurposeToken(purposeMFAChallenge, tokenString, claims); err != nil {
+		return nil, err
+	}
+
+	if claims.UserID == "" || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	revoked, err := revocations.IsTokenRevoked(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if revoked {
+		return nil, ErrRevokedToken
+	}
+
+	return claims, nil
+}
+
+// RevokeMFAChallenge prevents an MFA challenge token from being used again
+func RevokeMFAChallenge(claims *MFAChallengeClaims) error {
+	return revocations.RevokeToken(claims.ID, claims.ExpiresAt.Time)
+}
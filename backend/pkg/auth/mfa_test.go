@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// sensitiveStatus calls a route protected by MFARequired with the given access token
+func sensitiveStatus(t *testing.T, accessToken string) int {
+	t.Helper()
+
+	app := fiber.New()
+	app.Delete("/", Protected(), MFARequired(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	req := httptest.NewRequest("DELETE", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+
+	return resp.StatusCode
+}
+
+func TestMFARequiredEnforcedPerRole(t *testing.T) {
+	admin := &models.User{ID: "admin-1", Role: models.RoleAdmin, Status: models.StatusActive}
+	vet := &models.User{ID: "vet-1", Role: models.RoleVet, Status: models.StatusActive}
+	setupRefreshTest(map[string]*models.User{admin.ID: admin, vet.ID: vet})
+	SetRevocationStore(NewMemoryRevocationStore())
+	SetMFARequiredRoles(models.RoleAdmin)
+	defer SetMFARequiredRoles()
+
+	tests := []struct {
+		name string
+		user *models.User
+		amr  []string
+		want int
+	}{
+		{"admin with password only", admin, []string{AMRPassword}, fiber.StatusForbidden},
+		{"admin with a second factor", admin, []string{AMRPassword, AMROTP, AMRMFA}, fiber.StatusNoContent},
+		{"role without enforcement", vet, []string{AMRPassword}, fiber.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		pair, err := GenerateTokenPair(tt.user, tt.amr...)
+		if err != nil {
+			t.Fatalf("GenerateTokenPair() error = %v", err)
+		}
+
+		if status := sensitiveStatus(t, pair.AccessToken); status != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, status, tt.want)
+		}
+	}
+}
+
+func TestRefreshTokensKeepsAMR(t *testing.T) {
+	user := &models.User{ID: "admin-1", Role: models.RoleAdmin, Status: models.StatusActive}
+	loadUser := setupRefreshTest(map[string]*models.User{user.ID: user})
+
+	pair, err := GenerateTokenPair(user, AMRPassword, AMROTP, AMRMFA)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	refreshed, err := RefreshTokens(pair.RefreshToken, loadUser)
+	if err != nil {
+		t.Fatalf("RefreshTokens() error = %v", err)
+	}
+
+	claims, err := ValidateAccessToken(refreshed.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+
+	if len(claims.AMR) != 3 || claims.AMR[2] != AMRMFA {
+		t.Errorf("refreshed amr = %v, want [%s %s %s]", claims.AMR, AMRPassword, AMROTP, AMRMFA)
+	}
+}
+
+func TestMFAChallengeCanOnlyBeUsedOnce(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	setupRefreshTest(map[string]*models.User{user.ID: user})
+	SetRevocationStore(NewMemoryRevocationStore())
+
+	token, err := GenerateMFAChallengeToken(user, AMRExternal)
+	if err != nil {
+		t.Fatalf("GenerateMFAChallengeToken() error = %v", err)
+	}
+
+	if _, err := ValidateAccessToken(token); err == nil {
+		t.Error("ValidateAccessToken() accepted an MFA challenge token")
+	}
+
+	claims, err := ValidateMFAChallengeToken(token)
+	if err != nil {
+		t.Fatalf("ValidateMFAChallengeToken() error = %v", err)
+	}
+
+	if len(claims.AMR) != 1 || claims.AMR[0] != AMRExternal {
+		t.Errorf("challenge amr = %v, want [%s]", claims.AMR, AMRExternal)
+	}
+
+	if err := RevokeMFAChallenge(claims); err != nil {
+		t.Fatalf("RevokeMFAChallenge() error = %v", err)
+	}
+
+	if _, err := ValidateMFAChallengeToken(token); err != ErrRevokedToken {
+		t.Errorf("ValidateMFAChallengeToken() after use error = %v, want %v", err, ErrRevokedToken)
+	}
+}
@@ -1,15 +1,22 @@
 package auth
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/solrac97gr/petparadise/internal/users/domain/models"
 )
 
-// Protected is a middleware that checks if the request has a valid JWT token
+// Protected is a middleware that checks if the request has a valid JWT token, or a valid API key
+// in the X-API-Key header
 func Protected() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// Machine clients authenticate with an API key instead of a user's token
+		if apiKey := c.Get(APIKeyHeader); apiKey != "" {
+			return authenticateAPIKey(c, apiKey)
+		}
+
 		// Get the Authorization header
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
@@ -44,10 +51,28 @@ func Protected() fiber.Handler {
 			})
 		}
 
+		// Reject tokens revoked by a logout or a revoke-all
+		revoked, err := isAccessTokenRevoked(claims)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check token revocation",
+			})
+		}
+
+		if revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Token has been revoked",
+				"code":  "token_revoked",
+			})
+		}
+
 		// Set claims in context for use in handlers
 		c.Locals("userID", claims.UserID)
 		c.Locals("email", claims.Email)
 		c.Locals("role", claims.Role)
+		c.Locals("amr", claims.AMR)
+		c.Locals("sessionID", claims.SessionID)
+		c.Locals("shelterID", claims.ShelterID)
 		c.Locals("accessToken", tokenString) // Store the access token for potential revocation
 
 		// Continue to next middleware/handler
@@ -55,10 +80,29 @@ func Protected() fiber.Handler {
 	}
 }
 
-// RoleRequired is a middleware that checks if the user has the required role
-func RoleRequired(roles ...models.Role) fiber.Handler {
+// mfaRequiredRoles are the roles that must use a second factor on sensitive routes
+var mfaRequiredRoles []models.Role
+
+// SetMFARequiredRoles sets the roles that must use a second factor on sensitive routes
+func SetMFARequiredRoles(roles ...models.Role) {
+	mfaRequiredRoles = roles
+}
+
+// IsMFARequired checks if users with the given role must use a second factor on sensitive routes
+func IsMFARequired(role models.Role) bool {
+	for _, r := range mfaRequiredRoles {
+		if r.IsEquals(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// MFARequired is a middleware for sensitive routes. Users who enabled two-factor authentication
+// always log in with it; users whose role enforces it and who haven't enabled it are refused.
+func MFARequired() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Get user role from context (set by Protected middleware)
+		// Get user role and authentication methods from context (set by Protected middleware)
 		userRole, ok := c.Locals("role").(models.Role)
 		if !ok {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -66,21 +110,14 @@ func RoleRequired(roles ...models.Role) fiber.Handler {
 			})
 		}
 
-		// Admin role always has access
-		if userRole.IsEquals(models.RoleAdmin) {
-			return c.Next()
-		}
-
-		// Check if user has one of the required roles
-		for _, role := range roles {
-			if userRole.IsEquals(role) {
-				return c.Next()
-			}
+		amr, _ := c.Locals("amr").([]string)
+		if IsMFARequired(userRole) && !slices.Contains(amr, AMRMFA) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Two-factor authentication is required for this action",
+				"code":  "mfa_required",
+			})
 		}
 
-		// If we get here, the user doesn't have the required role
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Insufficient permissions",
-		})
+		return c.Next()
 	}
 }
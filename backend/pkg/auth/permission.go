@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// permissions stores the permissions of each role. It defaults to an in-memory store
+// until a persistent one is set with SetPermissionStore.
+var permissions PermissionStore = NewMemoryPermissionStore()
+
+// SetPermissionStore sets the store used to look up the permissions of each role
+func SetPermissionStore(store PermissionStore) {
+	permissions = store
+}
+
+// PermissionStore keeps the permissions of each role. Roles that were never edited have
+// their models.DefaultRolePermissions.
+type PermissionStore interface {
+	RolePermissions(role models.Role) ([]models.Permission, error)
+	SetRolePermissions(role models.Role, permissions []models.Permission, updatedBy string, updatedAt time.Time) error
+}
+
+// RolePermissions returns the permissions of a role
+func RolePermissions(role models.Role) ([]models.Permission, error) {
+	return permissions.RolePermissions(role)
+}
+
+// SetRolePermissions replaces the permissions of a role; they apply to the next request of every user with it
+func SetRolePermissions(role models.Role, rolePermissions []models.Permission, updatedBy string) error {
+	return permissions.SetRolePermissions(role, rolePermissions, updatedBy, time.Now())
+}
+
+// HasPermission checks if a role has every one of the given permissions
+func HasPermission(role models.Role, required ...models.Permission) (bool, error) {
+	granted, err := permissions.RolePermissions(role)
+	if err != nil {
+		return false, err
+	}
+
+	for _, permission := range required {
+		if !slices.Contains(granted, permission) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Allowed checks if the authenticated request may perform actions needing every one of the given
+// permissions: those of the user's role, or those granted to the API key it was made with
+func Allowed(c *fiber.Ctx, required ...models.Permission) (bool, error) {
+	if key, ok := c.Locals("apiKey").(*models.APIKey); ok {
+		return key.HasPermission(required...), nil
+	}
+
+	// Get user role from context (set by Protected middleware)
+	userRole, ok := c.Locals("role").(models.Role)
+	if !ok {
+		return false, nil
+	}
+
+	return HasPermission(userRole, required...)
+}
+
+// RequirePermission is a middleware that checks if the user's role, or the API key the request
+// was made with, has every one of the given permissions
+func RequirePermission(required ...models.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		allowed, err := Allowed(c, required...)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check permissions",
+			})
+		}
+
+		if !allowed {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Insufficient permissions",
+				"code":  "permission_denied",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// MemoryPermissionStore is an in-memory PermissionStore, used when no database store is configured and in tests
+type MemoryPermissionStore struct {
+	mu    sync.Mutex
+	roles map[models.Role][]models.Permission
+}
+
+// NewMemoryPermissionStore creates a new MemoryPermissionStore
+func NewMemoryPermissionStore() *MemoryPermissionStore {
+	return &MemoryPermissionStore{
+		roles: make(map[models.Role][]models.Permission),
+	}
+}
+
+// RolePermissions returns the permissions of a role
+func (s *MemoryPermissionStore) RolePermissions(role models.Role) ([]models.Permission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rolePermissions, ok := s.roles[role]; ok {
+		return slices.Clone(rolePermissions), nil
+	}
+
+	return slices.Clone(models.DefaultRolePermissions[role]), nil
+}
+
+// SetRolePermissions replaces the permissions of a role
+func (s *MemoryPermissionStore) SetRolePermissions(role models.Role, rolePermissions []models.Permission, updatedBy string, updatedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.roles[role] = slices.Clone(rolePermissions)
+	return nil
+}
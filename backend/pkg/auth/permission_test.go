@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// permissionStatus calls a route that needs the pets:write permission as the given user
+func permissionStatus(t *testing.T, user *models.User) int {
+	t.Helper()
+
+	pair, err := GenerateTokenPair(user, AMRPassword)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	app := fiber.New()
+	app.Post("/", Protected(), RequirePermission(models.PermissionPetsWrite), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+
+	return resp.StatusCode
+}
+
+func TestRequirePermissionUsesDefaults(t *testing.T) {
+	admin := &models.User{ID: "admin-1", Role: models.RoleAdmin, Status: models.StatusActive}
+	volunteer := &models.User{ID: "volunteer-1", Role: models.RoleVolunteer, Status: models.StatusActive}
+	user := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	setupRefreshTest(map[string]*models.User{admin.ID: admin, volunteer.ID: volunteer, user.ID: user})
+	SetRevocationStore(NewMemoryRevocationStore())
+	SetPermissionStore(NewMemoryPermissionStore())
+
+	tests := []struct {
+		name string
+		user *models.User
+		want int
+	}{
+		{"admin", admin, fiber.StatusCreated},
+		{"volunteer", volunteer, fiber.StatusCreated},
+		{"regular user", user, fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		if status := permissionStatus(t, tt.user); status != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, status, tt.want)
+		}
+	}
+}
+
+func TestEditedRolePermissionsApplyImmediately(t *testing.T) {
+	admin := &models.User{ID: "admin-1", Role: models.RoleAdmin, Status: models.StatusActive}
+	volunteer := &models.User{ID: "volunteer-1", Role: models.RoleVolunteer, Status: models.StatusActive}
+	setupRefreshTest(map[string]*models.User{admin.ID: admin, volunteer.ID: volunteer})
+	SetRevocationStore(NewMemoryRevocationStore())
+	SetPermissionStore(NewMemoryPermissionStore())
+	defer SetPermissionStore(NewMemoryPermissionStore())
+
+	if err := SetRolePermissions(models.RoleVolunteer, []models.Permission{models.PermissionAdoptionsRead}, admin.ID); err != nil {
+		t.Fatalf("SetRolePermissions() error = %v", err)
+	}
+
+	if status := permissionStatus(t, volunteer); status != fiber.StatusForbidden {
+		t.Errorf("volunteer without pets:write: status = %d, want %d", status, fiber.StatusForbidden)
+	}
+
+	// Admins have no implicit bypass: a permission taken away from the role is denied to them too
+	if err := SetRolePermissions(models.RoleAdmin, []models.Permission{models.PermissionRolesManage}, admin.ID); err != nil {
+		t.Fatalf("SetRolePermissions() error = %v", err)
+	}
+
+	if status := permissionStatus(t, admin); status != fiber.StatusForbidden {
+		t.Errorf("admin without pets:write: status = %d, want %d", status, fiber.StatusForbidden)
+	}
+
+	granted, err := HasPermission(models.RoleAdmin, models.PermissionRolesManage)
+	if err != nil {
+		t.Fatalf("HasPermission() error = %v", err)
+	}
+
+	if !granted {
+		t.Error("HasPermission() = false for a permission the admin role kept")
+	}
+}
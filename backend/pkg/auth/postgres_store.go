@@ -0,0 +1,514 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/database"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// PostgresRefreshTokenStore implements the RefreshTokenStore interface.
+type PostgresRefreshTokenStore struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRefreshTokenStore creates a new PostgresRefreshTokenStore
+func NewPostgresRefreshTokenStore(db *sqlx.DB) *PostgresRefreshTokenStore {
+	return &PostgresRefreshTokenStore{
+		db: db,
+	}
+}
+
+// Save stores a refresh token
+func (s *PostgresRefreshTokenStore) Save(token *StoredRefreshToken) error {
+	query := `INSERT INTO refresh_tokens (id, family_id, user_id, issued_at, expires_at, amr)
+              VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := s.db.Exec(
+		query,
+		token.TokenID,
+		token.FamilyID,
+		token.UserID,
+		token.IssuedAt.UTC(),
+		token.ExpiresAt.UTC(),
+		pq.Array(token.AMR),
+	)
+
+	return err
+}
+
+// FindByID finds a refresh token by its ID
+func (s *PostgresRefreshTokenStore) FindByID(tokenID string) (*StoredRefreshToken, error) {
+	var token StoredRefreshToken
+	var usedAt, revokedAt sql.NullTime
+	var replacedBy sql.NullString
+
+	query := `SELECT id, family_id, user_id, issued_at, expires_at, used_at, replaced_by, revoked_at, amr
+              FROM refresh_tokens WHERE id = $1`
+
+	err := s.db.QueryRow(query, tokenID).Scan(
+		&token.TokenID,
+		&token.FamilyID,
+		&token.UserID,
+		&token.IssuedAt,
+		&token.ExpiresAt,
+		&usedAt,
+		&replacedBy,
+		&revokedAt,
+		pq.Array(&token.AMR),
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	token.ReplacedBy = replacedBy.String
+
+	return &token, nil
+}
+
+// MarkUsed records that a refresh token was exchanged for a new one
+func (s *PostgresRefreshTokenStore) MarkUsed(tokenID, replacedBy string, usedAt time.Time) (bool, error) {
+	query := `UPDATE refresh_tokens SET used_at = $1, replaced_by = $2
+              WHERE id = $3 AND used_at IS NULL AND revoked_at IS NULL`
+
+	result, err := s.db.Exec(query, usedAt.UTC(), replacedBy, tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// RevokeFamily revokes every refresh token of a family
+func (s *PostgresRefreshTokenStore) RevokeFamily(familyID string, revokedAt time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL`
+	_, err := s.db.Exec(query, revokedAt.UTC(), familyID)
+	return err
+}
+
+// PurgeExpired removes the refresh tokens that have expired
+func (s *PostgresRefreshTokenStore) PurgeExpired(now time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM refresh_tokens WHERE expires_at < $1`, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// PostgresRevocationStore implements the RevocationStore interface
+type PostgresRevocationStore struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRevocationStore creates a new PostgresRevocationStore
+func NewPostgresRevocationStore(db *sqlx.DB) *PostgresRevocationStore {
+	return &PostgresRevocationStore{
+		db: db,
+	}
+}
+
+// RevokeToken revokes a single token until it expires
+func (s *PostgresRevocationStore) RevokeToken(tokenID string, expiresAt time.Time) error {
+	query := `INSERT INTO revoked_tokens (token_id, expires_at) VALUES ($1, $2)
+              ON CONFLICT (token_id) DO NOTHING`
+	_, err := s.db.Exec(query, tokenID, expiresAt.UTC())
+	return err
+}
+
+// IsTokenRevoked checks if a token has been revoked
+func (s *PostgresRevocationStore) IsTokenRevoked(tokenID string) (bool, error) {
+	var revoked bool
+	err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE token_id = $1)`, tokenID).Scan(&revoked)
+	return revoked, err
+}
+
+// RevokeUserTokensBefore revokes every token of a user issued up to the cutoff
+func (s *PostgresRevocationStore) RevokeUserTokensBefore(userID string, cutoff time.Time) error {
+	query := `INSERT INTO user_token_cutoffs (user_id, revoked_before) VALUES ($1, $2)
+              ON CONFLICT (user_id) DO UPDATE SET revoked_before = GREATEST(user_token_cutoffs.revoked_before, EXCLUDED.revoked_before)`
+	_, err := s.db.Exec(query, userID, cutoff.UTC())
+	return err
+}
+
+// UserTokensRevokedBefore returns the user's cutoff, or the zero time if there is none
+func (s *PostgresRevocationStore) UserTokensRevokedBefore(userID string) (time.Time, error) {
+	var cutoff time.Time
+
+	err := s.db.QueryRow(`SELECT revoked_before FROM user_token_cutoffs WHERE user_id = $1`, userID).Scan(&cutoff)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+
+	return cutoff, nil
+}
+
+// PurgeExpired removes the revocations of expired tokens and the cutoffs older than any unexpired token
+func (s *PostgresRevocationStore) PurgeExpired(now time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < $1`, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	purgedTokens, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err = s.db.Exec(`DELETE FROM user_token_cutoffs WHERE revoked_before < $1`, now.Add(-RefreshTokenExpiration).UTC())
+	if err != nil {
+		return purgedTokens, err
+	}
+
+	purgedCutoffs, err := result.RowsAffected()
+	if err != nil {
+		return purgedTokens, err
+	}
+
+	return purgedTokens + purgedCutoffs, nil
+}
+
+// PostgresPermissionStore implements the PermissionStore interface. Roles without a row
+// have their default permissions, so the defaults don't need to be seeded.
+type PostgresPermissionStore struct {
+	db *sqlx.DB
+}
+
+// NewPostgresPermissionStore creates a new PostgresPermissionStore
+func NewPostgresPermissionStore(db *sqlx.DB) *PostgresPermissionStore {
+	return &PostgresPermissionStore{
+		db: db,
+	}
+}
+
+// RolePermissions returns the permissions of a role
+func (s *PostgresPermissionStore) RolePermissions(role models.Role) ([]models.Permission, error) {
+	var names []string
+
+	err := s.db.QueryRow(`SELECT permissions FROM role_permissions WHERE role = $1`, role).Scan(pq.Array(&names))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return slices.Clone(models.DefaultRolePermissions[role]), nil
+		}
+		return nil, err
+	}
+
+	rolePermissions := make([]models.Permission, 0, len(names))
+	for _, name := range names {
+		rolePermissions = append(rolePermissions, models.Permission(name))
+	}
+
+	return rolePermissions, nil
+}
+
+// SetRolePermissions replaces the permissions of a role
+func (s *PostgresPermissionStore) SetRolePermissions(role models.Role, rolePermissions []models.Permission, updatedBy string, updatedAt time.Time) error {
+	names := make([]string, 0, len(rolePermissions))
+	for _, permission := range rolePermissions {
+		names = append(names, permission.String())
+	}
+
+	query := `INSERT INTO role_permissions (role, permissions, updated_by, updated) VALUES ($1, $2, $3, $4)
+              ON CONFLICT (role) DO UPDATE SET permissions = EXCLUDED.permissions,
+                  updated_by = EXCLUDED.updated_by, updated = EXCLUDED.updated`
+	_, err := s.db.Exec(query, role, pq.Array(names), updatedBy, updatedAt.UTC())
+	return err
+}
+
+// PostgresAPIKeyStore implements the APIKeyStore interface.
+type PostgresAPIKeyStore struct {
+	db *sqlx.DB
+}
+
+// NewPostgresAPIKeyStore creates a new PostgresAPIKeyStore
+func NewPostgresAPIKeyStore(db *sqlx.DB) *PostgresAPIKeyStore {
+	return &PostgresAPIKeyStore{
+		db: db,
+	}
+}
+
+// apiKeyColumns are the columns scanned by scanAPIKey
+const apiKeyColumns = `id, name, prefix, key_hash, permissions, created_by, COALESCE(shelter_id::text, ''), platform_wide, created,
+              expires_at, last_used_at, revoked_at`
+
+// Save stores an API key
+func (s *PostgresAPIKeyStore) Save(key *models.APIKey) error {
+	names := make([]string, 0, len(key.Permissions))
+	for _, permission := range key.Permissions {
+		names = append(names, permission.String())
+	}
+
+	var expiresAt sql.NullTime
+	if key.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: key.ExpiresAt.UTC(), Valid: true}
+	}
+
+	query := `INSERT INTO api_keys (id, name, prefix, key_hash, permissions, created_by, shelter_id, platform_wide, created, expires_at)
+              VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::uuid, $8, $9, $10)`
+
+	_, err := s.db.Exec(
+		query,
+		key.ID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		pq.Array(names),
+		key.CreatedBy,
+		key.ShelterID,
+		key.PlatformWide,
+		key.Created.UTC(),
+		expiresAt,
+	)
+
+	if database.IsForeignKeyViolation(err, "api_keys_shelter_id_fkey") {
+		return models.ErrShelterNotFound
+	}
+
+	return err
+}
+
+// FindByID finds an API key by its ID
+func (s *PostgresAPIKeyStore) FindByID(id string) (*models.APIKey, error) {
+	return scanAPIKey(s.db.QueryRow(`SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id))
+}
+
+// FindByPrefix finds an API key by its prefix
+func (s *PostgresAPIKeyStore) FindByPrefix(prefix string) (*models.APIKey, error) {
+	return scanAPIKey(s.db.QueryRow(`SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = $1`, prefix))
+}
+
+// FindAll returns every API key of the shelters in scope, newest first
+func (s *PostgresAPIKeyStore) FindAll(scope tenant.Scope) ([]*models.APIKey, error) {
+	all, shelterID := scope.Filter()
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE ` + tenant.Condition("shelter_id", 1) + ` ORDER BY created DESC`
+
+	rows, err := s.db.Query(query, all, shelterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke marks an API key as revoked
+func (s *PostgresAPIKeyStore) Revoke(id string, at time.Time) error {
+	_, err := s.db.Exec(`UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`, at.UTC(), id)
+	return err
+}
+
+// MarkUsed records when an API key was last used
+func (s *PostgresAPIKeyStore) MarkUsed(id string, at time.Time) error {
+	_, err := s.db.Exec(`UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, at.UTC(), id)
+	return err
+}
+
+// scanAPIKey scans a row of apiKeyColumns, returning nil if there is no row
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (*models.APIKey, error) {
+	var key models.APIKey
+	var names []string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&names),
+		&key.CreatedBy,
+		&key.ShelterID,
+		&key.PlatformWide,
+		&key.Created,
+		&expiresAt,
+		&lastUsedAt,
+		&revokedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, name := range names {
+		key.Permissions = append(key.Permissions, models.Permission(name))
+	}
+
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return &key, nil
+}
+
+// PostgresSessionStore implements the SessionStore interface.
+type PostgresSessionStore struct {
+	db *sqlx.DB
+}
+
+// NewPostgresSessionStore creates a new PostgresSessionStore
+func NewPostgresSessionStore(db *sqlx.DB) *PostgresSessionStore {
+	return &PostgresSessionStore{
+		db: db,
+	}
+}
+
+// sessionColumns are the columns scanned by scanSession
+const sessionColumns = `id, user_id, user_agent, ip_address, amr, created, last_refreshed_at, expires_at, revoked_at`
+
+// Save stores a session
+func (s *PostgresSessionStore) Save(session *models.Session) error {
+	query := `INSERT INTO sessions (id, user_id, user_agent, ip_address, amr, created, expires_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := s.db.Exec(
+		query,
+		session.ID,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+		pq.Array(session.AMR),
+		session.Created.UTC(),
+		session.ExpiresAt.UTC(),
+	)
+
+	return err
+}
+
+// FindByID finds a session by its ID
+func (s *PostgresSessionStore) FindByID(id string) (*models.Session, error) {
+	return scanSession(s.db.QueryRow(`SELECT `+sessionColumns+` FROM sessions WHERE id = $1`, id))
+}
+
+// FindActiveByUserID returns the sessions of a user that are neither ended nor expired, newest first
+func (s *PostgresSessionStore) FindActiveByUserID(userID string, now time.Time) ([]*models.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions
+              WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2 ORDER BY created DESC`
+
+	rows, err := s.db.Query(query, userID, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := []*models.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, session)
+	}
+
+	return found, rows.Err()
+}
+
+// Touch records a refresh of the session from the device, keeping the previous details the
+// device doesn't have
+func (s *PostgresSessionStore) Touch(id string, device Device, refreshedAt, expiresAt time.Time) error {
+	query := `UPDATE sessions SET user_agent = COALESCE(NULLIF($1, ''), user_agent),
+              ip_address = COALESCE(NULLIF($2, ''), ip_address), last_refreshed_at = $3, expires_at = $4
+              WHERE id = $5`
+
+	_, err := s.db.Exec(query, device.UserAgent, device.IPAddress, refreshedAt.UTC(), expiresAt.UTC(), id)
+	return err
+}
+
+// Revoke marks a session as ended
+func (s *PostgresSessionStore) Revoke(id string, revokedAt time.Time) error {
+	_, err := s.db.Exec(`UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`, revokedAt.UTC(), id)
+	return err
+}
+
+// RevokeByUserID marks every session of a user as ended
+func (s *PostgresSessionStore) RevokeByUserID(userID string, revokedAt time.Time) error {
+	_, err := s.db.Exec(`UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`, revokedAt.UTC(), userID)
+	return err
+}
+
+// PurgeExpired removes the sessions whose refresh tokens have expired
+func (s *PostgresSessionStore) PurgeExpired(now time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM sessions WHERE expires_at < $1`, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// scanSession scans a row of sessionColumns, returning nil if there is no row
+func scanSession(row interface{ Scan(dest ...any) error }) (*models.Session, error) {
+	var session models.Session
+	var lastRefreshedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IPAddress,
+		pq.Array(&session.AMR),
+		&session.Created,
+		&lastRefreshedAt,
+		&session.ExpiresAt,
+		&revokedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if lastRefreshedAt.Valid {
+		session.LastRefreshedAt = &lastRefreshedAt.Time
+	}
+
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+
+	return &session, nil
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// revocations stores revoked access tokens and per-user cutoffs. It defaults to an in-memory store
+// until a persistent one is set with SetRevocationStore.
+var revocations RevocationStore = NewMemoryRevocationStore()
+
+// SetRevocationStore sets the store used to keep track of revoked tokens
+func SetRevocationStore(store RevocationStore) {
+	revocations = store
+}
+
+// RevokeAccessToken revokes an access token until it expires
+func RevokeAccessToken(claims *AccessClaims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return ErrInvalidToken
+	}
+
+	return revocations.RevokeToken(claims.ID, claims.ExpiresAt.Time)
+}
+
+// RevokeRefreshToken revokes a refresh token of the given user along with the rest of its family,
+// ending its session
+func RevokeRefreshToken(refreshToken, userID string) error {
+	claims, err := ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	stored, err := refreshTokens.FindByID(claims.TokenID)
+	if err != nil {
+		return err
+	}
+
+	if stored == nil || stored.UserID != userID {
+		return ErrInvalidToken
+	}
+
+	return revokeSession(stored.FamilyID, time.Now())
+}
+
+// RevokeAllUserTokens revokes every access and refresh token issued to a user until now, ending
+// all their sessions
+func RevokeAllUserTokens(userID string) error {
+	now := time.Now()
+	if err := revocations.RevokeUserTokensBefore(userID, now); err != nil {
+		return err
+	}
+
+	return sessions.RevokeByUserID(userID, now)
+}
+
+// isAccessTokenRevoked checks if an access token was revoked on its own, with its session or by
+// its user's cutoff.
+// Issue times are kept in milliseconds, so tokens issued within the same millisecond as a
+// revoke-all are revoked too.
+func isAccessTokenRevoked(claims *AccessClaims) (bool, error) {
+	for _, id := range []string{claims.ID, claims.SessionID} {
+		if id == "" {
+			continue
+		}
+
+		revoked, err := revocations.IsTokenRevoked(id)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	if claims.IssuedAt == nil {
+		return true, nil
+	}
+
+	return isIssuedBeforeCutoff(claims.UserID, claims.IssuedAt.Time)
+}
+
+// isIssuedBeforeCutoff checks if a token issued at the given time falls under its user's cutoff
+func isIssuedBeforeCutoff(userID string, issuedAt time.Time) (bool, error) {
+	cutoff, err := revocations.UserTokensRevokedBefore(userID)
+	if err != nil {
+		return false, err
+	}
+
+	return !cutoff.IsZero() && !issuedAt.After(cutoff), nil
+}
+
+// StartSweeper purges expired refresh tokens, sessions and revocations every interval,
+// until the returned stop function is called
+func StartSweeper(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				sweep(now)
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// sweep purges the refresh tokens, sessions and revocations that expired before now
+func sweep(now time.Time) {
+	purgedTokens, err := refreshTokens.PurgeExpired(now)
+	if err != nil {
+		log.Printf("Failed to purge expired refresh tokens: %v", err)
+	}
+
+	purgedSessions, err := sessions.PurgeExpired(now)
+	if err != nil {
+		log.Printf("Failed to purge expired sessions: %v", err)
+	}
+
+	purgedRevocations, err := revocations.PurgeExpired(now)
+	if err != nil {
+		log.Printf("Failed to purge expired token revocations: %v", err)
+	}
+
+	if purgedTokens > 0 || purgedSessions > 0 || purgedRevocations > 0 {
+		log.Printf("Purged %d expired refresh tokens, %d expired sessions and %d expired revocations", purgedTokens, purgedSessions, purgedRevocations)
+	}
+}
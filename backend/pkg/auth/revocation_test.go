@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// protectedStatus sends a request with the access token to a route behind Protected and returns the status code
+func protectedStatus(t *testing.T, accessToken string) int {
+	t.Helper()
+
+	app := fiber.New()
+	app.Get("/", Protected(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+
+	return resp.StatusCode
+}
+
+func TestProtectedRejectsRevokedAccessToken(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	setupRefreshTest(map[string]*models.User{user.ID: user})
+	SetRevocationStore(NewMemoryRevocationStore())
+
+	pair, err := GenerateTokenPair(user)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	if status := protectedStatus(t, pair.AccessToken); status != fiber.StatusOK {
+		t.Fatalf("before logout: status = %d, want %d", status, fiber.StatusOK)
+	}
+
+	claims, err := ValidateAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+
+	if err := RevokeAccessToken(claims); err != nil {
+		t.Fatalf("RevokeAccessToken() error = %v", err)
+	}
+
+	if status := protectedStatus(t, pair.AccessToken); status != fiber.StatusUnauthorized {
+		t.Errorf("after logout: status = %d, want %d", status, fiber.StatusUnauthorized)
+	}
+}
+
+func TestRevokeRefreshTokenRevokesFamily(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	loadUser := setupRefreshTest(map[string]*models.User{user.ID: user})
+	SetRevocationStore(NewMemoryRevocationStore())
+
+	pair, err := GenerateTokenPair(user)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	if err := RevokeRefreshToken(pair.RefreshToken, "someone-else"); err != ErrInvalidToken {
+		t.Errorf("revoking another user's token: error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	if err := RevokeRefreshToken(pair.RefreshToken, user.ID); err != nil {
+		t.Fatalf("RevokeRefreshToken() error = %v", err)
+	}
+
+	if _, err := RefreshTokens(pair.RefreshToken, loadUser); err != ErrRevokedToken {
+		t.Errorf("refreshing a revoked token: error = %v, want %v", err, ErrRevokedToken)
+	}
+}
+
+func TestRevokeAllUserTokens(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	other := &models.User{ID: "user-2", Role: models.RoleUser, Status: models.StatusActive}
+	loadUser := setupRefreshTest(map[string]*models.User{user.ID: user, other.ID: other})
+	SetRevocationStore(NewMemoryRevocationStore())
+
+	pair, err := GenerateTokenPair(user)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	otherPair, err := GenerateTokenPair(other)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	if err := RevokeAllUserTokens(user.ID); err != nil {
+		t.Fatalf("RevokeAllUserTokens() error = %v", err)
+	}
+
+	if status := protectedStatus(t, pair.AccessToken); status != fiber.StatusUnauthorized {
+		t.Errorf("revoked user's access token: status = %d, want %d", status, fiber.StatusUnauthorized)
+	}
+
+	if _, err := RefreshTokens(pair.RefreshToken, loadUser); err != ErrRevokedToken {
+		t.Errorf("revoked user's refresh token: error = %v, want %v", err, ErrRevokedToken)
+	}
+
+	if status := protectedStatus(t, otherPair.AccessToken); status != fiber.StatusOK {
+		t.Errorf("other user's access token: status = %d, want %d", status, fiber.StatusOK)
+	}
+}
+
+func TestMemoryRevocationStorePurgeExpired(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	now := time.Now()
+
+	store.RevokeToken("expired", now.Add(-time.Minute))
+	store.RevokeToken("live", now.Add(time.Minute))
+	store.RevokeUserTokensBefore("old-cutoff", now.Add(-RefreshTokenExpiration-time.Minute))
+	store.RevokeUserTokensBefore("recent-cutoff", now.Add(-time.Minute))
+
+	purged, err := store.PurgeExpired(now)
+	if err != nil {
+		t.Fatalf("PurgeExpired() error = %v", err)
+	}
+
+	if purged != 2 {
+		t.Errorf("PurgeExpired() purged %d entries, want 2", purged)
+	}
+
+	if revoked, _ := store.IsTokenRevoked("live"); !revoked {
+		t.Error("unexpired revocation was purged")
+	}
+
+	if cutoff, _ := store.UserTokensRevokedBefore("recent-cutoff"); cutoff.IsZero() {
+		t.Error("recent cutoff was purged")
+	}
+}
+
+func TestRevokeAllUserTokensKeepsLaterTokens(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	loadUser := setupRefreshTest(map[string]*models.User{user.ID: user})
+	SetRevocationStore(NewMemoryRevocationStore())
+
+	if err := RevokeAllUserTokens(user.ID); err != nil {
+		t.Fatalf("RevokeAllUserTokens() error = %v", err)
+	}
+
+	// Logging in again right after revoking everything must work
+	time.Sleep(2 * time.Millisecond)
+	pair, err := GenerateTokenPair(user)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	if status := protectedStatus(t, pair.AccessToken); status != fiber.StatusOK {
+		t.Errorf("access token issued after revoking: status = %d, want %d", status, fiber.StatusOK)
+	}
+
+	if _, err := RefreshTokens(pair.RefreshToken, loadUser); err != nil {
+		t.Errorf("refresh token issued after revoking: error = %v", err)
+	}
+}
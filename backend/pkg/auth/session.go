@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// maxUserAgentLength is how much of the User-Agent header is kept with a session
+const maxUserAgentLength = 255
+
+// sessions stores the login sessions of users. It defaults to an in-memory store until a
+// persistent one is set with SetSessionStore.
+var sessions SessionStore = NewMemorySessionStore()
+
+// SetSessionStore sets the store used to keep track of login sessions
+func SetSessionStore(store SessionStore) {
+	sessions = store
+}
+
+// SessionStore keeps track of login sessions. A session's ID is the family ID of its refresh tokens.
+type SessionStore interface {
+	Save(session *models.Session) error
+	FindByID(id string) (*models.Session, error)
+	// FindActiveByUserID returns the sessions of a user that are neither ended nor expired, newest first
+	FindActiveByUserID(userID string, now time.Time) ([]*models.Session, error)
+	// Touch records a refresh of the session from the device
+	Touch(id string, device Device, refreshedAt, expiresAt time.Time) error
+	Revoke(id string, revokedAt time.Time) error
+	RevokeByUserID(userID string, revokedAt time.Time) error
+	PurgeExpired(now time.Time) (int64, error)
+}
+
+// Device describes where a session was started or refreshed from
+type Device struct {
+	UserAgent string
+	IPAddress string
+}
+
+// DeviceFromRequest returns the device a request comes from. The IP address honours the
+// trusted proxies the app is configured with.
+func DeviceFromRequest(c *fiber.Ctx) Device {
+	userAgent := c.Get(fiber.HeaderUserAgent)
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	return Device{
+		UserAgent: userAgent,
+		IPAddress: c.IP(),
+	}
+}
+
+// StartSession records a new login session of a user on a device and issues its first token
+// pair. amr lists the methods the user authenticated with.
+func StartSession(user *models.User, device Device, amr ...string) (*TokenPair, error) {
+	now := time.Now()
+	session := &models.Session{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		UserAgent: device.UserAgent,
+		IPAddress: device.IPAddress,
+		AMR:       amr,
+		Created:   now,
+		ExpiresAt: now.Add(RefreshTokenExpiration),
+	}
+
+	if err := sessions.Save(session); err != nil {
+		return nil, err
+	}
+
+	return generateTokenPair(user, uuid.New().String(), session.ID, amr)
+}
+
+// GetUserSessions returns the active sessions of a user, newest first
+func GetUserSessions(userID string) ([]*models.Session, error) {
+	return sessions.FindActiveByUserID(userID, time.Now())
+}
+
+// EndSession ends an active session of a user: its refresh tokens are revoked, and so are the
+// access tokens issued for it until they expire.
+func EndSession(userID, sessionID string) error {
+	now := time.Now()
+
+	session, err := sessions.FindByID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if session == nil || session.UserID != userID || !session.IsActive(now) {
+		return models.ErrSessionNotFound
+	}
+
+	if err := revokeSession(session.ID, now); err != nil {
+		return err
+	}
+
+	// Access tokens carry their session ID, which is revoked like a token ID
+	return revocations.RevokeToken(session.ID, now.Add(AccessTokenExpiration))
+}
+
+// revokeSession revokes the refresh token family of a session and marks the session as ended
+func revokeSession(familyID string, now time.Time) error {
+	if err := refreshTokens.RevokeFamily(familyID, now); err != nil {
+		return err
+	}
+
+	return sessions.Revoke(familyID, now)
+}
+
+// MemorySessionStore is an in-memory SessionStore, used when no database store is configured and in tests
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*models.Session
+}
+
+// NewMemorySessionStore creates a new MemorySessionStore
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		sessions: make(map[string]*models.Session),
+	}
+}
+
+// Save stores a session
+func (s *MemorySessionStore) Save(session *models.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *session
+	s.sessions[session.ID] = &stored
+	return nil
+}
+
+// FindByID finds a session by its ID
+func (s *MemorySessionStore) FindByID(id string) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[id]; ok {
+		found := *session
+		return &found, nil
+	}
+	return nil, nil
+}
+
+// FindActiveByUserID returns the sessions of a user that are neither ended nor expired, newest first
+func (s *MemorySessionStore) FindActiveByUserID(userID string, now time.Time) ([]*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found []*models.Session
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.IsActive(now) {
+			active := *session
+			found = append(found, &active)
+		}
+	}
+
+	slices.SortFunc(found, func(a, b *models.Session) int {
+		return b.Created.Compare(a.Created)
+	})
+
+	return found, nil
+}
+
+// Touch records a refresh of the session from the device
+func (s *MemorySessionStore) Touch(id string, device Device, refreshedAt, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[id]; ok {
+		if device.IPAddress != "" {
+			session.IPAddress = device.IPAddress
+		}
+		if device.UserAgent != "" {
+			session.UserAgent = device.UserAgent
+		}
+		session.LastRefreshedAt = &refreshedAt
+		session.ExpiresAt = expiresAt
+	}
+	return nil
+}
+
+// Revoke marks a session as ended
+func (s *MemorySessionStore) Revoke(id string, revokedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[id]; ok && session.RevokedAt == nil {
+		session.RevokedAt = &revokedAt
+	}
+	return nil
+}
+
+// RevokeByUserID marks every session of a user as ended
+func (s *MemorySessionStore) RevokeByUserID(userID string, revokedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &revokedAt
+		}
+	}
+	return nil
+}
+
+// PurgeExpired removes the sessions whose refresh tokens have expired
+func (s *MemorySessionStore) PurgeExpired(now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, session := range s.sessions {
+		if session.ExpiresAt.Before(now) {
+			delete(s.sessions, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+func TestSessionsRecordDeviceAndRefreshes(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	loadUser := setupRefreshTest(map[string]*models.User{user.ID: user})
+	SetSessionStore(NewMemorySessionStore())
+
+	pair, err := StartSession(user, Device{UserAgent: "Firefox", IPAddress: "203.0.113.1"}, AMRPassword)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+
+	if _, err := RefreshSession(pair.RefreshToken, Device{IPAddress: "203.0.113.2"}, loadUser); err != nil {
+		t.Fatalf("RefreshSession() error = %v", err)
+	}
+
+	found, err := GetUserSessions(user.ID)
+	if err != nil {
+		t.Fatalf("GetUserSessions() error = %v", err)
+	}
+
+	if len(found) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(found))
+	}
+
+	// The refresh comes from a new address, the user agent is kept
+	session := found[0]
+	if session.UserAgent != "Firefox" || session.IPAddress != "203.0.113.2" || session.LastRefreshedAt == nil {
+		t.Errorf("session = %+v, want the Firefox user agent, the new address and a refresh time", session)
+	}
+
+	claims, err := ValidateAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+
+	if claims.SessionID != session.ID {
+		t.Errorf("access token session = %q, want %q", claims.SessionID, session.ID)
+	}
+}
+
+func TestEndSessionRevokesItsTokens(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	loadUser := setupRefreshTest(map[string]*models.User{user.ID: user})
+	SetSessionStore(NewMemorySessionStore())
+	SetRevocationStore(NewMemoryRevocationStore())
+
+	lost, err := StartSession(user, Device{UserAgent: "Lost phone"}, AMRPassword)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+
+	kept, err := StartSession(user, Device{UserAgent: "Laptop"}, AMRPassword)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+
+	lostClaims, err := ValidateAccessToken(lost.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+
+	// Sessions can only be ended by their own user
+	if err := EndSession("user-2", lostClaims.SessionID); err != models.ErrSessionNotFound {
+		t.Errorf("EndSession() of another user's session error = %v, want %v", err, models.ErrSessionNotFound)
+	}
+
+	if err := EndSession(user.ID, lostClaims.SessionID); err != nil {
+		t.Fatalf("EndSession() error = %v", err)
+	}
+
+	if status := protectedStatus(t, lost.AccessToken); status != fiber.StatusUnauthorized {
+		t.Errorf("access token of the ended session: status = %d, want %d", status, fiber.StatusUnauthorized)
+	}
+
+	if _, err := RefreshTokens(lost.RefreshToken, loadUser); err != ErrRevokedToken {
+		t.Errorf("refresh token of the ended session: RefreshTokens() error = %v, want %v", err, ErrRevokedToken)
+	}
+
+	if status := protectedStatus(t, kept.AccessToken); status != fiber.StatusOK {
+		t.Errorf("access token of the other session: status = %d, want %d", status, fiber.StatusOK)
+	}
+
+	found, err := GetUserSessions(user.ID)
+	if err != nil {
+		t.Fatalf("GetUserSessions() error = %v", err)
+	}
+
+	if len(found) != 1 || found[0].UserAgent != "Laptop" {
+		t.Errorf("sessions after ending one = %+v, want only the laptop", found)
+	}
+
+	if err := EndSession(user.ID, lostClaims.SessionID); err != models.ErrSessionNotFound {
+		t.Errorf("EndSession() of an ended session error = %v, want %v", err, models.ErrSessionNotFound)
+	}
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+// ShelterQueryParam is the query parameter platform admins narrow a request down to one shelter with
+const ShelterQueryParam = "shelter_id"
+
+// IsPlatformAdmin checks if the request was made by an admin who doesn't belong to a shelter, or
+// with a platform-wide API key. Platform admins reach the records of every shelter.
+func IsPlatformAdmin(c *fiber.Ctx) bool {
+	if shelterID, _ := c.Locals("shelterID").(string); shelterID != "" {
+		return false
+	}
+
+	if key, ok := c.Locals("apiKey").(*models.APIKey); ok {
+		return key.PlatformWide
+	}
+
+	role, _ := c.Locals("role").(models.Role)
+	return role.IsEquals(models.RoleAdmin)
+}
+
+// ShelterScope returns the shelters whose records the request can reach. Platform admins reach
+// every shelter, or the one given in the shelter_id query parameter; everyone else reaches the
+// shelter they work at, and no shelter at all if they don't work at one.
+func ShelterScope(c *fiber.Ctx) tenant.Scope {
+	if IsPlatformAdmin(c) {
+		if shelterID := c.Query(ShelterQueryParam); shelterID != "" {
+			return tenant.Shelter(validShelterID(shelterID))
+		}
+		return tenant.AllShelters()
+	}
+
+	shelterID, _ := c.Locals("shelterID").(string)
+	return tenant.Shelter(shelterID)
+}
+
+// OwnerShelterScope returns the shelters whose records of a user the request reaches. Users reach
+// their own records whichever shelter keeps them; anyone else gets their ShelterScope, so users
+// who don't work at a shelter reach no one else's records.
+func OwnerShelterScope(c *fiber.Ctx, ownerID string) tenant.Scope {
+	if userID, _ := c.Locals("userID").(string); userID != "" && userID == ownerID {
+		return tenant.AllShelters()
+	}
+
+	return ShelterScope(c)
+}
+
+// CanReachRecord checks if the request reaches a record a user owns, kept at a shelter: users reach
+// their own records, and staff with the permission reach those of the shelters in their ShelterScope
+func CanReachRecord(c *fiber.Ctx, ownerID, shelterID string, permission models.Permission) bool {
+	if userID, _ := c.Locals("userID").(string); userID != "" && userID == ownerID {
+		return true
+	}
+
+	allowed, err := Allowed(c, permission)
+	return err == nil && allowed && ShelterScope(c).Allows(shelterID)
+}
+
+// PublicShelterScope returns the shelters listed by public routes: every shelter, or the one given
+// in the shelter_id query parameter
+func PublicShelterScope(c *fiber.Ctx) tenant.Scope {
+	if shelterID := c.Query(ShelterQueryParam); shelterID != "" {
+		return tenant.Shelter(validShelterID(shelterID))
+	}
+	return tenant.AllShelters()
+}
+
+// RecordShelter returns the shelter the records created by the request belong to: the shelter
+// the requester works at, or for platform admins the requested one. It is empty when the
+// requester doesn't work at a shelter and no valid one was requested.
+func RecordShelter(c *fiber.Ctx, requested string) string {
+	if IsPlatformAdmin(c) {
+		return validShelterID(requested)
+	}
+
+	shelterID, _ := c.Locals("shelterID").(string)
+	return shelterID
+}
+
+// RequirePlatformAdmin is a middleware for the routes that act on every shelter at once
+func RequirePlatformAdmin() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !IsPlatformAdmin(c) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Only platform admins can do this",
+				"code":  "platform_admin_required",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// validShelterID returns a shelter ID given by a client, or an empty one if it isn't a UUID
+func validShelterID(shelterID string) string {
+	if uuid.Validate(shelterID) != nil {
+		return ""
+	}
+	return shelterID
+}
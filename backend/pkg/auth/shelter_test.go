@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+	"github.com/solrac97gr/petparadise/pkg/tenant"
+)
+
+const (
+	shelter1 = "7b0c8c52-5a4c-4f0e-9d3b-1a6f4f2c9e01"
+	shelter2 = "7b0c8c52-5a4c-4f0e-9d3b-1a6f4f2c9e02"
+)
+
+// shelterScopeOf calls a route answering with the shelter scope of the request, authenticated
+// with the given header, and reports whether the route for platform admins let it through
+func shelterScopeOf(t *testing.T, header, value, query string) (string, bool) {
+	t.Helper()
+
+	app := fiber.New()
+	app.Get("/scope", Protected(), func(c *fiber.Ctx) error {
+		scope := ShelterScope(c)
+		if scope.IsAll() {
+			return c.SendString("*")
+		}
+		return c.SendString(scope.ShelterID())
+	})
+	app.Get("/platform", Protected(), RequirePlatformAdmin(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	req := httptest.NewRequest("GET", "/scope"+query, nil)
+	req.Header.Set(header, value)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error = %v", err)
+	}
+
+	req = httptest.NewRequest("GET", "/platform", nil)
+	req.Header.Set(header, value)
+
+	resp, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+
+	return string(body), resp.StatusCode == fiber.StatusNoContent
+}
+
+func TestShelterScope(t *testing.T) {
+	platformAdmin := &models.User{ID: "admin-1", Role: models.RoleAdmin, Status: models.StatusActive}
+	shelterAdmin := &models.User{ID: "admin-2", Role: models.RoleAdmin, Status: models.StatusActive, ShelterID: shelter1}
+	vet := &models.User{ID: "vet-1", Role: models.RoleVet, Status: models.StatusActive, ShelterID: shelter2}
+	adopter := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	setupRefreshTest(map[string]*models.User{})
+	SetRevocationStore(NewMemoryRevocationStore())
+	SetAPIKeyStore(NewMemoryAPIKeyStore())
+	defer SetAPIKeyStore(NewMemoryAPIKeyStore())
+
+	permissions := []models.Permission{models.PermissionPetsWrite}
+	_, platformKey, err := CreateAPIKey("reporting", permissions, platformAdmin.ID, "", true, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+
+	_, shelterKey, err := CreateAPIKey("clinic", permissions, shelterAdmin.ID, shelter1, false, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+
+	// A key without a shelter that wasn't asked to be platform-wide, as keys made before the flag existed
+	_, shelterlessKey, err := CreateAPIKey("legacy", permissions, platformAdmin.ID, "", false, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+
+	bearer := func(user *models.User) string {
+		pair, err := GenerateTokenPair(user)
+		if err != nil {
+			t.Fatalf("GenerateTokenPair() error = %v", err)
+		}
+		return "Bearer " + pair.AccessToken
+	}
+
+	tests := []struct {
+		name         string
+		header       string
+		value        string
+		query        string
+		wantScope    string
+		wantPlatform bool
+	}{
+		{"platform admin", "Authorization", bearer(platformAdmin), "", "*", true},
+		{"platform admin narrowed to a shelter", "Authorization", bearer(platformAdmin), "?shelter_id=" + shelter2, shelter2, true},
+		{"platform admin with a malformed shelter", "Authorization", bearer(platformAdmin), "?shelter_id=shelter", "", true},
+		{"shelter admin", "Authorization", bearer(shelterAdmin), "?shelter_id=" + shelter2, shelter1, false},
+		{"vet", "Authorization", bearer(vet), "", shelter2, false},
+		{"user without a shelter", "Authorization", bearer(adopter), "", "", false},
+		{"API key across shelters", APIKeyHeader, platformKey, "", "*", true},
+		{"API key of a shelter", APIKeyHeader, shelterKey, "", shelter1, false},
+		{"API key without a shelter", APIKeyHeader, shelterlessKey, "", "", false},
+	}
+
+	for _, tt := range tests {
+		scope, platform := shelterScopeOf(t, tt.header, tt.value, tt.query)
+		if scope != tt.wantScope {
+			t.Errorf("%s: scope = %q, want %q", tt.name, scope, tt.wantScope)
+		}
+		if platform != tt.wantPlatform {
+			t.Errorf("%s: platform admin = %v, want %v", tt.name, platform, tt.wantPlatform)
+		}
+	}
+}
+
+func TestAPIKeysAreScopedToTheirShelter(t *testing.T) {
+	SetAPIKeyStore(NewMemoryAPIKeyStore())
+	defer SetAPIKeyStore(NewMemoryAPIKeyStore())
+
+	permissions := []models.Permission{models.PermissionPetsWrite}
+	platformKey, _, err := CreateAPIKey("reporting", permissions, "admin-1", "", true, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+
+	shelterKey, _, err := CreateAPIKey("clinic", permissions, "admin-2", shelter1, false, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+
+	keys, err := GetAPIKeys(tenant.Shelter(shelter1))
+	if err != nil {
+		t.Fatalf("GetAPIKeys() error = %v", err)
+	}
+
+	if len(keys) != 1 || keys[0].ID != shelterKey.ID {
+		t.Errorf("GetAPIKeys() = %v, want only the key of the shelter", keys)
+	}
+
+	if _, err := RevokeAPIKey(platformKey.ID, tenant.Shelter(shelter1)); err != models.ErrAPIKeyNotFound {
+		t.Errorf("RevokeAPIKey() of another shelter's key error = %v, want %v", err, models.ErrAPIKeyNotFound)
+	}
+
+	keys, err = GetAPIKeys(tenant.AllShelters())
+	if err != nil {
+		t.Fatalf("GetAPIKeys() error = %v", err)
+	}
+
+	if len(keys) != 2 {
+		t.Errorf("GetAPIKeys() across shelters returned %d keys, want 2", len(keys))
+	}
+}
+
+func TestUnaffiliatedUsersOnlyReachTheirOwnRecords(t *testing.T) {
+	adopter := &models.User{ID: "user-1", Role: models.RoleUser, Status: models.StatusActive}
+	vet := &models.User{ID: "vet-1", Role: models.RoleVet, Status: models.StatusActive, ShelterID: shelter1}
+	setupRefreshTest(map[string]*models.User{})
+	SetRevocationStore(NewMemoryRevocationStore())
+
+	app := fiber.New()
+	app.Get("/records/:owner/:shelter", Protected(), func(c *fiber.Ctx) error {
+		scope := OwnerShelterScope(c, c.Params("owner"))
+		reach := CanReachRecord(c, c.Params("owner"), c.Params("shelter"), models.PermissionAdoptionsRead)
+
+		shelterID := scope.ShelterID()
+		if scope.IsAll() {
+			shelterID = "*"
+		}
+		return c.JSON(fiber.Map{"scope": shelterID, "reach": reach})
+	})
+
+	tests := []struct {
+		name      string
+		user      *models.User
+		owner     string
+		shelter   string
+		wantScope string
+		wantReach bool
+	}{
+		{"user's own records", adopter, adopter.ID, shelter2, "*", true},
+		{"another user's records", adopter, "user-2", shelter1, "", false},
+		{"staff reaching a record of their shelter", vet, "user-2", shelter1, shelter1, true},
+		{"staff reaching a record of another shelter", vet, "user-2", shelter2, shelter1, false},
+	}
+
+	for _, tt := range tests {
+		pair, err := GenerateTokenPair(tt.user)
+		if err != nil {
+			t.Fatalf("GenerateTokenPair() error = %v", err)
+		}
+
+		req := httptest.NewRequest("GET", "/records/"+tt.owner+"/"+tt.shelter, nil)
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test() error = %v", err)
+		}
+
+		var got struct {
+			Scope string `json:"scope"`
+			Reach bool   `json:"reach"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatalf("%s: decoding the response: %v", tt.name, err)
+		}
+
+		if got.Scope != tt.wantScope {
+			t.Errorf("%s: scope = %q, want %q", tt.name, got.Scope, tt.wantScope)
+		}
+		if got.Reach != tt.wantReach {
+			t.Errorf("%s: CanReachRecord() = %v, want %v", tt.name, got.Reach, tt.wantReach)
+		}
+	}
+}
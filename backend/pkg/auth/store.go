@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// StoredRefreshToken is the server-side record of an issued refresh token.
+// Tokens issued by rotating one another share a family, which starts at login.
+type StoredRefreshToken struct {
+	TokenID    string
+	FamilyID   string
+	UserID     string
+	IssuedAt   time.Time
+	ExpiresAt  time.Time
+	UsedAt     *time.Time // Set once the token has been exchanged for a new pair
+	ReplacedBy string     // ID of the token issued in exchange
+	RevokedAt  *time.Time
+	AMR        []string // Authentication methods of the login that started the family
+}
+
+// RefreshTokenStore keeps track of issued refresh tokens so they can be rotated and revoked
+type RefreshTokenStore interface {
+	Save(token *StoredRefreshToken) error
+	FindByID(tokenID string) (*StoredRefreshToken, error)
+	// MarkUsed records that a token was exchanged. It returns false when the token had
+	// already been used or revoked, so that concurrent refreshes cannot both succeed.
+	MarkUsed(tokenID, replacedBy string, usedAt time.Time) (bool, error)
+	RevokeFamily(familyID string, revokedAt time.Time) error
+	PurgeExpired(now time.Time) (int64, error)
+}
+
+// MemoryRefreshTokenStore is an in-memory RefreshTokenStore, used when no database store is configured
+type MemoryRefreshTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]StoredRefreshToken
+}
+
+// NewMemoryRefreshTokenStore creates a new MemoryRefreshTokenStore
+func NewMemoryRefreshTokenStore() *MemoryRefreshTokenStore {
+	return &MemoryRefreshTokenStore{
+		tokens: make(map[string]StoredRefreshToken),
+	}
+}
+
+// Save stores a refresh token
+func (s *MemoryRefreshTokenStore) Save(token *StoredRefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[token.TokenID] = *token
+	return nil
+}
+
+// FindByID finds a refresh token by its ID
+func (s *MemoryRefreshTokenStore) FindByID(tokenID string) (*StoredRefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[tokenID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &token, nil
+}
+
+// MarkUsed records that a refresh token was exchanged for a new one
+func (s *MemoryRefreshTokenStore) MarkUsed(tokenID, replacedBy string, usedAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[tokenID]
+	if !ok || token.UsedAt != nil || token.RevokedAt != nil {
+		return false, nil
+	}
+
+	token.UsedAt = &usedAt
+	token.ReplacedBy = replacedBy
+	s.tokens[tokenID] = token
+
+	return true, nil
+}
+
+// RevokeFamily revokes every refresh token of a family
+func (s *MemoryRefreshTokenStore) RevokeFamily(familyID string, revokedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, token := range s.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+			s.tokens[id] = token
+		}
+	}
+
+	return nil
+}
+
+// PurgeExpired removes the refresh tokens that have expired
+func (s *MemoryRefreshTokenStore) PurgeExpired(now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, token := range s.tokens {
+		if token.ExpiresAt.Before(now) {
+			delete(s.tokens, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// RevocationStore keeps track of revoked access tokens and of per-user revocation cutoffs
+type RevocationStore interface {
+	// RevokeToken revokes a single token until it expires
+	RevokeToken(tokenID string, expiresAt time.Time) error
+	IsTokenRevoked(tokenID string) (bool, error)
+	// RevokeUserTokensBefore revokes every token of a user issued up to the cutoff
+	RevokeUserTokensBefore(userID string, cutoff time.Time) error
+	// UserTokensRevokedBefore returns the user's cutoff, or the zero time if there is none
+	UserTokensRevokedBefore(userID string) (time.Time, error)
+	// PurgeExpired removes the revocations that no longer match any unexpired token
+	PurgeExpired(now time.Time) (int64, error)
+}
+
+// MemoryRevocationStore is an in-memory RevocationStore, used when no database store is configured and in tests
+type MemoryRevocationStore struct {
+	mu      sync.Mutex
+	tokens  map[string]time.Time // Token ID to expiration
+	cutoffs map[string]time.Time // User ID to cutoff
+}
+
+// NewMemoryRevocationStore creates a new MemoryRevocationStore
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		tokens:  make(map[string]time.Time),
+		cutoffs: make(map[string]time.Time),
+	}
+}
+
+// RevokeToken revokes a single token until it expires
+func (s *MemoryRevocationStore) RevokeToken(tokenID string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[tokenID] = expiresAt
+	return nil
+}
+
+// IsTokenRevoked checks if a token has been revoked
+func (s *MemoryRevocationStore) IsTokenRevoked(tokenID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.tokens[tokenID]
+	return ok, nil
+}
+
+// RevokeUserTokensBefore revokes every token of a user issued up to the cutoff
+func (s *MemoryRevocationStore) RevokeUserTokensBefore(userID string, cutoff time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cutoff.After(s.cutoffs[userID]) {
+		s.cutoffs[userID] = cutoff
+	}
+	return nil
+}
+
+// UserTokensRevokedBefore returns the user's cutoff, or the zero time if there is none
+func (s *MemoryRevocationStore) UserTokensRevokedBefore(userID string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cutoffs[userID], nil
+}
+
+// PurgeExpired removes the revocations of expired tokens and the cutoffs older than any unexpired token
+func (s *MemoryRevocationStore) PurgeExpired(now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, expiresAt := range s.tokens {
+		if expiresAt.Before(now) {
+			delete(s.tokens, id)
+			purged++
+		}
+	}
+
+	oldestLiveToken := now.Add(-RefreshTokenExpiration)
+	for userID, cutoff := range s.cutoffs {
+		if cutoff.Before(oldestLiveToken) {
+			delete(s.cutoffs, userID)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
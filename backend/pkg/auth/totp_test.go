@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	// Test vectors of RFC 6238 (SHA1), truncated to six digits
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode() error = %v", err)
+		}
+
+		if got != tt.want {
+			t.Errorf("TOTPCode() at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTPRejectsReplays(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+
+	now := time.Now()
+	code, err := TOTPCode(secret, TOTPStep(now))
+	if err != nil {
+		t.Fatalf("TOTPCode() error = %v", err)
+	}
+
+	step, ok := ValidateTOTP(secret, code, now, 0)
+	if !ok || step != TOTPStep(now) {
+		t.Fatalf("ValidateTOTP() = %d, %v, want %d, true", step, ok, TOTPStep(now))
+	}
+
+	// Accepted from the previous step to allow for clock drift
+	if _, ok := ValidateTOTP(secret, code, now.Add(TOTPPeriod), 0); !ok {
+		t.Error("ValidateTOTP() rejected a code from the previous step")
+	}
+
+	if _, ok := ValidateTOTP(secret, code, now, step); ok {
+		t.Error("ValidateTOTP() accepted a code that was already used")
+	}
+
+	if _, ok := ValidateTOTP(secret, code, now.Add(TOTPPeriod*3), 0); ok {
+		t.Error("ValidateTOTP() accepted an outdated code")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Pet Paradise", "jane@example.com", "JBSWY3DPEHPK3PXP")
+
+	for _, want := range []string{"otpauth://totp/Pet%20Paradise:jane@example.com?", "secret=JBSWY3DPEHPK3PXP", "issuer=Pet+Paradise"} {
+		if !strings.Contains(uri, want) {
+			t.Errorf("TOTPProvisioningURI() = %s, want it to contain %s", uri, want)
+		}
+	}
+}
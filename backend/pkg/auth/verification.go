@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/solrac97gr/petparadise/internal/users/domain/models"
+)
+
+// EmailVerificationExpiration is how long an email verification link stays valid
+const EmailVerificationExpiration = time.Hour * 24
+
+// Token purposes, each signed with its own key so a token can't be used for anything else
+const (
+	purposeEmailVerification = "email_verification"
+	purposeMFAChallenge      = "mfa_challenge"
+	purposeInvitation        = "invitation"
+	purposeDataExport        = "data_export"
+	purposeRefreshToken      = "refresh_token"
+)
+
+// EmailVerificationClaims represents the JWT claims of an email verification token
+type EmailVerificationClaims struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// purposeKey derives the signing key of a token purpose from the JWT secret
+func purposeKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, jwtSecret)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// signPurposeToken signs the claims of a token with the key of its purpose
+func signPurposeToken(purpose string, claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(purposeKey(purpose))
+}
+
+// parsePurposeToken validates a token signed for a purpose and fills in its claims
+func parsePurposeToken(purpose, tokenString string, claims jwt.Claims) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Validate the signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return purposeKey(purpose), nil
+	}, jwt.WithExpirationRequired())
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return ErrExpiredToken
+		}
+		return ErrInvalidToken
+	}
+
+	if !token.Valid {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+// GenerateEmailVerificationToken creates a token proving the user received mail at their current address
+func GenerateEmailVerificationToken(user *models.User) (string, error) {
+	now := time.Now()
+	return signPurposeToken(purposeEmailVerification, &EmailVerificationClaims{
+		UserID: user.ID,
+		Email:  user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(EmailVerificationExpiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Subject:   user.ID,
+		},
+	})
+}
+
+// ValidateEmailVerificationToken validates an email verification token and returns its claims
+func ValidateEmailVerificationToken(tokenString string) (*EmailVerificationClaims, error) {
+	claims := &EmailVerificationClaims{}
+	if err := parsePurposeToken(purposeEmailVerification, tokenString, claims); err != nil {
+		return nil, err
+	}
+
+	if claims.UserID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
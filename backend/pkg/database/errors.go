@@ -0,0 +1,29 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// PostgreSQL error codes of the constraint violations repositories map to domain errors
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
+// IsForeignKeyViolation checks if the error is the violation of the given foreign key
+func IsForeignKeyViolation(err error, constraint string) bool {
+	return isViolation(err, foreignKeyViolation, constraint)
+}
+
+// IsUniqueViolation checks if the error is the violation of the given unique constraint or index
+func IsUniqueViolation(err error, constraint string) bool {
+	return isViolation(err, uniqueViolation, constraint)
+}
+
+// isViolation checks if the error is a PostgreSQL error with the given code on the given constraint
+func isViolation(err error, code pq.ErrorCode, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code && pqErr.Constraint == constraint
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestIsForeignKeyViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "Violation of the constraint", err: &pq.Error{Code: "23503", Constraint: "pets_shelter_id_fkey"}, want: true},
+		{name: "Wrapped violation", err: fmt.Errorf("saving: %w", &pq.Error{Code: "23503", Constraint: "pets_shelter_id_fkey"}), want: true},
+		{name: "Violation of another constraint", err: &pq.Error{Code: "23503", Constraint: "donations_pet_id_fkey"}, want: false},
+		{name: "Unique violation", err: &pq.Error{Code: "23505", Constraint: "pets_shelter_id_fkey"}, want: false},
+		{name: "Other error", err: errors.New("connection refused"), want: false},
+		{name: "No error", err: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsForeignKeyViolation(tt.err, "pets_shelter_id_fkey"); got != tt.want {
+				t.Errorf("IsForeignKeyViolation(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	if !IsUniqueViolation(&pq.Error{Code: "23505", Constraint: "idx_pet_transfers_open"}, "idx_pet_transfers_open") {
+		t.Error("IsUniqueViolation() of the index = false, want true")
+	}
+
+	if IsUniqueViolation(&pq.Error{Code: "23503", Constraint: "idx_pet_transfers_open"}, "idx_pet_transfers_open") {
+		t.Error("IsUniqueViolation() of a foreign key violation = true, want false")
+	}
+}
@@ -6,8 +6,29 @@ import (
 
 // SetupDatabase initializes database tables if they don't exist
 func SetupDatabase(db *sqlx.DB) error {
-	// Create adoptions table
+	// Create shelters table, with the default shelter the records from before there were
+	// several shelters belong to
 	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS shelters (
+			id UUID PRIMARY KEY,
+			name VARCHAR(100) NOT NULL,
+			address VARCHAR(255) NOT NULL DEFAULT '',
+			phone VARCHAR(255) NOT NULL DEFAULT '',
+			email VARCHAR(255) NOT NULL DEFAULT '',
+			created TIMESTAMP NOT NULL,
+			updated TIMESTAMP NOT NULL
+		);
+
+		INSERT INTO shelters (id, name, created, updated)
+		VALUES ('00000000-0000-0000-0000-000000000001', 'Pet Paradise', NOW() AT TIME ZONE 'UTC', NOW() AT TIME ZONE 'UTC')
+		ON CONFLICT (id) DO NOTHING;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create adoptions table
+	_, err = db.Exec(`
 		CREATE TABLE IF NOT EXISTS adoptions (
 			id VARCHAR(36) PRIMARY KEY,
 			pet_id VARCHAR(36) NOT NULL,
@@ -21,6 +42,12 @@ func SetupDatabase(db *sqlx.DB) error {
 		CREATE INDEX IF NOT EXISTS idx_adoptions_pet_id ON adoptions(pet_id);
 		CREATE INDEX IF NOT EXISTS idx_adoptions_user_id ON adoptions(user_id);
 		CREATE INDEX IF NOT EXISTS idx_adoptions_status ON adoptions(status);
+
+		-- Existing adoptions belong to the default shelter
+		ALTER TABLE adoptions ADD COLUMN IF NOT EXISTS shelter_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+			REFERENCES shelters(id) ON DELETE RESTRICT;
+		ALTER TABLE adoptions ALTER COLUMN shelter_id DROP DEFAULT;
+		CREATE INDEX IF NOT EXISTS idx_adoptions_shelter_id ON adoptions(shelter_id);
 	`)
 	if err != nil {
 		return err
@@ -44,6 +71,12 @@ func SetupDatabase(db *sqlx.DB) error {
 		CREATE INDEX IF NOT EXISTS idx_pets_status ON pets(status);
 		CREATE INDEX IF NOT EXISTS idx_pets_species ON pets(species);
 		CREATE INDEX IF NOT EXISTS idx_pets_breed ON pets(breed);
+
+		-- Existing pets belong to the default shelter
+		ALTER TABLE pets ADD COLUMN IF NOT EXISTS shelter_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+			REFERENCES shelters(id) ON DELETE RESTRICT;
+		ALTER TABLE pets ALTER COLUMN shelter_id DROP DEFAULT;
+		CREATE INDEX IF NOT EXISTS idx_pets_shelter_id ON pets(shelter_id);
 	`)
 	if err != nil {
 		return err
@@ -68,6 +101,303 @@ func SetupDatabase(db *sqlx.DB) error {
 		CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
 		CREATE INDEX IF NOT EXISTS idx_users_status ON users(status);
 		CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+
+		-- Staff work at a shelter; admins without one are platform admins. Existing vets and
+		-- volunteers are moved to the default shelter once, when the column is added.
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'shelter_id') THEN
+				ALTER TABLE users ADD COLUMN shelter_id UUID REFERENCES shelters(id) ON DELETE RESTRICT;
+				UPDATE users SET shelter_id = '00000000-0000-0000-0000-000000000001' WHERE role IN ('vet', 'volunteer');
+			END IF;
+		END $$;
+		CREATE INDEX IF NOT EXISTS idx_users_shelter_id ON users(shelter_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create refresh tokens table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS refresh_tokens (
+			id VARCHAR(64) PRIMARY KEY,
+			family_id VARCHAR(64) NOT NULL,
+			user_id UUID NOT NULL,
+			issued_at TIMESTAMP NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			used_at TIMESTAMP,
+			replaced_by VARCHAR(64),
+			revoked_at TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+		CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+		CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
+
+		ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS amr TEXT[];
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create token revocation tables
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS revoked_tokens (
+			token_id VARCHAR(64) PRIMARY KEY,
+			expires_at TIMESTAMP NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+		CREATE TABLE IF NOT EXISTS user_token_cutoffs (
+			user_id UUID PRIMARY KEY,
+			revoked_before TIMESTAMP NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create verification emails table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS verification_emails (
+			id UUID PRIMARY KEY,
+			email VARCHAR(255) NOT NULL,
+			sent_at TIMESTAMP NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_verification_emails_email_sent_at ON verification_emails(email, sent_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create password reset tokens table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS password_reset_tokens (
+			token_hash VARCHAR(64) PRIMARY KEY,
+			user_id UUID NOT NULL,
+			created TIMESTAMP NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			used_at TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id, created);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create two-factor authentication tables
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS user_mfa (
+			user_id UUID PRIMARY KEY,
+			secret VARCHAR(64) NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT FALSE,
+			last_used_step BIGINT NOT NULL DEFAULT 0,
+			failed_attempts INT NOT NULL DEFAULT 0,
+			created TIMESTAMP NOT NULL,
+			updated TIMESTAMP NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL,
+			code_hash VARCHAR(64) NOT NULL,
+			used_at TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create login throttles table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS login_throttles (
+			kind VARCHAR(10) NOT NULL,
+			subject VARCHAR(100) NOT NULL,
+			failures INT NOT NULL DEFAULT 0,
+			last_failure TIMESTAMP NOT NULL,
+			locked_until TIMESTAMP,
+			PRIMARY KEY (kind, subject)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_login_throttles_last_failure ON login_throttles(kind, last_failure);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create external identity tables
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS user_identities (
+			provider VARCHAR(50) NOT NULL,
+			subject VARCHAR(255) NOT NULL,
+			user_id UUID NOT NULL,
+			email VARCHAR(100) NOT NULL,
+			created TIMESTAMP NOT NULL,
+			PRIMARY KEY (provider, subject),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+
+		CREATE TABLE IF NOT EXISTS oidc_login_states (
+			state VARCHAR(64) PRIMARY KEY,
+			provider VARCHAR(50) NOT NULL,
+			nonce VARCHAR(64) NOT NULL,
+			code_verifier VARCHAR(128) NOT NULL,
+			expires_at TIMESTAMP NOT NULL
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create role permissions table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS role_permissions (
+			role VARCHAR(20) PRIMARY KEY,
+			permissions TEXT[] NOT NULL,
+			updated_by UUID NOT NULL,
+			updated TIMESTAMP NOT NULL
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create API keys table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id UUID PRIMARY KEY,
+			name VARCHAR(100) NOT NULL,
+			prefix VARCHAR(20) UNIQUE NOT NULL,
+			key_hash VARCHAR(64) NOT NULL,
+			permissions TEXT[] NOT NULL,
+			created_by UUID NOT NULL,
+			created TIMESTAMP NOT NULL,
+			expires_at TIMESTAMP,
+			last_used_at TIMESTAMP,
+			revoked_at TIMESTAMP
+		);
+
+		-- Keys without a shelter work across shelters
+		ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS shelter_id UUID REFERENCES shelters(id) ON DELETE RESTRICT;
+
+		-- Only keys created as platform-wide work across shelters, a key without a shelter alone doesn't
+		ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS platform_wide BOOLEAN NOT NULL DEFAULT FALSE;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create staff invitations table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS invitations (
+			id UUID PRIMARY KEY,
+			email VARCHAR(255) NOT NULL,
+			role VARCHAR(20) NOT NULL,
+			invited_by UUID NOT NULL,
+			created TIMESTAMP NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			accepted_at TIMESTAMP,
+			accepted_user_id UUID,
+			revoked_at TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_invitations_created ON invitations(created);
+
+		ALTER TABLE invitations ADD COLUMN IF NOT EXISTS shelter_id UUID REFERENCES shelters(id) ON DELETE RESTRICT;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create password history table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS password_history (
+			id BIGSERIAL PRIMARY KEY,
+			user_id UUID NOT NULL,
+			password_hash VARCHAR(100) NOT NULL,
+			created TIMESTAMP NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history(user_id, created);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create sessions table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS sessions (
+			id VARCHAR(64) PRIMARY KEY,
+			user_id UUID NOT NULL,
+			user_agent VARCHAR(255) NOT NULL DEFAULT '',
+			ip_address VARCHAR(45) NOT NULL DEFAULT '',
+			amr TEXT[],
+			created TIMESTAMP NOT NULL,
+			last_refreshed_at TIMESTAMP,
+			expires_at TIMESTAMP NOT NULL,
+			revoked_at TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+		CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create personal data exports table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS data_exports (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			created TIMESTAMP NOT NULL,
+			completed_at TIMESTAMP,
+			expires_at TIMESTAMP,
+			size BIGINT NOT NULL DEFAULT 0,
+			archive BYTEA,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_data_exports_user_id ON data_exports(user_id, created);
+		CREATE INDEX IF NOT EXISTS idx_data_exports_expires_at ON data_exports(expires_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create account deletions table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS account_deletions (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL,
+			requested_by UUID NOT NULL,
+			requested_at TIMESTAMP NOT NULL,
+			scheduled_for TIMESTAMP NOT NULL,
+			cancelled_at TIMESTAMP,
+			erased_at TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_account_deletions_user_id ON account_deletions(user_id) WHERE cancelled_at IS NULL;
+		CREATE INDEX IF NOT EXISTS idx_account_deletions_scheduled_for ON account_deletions(scheduled_for) WHERE cancelled_at IS NULL AND erased_at IS NULL;
+
+		-- Set once the other modules erased what they keep, after the account itself
+		ALTER TABLE account_deletions ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
 	`)
 	if err != nil {
 		return err
@@ -84,12 +414,316 @@ func SetupDatabase(db *sqlx.DB) error {
 			updated TIMESTAMP NOT NULL,
 			comment TEXT,
 			anonymous BOOLEAN NOT NULL DEFAULT FALSE,
-			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT
 		);
 		
 		CREATE INDEX IF NOT EXISTS idx_donations_user_id ON donations(user_id);
 		CREATE INDEX IF NOT EXISTS idx_donations_status ON donations(status);
 		CREATE INDEX IF NOT EXISTS idx_donations_created ON donations(created);
+
+		ALTER TABLE donations ADD COLUMN IF NOT EXISTS pet_id VARCHAR(36);
+		ALTER TABLE donations ADD COLUMN IF NOT EXISTS sponsorship VARCHAR(20);
+		ALTER TABLE donations DROP CONSTRAINT IF EXISTS donations_pet_id_fkey;
+		ALTER TABLE donations ADD CONSTRAINT donations_pet_id_fkey
+			FOREIGN KEY (pet_id) REFERENCES pets(id) ON DELETE SET NULL;
+
+		CREATE INDEX IF NOT EXISTS idx_donations_pet_id ON donations(pet_id);
+
+		ALTER TABLE donations ADD COLUMN IF NOT EXISTS campaign VARCHAR(100);
+		CREATE INDEX IF NOT EXISTS idx_donations_campaign ON donations(campaign);
+
+		-- Donations are financial records, deleted accounts are anonymized instead
+		ALTER TABLE donations DROP CONSTRAINT IF EXISTS donations_user_id_fkey;
+		ALTER TABLE donations ADD CONSTRAINT donations_user_id_fkey
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+		-- Existing donations belong to the default shelter
+		ALTER TABLE donations ADD COLUMN IF NOT EXISTS shelter_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+			REFERENCES shelters(id) ON DELETE RESTRICT;
+		ALTER TABLE donations ALTER COLUMN shelter_id DROP DEFAULT;
+		CREATE INDEX IF NOT EXISTS idx_donations_shelter_id ON donations(shelter_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create donation refunds and ledger tables
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS donation_refunds (
+			id UUID PRIMARY KEY,
+			donation_id UUID NOT NULL,
+			amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+			reason TEXT NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			requested_by UUID NOT NULL,
+			reviewed_by UUID,
+			created TIMESTAMP NOT NULL,
+			updated TIMESTAMP NOT NULL,
+			FOREIGN KEY (donation_id) REFERENCES donations(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_donation_refunds_donation_id ON donation_refunds(donation_id);
+		CREATE INDEX IF NOT EXISTS idx_donation_refunds_status ON donation_refunds(status);
+
+		CREATE TABLE IF NOT EXISTS donation_ledger (
+			id UUID PRIMARY KEY,
+			donation_id UUID NOT NULL,
+			entry_type VARCHAR(20) NOT NULL,
+			amount DECIMAL(10, 2) NOT NULL,
+			refund_id UUID,
+			actor_id UUID,
+			created TIMESTAMP NOT NULL,
+			FOREIGN KEY (donation_id) REFERENCES donations(id) ON DELETE RESTRICT,
+			FOREIGN KEY (refund_id) REFERENCES donation_refunds(id) ON DELETE RESTRICT
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_donation_ledger_donation_id ON donation_ledger(donation_id);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_donation_ledger_charge ON donation_ledger(donation_id) WHERE entry_type = 'charge';
+
+		-- The ledger is append-only: entries can never be changed or removed
+		CREATE OR REPLACE FUNCTION donation_ledger_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'donation_ledger is append-only';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS trg_donation_ledger_append_only ON donation_ledger;
+		CREATE TRIGGER trg_donation_ledger_append_only BEFORE UPDATE OR DELETE ON donation_ledger
+			FOR EACH ROW EXECUTE FUNCTION donation_ledger_append_only();
+
+		-- Backfill the ledger for donations completed or refunded before it existed
+		INSERT INTO donation_ledger (id, donation_id, entry_type, amount, created)
+		SELECT gen_random_uuid(), d.id, 'charge', d.amount, d.updated FROM donations d
+		WHERE d.status IN ('completed', 'refunded')
+			AND NOT EXISTS (SELECT 1 FROM donation_ledger l WHERE l.donation_id = d.id);
+
+		INSERT INTO donation_ledger (id, donation_id, entry_type, amount, created)
+		SELECT gen_random_uuid(), d.id, 'refund', -d.amount, d.updated FROM donations d
+		WHERE d.status = 'refunded'
+			AND NOT EXISTS (SELECT 1 FROM donation_ledger l WHERE l.donation_id = d.id AND l.entry_type = 'refund');
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create donor profiles table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS donor_profiles (
+			user_id UUID PRIMARY KEY,
+			display_name VARCHAR(100) NOT NULL DEFAULT '',
+			hide_amounts BOOLEAN NOT NULL DEFAULT FALSE,
+			updated TIMESTAMP NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create in-kind donations and supply inventory tables
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS in_kind_donations (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL,
+			drop_off_date DATE NOT NULL,
+			comment TEXT,
+			received_by UUID,
+			acknowledged_at TIMESTAMP,
+			created TIMESTAMP NOT NULL,
+			updated TIMESTAMP NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT
+		);
+
+		ALTER TABLE in_kind_donations DROP CONSTRAINT IF EXISTS in_kind_donations_user_id_fkey;
+		ALTER TABLE in_kind_donations ADD CONSTRAINT in_kind_donations_user_id_fkey
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+		CREATE INDEX IF NOT EXISTS idx_in_kind_donations_user_id ON in_kind_donations(user_id);
+		CREATE INDEX IF NOT EXISTS idx_in_kind_donations_drop_off_date ON in_kind_donations(drop_off_date);
+
+		CREATE TABLE IF NOT EXISTS in_kind_items (
+			id UUID PRIMARY KEY,
+			donation_id UUID NOT NULL,
+			category VARCHAR(20) NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			quantity DECIMAL(10, 2) NOT NULL CHECK (quantity > 0),
+			unit VARCHAR(20) NOT NULL,
+			estimated_value DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (estimated_value >= 0),
+			condition VARCHAR(20) NOT NULL,
+			FOREIGN KEY (donation_id) REFERENCES in_kind_donations(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_in_kind_items_donation_id ON in_kind_items(donation_id);
+
+		CREATE TABLE IF NOT EXISTS supply_inventory (
+			id UUID PRIMARY KEY,
+			category VARCHAR(20) NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			unit VARCHAR(20) NOT NULL,
+			quantity DECIMAL(12, 2) NOT NULL CHECK (quantity >= 0),
+			updated TIMESTAMP NOT NULL
+		);
+
+		-- Existing in-kind donations and supplies belong to the default shelter, and each shelter
+		-- keeps its own inventory
+		ALTER TABLE in_kind_donations ADD COLUMN IF NOT EXISTS shelter_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+			REFERENCES shelters(id) ON DELETE RESTRICT;
+		ALTER TABLE in_kind_donations ALTER COLUMN shelter_id DROP DEFAULT;
+		CREATE INDEX IF NOT EXISTS idx_in_kind_donations_shelter_id ON in_kind_donations(shelter_id);
+
+		ALTER TABLE supply_inventory ADD COLUMN IF NOT EXISTS shelter_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+			REFERENCES shelters(id) ON DELETE RESTRICT;
+		ALTER TABLE supply_inventory ALTER COLUMN shelter_id DROP DEFAULT;
+		DROP INDEX IF EXISTS idx_supply_inventory_item;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_supply_inventory_shelter_item ON supply_inventory(shelter_id, category, lower(name), unit);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create vet appointments table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS appointments (
+			id UUID PRIMARY KEY,
+			pet_id VARCHAR(36) NOT NULL,
+			vet_id UUID NOT NULL,
+			procedure VARCHAR(20) NOT NULL,
+			starts_at TIMESTAMP NOT NULL,
+			ends_at TIMESTAMP NOT NULL,
+			notes TEXT NOT NULL DEFAULT '',
+			status VARCHAR(20) NOT NULL,
+			care_started_at TIMESTAMP,
+			care_ends_at TIMESTAMP,
+			care_ended_at TIMESTAMP,
+			previous_pet_status VARCHAR(50) NOT NULL DEFAULT '',
+			created_by UUID NOT NULL,
+			created TIMESTAMP NOT NULL,
+			updated TIMESTAMP NOT NULL,
+			FOREIGN KEY (pet_id) REFERENCES pets(id) ON DELETE CASCADE,
+			FOREIGN KEY (vet_id) REFERENCES users(id) ON DELETE RESTRICT,
+			CHECK (ends_at > starts_at)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_appointments_vet_id_starts_at ON appointments(vet_id, starts_at);
+		CREATE INDEX IF NOT EXISTS idx_appointments_pet_id_starts_at ON appointments(pet_id, starts_at);
+		CREATE INDEX IF NOT EXISTS idx_appointments_starts_at ON appointments(starts_at);
+		CREATE INDEX IF NOT EXISTS idx_appointments_in_care ON appointments(pet_id) WHERE care_started_at IS NOT NULL AND care_ended_at IS NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create pet transfers table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS pet_transfers (
+			id UUID PRIMARY KEY,
+			pet_id VARCHAR(36) NOT NULL,
+			from_shelter_id UUID NOT NULL,
+			to_shelter_id UUID NOT NULL,
+			reason VARCHAR(20) NOT NULL,
+			notes TEXT NOT NULL DEFAULT '',
+			transport_date DATE NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			requested_by UUID NOT NULL,
+			reviewed_by UUID,
+			reviewed_at TIMESTAMP,
+			completed_by UUID,
+			completed_at TIMESTAMP,
+			created TIMESTAMP NOT NULL,
+			updated TIMESTAMP NOT NULL,
+			FOREIGN KEY (pet_id) REFERENCES pets(id) ON DELETE CASCADE,
+			FOREIGN KEY (from_shelter_id) REFERENCES shelters(id) ON DELETE RESTRICT,
+			FOREIGN KEY (to_shelter_id) REFERENCES shelters(id) ON DELETE RESTRICT,
+			CHECK (to_shelter_id <> from_shelter_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_pet_transfers_pet_id ON pet_transfers(pet_id, created);
+		CREATE INDEX IF NOT EXISTS idx_pet_transfers_from_shelter_id ON pet_transfers(from_shelter_id);
+		CREATE INDEX IF NOT EXISTS idx_pet_transfers_to_shelter_id ON pet_transfers(to_shelter_id);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_pet_transfers_open ON pet_transfers(pet_id) WHERE status IN ('requested', 'approved');
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create volunteer shift tables
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS shifts (
+			id UUID PRIMARY KEY,
+			task VARCHAR(100) NOT NULL,
+			location VARCHAR(100) NOT NULL,
+			capacity INT NOT NULL CHECK (capacity > 0),
+			starts_at TIMESTAMP NOT NULL,
+			ends_at TIMESTAMP NOT NULL,
+			created_by UUID NOT NULL,
+			created TIMESTAMP NOT NULL,
+			updated TIMESTAMP NOT NULL,
+			CHECK (ends_at > starts_at)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_shifts_starts_at ON shifts(starts_at);
+
+		-- Existing shifts belong to the default shelter
+		ALTER TABLE shifts ADD COLUMN IF NOT EXISTS shelter_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+			REFERENCES shelters(id) ON DELETE RESTRICT;
+		ALTER TABLE shifts ALTER COLUMN shelter_id DROP DEFAULT;
+		CREATE INDEX IF NOT EXISTS idx_shifts_shelter_id_starts_at ON shifts(shelter_id, starts_at);
+
+		CREATE TABLE IF NOT EXISTS shift_sign_ups (
+			id UUID PRIMARY KEY,
+			shift_id UUID NOT NULL,
+			user_id UUID NOT NULL,
+			created TIMESTAMP NOT NULL,
+			cancelled_at TIMESTAMP,
+			checked_in_at TIMESTAMP,
+			checked_out_at TIMESTAMP,
+			FOREIGN KEY (shift_id) REFERENCES shifts(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_shift_sign_ups_active ON shift_sign_ups(shift_id, user_id) WHERE cancelled_at IS NULL;
+		CREATE INDEX IF NOT EXISTS idx_shift_sign_ups_user_id ON shift_sign_ups(user_id);
+		CREATE INDEX IF NOT EXISTS idx_shift_sign_ups_checked_in_at ON shift_sign_ups(checked_in_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create audit log table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS audit_log (
+			sequence BIGINT PRIMARY KEY,
+			id UUID UNIQUE NOT NULL,
+			actor VARCHAR(100) NOT NULL,
+			action VARCHAR(100) NOT NULL,
+			entity_type VARCHAR(50) NOT NULL,
+			entity_id VARCHAR(100) NOT NULL,
+			changes JSON NOT NULL,
+			ip_address VARCHAR(45) NOT NULL DEFAULT '',
+			request_id VARCHAR(100) NOT NULL DEFAULT '',
+			created TIMESTAMP NOT NULL,
+			prev_hash VARCHAR(64) NOT NULL,
+			hash VARCHAR(64) NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id);
+		CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);
+		CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created);
+
+		-- The audit log is append-only: entries can never be changed or removed
+		CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_log is append-only';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS trg_audit_log_append_only ON audit_log;
+		CREATE TRIGGER trg_audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+			FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+		DROP TRIGGER IF EXISTS trg_audit_log_no_truncate ON audit_log;
+		CREATE TRIGGER trg_audit_log_no_truncate BEFORE TRUNCATE ON audit_log
+			FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
 	`)
 	if err != nil {
 		return err
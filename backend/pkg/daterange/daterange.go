@@ -0,0 +1,54 @@
+// Package daterange reads the inclusive date ranges (YYYY-MM-DD) that lists and reports are
+// filtered by
+package daterange
+
+import "time"
+
+// Layout is the layout of the dates of a range
+const Layout = time.DateOnly
+
+// Error is returned for a date that isn't in the YYYY-MM-DD layout
+type Error struct {
+	Param string // Which end of the range, from or to
+}
+
+func (e *Error) Error() string {
+	return "invalid " + e.Param + " date, use YYYY-MM-DD"
+}
+
+// Today returns the start of the current UTC day
+func Today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Parse reads the inclusive from and to dates of a range, either of which may be empty to use
+// the default one, and returns the half-open range [from, to) they cover in UTC: to is the start
+// of the day after the to date.
+func Parse(from, to string, defaultFrom, defaultTo time.Time) (time.Time, time.Time, error) {
+	start, err := parseDate("from", from, defaultFrom)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	end, err := parseDate("to", to, defaultTo)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return start, end.AddDate(0, 0, 1), nil
+}
+
+// parseDate parses one end of a range, returning the default one when it is empty
+func parseDate(param, value string, defaultDate time.Time) (time.Time, error) {
+	if value == "" {
+		return defaultDate, nil
+	}
+
+	date, err := time.Parse(Layout, value)
+	if err != nil {
+		return time.Time{}, &Error{Param: param}
+	}
+
+	return date, nil
+}
@@ -0,0 +1,55 @@
+package daterange
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	defaultFrom := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	defaultTo := time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		from      string
+		to        string
+		wantFrom  time.Time
+		wantTo    time.Time
+		wantParam string // Of the invalid date
+	}{
+		{name: "Defaults", wantFrom: defaultFrom, wantTo: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{
+			name:     "Both dates",
+			from:     "2024-01-15",
+			to:       "2024-01-15",
+			wantFrom: time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, time.January, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{name: "Only from", from: "2024-02-29", wantFrom: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), wantTo: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "Invalid from", from: "2024-02-30", wantParam: "from"},
+		{name: "Invalid to", to: "31/03/2024", wantParam: "to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := Parse(tt.from, tt.to, defaultFrom, defaultTo)
+
+			var dateErr *Error
+			if tt.wantParam != "" {
+				if !errors.As(err, &dateErr) || dateErr.Param != tt.wantParam {
+					t.Fatalf("Parse() error = %v, want an invalid %s date", err, tt.wantParam)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+				t.Errorf("Parse() = [%v, %v), want [%v, %v)", from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
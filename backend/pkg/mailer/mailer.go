@@ -0,0 +1,78 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/solrac97gr/petparadise/pkg/config"
+)
+
+// Mail drivers that can be selected in the configuration
+const (
+	DriverLog    = "log"
+	DriverSMTP   = "smtp"
+	DriverOutbox = "outbox"
+)
+
+// Message represents an email message
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer defines the interface for sending emails
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// New creates the Mailer selected by the configured mail driver
+func New(cfg *config.Config) (Mailer, error) {
+	switch cfg.MailDriver {
+	case "", DriverLog:
+		return NewLogMailer(), nil
+	case DriverSMTP:
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	case DriverOutbox:
+		return NewOutboxMailer(cfg.MailOutboxDir, cfg.MailFrom)
+	}
+
+	return nil, fmt.Errorf("unknown mail driver: %s", cfg.MailDriver)
+}
+
+// LogMailer is a Mailer that writes messages to the application log instead of sending them
+type LogMailer struct{}
+
+// NewLogMailer creates a new LogMailer
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send logs the message
+func (m *LogMailer) Send(msg *Message) error {
+	log.Printf("Mail to %s: %s\n%s", strings.Join(msg.To, ", "), msg.Subject, msg.Body)
+	return nil
+}
+
+// format renders the message as a plain text email with its headers
+func format(from string, msg *Message, date time.Time) []byte {
+	var b strings.Builder
+
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + strings.Join(msg.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + sanitizeHeader(msg.Subject) + "\r\n")
+	b.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
+
+// sanitizeHeader keeps a header value on a single line so it can't inject other headers
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// OutboxMailer is a Mailer that writes every message as an .eml file into a directory,
+// so development setups and tests can read what would have been sent
+type OutboxMailer struct {
+	dir  string
+	from string
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewOutboxMailer creates a new OutboxMailer, creating the outbox directory if needed
+func NewOutboxMailer(dir, from string) (*OutboxMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &OutboxMailer{
+		dir:  dir,
+		from: from,
+	}, nil
+}
+
+// Send writes the message to the outbox
+func (m *OutboxMailer) Send(msg *Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipients
+	}
+
+	now := time.Now()
+
+	m.mu.Lock()
+	m.seq++
+	name := fmt.Sprintf("%s-%06d.eml", now.UTC().Format("20060102T150405.000000000"), m.seq)
+	m.mu.Unlock()
+
+	return os.WriteFile(filepath.Join(m.dir, name), format(m.from, msg, now), 0o644)
+}
+
+// Messages returns the paths of the messages in the outbox, oldest first
+func (m *OutboxMailer) Messages() ([]string, error) {
+	return filepath.Glob(filepath.Join(m.dir, "*.eml"))
+}
@@ -0,0 +1,65 @@
+package mailer
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestOutboxMailerWritesMessages(t *testing.T) {
+	outbox, err := NewOutboxMailer(t.TempDir(), "Pet Paradise <no-reply@petparadise.local>")
+	if err != nil {
+		t.Fatalf("NewOutboxMailer() error = %v", err)
+	}
+
+	for _, subject := range []string{"First", "Second\r\nBcc: attacker@example.com"} {
+		err := outbox.Send(&Message{
+			To:      []string{"jane@example.com"},
+			Subject: subject,
+			Body:    "Hello Jane,\n\nWelcome!",
+		})
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	paths, err := outbox.Messages()
+	if err != nil {
+		t.Fatalf("Messages() error = %v", err)
+	}
+
+	if len(paths) != 2 {
+		t.Fatalf("Messages() returned %d messages, want 2", len(paths))
+	}
+
+	first, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("reading first message: %v", err)
+	}
+
+	for _, want := range []string{"To: jane@example.com\r\n", "Subject: First\r\n", "\r\n\r\nHello Jane,\r\n\r\nWelcome!"} {
+		if !strings.Contains(string(first), want) {
+			t.Errorf("first message = %q, want it to contain %q", first, want)
+		}
+	}
+
+	second, err := os.ReadFile(paths[1])
+	if err != nil {
+		t.Fatalf("reading second message: %v", err)
+	}
+
+	if strings.Contains(string(second), "\r\nBcc:") {
+		t.Errorf("second message = %q, the subject must not inject headers", second)
+	}
+}
+
+func TestOutboxMailerRequiresRecipients(t *testing.T) {
+	outbox, err := NewOutboxMailer(t.TempDir(), "no-reply@petparadise.local")
+	if err != nil {
+		t.Fatalf("NewOutboxMailer() error = %v", err)
+	}
+
+	if err := outbox.Send(&Message{Subject: "Nobody"}); err != ErrNoRecipients {
+		t.Errorf("Send() error = %v, want %v", err, ErrNoRecipients)
+	}
+}
@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"errors"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrNoRecipients = errors.New("message has no recipients")
+
+// SMTPMailer is a Mailer that delivers messages through an SMTP server
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer creates a new SMTPMailer. Authentication is skipped when no username is given.
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send delivers the message to every recipient
+func (m *SMTPMailer) Send(msg *Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipients
+	}
+
+	for _, to := range msg.To {
+		if strings.ContainsAny(to, "\r\n") {
+			return errors.New("invalid recipient address")
+		}
+	}
+
+	return smtp.SendMail(m.addr, m.auth, m.from, msg.To, format(m.from, msg, time.Now()))
+}
@@ -0,0 +1,169 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/solrac97gr/petparadise/pkg/oidc"
+	"github.com/solrac97gr/petparadise/pkg/oidc/oidctest"
+)
+
+const redirectURL = "http://localhost:3000/api/users/oidc/test/callback"
+
+func newTestProvider(t *testing.T) (*oidctest.Issuer, *oidc.Provider) {
+	t.Helper()
+
+	issuer := oidctest.NewIssuer("pet-paradise", "s3cret")
+	t.Cleanup(issuer.Close)
+
+	provider := oidc.NewProvider(oidc.Config{
+		Name:         "test",
+		Issuer:       issuer.URL,
+		ClientID:     issuer.ClientID,
+		ClientSecret: issuer.ClientSecret,
+		RedirectURL:  redirectURL,
+	}, nil)
+
+	return issuer, provider
+}
+
+// authorize runs the browser part of the flow and returns the code sent back to the callback
+func authorize(t *testing.T, issuer *oidctest.Issuer, provider *oidc.Provider, state, nonce, verifier string) string {
+	t.Helper()
+
+	loginURL, err := provider.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+
+	callback, err := issuer.Authorize(loginURL)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+
+	if got := callback.Query().Get("state"); got != state {
+		t.Fatalf("callback state = %q, want %q", got, state)
+	}
+
+	return callback.Query().Get("code")
+}
+
+func TestAuthorizationCodeFlowWithPKCE(t *testing.T) {
+	issuer, provider := newTestProvider(t)
+	issuer.SetIdentity(oidctest.Identity{
+		Subject:       "subject-1",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		Name:          "Jane Doe",
+	})
+
+	verifier, _ := oidc.RandomString()
+	code := authorize(t, issuer, provider, "state-1", "nonce-1", verifier)
+
+	tokens, err := provider.Exchange(context.Background(), code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	claims, err := provider.VerifyIDToken(context.Background(), tokens.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+
+	if claims.Subject != "subject-1" || claims.Email != "jane@example.com" || !bool(claims.EmailVerified) || claims.Name != "Jane Doe" {
+		t.Errorf("VerifyIDToken() claims = %+v", claims)
+	}
+
+	// Codes can't be exchanged twice
+	if _, err := provider.Exchange(context.Background(), code, verifier); !errors.Is(err, oidc.ErrExchange) {
+		t.Errorf("second Exchange() error = %v, want %v", err, oidc.ErrExchange)
+	}
+}
+
+func TestExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	issuer, provider := newTestProvider(t)
+	issuer.SetIdentity(oidctest.Identity{Subject: "subject-1"})
+
+	verifier, _ := oidc.RandomString()
+	code := authorize(t, issuer, provider, "state-1", "nonce-1", verifier)
+
+	other, _ := oidc.RandomString()
+	if _, err := provider.Exchange(context.Background(), code, other); !errors.Is(err, oidc.ErrExchange) {
+		t.Errorf("Exchange() error = %v, want %v", err, oidc.ErrExchange)
+	}
+}
+
+func TestVerifyIDTokenRejectsInvalidTokens(t *testing.T) {
+	issuer, provider := newTestProvider(t)
+	now := time.Now()
+
+	valid := func() *oidc.IDTokenClaims {
+		return &oidc.IDTokenClaims{
+			Nonce: "nonce-1",
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    issuer.URL,
+				Subject:   "subject-1",
+				Audience:  jwt.ClaimStrings{issuer.ClientID},
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute * 5)),
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(claims *oidc.IDTokenClaims)
+		nonce  string
+	}{
+		{"nonce mismatch", func(claims *oidc.IDTokenClaims) {}, "nonce-2"},
+		{"other issuer", func(claims *oidc.IDTokenClaims) { claims.Issuer = "https://evil.example.com" }, "nonce-1"},
+		{"other audience", func(claims *oidc.IDTokenClaims) { claims.Audience = jwt.ClaimStrings{"other-client"} }, "nonce-1"},
+		{"expired", func(claims *oidc.IDTokenClaims) { claims.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Hour)) }, "nonce-1"},
+		{"missing subject", func(claims *oidc.IDTokenClaims) { claims.Subject = "" }, "nonce-1"},
+		{"issued to another client", func(claims *oidc.IDTokenClaims) {
+			claims.Audience = jwt.ClaimStrings{issuer.ClientID, "other-client"}
+			claims.AuthorizedBy = "other-client"
+		}, "nonce-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.modify(claims)
+
+			idToken, err := issuer.SignIDToken(claims)
+			if err != nil {
+				t.Fatalf("SignIDToken() error = %v", err)
+			}
+
+			if _, err := provider.VerifyIDToken(context.Background(), idToken, tt.nonce); !errors.Is(err, oidc.ErrInvalidIDToken) {
+				t.Errorf("VerifyIDToken() error = %v, want %v", err, oidc.ErrInvalidIDToken)
+			}
+		})
+	}
+
+	// A token signed with another key is rejected
+	forged, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, valid()).SignedString([]byte("secret"))
+	if _, err := provider.VerifyIDToken(context.Background(), forged, "nonce-1"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Errorf("VerifyIDToken() with a forged token error = %v, want %v", err, oidc.ErrInvalidIDToken)
+	}
+}
+
+func TestDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	issuer, _ := newTestProvider(t)
+
+	provider := oidc.NewProvider(oidc.Config{
+		Name:        "test",
+		Issuer:      issuer.URL + "/",
+		ClientID:    issuer.ClientID,
+		RedirectURL: redirectURL,
+	}, nil)
+
+	if _, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier"); !errors.Is(err, oidc.ErrDiscovery) {
+		t.Errorf("AuthCodeURL() error = %v, want %v", err, oidc.ErrDiscovery)
+	}
+}
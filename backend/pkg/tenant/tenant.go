@@ -0,0 +1,50 @@
+// Package tenant scopes the records of the shelters sharing the platform
+package tenant
+
+import "fmt"
+
+// Scope is the shelters a request can reach: a single shelter, or every shelter for platform
+// admins. The zero Scope reaches no shelter, so a scope that was never set denies access.
+type Scope struct {
+	shelterID string
+	all       bool
+}
+
+// Shelter returns the scope of a single shelter. An empty ID reaches no shelter.
+func Shelter(shelterID string) Scope {
+	return Scope{shelterID: shelterID}
+}
+
+// AllShelters returns the scope of every shelter, for platform admins and background jobs
+func AllShelters() Scope {
+	return Scope{all: true}
+}
+
+// IsAll checks if the scope reaches every shelter
+func (s Scope) IsAll() bool {
+	return s.all
+}
+
+// ShelterID returns the shelter of a single-shelter scope, empty for every shelter
+func (s Scope) ShelterID() string {
+	if s.all {
+		return ""
+	}
+	return s.shelterID
+}
+
+// Allows checks if the records of a shelter are within the scope
+func (s Scope) Allows(shelterID string) bool {
+	return s.all || (s.shelterID != "" && s.shelterID == shelterID)
+}
+
+// Filter returns the arguments of the SQL condition built by Condition
+func (s Scope) Filter() (all bool, shelterID string) {
+	return s.all, s.shelterID
+}
+
+// Condition returns an SQL condition limiting a UUID shelter column to a scope, whose Filter
+// values are passed as the arguments $n and $n+1. An empty shelter matches no row.
+func Condition(column string, n int) string {
+	return fmt.Sprintf("($%d OR %s = NULLIF($%d, '')::uuid)", n, column, n+1)
+}
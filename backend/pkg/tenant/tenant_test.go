@@ -0,0 +1,53 @@
+package tenant
+
+import "testing"
+
+func TestScopeAllows(t *testing.T) {
+	tests := []struct {
+		name      string
+		scope     Scope
+		shelterID string
+		want      bool
+	}{
+		{name: "Own shelter", scope: Shelter("north"), shelterID: "north", want: true},
+		{name: "Other shelter", scope: Shelter("north"), shelterID: "south", want: false},
+		{name: "Record without a shelter", scope: Shelter("north"), shelterID: "", want: false},
+		{name: "Every shelter", scope: AllShelters(), shelterID: "south", want: true},
+		{name: "Every shelter and no shelter", scope: AllShelters(), shelterID: "", want: true},
+		{name: "Empty shelter", scope: Shelter(""), shelterID: "", want: false},
+		{name: "Zero scope", scope: Scope{}, shelterID: "north", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.Allows(tt.shelterID); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.shelterID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopeFilter(t *testing.T) {
+	if all, shelterID := Shelter("north").Filter(); all || shelterID != "north" {
+		t.Errorf("Shelter(north).Filter() = %v, %q, want false, north", all, shelterID)
+	}
+
+	if all, shelterID := AllShelters().Filter(); !all || shelterID != "" {
+		t.Errorf("AllShelters().Filter() = %v, %q, want true, empty", all, shelterID)
+	}
+
+	if all, shelterID := (Scope{}).Filter(); all || shelterID != "" {
+		t.Errorf("Scope{}.Filter() = %v, %q, want false, empty", all, shelterID)
+	}
+
+	if got := AllShelters().ShelterID(); got != "" {
+		t.Errorf("AllShelters().ShelterID() = %q, want empty", got)
+	}
+}
+
+func TestCondition(t *testing.T) {
+	want := "($3 OR p.shelter_id = NULLIF($4, '')::uuid)"
+	if got := Condition("p.shelter_id", 3); got != want {
+		t.Errorf("Condition() = %q, want %q", got, want)
+	}
+}
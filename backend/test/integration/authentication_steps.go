@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/cucumber/godog"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/solrac97gr/petparadise/pkg/auth"
 )
 
 // AuthSteps contains authentication test steps
@@ -20,6 +22,11 @@ type AuthSteps struct {
 	testToken            string
 	testUserID           string // Store the user ID from authentication
 	explicitRefreshToken string // Added to store an explicitly set refresh token
+	usedRefreshToken     string // The refresh token sent in the last refresh request
+	rotatedRefreshToken  string // The refresh token received from the last successful refresh
+	mfaSecret            string // TOTP secret received when enrolling in two-factor authentication
+	recoveryCodes        []string
+	mfaToken             string // MFA challenge received from the first login step
 }
 
 // RegisterAuthenticationSteps registers step definitions for authentication scenarios
@@ -35,14 +42,34 @@ func RegisterAuthenticationSteps(ctx *godog.ScenarioContext, client *APIClient,
 	ctx.Step(`^I have an expired refresh token$`, steps.iHaveExpiredRefreshToken)
 	ctx.Step(`^I have a valid access token$`, steps.iHaveValidAccessToken)
 	ctx.Step(`^I have an expired access token$`, steps.iHaveExpiredAccessToken)
+	ctx.Step(`^I have registered without verifying my email$`, steps.iHaveRegisteredWithoutVerifyingMyEmail)
 
 	// When steps
 	ctx.Step(`^I login with my credentials$`, steps.iLoginWithMyCredentials)
 	ctx.Step(`^I request to refresh my tokens$`, steps.iRequestToRefreshMyTokens)
+	ctx.Step(`^I reuse my previous refresh token$`, steps.iReuseMyPreviousRefreshToken)
+	ctx.Step(`^I request to refresh my tokens with the rotated refresh token$`, steps.iRequestToRefreshWithRotatedRefreshToken)
+	ctx.Step(`^I use my new access token to access an admin resource$`, steps.iUseMyNewAccessTokenToAccessAdminResource)
 	ctx.Step(`^I logout$`, steps.iLogout)
 	ctx.Step(`^I use my token to access a protected resource$`, steps.iUseMyTokenToAccessProtectedResource)
 	ctx.Step(`^I try to access a protected resource without authentication$`, steps.iTryToAccessProtectedResourceWithoutAuth)
 	ctx.Step(`^I revoke all my user tokens$`, steps.iRevokeAllMyUserTokens)
+	ctx.Step(`^I login with my unverified account$`, steps.iLoginWithMyUnverifiedAccount)
+	ctx.Step(`^I verify my email with the token "([^"]*)"$`, steps.iVerifyMyEmailWithTheToken)
+	ctx.Step(`^I request a new verification email$`, steps.iRequestANewVerificationEmail)
+	ctx.Step(`^I request a password reset for my email$`, steps.iRequestAPasswordResetForMyEmail)
+	ctx.Step(`^I request a password reset for an unknown email$`, steps.iRequestAPasswordResetForAnUnknownEmail)
+	ctx.Step(`^I reset my password with the token "([^"]*)"$`, steps.iResetMyPasswordWithTheToken)
+	ctx.Step(`^I enroll in two-factor authentication$`, steps.iEnrollInTwoFactorAuthentication)
+	ctx.Step(`^I confirm the enrollment with a code from my authenticator app$`, steps.iConfirmTheEnrollmentWithACode)
+	ctx.Step(`^I login again with my password$`, steps.iLoginAgainWithMyPassword)
+	ctx.Step(`^I complete the login with a recovery code$`, steps.iCompleteTheLoginWithARecoveryCode)
+	ctx.Step(`^I complete the login with the code "([^"]*)"$`, steps.iCompleteTheLoginWithTheCode)
+	ctx.Step(`^I login with a wrong password (\d+) times$`, steps.iLoginWithAWrongPasswordTimes)
+	ctx.Step(`^an admin unlocks my account$`, steps.anAdminUnlocksMyAccount)
+	ctx.Step(`^the response should have a Retry-After header$`, steps.theResponseShouldHaveARetryAfterHeader)
+	ctx.Step(`^I start a login with the identity provider "([^"]*)"$`, steps.iStartALoginWithTheIdentityProvider)
+	ctx.Step(`^the identity provider "([^"]*)" redirects me back with the state "([^"]*)"$`, steps.theIdentityProviderRedirectsMeBack)
 
 	// Then steps
 	ctx.Step(`^I should receive a valid token pair$`, steps.iShouldReceiveValidTokenPair)
@@ -51,6 +78,8 @@ func RegisterAuthenticationSteps(ctx *godog.ScenarioContext, client *APIClient,
 	ctx.Step(`^I should receive a (\d+) status code$`, steps.iShouldReceiveStatusCode)
 	ctx.Step(`^the response should contain "([^"]*)"$`, steps.theResponseShouldContain)
 	ctx.Step(`^my tokens should be invalidated$`, steps.myTokensShouldBeInvalidated)
+	ctx.Step(`^I should receive recovery codes$`, steps.iShouldReceiveRecoveryCodes)
+	ctx.Step(`^I should receive an MFA challenge instead of tokens$`, steps.iShouldReceiveAnMFAChallengeInsteadOfTokens)
 }
 
 // Step definition implementations
@@ -77,8 +106,8 @@ func (s *AuthSteps) iAmAuthenticatedAs(role string) error {
 		return fmt.Errorf("failed to create test user, got status %d, and body %v", s.client.GetResponseStatusCode(), string(s.client.GetResponseBody()))
 	}
 
-	// we convert the user to the specified role for testing purposes
-	res, err := s.db.Exec("UPDATE users SET role = $1 WHERE email = $2", role, s.testEmail)
+	// we convert the user to the specified role and skip email verification for testing purposes
+	res, err := s.db.Exec("UPDATE users SET role = $1, status = 'active' WHERE email = $2", role, s.testEmail)
 	if err != nil {
 		return fmt.Errorf("failed to update user role: %v", err)
 	}
@@ -183,7 +212,191 @@ func (s *AuthSteps) iHaveExpiredAccessToken() error {
 	return nil
 }
 
+func (s *AuthSteps) iHaveRegisteredWithoutVerifyingMyEmail() error {
+	s.testEmail = "test" + uuid.New().String() + "pending@example.com"
+	s.testPassword = "password123"
+
+	if err := s.client.Post("/users/register", map[string]string{
+		"name":     "Test User",
+		"email":    s.testEmail,
+		"password": s.testPassword,
+	}); err != nil {
+		return fmt.Errorf("failed to create test user: %v", err)
+	}
+	if s.client.GetResponseStatusCode() != http.StatusCreated {
+		return fmt.Errorf("failed to create test user, got status %d, and body %v", s.client.GetResponseStatusCode(), string(s.client.GetResponseBody()))
+	}
+
+	if status, _ := s.client.GetResponseBodyAsMap()["status"].(string); status != "pending" {
+		return fmt.Errorf("expected new user to be pending, got status %q", status)
+	}
+	return nil
+}
+
 // When steps
+func (s *AuthSteps) iLoginWithMyUnverifiedAccount() error {
+	return s.client.Post("/users/login", map[string]string{
+		"email":    s.testEmail,
+		"password": s.testPassword,
+	})
+}
+
+func (s *AuthSteps) iVerifyMyEmailWithTheToken(token string) error {
+	return s.client.Get("/users/verify?token=" + token)
+}
+
+func (s *AuthSteps) iRequestANewVerificationEmail() error {
+	return s.client.Post("/users/verify/resend", map[string]string{
+		"email": s.testEmail,
+	})
+}
+
+func (s *AuthSteps) iRequestAPasswordResetForMyEmail() error {
+	return s.client.Post("/users/password/forgot", map[string]string{
+		"email": s.testEmail,
+	})
+}
+
+func (s *AuthSteps) iRequestAPasswordResetForAnUnknownEmail() error {
+	return s.client.Post("/users/password/forgot", map[string]string{
+		"email": "unknown" + uuid.New().String() + "@example.com",
+	})
+}
+
+func (s *AuthSteps) iResetMyPasswordWithTheToken(token string) error {
+	return s.client.Post("/users/password/reset", map[string]string{
+		"token":        token,
+		"new_password": "newpassword123",
+	})
+}
+
+func (s *AuthSteps) iLoginWithAWrongPasswordTimes(times int) error {
+	s.client.AuthToken = ""
+	for i := 0; i < times; i++ {
+		if err := s.client.Post("/users/login", map[string]string{
+			"email":    s.testEmail,
+			"password": "wrong-" + s.testPassword,
+		}); err != nil {
+			return err
+		}
+
+		if s.client.GetResponseStatusCode() != http.StatusUnauthorized {
+			return fmt.Errorf("expected failed login %d to get status 401, got %d", i+1, s.client.GetResponseStatusCode())
+		}
+	}
+	return nil
+}
+
+func (s *AuthSteps) anAdminUnlocksMyAccount() error {
+	adminEmail := "admin" + uuid.New().String() + "@example.com"
+
+	if err := s.client.Post("/users/register", map[string]string{
+		"name":     "Test Admin",
+		"email":    adminEmail,
+		"password": "password123",
+	}); err != nil {
+		return err
+	}
+
+	if _, err := s.db.Exec("UPDATE users SET role = 'admin', status = 'active' WHERE email = $1", adminEmail); err != nil {
+		return fmt.Errorf("failed to update admin role: %v", err)
+	}
+
+	if err := s.client.Post("/users/login", map[string]string{
+		"email":    adminEmail,
+		"password": "password123",
+	}); err != nil {
+		return err
+	}
+
+	tokens, ok := s.client.GetResponseBodyAsMap()["tokens"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("admin login failed, got status %d", s.client.GetResponseStatusCode())
+	}
+
+	var userID string
+	if err := s.db.Get(&userID, "SELECT id FROM users WHERE email = $1", s.testEmail); err != nil {
+		return fmt.Errorf("failed to find user: %v", err)
+	}
+
+	s.client.SetAuthToken(tokens["access_token"].(string))
+	defer s.client.SetAuthToken("")
+
+	if err := s.client.Post("/users/"+userID+"/unlock", nil); err != nil {
+		return err
+	}
+
+	if s.client.GetResponseStatusCode() != http.StatusOK {
+		return fmt.Errorf("failed to unlock account, got status %d", s.client.GetResponseStatusCode())
+	}
+	return nil
+}
+
+func (s *AuthSteps) theResponseShouldHaveARetryAfterHeader() error {
+	if s.client.LastResponse == nil || s.client.LastResponse.Headers.Get("Retry-After") == "" {
+		return fmt.Errorf("expected a Retry-After header")
+	}
+	return nil
+}
+
+func (s *AuthSteps) iStartALoginWithTheIdentityProvider(provider string) error {
+	return s.client.Get("/users/oidc/" + provider + "/login")
+}
+
+func (s *AuthSteps) theIdentityProviderRedirectsMeBack(provider, state string) error {
+	return s.client.Get("/users/oidc/" + provider + "/callback?code=some-code&state=" + state)
+}
+
+func (s *AuthSteps) iEnrollInTwoFactorAuthentication() error {
+	if err := s.client.Post("/users/me/mfa/enroll", nil); err != nil {
+		return err
+	}
+	if s.client.GetResponseStatusCode() != http.StatusCreated {
+		return fmt.Errorf("failed to enroll, got status %d, and body %v", s.client.GetResponseStatusCode(), string(s.client.GetResponseBody()))
+	}
+
+	secret, ok := s.client.GetResponseBodyAsMap()["secret"].(string)
+	if !ok || secret == "" {
+		return fmt.Errorf("secret not found in enrollment response")
+	}
+	s.mfaSecret = secret
+	return nil
+}
+
+func (s *AuthSteps) iConfirmTheEnrollmentWithACode() error {
+	// Compute the code the authenticator app would show
+	code, err := auth.TOTPCode(s.mfaSecret, auth.TOTPStep(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to compute TOTP code: %v", err)
+	}
+
+	return s.client.Post("/users/me/mfa/confirm", map[string]string{
+		"code": code,
+	})
+}
+
+func (s *AuthSteps) iLoginAgainWithMyPassword() error {
+	s.client.AuthToken = ""
+	return s.client.Post("/users/login", map[string]string{
+		"email":    s.testEmail,
+		"password": s.testPassword,
+	})
+}
+
+func (s *AuthSteps) iCompleteTheLoginWithARecoveryCode() error {
+	if len(s.recoveryCodes) == 0 {
+		return fmt.Errorf("no recovery codes available")
+	}
+	return s.iCompleteTheLoginWithTheCode(s.recoveryCodes[0])
+}
+
+func (s *AuthSteps) iCompleteTheLoginWithTheCode(code string) error {
+	return s.client.Post("/users/login/mfa", map[string]string{
+		"mfa_token": s.mfaToken,
+		"code":      code,
+	})
+}
+
 func (s *AuthSteps) iLoginWithMyCredentials() error {
 
 	// Use the credentials set in the previous steps
@@ -215,8 +428,8 @@ func (s *AuthSteps) iLoginWithMyCredentials() error {
 		return fmt.Errorf("failed to create test user, got status %d, and body %v", s.client.GetResponseStatusCode(), string(s.client.GetResponseBody()))
 	}
 
-	// we convert the user to an admin for testing purposes
-	res, err := s.db.Exec("UPDATE users SET role = 'admin' WHERE email = $1", email)
+	// we convert the user to an admin and skip email verification for testing purposes
+	res, err := s.db.Exec("UPDATE users SET role = 'admin', status = 'active' WHERE email = $1", email)
 	if err != nil {
 		return fmt.Errorf("failed to update user role: %v", err)
 	}
@@ -270,7 +483,54 @@ func (s *AuthSteps) iRequestToRefreshMyTokens() error {
 	// Send refresh request
 	// The client.Post method should handle storing the response (status code, body)
 	// so subsequent "Then" steps can assert on it.
-	return s.client.Post("/users/refresh", refreshData)
+	s.usedRefreshToken = tokenForRefresh
+	if err := s.client.Post("/users/refresh", refreshData); err != nil {
+		return err
+	}
+
+	// Remember the rotated refresh token for reuse scenarios
+	if tokensObj, ok := s.client.GetResponseBodyAsMap()["tokens"].(map[string]interface{}); ok {
+		if refreshToken, ok := tokensObj["refresh_token"].(string); ok {
+			s.rotatedRefreshToken = refreshToken
+		}
+	}
+
+	return nil
+}
+
+func (s *AuthSteps) iReuseMyPreviousRefreshToken() error {
+	if s.usedRefreshToken == "" {
+		return fmt.Errorf("no refresh token has been used yet")
+	}
+
+	s.explicitRefreshToken = s.usedRefreshToken
+	return s.iRequestToRefreshMyTokens()
+}
+
+func (s *AuthSteps) iRequestToRefreshWithRotatedRefreshToken() error {
+	if s.rotatedRefreshToken == "" {
+		return fmt.Errorf("no rotated refresh token available")
+	}
+
+	s.explicitRefreshToken = s.rotatedRefreshToken
+	return s.iRequestToRefreshMyTokens()
+}
+
+func (s *AuthSteps) iUseMyNewAccessTokenToAccessAdminResource() error {
+	tokensObj, ok := s.client.GetResponseBodyAsMap()["tokens"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("tokens object not found in refresh response")
+	}
+
+	accessToken, ok := tokensObj["access_token"].(string)
+	if !ok {
+		return fmt.Errorf("access_token not found in refresh response")
+	}
+
+	s.client.SetAuthToken(accessToken)
+
+	// Listing all donations is restricted to admins
+	return s.client.Get("/donations/")
 }
 
 func (s *AuthSteps) iLogout() error {
@@ -339,6 +599,39 @@ func (s *AuthSteps) iShouldReceiveValidTokenPair() error {
 	return nil
 }
 
+func (s *AuthSteps) iShouldReceiveRecoveryCodes() error {
+	codes, ok := s.client.GetResponseBodyAsMap()["recovery_codes"].([]interface{})
+	if !ok || len(codes) == 0 {
+		return fmt.Errorf("recovery codes not found in response: %s", string(s.client.GetResponseBody()))
+	}
+
+	s.recoveryCodes = nil
+	for _, code := range codes {
+		if c, ok := code.(string); ok {
+			s.recoveryCodes = append(s.recoveryCodes, c)
+		}
+	}
+	return nil
+}
+
+func (s *AuthSteps) iShouldReceiveAnMFAChallengeInsteadOfTokens() error {
+	respBody := s.client.GetResponseBodyAsMap()
+	if _, exists := respBody["tokens"]; exists {
+		return fmt.Errorf("expected no tokens before the second factor, got %v", respBody)
+	}
+
+	if required, _ := respBody["mfa_required"].(bool); !required {
+		return fmt.Errorf("expected mfa_required to be true, got %v", respBody)
+	}
+
+	mfaToken, ok := respBody["mfa_token"].(string)
+	if !ok || mfaToken == "" {
+		return fmt.Errorf("mfa_token not found in response")
+	}
+	s.mfaToken = mfaToken
+	return nil
+}
+
 func (s *AuthSteps) iShouldReceiveNewValidTokens() error {
 	// Similar to iShouldReceiveValidTokenPair, but could add additional checks
 	// for token freshness if needed
@@ -15,6 +15,87 @@ Feature: Authentication
     Then I should receive an authentication error
     And I should receive a 401 status code
 
+  Scenario: Unverified users cannot log in
+    Given I have registered without verifying my email
+    When I login with my unverified account
+    Then I should receive a 403 status code
+    And the response should contain "email address not verified"
+
+  Scenario: Verify email with an invalid token
+    Given I have registered without verifying my email
+    When I verify my email with the token "not-a-valid-token"
+    Then I should receive a 400 status code
+    And the response should contain "invalid verification token"
+
+  Scenario: Resending the verification email is rate limited
+    Given I have registered without verifying my email
+    When I request a new verification email
+    Then I should receive a 429 status code
+    And the response should have a Retry-After header
+
+  Scenario: Requesting a password reset for a registered email
+    Given I am authenticated as a "user"
+    When I request a password reset for my email
+    Then I should receive a 202 status code
+    And the response should contain "If an account exists for this email"
+
+  Scenario: Requesting a password reset does not reveal unknown emails
+    When I request a password reset for an unknown email
+    Then I should receive a 202 status code
+    And the response should contain "If an account exists for this email"
+
+  Scenario: Reset password with an invalid token
+    When I reset my password with the token "not-a-valid-token"
+    Then I should receive a 400 status code
+    And the response should contain "invalid or expired reset token"
+
+  Scenario: Login with two-factor authentication and a recovery code
+    Given I am authenticated as a "vet"
+    When I enroll in two-factor authentication
+    And I confirm the enrollment with a code from my authenticator app
+    Then I should receive a 200 status code
+    And I should receive recovery codes
+    When I login again with my password
+    Then I should receive an MFA challenge instead of tokens
+    When I complete the login with a recovery code
+    Then I should receive a 200 status code
+    And I should receive a valid token pair
+
+  Scenario: Second login step with an invalid code
+    Given I am authenticated as a "vet"
+    When I enroll in two-factor authentication
+    And I confirm the enrollment with a code from my authenticator app
+    And I login again with my password
+    Then I should receive an MFA challenge instead of tokens
+    When I complete the login with the code "000000"
+    Then I should receive a 401 status code
+    And the response should contain "invalid authentication code"
+
+  Scenario: Repeated failed logins are throttled
+    Given I am authenticated as a "user"
+    When I login with a wrong password 3 times
+    And I login again with my password
+    Then I should receive a 429 status code
+    And the response should have a Retry-After header
+    And the response should contain "too many failed login attempts"
+
+  Scenario: Admins can unlock a throttled account
+    Given I am authenticated as a "user"
+    When I login with a wrong password 3 times
+    And an admin unlocks my account
+    And I login again with my password
+    Then I should receive a 200 status code
+
+  Scenario: Login with an unknown identity provider
+    When I start a login with the identity provider "unknown"
+    Then I should receive a 404 status code
+    And the response should contain "unknown identity provider"
+
+  Scenario: Identity provider callback without a login started in this browser
+    When the identity provider "unknown" redirects me back with the state "forged-state"
+    Then I should receive a 400 status code
+    And the response should contain "invalid or expired login state"
+
   Scenario: Refresh access token with valid refresh token
     Given I am authenticated as a "user"
     And I have a valid refresh token
@@ -29,6 +110,36 @@ Feature: Authentication
     And I should receive a 401 status code
     And the response should contain "Invalid refresh token"
 
+  Scenario: Reuse of a rotated refresh token revokes the token family
+    Given I am authenticated as a "user"
+    And I have a valid refresh token
+    When I request to refresh my tokens
+    And I reuse my previous refresh token
+    Then I should receive an authentication error
+    And the response should contain "refresh_token_reused"
+    When I request to refresh my tokens with the rotated refresh token
+    Then I should receive an authentication error
+    And I should receive a 401 status code
+
+  Scenario: Refreshed tokens keep the user's current role
+    Given I am authenticated as an "admin"
+    And I have a valid refresh token
+    When I request to refresh my tokens
+    And I use my new access token to access an admin resource
+    Then I should receive a 200 status code
+
+  Scenario: Logout revokes the access token
+    Given I am authenticated as a "user"
+    When I logout
+    Then I should receive a 200 status code
+    And my tokens should be invalidated
+
+  Scenario: Revoke all tokens of my account
+    Given I am authenticated as a "user"
+    When I revoke all my user tokens
+    Then I should receive a 200 status code
+    And my tokens should be invalidated
+
   Scenario: Access protected resource with valid token
     Given I am authenticated as a "user"
     When I use my token to access a protected resource
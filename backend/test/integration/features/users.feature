@@ -25,6 +25,17 @@ Feature: User Management
     Then I should receive a 409 status code
     And the response should contain "email already in use"
 
+  Scenario: Register with a password that breaks the password policy
+    Given I have registration data with the password "short"
+    When I register a new user
+    Then I should receive a 400 status code
+    And the response should contain "too_short"
+
+  Scenario: Register with a staff role
+    Given I have registration data with the "admin" role
+    When I register a new user
+    Then I should receive a 403 status code
+
   Scenario: Get all users as admin
     Given I am authenticated as an "admin"
     When I request all users
@@ -95,8 +106,8 @@ Feature: User Management
     Given I am authenticated as an "admin"
     And a user exists in the system
     When I delete the user
-    Then I should receive a 204 status code
-    And the user should be removed from the system
+    Then I should receive a 202 status code
+    And the user should be scheduled for deletion
 
   Scenario: Delete user as regular user
     Given I am authenticated as a "user"
@@ -104,7 +115,83 @@ Feature: User Management
     When I try to delete the other user
     Then I should receive a 403 status code
 
-  Scenario: Access user endpoints without authentication
+  Scenario: Update role permissions as admin
+    Given I am authenticated as an "admin"
+    When I set the permissions of the "volunteer" role to "pets:write,adoptions:read"
+    Then I should receive a 200 status code
+
+  Scenario: Update role permissions as regular user
+    Given I am authenticated as a "user"
+    When I set the permissions of the "volunteer" role to "pets:write,pets:delete"
+    Then I should receive a 403 status code
+
+  Scenario: Create an API key as admin
+    Given I am authenticated as an "admin"
+    When I create an API key with the "pets:write,adoptions:read" permissions
+    Then I should receive a 201 status code
+
+  Scenario: Create an API key as regular user
+    Given I am authenticated as a "user"
+    When I create an API key with the "pets:write" permissions
+    Then I should receive a 403 status code
+
+  Scenario: Invite a staff member as admin
+    Given I am authenticated as an "admin"
+    When I invite a staff member as a "volunteer"
+    Then I should receive a 201 status code
+
+  Scenario: Invite a staff member as regular user
+    Given I am authenticated as a "user"
+    When I invite a staff member as a "volunteer"
+    Then I should receive a 403 status code
+
+  Scenario: List my sessions
+    Given I am authenticated as a "user"
+    When I request my sessions
+    Then I should receive a 200 status code
+    And the response should contain my current session
+
+  Scenario: View the sessions of another user as admin
+    Given I am authenticated as an "admin"
+    And another user exists in the system
+    When I request the sessions of the other user
+    Then I should receive a 200 status code
+
+  Scenario: View the sessions of another user as regular user
+    Given I am authenticated as a "user"
+    And another user exists in the system
+    When I request the sessions of the other user
+    Then I should receive a 403 status code
+
+  Scenario: Export my personal data
+    Given I am authenticated as a "user"
+    When I request an export of my data
+    Then I should receive a 200 status code
+    When I download my data export
+    Then I should receive a 200 status code
+
+  Scenario: Delete my account and change my mind
+    Given I am authenticated as a "user"
+    When I delete my account
+    Then I should receive a 202 status code
+    When I cancel the deletion of my account
+    Then I should receive a 200 status code
+
+  Scenario: Audit a role change as admin
+    Given I am authenticated as an "admin"
+    And a user exists in the system
+    When I update the user role to "volunteer"
+    Then I should receive a 200 status code
+    When I request the audit log of the user
+    Then I should receive a 200 status code
+    And the audit log should contain the "user.role_update" action
+
+  Scenario: Read the audit log as regular user
+    Given I am authenticated as a "user"
+    And a user exists in the system
+    When I request the audit log of the user
+    Then I should receive a 403 status code
+
     Given I am not authenticated
     When I try to access user endpoints without authentication
     Then I should receive a 401 status code
@@ -131,6 +131,10 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 		// Clean up test data from database to ensure scenario isolation
 		if testDB != nil {
 			testDB.Exec("TRUNCATE TABLE users CASCADE")
+			testDB.Exec("TRUNCATE TABLE login_throttles")
+			testDB.Exec("TRUNCATE TABLE role_permissions")
+			testDB.Exec("TRUNCATE TABLE api_keys")
+			testDB.Exec("TRUNCATE TABLE invitations")
 		}
 		
 		return ctx, nil
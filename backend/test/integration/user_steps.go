@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/cucumber/godog"
 	"github.com/google/uuid"
@@ -35,6 +36,8 @@ func RegisterUserSteps(ctx *godog.ScenarioContext, client *APIClient, db *sqlx.D
 	ctx.Step(`^I have valid registration data$`, steps.iHaveValidRegistrationData)
 	ctx.Step(`^I have invalid registration data$`, steps.iHaveInvalidRegistrationData)
 	ctx.Step(`^I have registration data with an existing email$`, steps.iHaveRegistrationDataWithExistingEmail)
+	ctx.Step(`^I have registration data with the "([^"]*)" role$`, steps.iHaveRegistrationDataWithTheRole)
+	ctx.Step(`^I have registration data with the password "([^"]*)"$`, steps.iHaveRegistrationDataWithThePassword)
 	ctx.Step(`^a user exists in the system$`, steps.aUserExistsInTheSystem)
 	ctx.Step(`^another user exists in the system$`, steps.anotherUserExistsInTheSystem)
 	ctx.Step(`^users with different statuses exist$`, steps.usersWithDifferentStatusesExist)
@@ -55,6 +58,16 @@ func RegisterUserSteps(ctx *godog.ScenarioContext, client *APIClient, db *sqlx.D
 	ctx.Step(`^I delete the user$`, steps.iDeleteTheUser)
 	ctx.Step(`^I try to delete the other user$`, steps.iTryToDeleteTheOtherUser)
 	ctx.Step(`^I try to access user endpoints without authentication$`, steps.iTryToAccessUserEndpointsWithoutAuthentication)
+	ctx.Step(`^I set the permissions of the "([^"]*)" role to "([^"]*)"$`, steps.iSetThePermissionsOfTheRoleTo)
+	ctx.Step(`^I create an API key with the "([^"]*)" permissions$`, steps.iCreateAnAPIKeyWithThePermissions)
+	ctx.Step(`^I invite a staff member as a "([^"]*)"$`, steps.iInviteAStaffMemberAsA)
+	ctx.Step(`^I request my sessions$`, steps.iRequestMySessions)
+	ctx.Step(`^I request the sessions of the other user$`, steps.iRequestTheSessionsOfTheOtherUser)
+	ctx.Step(`^I request an export of my data$`, steps.iRequestAnExportOfMyData)
+	ctx.Step(`^I download my data export$`, steps.iDownloadMyDataExport)
+	ctx.Step(`^I delete my account$`, steps.iDeleteMyAccount)
+	ctx.Step(`^I cancel the deletion of my account$`, steps.iCancelTheDeletionOfMyAccount)
+	ctx.Step(`^I request the audit log of the user$`, steps.iRequestTheAuditLogOfTheUser)
 
 	// Then steps
 	ctx.Step(`^the response should contain user details$`, steps.theResponseShouldContainUserDetails)
@@ -66,7 +79,9 @@ func RegisterUserSteps(ctx *godog.ScenarioContext, client *APIClient, db *sqlx.D
 	ctx.Step(`^the user information should be updated$`, steps.theUserInformationShouldBeUpdated)
 	ctx.Step(`^the user role should be updated$`, steps.theUserRoleShouldBeUpdated)
 	ctx.Step(`^the user status should be updated$`, steps.theUserStatusShouldBeUpdated)
-	ctx.Step(`^the user should be removed from the system$`, steps.theUserShouldBeRemovedFromTheSystem)
+	ctx.Step(`^the user should be scheduled for deletion$`, steps.theUserShouldBeScheduledForDeletion)
+	ctx.Step(`^the response should contain my current session$`, steps.theResponseShouldContainMyCurrentSession)
+	ctx.Step(`^the audit log should contain the "([^"]*)" action$`, steps.theAuditLogShouldContainTheAction)
 }
 
 // Given step implementations
@@ -91,6 +106,24 @@ func (s *UserSteps) iHaveValidRegistrationData() error {
 	return nil
 }
 
+func (s *UserSteps) iHaveRegistrationDataWithTheRole(role string) error {
+	if err := s.iHaveValidRegistrationData(); err != nil {
+		return err
+	}
+	s.registrationData["role"] = role
+	s.registrationValid = false
+	return nil
+}
+
+func (s *UserSteps) iHaveRegistrationDataWithThePassword(password string) error {
+	if err := s.iHaveValidRegistrationData(); err != nil {
+		return err
+	}
+	s.registrationData["password"] = password
+	s.registrationValid = false
+	return nil
+}
+
 func (s *UserSteps) iHaveInvalidRegistrationData() error {
 	s.registrationData = map[string]interface{}{
 		"name":     "", // Invalid: empty name
@@ -206,6 +239,12 @@ func (s *UserSteps) usersWithDifferentStatusesExist() error {
 		return fmt.Errorf("failed to create active user: %v", err)
 	}
 
+	// Skip email verification for the active user
+	_, err = s.db.Exec("UPDATE users SET status = 'active' WHERE email = $1", activeUser["email"])
+	if err != nil {
+		return fmt.Errorf("failed to update user status: %v", err)
+	}
+
 	// Create another user and make it inactive
 	randomUUID2 := uuid.New().String()
 	inactiveUserEmail := "inactiveuser" + randomUUID2 + "@example.com"
@@ -426,6 +465,76 @@ func (s *UserSteps) iTryToDeleteTheOtherUser() error {
 	return s.client.Delete("/users/" + s.anotherTestUserID)
 }
 
+func (s *UserSteps) iSetThePermissionsOfTheRoleTo(role, permissions string) error {
+	permissionData := map[string]interface{}{
+		"permissions": strings.Split(permissions, ","),
+	}
+
+	return s.client.Put("/users/roles/"+role+"/permissions", permissionData)
+}
+
+func (s *UserSteps) iCreateAnAPIKeyWithThePermissions(permissions string) error {
+	apiKeyData := map[string]interface{}{
+		"name":        "Partner clinic",
+		"permissions": strings.Split(permissions, ","),
+	}
+
+	return s.client.Post("/users/api-keys", apiKeyData)
+}
+
+func (s *UserSteps) iInviteAStaffMemberAsA(role string) error {
+	invitationData := map[string]interface{}{
+		"email": "staff" + uuid.New().String() + "@example.com",
+		"role":  role,
+	}
+
+	return s.client.Post("/users/invitations", invitationData)
+}
+
+func (s *UserSteps) iRequestMySessions() error {
+	return s.client.Get("/users/me/sessions")
+}
+
+func (s *UserSteps) iRequestTheSessionsOfTheOtherUser() error {
+	if s.anotherTestUserID == "" {
+		return fmt.Errorf("no other test user ID available")
+	}
+	return s.client.Get("/users/" + s.anotherTestUserID + "/sessions")
+}
+
+func (s *UserSteps) iRequestAnExportOfMyData() error {
+	return s.client.Get("/users/me/export")
+}
+
+func (s *UserSteps) iDownloadMyDataExport() error {
+	respBody := s.client.GetResponseBodyAsMap()
+	downloadURL, _ := respBody["download_url"].(string)
+
+	// The link points at the public URL of the API, the test client only needs its path
+	index := strings.Index(downloadURL, "/users/exports/download")
+	if index < 0 {
+		return fmt.Errorf("no download link in the response, export status: %v", respBody["status"])
+	}
+
+	s.client.SetAuthToken("")
+	return s.client.Get(downloadURL[index:])
+}
+
+func (s *UserSteps) iRequestTheAuditLogOfTheUser() error {
+	if s.testUserID == "" {
+		return fmt.Errorf("no test user ID available")
+	}
+	return s.client.Get("/audit?entity_type=user&entity_id=" + s.testUserID)
+}
+
+func (s *UserSteps) iDeleteMyAccount() error {
+	return s.client.Delete("/users/me")
+}
+
+func (s *UserSteps) iCancelTheDeletionOfMyAccount() error {
+	return s.client.Delete("/users/me/deletion")
+}
+
 func (s *UserSteps) iTryToAccessUserEndpointsWithoutAuthentication() error {
 	// Clear authentication
 	s.client.AuthToken = ""
@@ -646,20 +755,20 @@ func (s *UserSteps) iShouldBeAbleToLoginWithTheNewPassword() error {
 	return nil
 }
 
-func (s *UserSteps) theUserShouldBeRemovedFromTheSystem() error {
+func (s *UserSteps) theUserShouldBeScheduledForDeletion() error {
 	if s.testUserID == "" {
 		return fmt.Errorf("no test user ID available")
 	}
 
-	// Check that user no longer exists in database
+	// The user is kept until the grace period is over, with a pending deletion
 	var count int
-	err := s.db.Get(&count, "SELECT COUNT(*) FROM users WHERE id = $1", s.testUserID)
+	err := s.db.Get(&count, "SELECT COUNT(*) FROM account_deletions WHERE user_id = $1 AND cancelled_at IS NULL", s.testUserID)
 	if err != nil {
-		return fmt.Errorf("failed to check user in database: %v", err)
+		return fmt.Errorf("failed to check account deletion in database: %v", err)
 	}
 
-	if count != 0 {
-		return fmt.Errorf("user was not removed from database")
+	if count != 1 {
+		return fmt.Errorf("user was not scheduled for deletion")
 	}
 
 	return nil
@@ -682,3 +791,33 @@ func (s *UserSteps) allMyTokensShouldBeInvalidated() error {
 
 	return nil
 }
+
+func (s *UserSteps) theResponseShouldContainMyCurrentSession() error {
+	var sessions []map[string]interface{}
+	if err := json.Unmarshal(s.client.GetResponseBody(), &sessions); err != nil {
+		return fmt.Errorf("response is not a list of sessions: %v", err)
+	}
+
+	for _, session := range sessions {
+		if current, _ := session["current"].(bool); current {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("current session not found in %d sessions", len(sessions))
+}
+
+func (s *UserSteps) theAuditLogShouldContainTheAction(action string) error {
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(s.client.GetResponseBody(), &entries); err != nil {
+		return fmt.Errorf("response is not a list of audit log entries: %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry["action"] == action && entry["hash"] != "" {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s not found in %d audit log entries", action, len(entries))
+}